@@ -0,0 +1,135 @@
+// Package audit reports actions that use an old version of another
+// repository, for a scheduled job to flag before they fall too far
+// behind.
+//
+//	config, err := parser.ParseFile("main.workflow")
+//	...
+//	updates, err := audit.Outdated(config, audit.NewGitResolver(lock.GitHub))
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/actions/workflow-parser/lock"
+	"github.com/actions/workflow-parser/model"
+)
+
+// Resolver finds what refs name in a repository, and the newest version
+// of it.  Resolve is as for lock.Resolver.
+//
+// Latest returns the newest release of repository and the commit SHA it
+// names: the tag with the highest version, like `v1.2.3', or the default
+// branch if the repository has no such tags.
+type Resolver interface {
+	lock.Resolver
+	Latest(repository string) (ref, sha string, err error)
+}
+
+// Update is an action whose ref is behind the latest release of its
+// repository.
+type Update struct {
+	Action *model.Action
+	Uses   *model.UsesRepository
+
+	// Latest is the ref of the latest release, and SHA the commit it
+	// names.
+	Latest string
+	SHA    string
+}
+
+func (u *Update) String() string {
+	return fmt.Sprintf("action `%s' uses `%s', but the latest is `%s'", u.Action.Identifier, u.Uses, u.Latest)
+}
+
+// Outdated returns the actions in c that use another repository at a
+// ref other than its latest release, in the order of c.Actions.  A ref
+// is up to date if it names the same commit as the latest release, or if
+// it is a version tag no older than it.  Each repository and ref is
+// resolved once.  Outdated stops at the first error from r.
+func Outdated(c *model.Configuration, r Resolver) ([]*Update, error) {
+	type latest struct{ ref, sha string }
+	latests := make(map[string]latest)
+	current := make(map[string]string)
+
+	var ret []*Update
+	for _, action := range c.Actions {
+		repo, ok := action.Uses.(*model.UsesRepository)
+		if !ok {
+			continue
+		}
+
+		l, ok := latests[repo.Repository]
+		if !ok {
+			var err error
+			l.ref, l.sha, err = r.Latest(repo.Repository)
+			if err != nil {
+				return nil, fmt.Errorf("action `%s': cannot find the latest release of `%s': %s", action.Identifier, repo.Repository, err)
+			}
+			latests[repo.Repository] = l
+		}
+
+		key := repo.Repository + "@" + repo.Ref
+		sha, ok := current[key]
+		if !ok {
+			var err error
+			sha, err = r.Resolve(repo.Repository, repo.Ref)
+			if err != nil {
+				return nil, fmt.Errorf("action `%s': cannot resolve `%s': %s", action.Identifier, key, err)
+			}
+			current[key] = sha
+		}
+
+		if strings.EqualFold(sha, l.sha) || !olderVersion(repo.Ref, l.ref) {
+			continue
+		}
+		ret = append(ret, &Update{Action: action, Uses: repo, Latest: l.ref, SHA: l.sha})
+	}
+	return ret, nil
+}
+
+// olderVersion returns whether ref is older than latest.  Refs that are
+// not both versions are always older, since they named different
+// commits.
+func olderVersion(ref, latest string) bool {
+	a, ok := ParseVersion(ref)
+	if !ok {
+		return true
+	}
+	b, ok := ParseVersion(latest)
+	if !ok {
+		return true
+	}
+	return compareVersions(a, b) < 0
+}
+
+// ParseVersion parses a release tag like `v1', `v1.2', or `1.2.3' into
+// its major, minor, and patch numbers, with missing numbers zero.
+func ParseVersion(tag string) ([3]int, bool) {
+	var ret [3]int
+	parts := strings.Split(strings.TrimPrefix(tag, "v"), ".")
+	if len(parts) > len(ret) {
+		return ret, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part[0] == '+' {
+			return ret, false
+		}
+		ret[i] = n
+	}
+	return ret, true
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/lock"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver resolves refs from a map of repositories to their refs.
+type fakeResolver map[string]*lock.RemoteRefs
+
+func (f fakeResolver) Resolve(repository, ref string) (string, error) {
+	if lock.IsSHA(ref) {
+		return ref, nil
+	}
+	refs, ok := f[repository]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return refs.Resolve(ref)
+}
+
+func (f fakeResolver) Latest(repository string) (string, string, error) {
+	refs, ok := f[repository]
+	if !ok {
+		return "", "", errors.New("not found")
+	}
+	ref, sha := latest(refs)
+	return ref, sha, nil
+}
+
+func sha(c byte) string {
+	return strings.Repeat(string(c), 40)
+}
+
+func TestOutdated(t *testing.T) {
+	r := fakeResolver{
+		"tagged/repo": {Head: "refs/heads/master", Refs: map[string]string{
+			"refs/heads/master": sha('a'),
+			"refs/tags/v1":      sha('1'),
+			"refs/tags/v1.0.0":  sha('1'),
+			"refs/tags/v2":      sha('2'),
+			"refs/tags/v2.1":    sha('2'),
+			"refs/tags/v2.1.0":  sha('2'),
+			"refs/tags/nightly": sha('a'),
+		}},
+		"branch/repo": {Head: "refs/heads/main", Refs: map[string]string{
+			"refs/heads/main":    sha('b'),
+			"refs/heads/feature": sha('c'),
+		}},
+	}
+
+	src := `workflow "w" {
+  on = "push"
+  resolves = ["a", "b", "c", "d", "e", "f", "g"]
+}
+action "a" { uses = "tagged/repo@v1" }
+action "b" { uses = "tagged/repo@v2" }
+action "c" { uses = "tagged/repo/path@v2.1.0" }
+action "d" { uses = "tagged/repo@master" }
+action "e" { uses = "branch/repo@main" }
+action "f" { uses = "branch/repo@feature" }
+action "g" { uses = "tagged/repo@2222222222222222222222222222222222222222" }
+`
+	c, err := parser.Parse(strings.NewReader(src))
+	require.NoError(t, err)
+
+	updates, err := Outdated(c, r)
+	require.NoError(t, err)
+	var got []string
+	for _, u := range updates {
+		got = append(got, u.String())
+	}
+	assert.Equal(t, []string{
+		"action `a' uses `tagged/repo@v1', but the latest is `v2.1.0'",
+		"action `d' uses `tagged/repo@master', but the latest is `v2.1.0'",
+		"action `f' uses `branch/repo@feature', but the latest is `main'",
+	}, got)
+	assert.Equal(t, sha('2'), updates[0].SHA)
+	assert.Equal(t, c.Actions[0], updates[0].Action)
+
+	delete(r, "branch/repo")
+	_, err = Outdated(c, r)
+	assert.EqualError(t, err, "action `e': cannot find the latest release of `branch/repo': not found")
+}
+
+func TestParseVersion(t *testing.T) {
+	for tag, want := range map[string][3]int{
+		"v1":      {1, 0, 0},
+		"v1.2":    {1, 2, 0},
+		"1.2.3":   {1, 2, 3},
+		"v10.0.1": {10, 0, 1},
+	} {
+		v, ok := ParseVersion(tag)
+		assert.True(t, ok, tag)
+		assert.Equal(t, want, v, tag)
+	}
+	for _, tag := range []string{"", "v", "master", "v1.2.3.4", "v1.-2", "v1.2-beta", "v+1"} {
+		_, ok := ParseVersion(tag)
+		assert.False(t, ok, tag)
+	}
+}
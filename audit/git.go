@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actions/workflow-parser/lock"
+)
+
+// NewGitResolver returns a Resolver that lists the refs of baseURL
+// followed by the repository name with lock.ListRemote, once per
+// repository.
+func NewGitResolver(baseURL string) Resolver {
+	return &gitResolver{baseURL: baseURL, remotes: make(map[string]*lock.RemoteRefs)}
+}
+
+type gitResolver struct {
+	baseURL string
+	remotes map[string]*lock.RemoteRefs
+}
+
+func (g *gitResolver) list(repository string) (*lock.RemoteRefs, error) {
+	if refs, ok := g.remotes[repository]; ok {
+		return refs, nil
+	}
+	refs, err := lock.ListRemote(g.baseURL + repository)
+	if err != nil {
+		return nil, err
+	}
+	g.remotes[repository] = refs
+	return refs, nil
+}
+
+func (g *gitResolver) Resolve(repository, ref string) (string, error) {
+	if lock.IsSHA(ref) {
+		return ref, nil
+	}
+	refs, err := g.list(repository)
+	if err != nil {
+		return "", err
+	}
+	return refs.Resolve(ref)
+}
+
+func (g *gitResolver) Latest(repository string) (string, string, error) {
+	refs, err := g.list(repository)
+	if err != nil {
+		return "", "", err
+	}
+	ref, sha := latest(refs)
+	if ref == "" {
+		return "", "", fmt.Errorf("no release tags or default branch")
+	}
+	return ref, sha, nil
+}
+
+// latest returns the tag in refs with the highest version, or the
+// default branch if there is none.
+func latest(refs *lock.RemoteRefs) (string, string) {
+	var best string
+	var bestVersion [3]int
+	for name := range refs.Refs {
+		if !strings.HasPrefix(name, "refs/tags/") {
+			continue
+		}
+		tag := strings.TrimPrefix(name, "refs/tags/")
+		v, ok := ParseVersion(tag)
+		if !ok {
+			continue
+		}
+		// Prefer the most specific tag, e.g. v1.2.0 over v1.2.
+		if c := compareVersions(v, bestVersion); best == "" || c > 0 || c == 0 && len(tag) > len(best) {
+			best, bestVersion = tag, v
+		}
+	}
+	if best != "" {
+		return best, refs.Refs["refs/tags/"+best]
+	}
+	if sha, ok := refs.Refs[refs.Head]; ok {
+		return strings.TrimPrefix(refs.Head, "refs/heads/"), sha
+	}
+	return "", ""
+}
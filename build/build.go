@@ -0,0 +1,233 @@
+// Package build constructs Configurations in Go, for tests and for
+// programs that generate .workflow files, without writing HCL by hand:
+//
+//	config, err := build.NewConfig().
+//		Workflow("ci").On("push").Resolves("test").
+//		Action("test").Uses("./test").Needs("lint").
+//		Action("lint").Uses("docker://golang:1.11").Runs("go", "vet", "./...").
+//		Build()
+//
+// Build checks the result as parser.Parse would check the same file.
+package build
+
+import (
+	"bytes"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+)
+
+// Config builds a Configuration.  Its Action and Workflow methods start a
+// new block, which the methods of Action and Workflow fill in, and Build
+// returns the result.
+type Config struct {
+	config *model.Configuration
+}
+
+// NewConfig returns a Config with no actions or workflows.
+func NewConfig() *Config {
+	return &Config{config: &model.Configuration{}}
+}
+
+// Action adds an action with the given identifier, after any blocks
+// already added, and returns it to fill in.
+func (c *Config) Action(id string) *Action {
+	a := &model.Action{Identifier: id}
+	c.config.Actions = append(c.config.Actions, a)
+	return &Action{Config: c, action: a}
+}
+
+// Template adds a template with the given identifier, after any
+// templates already added, and returns it to fill in like an action.
+func (c *Config) Template(id string) *Action {
+	t := &model.Action{Identifier: id}
+	c.config.Templates = append(c.config.Templates, t)
+	return &Action{Config: c, action: t}
+}
+
+// Workflow adds a workflow with the given identifier, after any workflows
+// already added, and returns it to fill in.
+func (c *Config) Workflow(id string) *Workflow {
+	w := &model.Workflow{Identifier: id}
+	c.config.Workflows = append(c.config.Workflows, w)
+	return &Workflow{Config: c, workflow: w}
+}
+
+// Env sets a variable in the file's top-level `env' block, which applies
+// to every action.
+func (c *Config) Env(name, value string) *Config {
+	if c.config.Env == nil {
+		c.config.Env = make(map[string]string)
+	}
+	c.config.Env[name] = value
+	return c
+}
+
+// Build declares the lowest version of the language that has every
+// feature the configuration uses, then checks it with parser.Validate,
+// passing it options, and returns it if there are no problems more
+// severe than INFO, as parser.Parse would.  Otherwise it returns an *Error.  Build
+// hands over the configuration it built, so c shouldn't be changed or
+// built again afterwards.
+func (c *Config) Build(options ...parser.OptionFunc) (*model.Configuration, error) {
+	c.config.Version = c.config.RequiredVersion()
+	errs := parser.Validate(c.config, options...)
+	if problems := errs.FilterSeverity(parser.WARNING); len(problems) > 0 {
+		return nil, &Error{Errors: problems}
+	}
+	return c.config, nil
+}
+
+// Action fills in an action.  Its methods return it, so calls can be
+// chained, and it embeds the Config it belongs to, so the chain can go
+// on to the next block or to Build.
+type Action struct {
+	*Config
+	action *model.Action
+}
+
+// Description sets the action's description, a summary for people to
+// read.
+func (a *Action) Description(description string) *Action {
+	a.action.Description = description
+	return a
+}
+
+// Uses sets the action's `uses' attribute: a path like "./x", a Docker
+// image like "docker://alpine", or a repository like "owner/repo@ref".
+func (a *Action) Uses(uses string) *Action {
+	a.action.Uses = model.ParseUses(uses)
+	return a
+}
+
+// Needs adds to the actions the action needs.
+func (a *Action) Needs(ids ...string) *Action {
+	a.action.Needs = append(a.action.Needs, ids...)
+	return a
+}
+
+// Runs sets the action's `runs' attribute to a list of the given
+// arguments.
+func (a *Action) Runs(args ...string) *Action {
+	a.action.Runs = &model.ListCommand{Values: args}
+	return a
+}
+
+// Args sets the action's `args' attribute to a list of the given
+// arguments.
+func (a *Action) Args(args ...string) *Action {
+	a.action.Args = &model.ListCommand{Values: args}
+	return a
+}
+
+// Env sets an environment variable for the action.
+func (a *Action) Env(name, value string) *Action {
+	if a.action.Env == nil {
+		a.action.Env = make(map[string]string)
+	}
+	a.action.Env[name] = value
+	return a
+}
+
+// Matrix adds a dimension to the action's matrix, which makes it stand for
+// an action for each combination of values.
+func (a *Action) Matrix(name string, values ...string) *Action {
+	if a.action.Matrix == nil {
+		a.action.Matrix = make(map[string][]string)
+	}
+	a.action.Matrix[name] = values
+	return a
+}
+
+// Extends makes the action take the attributes it doesn't set from the
+// template with the given identifier.
+func (a *Action) Extends(template string) *Action {
+	a.action.Extends = template
+	return a
+}
+
+// If sets the condition under which the action runs, an expression like
+// "github.ref == 'refs/heads/master'".
+func (a *Action) If(cond string) *Action {
+	a.action.If = cond
+	return a
+}
+
+// ContinueOnError lets the workflow go on even if the action fails.
+func (a *Action) ContinueOnError() *Action {
+	a.action.ContinueOnError = true
+	return a
+}
+
+// Secrets adds to the secrets the action can read.
+func (a *Action) Secrets(names ...string) *Action {
+	a.action.Secrets = append(a.action.Secrets, names...)
+	return a
+}
+
+// Workflow fills in a workflow.  Like Action, its methods return it, and
+// it embeds the Config it belongs to.
+type Workflow struct {
+	*Config
+	workflow *model.Workflow
+}
+
+// Description sets the workflow's description, a summary for people to
+// read.
+func (w *Workflow) Description(description string) *Workflow {
+	w.workflow.Description = description
+	return w
+}
+
+// On sets the events that start the workflow, like "push" or
+// "pull_request.opened".  With more than one, it is as if they were
+// written as a list, `on = ["push", "release"]'.
+func (w *Workflow) On(events ...string) *Workflow {
+	w.workflow.On = nil
+	if len(events) > 0 {
+		w.workflow.On = model.ParseEvents(events...)
+	}
+	return w
+}
+
+// Resolves adds to the actions the workflow resolves.
+func (w *Workflow) Resolves(ids ...string) *Workflow {
+	w.workflow.Resolves = append(w.workflow.Resolves, ids...)
+	return w
+}
+
+// Env sets an environment variable for the actions the workflow
+// resolves.
+func (w *Workflow) Env(name, value string) *Workflow {
+	if w.workflow.Env == nil {
+		w.workflow.Env = make(map[string]string)
+	}
+	w.workflow.Env[name] = value
+	return w
+}
+
+// Error is the error Build returns for a configuration with problems.
+type Error struct {
+	Errors parser.ErrorList
+}
+
+func (e *Error) Error() string {
+	buffer := bytes.NewBufferString("invalid configuration")
+	for _, pe := range e.Errors {
+		buffer.WriteString("\n  ")
+		buffer.WriteString(pe.Error())
+	}
+	return buffer.String()
+}
+
+// Is reports whether any of the problems matches target, so, e.g.,
+// errors.Is(err, parser.CodeUnknownNeeds) says whether an action needs
+// one that doesn't exist.
+func (e *Error) Is(target error) bool {
+	for _, pe := range e.Errors {
+		if pe.Is(target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package build
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/printer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	config, err := NewConfig().Env("GOPATH", "/go").
+		Workflow("ci").On("push").Resolves("test").Env("STAGE", "ci").
+		Action("test").Uses("./test").Needs("lint").Env("CI", "1").Secrets("TOKEN").
+		Action("lint").Uses("docker://golang:1.11").Runs("go", "vet").Args("./...").
+		Build()
+	require.NoError(t, err)
+
+	require.Len(t, config.Actions, 2)
+	require.Len(t, config.Workflows, 1)
+	assert.Equal(t, []string{"push"}, model.EventStrings(config.Workflows[0].On))
+	assert.Equal(t, &model.UsesPath{Path: "test"}, config.Actions[0].Uses)
+	assert.Equal(t, map[string]string{"CI": "1"}, config.Actions[0].Env)
+	assert.Equal(t, map[string]string{"GOPATH": "/go"}, config.Env)
+	assert.Equal(t, map[string]string{"STAGE": "ci"}, config.Workflows[0].Env)
+	assert.Equal(t, []string{"go", "vet"}, config.Actions[1].Runs.Split())
+
+	parsed, err := parser.Parse(strings.NewReader(printer.String(config)))
+	require.NoError(t, err)
+	assert.Equal(t, printer.String(config), printer.String(parsed))
+}
+
+func TestBuildEvents(t *testing.T) {
+	config, err := NewConfig().
+		Workflow("w").On("push", "release.published").Resolves("a").
+		Action("a").Uses("./a").
+		Build()
+	require.NoError(t, err)
+	w := config.Workflows[0]
+	assert.Equal(t, []string{"push", "release.published"}, model.EventStrings(w.On))
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, err := NewConfig().
+		Workflow("w").On("shove").Resolves("a").
+		Action("a").Uses("nope").Needs("b").
+		Build()
+	require.Error(t, err)
+
+	var buildErr *Error
+	require.True(t, errors.As(err, &buildErr))
+	assert.Len(t, buildErr.Errors, 3)
+	assert.True(t, errors.Is(err, parser.CodeUnknownNeeds))
+	assert.True(t, errors.Is(err, parser.CodeUsesInvalid))
+	assert.Contains(t, err.Error(), "Workflow `w' has unknown `on' value `shove'")
+
+	_, err = NewConfig().
+		Workflow("w").On("push").Resolves("a").
+		Action("a").Uses("./a").
+		Build(parser.WithoutChecks(parser.AllChecks))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/actions/workflow-parser/parser"
+)
+
+// annotateFile parses fn and prints each problem with it as a GitHub
+// Actions workflow command, like
+//
+//	::error file=a.workflow,line=3,col=5,title=E_UNKNOWN_NEEDS::Action `b' needs nonexistent action `c'
+//
+// so that a validation step in a CI job annotates the lines of a pull
+// request.  It returns false if the file has any problems at or above
+// failOn.
+func annotateFile(w io.Writer, fn string, failOn parser.Severity, options ...parser.OptionFunc) bool {
+	_, err := parsePath(fn, options...)
+	if err == nil {
+		return true
+	}
+	pe, ok := err.(*parser.Error)
+	if !ok {
+		writeAnnotation(w, "error", displayName(fn), parser.ErrorPos{}, "", err.Error())
+		return false
+	}
+	for _, e := range pe.Errors {
+		command := "error"
+		switch e.Severity {
+		case parser.INFO:
+			command = "notice"
+		case parser.WARNING:
+			command = "warning"
+		}
+		writeAnnotation(w, command, displayName(fn), e.Pos, string(e.Code), e.Message())
+	}
+	return pe.FirstError(failOn) == nil
+}
+
+func writeAnnotation(w io.Writer, command, fn string, pos parser.ErrorPos, title, message string) {
+	props := []string{"file=" + escapeProperty(fn)}
+	if pos.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", pos.Line))
+		if pos.Column > 0 {
+			props = append(props, fmt.Sprintf("col=%d", pos.Column))
+		}
+	}
+	if title != "" {
+		props = append(props, "title="+escapeProperty(title))
+	}
+	fmt.Fprintf(w, "::%s %s::%s\n", command, strings.Join(props, ","), escapeData(message))
+}
+
+// escapeData escapes the message of a workflow command, which ends at the
+// first newline.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command, which
+// also can't contain the `:' and `,' that separate properties.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "annotations")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "a,b.workflow")
+	require.NoError(t, ioutil.WriteFile(fn, []byte(`action "a" {
+  uses = "./a"
+  needs = "c"
+  bogus = "x"
+}
+`), 0644))
+
+	var buf bytes.Buffer
+	assert.False(t, annotateFile(&buf, fn, parser.ERROR))
+	escaped := filepath.Join(dir, "a%2Cb.workflow")
+	assert.Equal(t,
+		"::error file="+escaped+",line=3,col=11,title=E_UNKNOWN_NEEDS::Action `a' needs nonexistent action `c'\n"+
+			"::warning file="+escaped+",line=4,col=11,title=W_UNKNOWN_ATTRIBUTE::Unknown action attribute `bogus'\n",
+		buf.String())
+
+	buf.Reset()
+	assert.False(t, annotateFile(&buf, filepath.Join(dir, "missing.workflow"), parser.ERROR))
+	assert.Contains(t, buf.String(), "::error file=")
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, "100%25%0Adone", escapeData("100%\ndone"))
+	assert.Equal(t, "a%3Ab%2Cc", escapeProperty("a:b,c"))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/actions/workflow-parser/audit"
+	"github.com/actions/workflow-parser/lock"
+)
+
+// auditFiles reports the actions in each file named in args that use an
+// old version of another repository, exiting with an error if there are
+// any.
+func auditFiles(args []string) {
+	flags := flag.NewFlagSet(os.Args[0]+" audit", flag.ExitOnError)
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		usage()
+	}
+
+	resolver := audit.NewGitResolver(lock.GitHub)
+	outdated := 0
+	for _, fn := range flags.Args() {
+		config := mustParse(fn)
+		updates, err := audit.Outdated(config, resolver)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", fn, err)
+			os.Exit(1)
+		}
+		for _, u := range updates {
+			fmt.Printf("%s:%d: %s\n", fn, u.Action.AttributePos("uses").Line, u)
+		}
+		outdated += len(updates)
+	}
+	if outdated > 0 {
+		fmt.Println(plural(outdated, "outdated action"))
+		os.Exit(1)
+	}
+}
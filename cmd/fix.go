@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/actions/workflow-parser/parser"
+)
+
+// fixFiles applies the suggested fixes for each file named in args, in
+// place, or prints them as a unified diff with --diff.
+func fixFiles(args []string) {
+	flags := flag.NewFlagSet(os.Args[0]+" fix", flag.ExitOnError)
+	diff := flags.Bool("diff", false, "print the fixes as a diff instead of applying them")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		usage()
+	}
+
+	for _, fn := range flags.Args() {
+		src, err := ioutil.ReadFile(fn)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fixed, n := fix(src)
+		switch {
+		case *diff:
+			writeDiff(os.Stdout, fn, src, fixed)
+		case n == 0:
+			fmt.Println(fn, "has nothing to fix")
+		default:
+			if err := ioutil.WriteFile(fn, fixed, 0644); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Println("fixed", plural(n, "problem"), "in", fn)
+		}
+	}
+}
+
+// maxFixPasses bounds how many times fix reparses a file, in case a fix
+// keeps producing new problems.
+const maxFixPasses = 10
+
+// fix applies every available fix to src, reparsing after each pass
+// since fixes can overlap or reveal other problems.  It returns the
+// result and the number of fixes applied.
+func fix(src []byte) ([]byte, int) {
+	total := 0
+	for pass := 0; pass < maxFixPasses; pass++ {
+		_, errors, err := parser.ParseWithDiagnostics(bytes.NewReader(src))
+		if err != nil {
+			break
+		}
+		var fixes []*parser.Fix
+		for _, e := range errors {
+			if e.Fix != nil {
+				fixes = append(fixes, e.Fix)
+			}
+		}
+		fixed, n := parser.ApplyFixes(src, fixes)
+		if n == 0 {
+			break
+		}
+		src, total = fixed, total+n
+	}
+	return src, total
+}
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// writeDiff prints the difference between a and b as a unified diff, or
+// nothing if they are the same.
+func writeDiff(w io.Writer, fn string, a, b []byte) {
+	if bytes.Equal(a, b) {
+		return
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", fn, fn)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk over every change within 2*diffContext lines of
+		// the previous one.
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops) && j <= end+2*diffContext; j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			}
+		}
+		end += diffContext + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		hunk := ops[start:end]
+		aStart, bStart := hunk[0].a+1, hunk[0].b+1
+		aLen, bLen := 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		for _, op := range hunk {
+			fmt.Fprintf(w, "%c%s\n", op.kind, op.line)
+		}
+		i = end
+	}
+}
+
+func hunkRange(start, n int) string {
+	if n == 0 {
+		start--
+	}
+	if n == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, n)
+}
+
+func splitLines(b []byte) []string {
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+// diffOp is one line of a diff: kept (' '), removed ('-'), or added
+// ('+').  a and b are the indexes of the line in the old and new text, or
+// of the next line for one that isn't in that text.
+type diffOp struct {
+	kind byte
+	line string
+	a, b int
+}
+
+// diffLines returns the shortest edit script from a to b, computed from
+// their longest common subsequence.  Workflow files are small, so the
+// quadratic table is fine.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		default:
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		}
+	}
+	return ops
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFix(t *testing.T) {
+	src := "action \"a\" {\n  uses = \"./a\"\n  need = \"b\"\n}\n\naction b {\n  uses = \"./b\"\n}\n"
+	fixed, n := fix([]byte(src))
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "action \"a\" {\n  uses = \"./a\"\n  needs = \"b\"\n}\n\naction \"b\" {\n  uses = \"./b\"\n}\n", string(fixed))
+
+	fixed, n = fix(fixed)
+	assert.Equal(t, 0, n)
+
+	var buf bytes.Buffer
+	writeDiff(&buf, "a.workflow", []byte(src), fixed)
+	assert.Equal(t, `--- a.workflow
++++ a.workflow
+@@ -1,8 +1,8 @@
+ action "a" {
+   uses = "./a"
+-  need = "b"
++  needs = "b"
+ }
+ 
+-action b {
++action "b" {
+   uses = "./b"
+ }
+`, buf.String())
+}
+
+func TestWriteDiffHunks(t *testing.T) {
+	a := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	b := []byte("1\nTWO\n3\n4\n5\n6\n7\n8\n9\n10\n12\n")
+
+	var buf bytes.Buffer
+	writeDiff(&buf, "f", a, b)
+	assert.Equal(t, `--- f
++++ f
+@@ -1,5 +1,5 @@
+ 1
+-2
++TWO
+ 3
+ 4
+ 5
+@@ -8,5 +8,4 @@
+ 8
+ 9
+ 10
+-11
+ 12
+`, buf.String())
+
+	buf.Reset()
+	writeDiff(&buf, "f", a, a)
+	assert.Empty(t, buf.String())
+}
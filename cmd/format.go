@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/actions/workflow-parser/printer"
+)
+
+// formatFiles reformats each file named in args, printing the result,
+// or with -w writing it back in place, or with -d printing a diff.
+func formatFiles(args []string) {
+	flags := flag.NewFlagSet(os.Args[0]+" fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write the result to the file instead of standard output")
+	diff := flags.Bool("d", false, "print a diff instead of the formatted file")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		usage()
+	}
+
+	for _, fn := range flags.Args() {
+		src, err := ioutil.ReadFile(fn)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		formatted, err := printer.Format(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", fn, err)
+			os.Exit(1)
+		}
+		switch {
+		case *diff:
+			writeDiff(os.Stdout, fn, src, formatted)
+		case *write:
+			if bytes.Equal(src, formatted) {
+				continue
+			}
+			if err := ioutil.WriteFile(fn, formatted, 0644); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Println(fn)
+		default:
+			os.Stdout.Write(formatted)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/actions/workflow-parser/lock"
+	"github.com/actions/workflow-parser/printer"
+)
+
+// lockFile resolves the repository refs in the file named in args and
+// writes them to a lockfile, or with -verify checks the file against an
+// existing lockfile.
+func lockFile(args []string) {
+	flags := flag.NewFlagSet(os.Args[0]+" lock", flag.ExitOnError)
+	lockfile := flags.String("lockfile", "", "lockfile `path` (default: the workflow file with .lock appended)")
+	write := flags.Bool("w", false, "also rewrite the workflow file to use the locked SHAs")
+	verify := flags.Bool("verify", false, "fail if the workflow file doesn't match the lockfile")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	fn := flags.Arg(0)
+	if *lockfile == "" {
+		*lockfile = fn + ".lock"
+	}
+	config := mustParse(fn)
+	resolver := lock.NewGitResolver(lock.GitHub)
+
+	if *verify {
+		f, err := os.Open(*lockfile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		l, err := lock.Read(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", *lockfile, err)
+			os.Exit(1)
+		}
+		if err := lock.Verify(config, l, resolver); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", fn, err)
+			os.Exit(1)
+		}
+		fmt.Println(fn, "matches", *lockfile)
+		return
+	}
+
+	l, err := lock.Lock(config, resolver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", fn, err)
+		os.Exit(1)
+	}
+	f, err := os.Create(*lockfile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	err = l.Write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("wrote", *lockfile)
+
+	if *write {
+		if err := ioutil.WriteFile(fn, []byte(printer.String(config)), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("wrote", fn)
+	}
+}
@@ -1,39 +1,283 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/actions/workflow-parser/converter"
+	"github.com/actions/workflow-parser/daemon"
+	"github.com/actions/workflow-parser/lsp"
+	"github.com/actions/workflow-parser/model"
 	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/report"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage:")
-		fmt.Println("  " + os.Args[0] + " filename.workflow...")
-		os.Exit(1)
+	var command string
+	if len(os.Args) > 1 {
+		command = os.Args[1]
 	}
 
-	for _, fn := range os.Args[1:] {
-		parseFile(fn)
+	switch command {
+	case "daemon":
+		if err := daemon.NewServer().Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "lsp":
+		if err := lsp.NewServer().Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "fix":
+		fixFiles(os.Args[2:])
+	case "fmt":
+		formatFiles(os.Args[2:])
+	case "lock":
+		lockFile(os.Args[2:])
+	case "audit":
+		auditFiles(os.Args[2:])
+	case "plan":
+		planFile(os.Args[2:])
+	case "convert":
+		if len(os.Args) < 3 || len(os.Args) > 4 {
+			usage()
+		}
+		dir := ""
+		if len(os.Args) == 4 {
+			dir = os.Args[3]
+		}
+		convertFile(os.Args[2], dir)
+	case "schema":
+		if len(os.Args) != 2 {
+			usage()
+		}
+		b, err := parser.JSONSchema()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(string(b))
+	default:
+		validate(os.Args[1:])
 	}
 }
 
-func parseFile(fn string) {
-	file, err := os.Open(fn)
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  " + os.Args[0] + " [--format text|json|checkstyle|annotations] [--fail-on severity] [--baseline file [--write-baseline]] [--dedup n] [--hcl2] [filename.workflow... | -]")
+	fmt.Println("  " + os.Args[0] + " --recursive [flags] [directory...]")
+	fmt.Println("  " + os.Args[0] + " fix [--diff] filename.workflow...")
+	fmt.Println("  " + os.Args[0] + " fmt [-w] [-d] filename.workflow...")
+	fmt.Println("  " + os.Args[0] + " lock [-w] [-verify] [-lockfile path] filename.workflow")
+	fmt.Println("  " + os.Args[0] + " audit filename.workflow...")
+	fmt.Println("  " + os.Args[0] + " plan [--event type[.activity]] [--payload file] [--lockfile path] filename.workflow")
+	fmt.Println("  " + os.Args[0] + " convert filename.workflow [directory]")
+	fmt.Println("  " + os.Args[0] + " schema")
+	fmt.Println("  " + os.Args[0] + " daemon")
+	fmt.Println("  " + os.Args[0] + " lsp")
+	os.Exit(1)
+}
+
+// validate checks each file named in args, printing the result in the
+// format chosen by the --format flag.
+func validate(args []string) {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	format := flags.String("format", "text", "output `format`: text, json, checkstyle, or annotations for GitHub Actions")
+	failOn := flags.String("fail-on", "error", "with json, checkstyle, or annotations, exit with an error for problems at or above `severity`: info, warning, error, or fatal")
+	baseline := flags.String("baseline", "", "ignore the problems recorded in the baseline `file`")
+	writeBaseline := flags.Bool("write-baseline", false, "record the current problems in the -baseline file instead of reporting them")
+	dedup := flags.Int("dedup", 0, "report problems with the same code and message once, listing where the first `n` of them are")
+	hcl2 := flags.Bool("hcl2", false, "read files written in HCL, rather than JSON, in the newer HCL2 syntax")
+	recursive := flags.Bool("recursive", false, "check every .workflow file in the given directories and their subdirectories")
+	_ = flags.Parse(args)
+	files := flags.Args()
+	if *recursive {
+		files = discoverFiles(files)
+	} else if len(files) == 0 {
+		if !stdinPiped() {
+			usage()
+		}
+		files = []string{"-"}
+	}
+
+	var options []parser.OptionFunc
+	if *writeBaseline {
+		if *baseline == "" {
+			usage()
+		}
+		writeBaselineFile(*baseline, files)
+		return
+	}
+	if *baseline != "" {
+		options = append(options, parser.WithBaseline(*baseline))
+	}
+	if *dedup > 0 {
+		options = append(options, parser.WithDeduplication(*dedup))
+	}
+	if *hcl2 {
+		options = append(options, parser.WithSyntax(parser.HCL2))
+	}
+	threshold, err := parser.ParseSeverity(*failOn)
 	if err != nil {
-		panic(err)
+		usage()
 	}
-	defer file.Close()
 
-	config, err := parser.Parse(file)
+	switch *format {
+	case "text":
+		invalid := 0
+		for _, fn := range files {
+			if !parseFile(fn, options...) {
+				invalid++
+			}
+		}
+		if *recursive {
+			fmt.Println("checked", plural(len(files), "file")+",", invalid, "invalid")
+		}
+		if invalid > 0 {
+			os.Exit(1)
+		}
+	case "json":
+		if !writeJSON(os.Stdout, files, threshold, options...) {
+			os.Exit(1)
+		}
+	case "checkstyle":
+		if !writeCheckstyle(os.Stdout, files, threshold, options...) {
+			os.Exit(1)
+		}
+	case "annotations":
+		ok := true
+		for _, fn := range files {
+			if !annotateFile(os.Stdout, fn, threshold, options...) {
+				ok = false
+			}
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
 
+// parseFile checks fn, printing whether it is valid, and returns false
+// if it isn't.
+func parseFile(fn string, options ...parser.OptionFunc) bool {
+	config, err := parsePath(fn, options...)
 	if err != nil {
-		fmt.Println(err)
+		printError(fn, err)
+		return false
+	}
+	fmt.Println(displayName(fn), "is a valid file with", plural(len(config.Actions), "action"), "and", plural(len(config.Workflows), "workflow"))
+	return true
+}
+
+// discoverFiles returns the .workflow files in each of dirs, or in the
+// current directory if there are none, exiting if there are no files.
+func discoverFiles(dirs []string) []string {
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	var ret []string
+	for _, dir := range dirs {
+		paths, err := parser.DiscoverWorkflows(os.DirFS(dir))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for _, path := range paths {
+			ret = append(ret, filepath.Join(dir, filepath.FromSlash(path)))
+		}
+	}
+	if len(ret) == 0 {
+		fmt.Fprintln(os.Stderr, "no .workflow files in", strings.Join(dirs, ", "))
 		os.Exit(1)
 	}
+	return ret
+}
+
+// convertFile converts each workflow in fn to a YAML file in dir, or to
+// standard output, separated by document markers, if dir is blank.
+func convertFile(fn, dir string) {
+	config := mustParse(fn)
 
-	fmt.Println(fn, "is a valid file with", plural(len(config.Actions), "action"), "and", plural(len(config.Workflows), "workflow"))
+	for i, file := range converter.Convert(config) {
+		if dir == "" {
+			if i > 0 {
+				fmt.Println("---")
+			}
+			fmt.Printf("# %s\n", file.Name)
+			if err := converter.Write(os.Stdout, file.Workflow); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name)
+		if err := ioutil.WriteFile(path, []byte(converter.String(file.Workflow)), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("wrote", path)
+	}
+}
+
+func mustParse(fn string, options ...parser.OptionFunc) *model.Configuration {
+	config, err := parsePath(fn, options...)
+	if err != nil {
+		printError(fn, err)
+		os.Exit(1)
+	}
+	return config
+}
+
+// printError prints an error from parsing fn.  On a terminal, problems
+// with the file are shown with the lines of source they are about.
+func printError(fn string, err error) {
+	pe, ok := err.(*parser.Error)
+	if ok && report.IsTerminal(os.Stdout) {
+		if src, rerr := readSource(fn); rerr == nil {
+			if report.Render(os.Stdout, src, pe.Errors) == nil {
+				return
+			}
+		}
+	}
+	fmt.Println(err)
+}
+
+// writeBaselineFile records the problems in each of files in a baseline
+// at path.
+func writeBaselineFile(path string, files []string) {
+	var errs parser.ErrorList
+	for _, fn := range files {
+		_, err := parsePath(fn)
+		if pe, ok := err.(*parser.Error); ok {
+			errs = append(errs, pe.Errors...)
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	err = parser.NewBaseline(errs).Write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("wrote", path, "with", plural(len(errs), "problem"))
 }
 
 func plural(n int, s string) string {
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/actions/workflow-parser/lock"
+	"github.com/actions/workflow-parser/match"
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/plan"
+	"github.com/actions/workflow-parser/webhook"
+)
+
+// planFile prints what would run in the file named in args for the event
+// given by -event, or by -payload: each workflow the event triggers, with
+// its actions in the stages a runner would run them in.
+func planFile(args []string) {
+	flags := flag.NewFlagSet(os.Args[0]+" plan", flag.ExitOnError)
+	eventType := flags.String("event", "push", "the `type` of event, like push, optionally with an activity, like pull_request.opened")
+	payload := flags.String("payload", "", "read the activity and branch from the webhook payload in `file`")
+	lockfile := flags.String("lockfile", "", "show the commit each repository ref is locked to in the lockfile at `path`")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	var event webhook.Event
+	event.Type = *eventType
+	if i := strings.IndexByte(event.Type, '.'); i >= 0 {
+		event.Type, event.Action = event.Type[:i], event.Type[i+1:]
+	}
+	if *payload != "" {
+		b, err := ioutil.ReadFile(*payload)
+		if err == nil {
+			event, err = webhook.Parse(event.Type, b)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", *payload, err)
+			os.Exit(1)
+		}
+	}
+
+	var l *lock.Lockfile
+	if *lockfile != "" {
+		f, err := os.Open(*lockfile)
+		if err == nil {
+			l, err = lock.Read(f)
+			f.Close()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", *lockfile, err)
+			os.Exit(1)
+		}
+	}
+
+	config := mustParse(flags.Arg(0))
+	if err := writePlan(os.Stdout, config, event, l); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// writePlan writes the plan for event to w.  If l is not nil, repository
+// refs are shown with the commits they are locked to.
+func writePlan(w io.Writer, c *model.Configuration, event webhook.Event, l *lock.Lockfile) error {
+	workflows := match.WorkflowsForEvent(c, event)
+	if len(workflows) == 0 {
+		_, err := fmt.Fprintf(w, "no workflows run on %s\n", event)
+		return err
+	}
+
+	var sb strings.Builder
+	for i, workflow := range workflows {
+		stages, err := plan.Stages(c, workflow.Identifier)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "workflow %s, on %s:\n", strconv.Quote(workflow.Identifier), event)
+		for n, stage := range stages {
+			fmt.Fprintf(&sb, "  stage %d:\n", n+1)
+			for _, action := range stage {
+				fmt.Fprintf(&sb, "    action %s\n", strconv.Quote(action.Identifier))
+				fmt.Fprintf(&sb, "      uses: %s\n", resolvedUses(action.Uses, l))
+				if action.Runs != nil {
+					fmt.Fprintf(&sb, "      runs: %s\n", commandLine(action.Runs))
+				}
+				if action.Args != nil {
+					fmt.Fprintf(&sb, "      args: %s\n", commandLine(action.Args))
+				}
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// resolvedUses returns uses as written, followed, for a repository ref
+// that l locks, by the commit it is locked to.
+func resolvedUses(uses model.Uses, l *lock.Lockfile) string {
+	if uses == nil {
+		return "(none)"
+	}
+	if repo, ok := uses.(*model.UsesRepository); ok && l != nil && !lock.IsSHA(repo.Ref) {
+		if sha, ok := l.Refs[repo.Repository+"@"+repo.Ref]; ok {
+			return fmt.Sprintf("%s (%s)", uses, sha)
+		}
+	}
+	return uses.String()
+}
+
+// commandLine returns the words of cmd separated by spaces, quoting any
+// that would otherwise be ambiguous.
+func commandLine(cmd model.Command) string {
+	words := append([]string(nil), cmd.Split()...)
+	for i, word := range words {
+		if word == "" || strings.ContainsAny(word, " \t\n\"'\\") {
+			words[i] = strconv.Quote(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/lock"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePlan(t *testing.T) {
+	config, err := parser.Parse(strings.NewReader(`
+		workflow "ci" { on = "push" resolves = "deploy" }
+		workflow "review" { on = "pull_request.opened" resolves = "lint" }
+		action "build" { uses = "docker://golang" runs = "go build" }
+		action "lint" { uses = "./lint" args = ["-v", "a b"] }
+		action "deploy" { uses = "actions/deploy@v1" needs = ["build", "lint"] }
+	`))
+	require.NoError(t, err)
+	l := &lock.Lockfile{Refs: map[string]string{"actions/deploy@v1": "0123456789012345678901234567890123456789"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writePlan(&buf, config, webhook.Event{Type: "push"}, l))
+	assert.Equal(t, `workflow "ci", on push:
+  stage 1:
+    action "build"
+      uses: docker://golang
+      runs: go build
+    action "lint"
+      uses: ./lint
+      args: -v "a b"
+  stage 2:
+    action "deploy"
+      uses: actions/deploy@v1 (0123456789012345678901234567890123456789)
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, writePlan(&buf, config, webhook.Event{Type: "pull_request", Action: "closed"}, nil))
+	assert.Equal(t, "no workflows run on pull_request.closed\n", buf.String())
+
+	// The model is left alone.
+	assert.Equal(t, []string{"-v", "a b"}, config.GetAction("lint").Args.Split())
+}
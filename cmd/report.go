@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/report"
+)
+
+// parseAll parses each of files and returns all of their problems.  Files
+// that can't be read are reported on standard error instead.  The result
+// is false if any file can't be read or has a problem at or above failOn.
+func parseAll(files []string, failOn parser.Severity, options ...parser.OptionFunc) (parser.ErrorList, bool) {
+	ok := true
+	errs := parser.ErrorList{}
+	for _, fn := range files {
+		_, err := parsePath(fn, options...)
+		if err == nil {
+			continue
+		}
+		pe, isParseError := err.(*parser.Error)
+		if !isParseError {
+			fmt.Fprintln(os.Stderr, err)
+			ok = false
+			continue
+		}
+		if pe.FirstError(failOn) != nil {
+			ok = false
+		}
+		errs = append(errs, pe.Errors...)
+	}
+	return errs, ok
+}
+
+// writeJSON parses each of files and prints all of their problems as a
+// single JSON array, in the stable format of parser.ParseError:
+//
+//	[{"message": "Action `b' needs nonexistent action `c'", "code": "E_UNKNOWN_NEEDS",
+//	  "severity": "error", "file": "a.workflow", "line": 3, "column": 11, "offset": 30}]
+//
+// It returns false as parseAll does, or if the output can't be written.
+func writeJSON(w io.Writer, files []string, failOn parser.Severity, options ...parser.OptionFunc) bool {
+	errs, ok := parseAll(files, failOn, options...)
+	b, err := json.MarshalIndent(errs, "", "  ")
+	if err == nil {
+		_, err = w.Write(append(b, '\n'))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	return ok
+}
+
+// writeCheckstyle parses each of files and prints all of their problems
+// as a Checkstyle XML report.  It returns false as writeJSON does.
+func writeCheckstyle(w io.Writer, files []string, failOn parser.Severity, options ...parser.OptionFunc) bool {
+	errs, ok := parseAll(files, failOn, options...)
+	if err := report.WriteCheckstyle(w, errs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	return ok
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "json")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	good := filepath.Join(dir, "good.workflow")
+	require.NoError(t, ioutil.WriteFile(good, []byte(`action "a" { uses = "./a" }`), 0644))
+	warn := filepath.Join(dir, "warn.workflow")
+	require.NoError(t, ioutil.WriteFile(warn, []byte(`action "a" {
+  uses = "./a"
+  bogus = "x"
+}
+`), 0644))
+
+	var buf bytes.Buffer
+	assert.True(t, writeJSON(&buf, []string{good}, parser.ERROR))
+	assert.Equal(t, "[]\n", buf.String())
+
+	buf.Reset()
+	assert.True(t, writeJSON(&buf, []string{good, warn}, parser.ERROR))
+	var diags []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &diags))
+	require.Len(t, diags, 1)
+	assert.Equal(t, map[string]interface{}{
+		"message":  "Unknown action attribute `bogus'",
+		"code":     "W_UNKNOWN_ATTRIBUTE",
+		"severity": "warning",
+		"file":     warn,
+		"line":     float64(3),
+		"column":   float64(11),
+		"offset":   float64(38),
+	}, diags[0])
+
+	buf.Reset()
+	assert.False(t, writeJSON(&buf, []string{warn}, parser.WARNING))
+}
+
+func TestWriteCheckstyle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkstyle")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "a.workflow")
+	require.NoError(t, ioutil.WriteFile(fn, []byte(`action "a" { uses = "./a" needs = "b" }`), 0644))
+
+	var buf bytes.Buffer
+	assert.False(t, writeCheckstyle(&buf, []string{fn}, parser.ERROR))
+	assert.Contains(t, buf.String(), `<file name="`+fn+`">`)
+	assert.Contains(t, buf.String(), `source="E_UNKNOWN_NEEDS"`)
+}
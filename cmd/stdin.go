@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/report"
+)
+
+// stdinName labels standard input, given as `-' on the command line, in
+// diagnostics.
+const stdinName = "<stdin>"
+
+// stdin holds standard input once it has been read, since it can only be
+// read once.
+var stdin *[]byte
+
+// readSource returns the contents of the file fn, or of standard input if
+// fn is `-'.
+func readSource(fn string) ([]byte, error) {
+	if fn != "-" {
+		return ioutil.ReadFile(fn)
+	}
+	if stdin == nil {
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		stdin = &b
+	}
+	return *stdin, nil
+}
+
+// parsePath parses the file fn, or standard input if fn is `-'.
+func parsePath(fn string, options ...parser.OptionFunc) (*model.Configuration, error) {
+	if fn != "-" {
+		return parser.ParseFile(fn, options...)
+	}
+	src, err := readSource(fn)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseReader(stdinName, bytes.NewReader(src), options...)
+}
+
+// displayName returns the name to show for fn in messages.
+func displayName(fn string) string {
+	if fn == "-" {
+		return stdinName
+	}
+	return fn
+}
+
+// stdinPiped returns whether standard input is a file or pipe rather than
+// a terminal, so that the command can read a workflow from it when not
+// given any files.
+func stdinPiped() bool {
+	return !report.IsTerminal(os.Stdin)
+}
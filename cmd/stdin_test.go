@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStdin(t *testing.T) {
+	f, err := ioutil.TempFile("", "stdin")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("action \"a\" {\n  uses = \"./a\"\n  needs = \"b\"\n}\n")
+	require.NoError(t, err)
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+
+	saved := os.Stdin
+	os.Stdin = f
+	defer func() {
+		os.Stdin, stdin = saved, nil
+	}()
+
+	_, err = parsePath("-")
+	pe, ok := err.(*parser.Error)
+	require.True(t, ok)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, "<stdin>", pe.Errors[0].Pos.File)
+
+	// Standard input can be read only once, but parsed again.
+	src, err := readSource("-")
+	require.NoError(t, err)
+	assert.Contains(t, string(src), `needs = "b"`)
+	assert.True(t, stdinPiped())
+}
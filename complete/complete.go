@@ -0,0 +1,268 @@
+// Package complete suggests what can be typed at a position in a
+// .workflow file, for editors to offer as completions.
+package complete
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/hashicorp/hcl/hcl/scanner"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// Kind is the kind of thing a Candidate names.
+type Kind int
+
+const (
+	// Attribute is the name of an attribute of an action or workflow.
+	Attribute Kind = iota
+
+	// Action is the identifier of an action, for `needs' or `resolves'.
+	Action
+
+	// Event is an event type, or an event type and activity, for `on'.
+	Event
+)
+
+// Candidate is a suggestion for the text at a position.  Label is the
+// text to insert, without quotes.
+type Candidate struct {
+	Label string
+	Kind  Kind
+}
+
+var attributes = map[string][]string{
+	"action":   {"uses", "needs", "runs", "args", "env", "secrets"},
+	"workflow": {"on", "resolves"},
+}
+
+// At returns the candidates for the text at the given 1-based line and
+// column of source, counting columns in characters as the parser does.
+// In an action or workflow block, it suggests the attributes the block
+// doesn't set yet.  In the value of `needs' or `resolves', it suggests
+// the identifiers of the file's actions, and in the value of `on', the
+// allowed event types, and their activities once a type and a `.' have
+// been typed.  Only candidates that start with the word being typed, if
+// any, are returned, ignoring case.
+//
+// At only looks at tokens, so it works on incomplete files and files with
+// syntax errors, which is what editors have as the user types.
+func At(source []byte, line, col int) []Candidate {
+	toks := scan(source)
+	offset := model.Pos{Line: line, Column: col}.OffsetIn(source)
+
+	// Find the word being typed, if any, and the tokens before it.
+	n := sort.Search(len(toks), func(i int) bool { return toks[i].Pos.Offset >= offset })
+	var prefix string
+	var current *token.Token
+	if n > 0 {
+		if p, ok := partial(toks[n-1], offset); ok {
+			n--
+			prefix, current = p, &toks[n]
+		}
+	}
+
+	ctx := context(toks[:n])
+	switch {
+	case ctx.kind == "":
+		return nil
+	case ctx.key == "":
+		return attributeCandidates(ctx, toks, current, prefix)
+	case ctx.key == "on" && ctx.kind == "workflow":
+		return eventCandidates(prefix)
+	case ctx.key == "needs" && ctx.kind == "action", ctx.key == "resolves" && ctx.kind == "workflow":
+		return actionCandidates(ctx, toks, current, prefix)
+	}
+	return nil
+}
+
+// scan returns the tokens of src, except comments, up to the end or the
+// first illegal token.
+func scan(src []byte) []token.Token {
+	s := scanner.New(src)
+	s.Error = func(token.Pos, string) {}
+	var ret []token.Token
+	for {
+		t := s.Scan()
+		switch t.Type {
+		case token.EOF, token.ILLEGAL:
+			return ret
+		case token.COMMENT:
+			continue
+		}
+		ret = append(ret, t)
+	}
+}
+
+// partial returns the part of t before offset, without its opening
+// quote, if t is a word or string that offset is in or just after.
+func partial(t token.Token, offset int) (string, bool) {
+	text := strings.TrimSuffix(t.Text, "\n")
+	end := t.Pos.Offset + len(text)
+	switch t.Type {
+	case token.IDENT:
+		if offset <= end {
+			return text[:offset-t.Pos.Offset], true
+		}
+	case token.STRING:
+		closed := len(text) >= 2 && strings.HasSuffix(text, `"`)
+		if offset < end || offset == end && !closed {
+			return text[1 : offset-t.Pos.Offset], true
+		}
+	}
+	return "", false
+}
+
+// position describes where the tokens before the cursor leave off: in
+// the block of the given kind, if any, and in the value of the given
+// attribute, if any.
+type position struct {
+	kind  string // "action", "workflow", or "" outside any block
+	name  string // the block's identifier
+	start int    // the index of the block's opening brace
+	key   string // the attribute whose value the cursor is in
+}
+
+// context works out the position after toks.
+func context(toks []token.Token) position {
+	var ret position
+	var stack []token.Type
+	var key string
+	inValue := false
+
+	for i, t := range toks {
+		switch t.Type {
+		case token.LBRACE, token.LBRACK:
+			if len(stack) == 0 && t.Type == token.LBRACE && i >= 2 && toks[i-2].Type == token.IDENT && toks[i-1].Type == token.STRING {
+				ret = position{kind: toks[i-2].Text, name: unquote(toks[i-1].Text), start: i}
+				if attributes[ret.kind] == nil {
+					ret.kind = ""
+				}
+			}
+			stack = append(stack, t.Type)
+		case token.RBRACE, token.RBRACK:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				ret = position{}
+			}
+			if len(stack) == 1 {
+				inValue = false
+			}
+		case token.ASSIGN:
+			if len(stack) == 1 && i > 0 {
+				key, inValue = unquote(toks[i-1].Text), true
+			}
+		default:
+			if len(stack) == 1 && inValue && i > 0 && toks[i-1].Type == token.ASSIGN {
+				inValue = false
+			}
+		}
+	}
+
+	switch {
+	case ret.kind == "":
+	case len(stack) == 1 && inValue:
+		ret.key = key
+	case len(stack) == 2 && inValue && stack[1] == token.LBRACK:
+		ret.key = key
+	case len(stack) != 1:
+		ret.kind = ""
+	}
+	return ret
+}
+
+// attributeCandidates returns the attributes the block at ctx doesn't
+// set, other than with the word being typed.
+func attributeCandidates(ctx position, toks []token.Token, current *token.Token, prefix string) []Candidate {
+	set := make(map[string]bool)
+	depth := 0
+scan:
+	for i := ctx.start; i < len(toks); i++ {
+		switch toks[i].Type {
+		case token.LBRACE, token.LBRACK:
+			depth++
+		case token.RBRACE, token.RBRACK:
+			if depth--; depth == 0 {
+				break scan
+			}
+		case token.ASSIGN:
+			if depth == 1 && (current == nil || &toks[i-1] != current) {
+				set[unquote(toks[i-1].Text)] = true
+			}
+		}
+	}
+
+	var ret []Candidate
+	for _, name := range attributes[ctx.kind] {
+		if !set[name] && hasPrefix(name, prefix) {
+			ret = append(ret, Candidate{Label: name, Kind: Attribute})
+		}
+	}
+	return ret
+}
+
+// actionCandidates returns the identifiers of the actions in toks, except
+// the action being edited.
+func actionCandidates(ctx position, toks []token.Token, current *token.Token, prefix string) []Candidate {
+	var ret []Candidate
+	seen := make(map[string]bool)
+	depth := 0
+	for i, t := range toks {
+		switch t.Type {
+		case token.LBRACE, token.LBRACK:
+			if depth == 0 && i >= 2 && toks[i-2].Type == token.IDENT && toks[i-2].Text == "action" && toks[i-1].Type == token.STRING {
+				id := unquote(toks[i-1].Text)
+				if !seen[id] && !(ctx.kind == "action" && id == ctx.name) && hasPrefix(id, prefix) {
+					ret = append(ret, Candidate{Label: id, Kind: Action})
+				}
+				seen[id] = true
+			}
+			depth++
+		case token.RBRACE, token.RBRACK:
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return ret
+}
+
+// eventCandidates returns the allowed event types, or the activities of
+// an event type once prefix names one and a `.'.
+func eventCandidates(prefix string) []Candidate {
+	var ret []Candidate
+	if i := strings.IndexByte(prefix, '.'); i >= 0 {
+		for _, activity := range parser.EventActivities(prefix[:i]) {
+			label := prefix[:i] + "." + activity
+			if hasPrefix(label, prefix) {
+				ret = append(ret, Candidate{Label: label, Kind: Event})
+			}
+		}
+		return ret
+	}
+
+	for _, t := range parser.DefaultEventRegistry().Types() {
+		if hasPrefix(t, prefix) {
+			ret = append(ret, Candidate{Label: t, Kind: Event})
+		}
+	}
+	return ret
+}
+
+func hasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// unquote returns the text of an identifier or string token, without
+// quotes.
+func unquote(text string) string {
+	text = strings.TrimSuffix(text, "\n")
+	if strings.HasPrefix(text, `"`) {
+		text = strings.TrimSuffix(text[1:], `"`)
+	}
+	return text
+}
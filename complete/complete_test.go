@@ -0,0 +1,71 @@
+package complete
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func labels(candidates []Candidate) []string {
+	ret := []string{}
+	for _, c := range candidates {
+		ret = append(ret, c.Label)
+	}
+	return ret
+}
+
+func TestAttributes(t *testing.T) {
+	src := []byte(`action "a" {
+  uses = "./a"
+  
+  ne
+}
+workflow "w" {
+  "o"
+}
+`)
+	assert.Equal(t, []string{"needs", "runs", "args", "env", "secrets"}, labels(At(src, 3, 3)))
+	assert.Equal(t, []string{"needs"}, labels(At(src, 4, 5)))
+	assert.Equal(t, []string{"on"}, labels(At(src, 7, 5)))
+	assert.Equal(t, Attribute, At(src, 7, 5)[0].Kind)
+	assert.Empty(t, At(src, 5, 2))
+	assert.Empty(t, At(src, 1, 1))
+}
+
+func TestActions(t *testing.T) {
+	src := []byte(`workflow "w" {
+  on = "push"
+  resolves = ["b", ""]
+}
+action "a" {
+  uses = "./a"
+  env = { X = "" }
+}
+action "b" {
+  needs = "
+}
+action "c" {}
+`)
+	assert.Equal(t, []string{"a", "b", "c"}, labels(At(src, 3, 20)))
+	assert.Equal(t, []string{"b"}, labels(At(src, 3, 17)))
+	assert.Equal(t, []string{"a", "c"}, labels(At(src, 10, 12)))
+	assert.Equal(t, Action, At(src, 10, 12)[0].Kind)
+	assert.Empty(t, At(src, 7, 16))
+}
+
+func TestEvents(t *testing.T) {
+	src := []byte(`workflow "w" {
+  on = "pu"
+}
+workflow "x" {
+  on = "pull_request.re"
+}
+workflow "y" {
+  on =
+}
+`)
+	assert.Equal(t, []string{"public", "pull_request", "pull_request_review", "pull_request_review_comment", "push"}, labels(At(src, 2, 11)))
+	assert.Equal(t, []string{"pull_request.review_requested", "pull_request.review_request_removed", "pull_request.ready_for_review", "pull_request.reopened"}, labels(At(src, 5, 24)))
+	assert.Len(t, At(src, 8, 7), 28)
+	assert.Equal(t, Event, At(src, 8, 7)[0].Kind)
+}
@@ -0,0 +1,203 @@
+// Package conformance is a regression suite for parsers of .workflow
+// files.  It runs a parser over a set of golden inputs and compares the
+// actions, workflows, and diagnostics it produces with those of the
+// reference parser in this repository.  Alternate front-ends and forks
+// can use it to prove that they behave the same way.  The golden files
+// change along with the reference parser, so a fork passes the suite of
+// the version of this package it depends on:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, func(r io.Reader) (*model.Configuration, error) {
+//			return myparser.Parse(r)
+//		})
+//	}
+package conformance
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:embed golden
+var golden embed.FS
+
+// ParserFunc parses a .workflow file.  It has the same contract as
+// parser.Parse: if the file has any problems, it returns a *parser.Error
+// listing them, along with whatever actions and workflows it found.
+type ParserFunc func(r io.Reader) (*model.Configuration, error)
+
+// Case is a single golden input and its expected result.
+type Case struct {
+	Name     string
+	Input    []byte
+	Expected *Snapshot
+}
+
+// Snapshot is the observable result of parsing a file, in a form that
+// can be compared across parsers and stored as JSON.
+type Snapshot struct {
+	Actions     []ActionSnapshot   `json:"actions"`
+	Workflows   []WorkflowSnapshot `json:"workflows"`
+	Diagnostics []Diagnostic       `json:"diagnostics"`
+}
+
+// ActionSnapshot records the attributes of a parsed action.
+type ActionSnapshot struct {
+	Identifier string            `json:"identifier"`
+	Uses       string            `json:"uses"`
+	Runs       []string          `json:"runs,omitempty"`
+	Args       []string          `json:"args,omitempty"`
+	Needs      []string          `json:"needs,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+	Secrets    []string          `json:"secrets,omitempty"`
+}
+
+// WorkflowSnapshot records the attributes of a parsed workflow.
+type WorkflowSnapshot struct {
+	Identifier string   `json:"identifier"`
+	On         []string `json:"on,omitempty"`
+	Resolves   []string `json:"resolves,omitempty"`
+}
+
+// Diagnostic records a single error reported by the parser.
+type Diagnostic struct {
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Cases returns the golden inputs, in name order.
+func Cases() ([]Case, error) {
+	const dir = "golden"
+	entries, err := fs.ReadDir(golden, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Case
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".workflow") {
+			continue
+		}
+		name = strings.TrimSuffix(name, ".workflow")
+
+		input, err := fs.ReadFile(golden, path.Join(dir, name+".workflow"))
+		if err != nil {
+			return nil, err
+		}
+		b, err := fs.ReadFile(golden, path.Join(dir, name+".golden"))
+		if err != nil {
+			return nil, err
+		}
+		var expected Snapshot
+		if err := json.Unmarshal(b, &expected); err != nil {
+			return nil, fmt.Errorf("%s.golden: %v", name, err)
+		}
+
+		ret = append(ret, Case{Name: name, Input: input, Expected: &expected})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret, nil
+}
+
+// Run runs fn over every golden input, as a subtest per input, and fails
+// each subtest whose result differs from the reference parser's.
+func Run(t *testing.T, fn ParserFunc) {
+	cases, err := Cases()
+	if err != nil {
+		t.Fatalf("loading golden files: %v", err)
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			actual := Take(fn, c.Input)
+			assert.Equal(t, c.Expected, actual)
+		})
+	}
+}
+
+// Take parses input with fn and records the result.
+func Take(fn ParserFunc, input []byte) *Snapshot {
+	config, err := fn(strings.NewReader(string(input)))
+
+	var actions []*model.Action
+	var workflows []*model.Workflow
+	ret := &Snapshot{
+		Actions:     []ActionSnapshot{},
+		Workflows:   []WorkflowSnapshot{},
+		Diagnostics: []Diagnostic{},
+	}
+
+	switch e := err.(type) {
+	case nil:
+	case *parser.Error:
+		actions, workflows = e.Actions, e.Workflows
+		for _, pe := range e.Errors {
+			ret.Diagnostics = append(ret.Diagnostics, Diagnostic{
+				Line:     pe.Pos.Line,
+				Column:   pe.Pos.Column,
+				Code:     string(pe.Code),
+				Severity: pe.Severity.String(),
+				Message:  pe.Message(),
+			})
+		}
+	default:
+		ret.Diagnostics = append(ret.Diagnostics, Diagnostic{Severity: "fatal", Message: err.Error()})
+	}
+	if config != nil {
+		actions, workflows = config.Actions, config.Workflows
+	}
+
+	for _, a := range actions {
+		as := ActionSnapshot{
+			Identifier: a.Identifier,
+			Needs:      nonEmpty(a.Needs),
+			Secrets:    nonEmpty(a.Secrets),
+		}
+		if a.Uses != nil {
+			as.Uses = fmt.Sprint(a.Uses)
+		}
+		if a.Runs != nil {
+			as.Runs = nonEmpty(a.Runs.Split())
+		}
+		if a.Args != nil {
+			as.Args = nonEmpty(a.Args.Split())
+		}
+		if len(a.Env) > 0 {
+			as.Env = a.Env
+		}
+		ret.Actions = append(ret.Actions, as)
+	}
+	for _, w := range workflows {
+		ret.Workflows = append(ret.Workflows, WorkflowSnapshot{
+			Identifier: w.Identifier,
+			On:         nonEmpty(model.EventStrings(w.On)),
+			Resolves:   nonEmpty(w.Resolves),
+		})
+	}
+	return ret
+}
+
+// nonEmpty normalizes empty slices to nil, so that parsers needn't agree
+// on the difference.
+func nonEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package conformance
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files from the reference parser")
+
+func reference(r io.Reader) (*model.Configuration, error) {
+	return parser.Parse(r)
+}
+
+func TestReference(t *testing.T) {
+	if *update {
+		cases, err := Cases()
+		require.NoError(t, err)
+		for _, c := range cases {
+			b, err := json.MarshalIndent(Take(reference, c.Input), "", "  ")
+			require.NoError(t, err)
+			fn := filepath.Join("golden", c.Name+".golden")
+			require.NoError(t, ioutil.WriteFile(fn, append(b, '\n'), 0644))
+		}
+		return
+	}
+
+	Run(t, reference)
+}
@@ -0,0 +1,217 @@
+// Package converter writes .workflow files as GitHub Actions v2 YAML
+// workflows, so that users migrating off the HCL format can keep using
+// this parser as the source of truth.  The mapping from actions to jobs
+// and steps is done by the jobs package; this package only renders the
+// result as YAML.
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/actions/workflow-parser/jobs"
+	"github.com/actions/workflow-parser/model"
+)
+
+// File is a single converted workflow.  Name is a file name for the
+// workflow, unique among the files returned by Convert, suitable for a
+// .github/workflows directory.
+type File struct {
+	Name     string
+	Workflow *jobs.Workflow
+}
+
+// Convert converts every workflow in c to a YAML workflow file.
+func Convert(c *model.Configuration) []File {
+	workflows := jobs.FromConfiguration(c)
+	ret := make([]File, 0, len(workflows))
+	used := make(map[string]bool, len(workflows))
+	for _, w := range workflows {
+		base := fileBase(w.Name)
+		name := base + ".yml"
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d.yml", base, i)
+		}
+		used[name] = true
+		ret = append(ret, File{Name: name, Workflow: w})
+	}
+	return ret
+}
+
+var invalidFileChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// fileBase turns a workflow name into a file name without an extension.
+func fileBase(name string) string {
+	base := strings.Trim(invalidFileChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		return "workflow"
+	}
+	return base
+}
+
+// Write renders w as a YAML document.
+func Write(out io.Writer, w *jobs.Workflow) error {
+	p := &printer{w: bufio.NewWriter(out)}
+
+	p.printf("name: %s\n", scalar(w.Name))
+	switch {
+	case len(w.On) == 0:
+	case len(w.Schedules) > 0:
+		// Schedules need the mapping form of `on'.
+		p.printf("on:\n")
+		for _, on := range w.On {
+			p.printf("  %s:\n", scalar(on))
+			if on == model.ScheduleEventType {
+				for _, cron := range w.Schedules {
+					p.printf("    - cron: %s\n", scalar(cron))
+				}
+			}
+		}
+	case len(w.On) == 1:
+		p.printf("on: %s\n", scalar(w.On[0]))
+	default:
+		p.printf("on: %s\n", flowList(w.On))
+	}
+
+	if len(w.Jobs) == 0 {
+		p.printf("jobs: {}\n")
+	} else {
+		p.printf("jobs:\n")
+	}
+	for _, job := range w.Jobs {
+		p.printf("  %s:\n", scalar(job.ID))
+		if job.Name != "" && job.Name != job.ID {
+			p.printf("    name: %s\n", scalar(job.Name))
+		}
+		p.printf("    runs-on: %s\n", scalar(job.RunsOn))
+		if len(job.Needs) > 0 {
+			p.printf("    needs: %s\n", flowList(job.Needs))
+		}
+		if len(job.Matrix) > 0 {
+			p.printf("    strategy:\n      matrix:\n")
+			keys := make([]string, 0, len(job.Matrix))
+			for k := range job.Matrix {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				p.printf("        %s: %s\n", scalar(k), flowList(job.Matrix[k]))
+			}
+		}
+		p.printf("    steps:\n")
+		for _, step := range job.Steps {
+			p.step(step)
+		}
+	}
+
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// String renders w as a YAML document.
+func String(w *jobs.Workflow) string {
+	var sb strings.Builder
+	_ = Write(&sb, w)
+	return sb.String()
+}
+
+type printer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (p *printer) printf(format string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, a...)
+}
+
+// step prints a step as an entry in a `steps' list.
+func (p *printer) step(s *jobs.Step) {
+	var lines []string
+	if s.Name != "" {
+		lines = append(lines, "name: "+scalar(s.Name))
+	}
+	if s.Uses != "" {
+		lines = append(lines, "uses: "+scalar(s.Uses))
+	}
+	if s.Run != "" {
+		lines = append(lines, "run: "+scalar(s.Run))
+	}
+	lines = append(lines, mapping("with", s.With)...)
+	lines = append(lines, mapping("env", s.Env)...)
+
+	if len(lines) == 0 {
+		p.printf("      - {}\n")
+		return
+	}
+	for i, line := range lines {
+		if i == 0 {
+			p.printf("      - %s\n", line)
+		} else {
+			p.printf("        %s\n", line)
+		}
+	}
+}
+
+// mapping returns the lines of a block mapping with sorted keys, or
+// nothing if m is empty.
+func mapping(key string, m map[string]string) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ret := []string{key + ":"}
+	for _, k := range keys {
+		ret = append(ret, "  "+scalar(k)+": "+scalar(m[k]))
+	}
+	return ret
+}
+
+func flowList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = scalar(v)
+		if strings.ContainsAny(quoted[i], ",[]{}") && quoted[i][0] != '"' {
+			quoted[i] = strconv.Quote(v)
+		}
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+var plainScalar = regexp.MustCompile(`^[A-Za-z_./$][A-Za-z0-9_./@:${}+ -]*$`)
+
+// reservedScalars are plain scalars that YAML would read as something
+// other than a string.
+var reservedScalars = map[string]bool{
+	"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true,
+	"true": true, "false": true, "null": true,
+}
+
+// scalar returns s as a YAML scalar, quoting it unless it is certain to
+// read back as the same string.  Go's double-quoted escapes are a subset
+// of YAML's, so strconv.Quote does the quoting.
+func scalar(s string) string {
+	if plainScalar.MatchString(s) &&
+		!reservedScalars[strings.ToLower(s)] &&
+		!strings.HasSuffix(s, " ") &&
+		!strings.HasSuffix(s, ":") &&
+		!strings.Contains(s, ": ") &&
+		!strings.Contains(s, " #") {
+		return s
+	}
+	return strconv.Quote(s)
+}
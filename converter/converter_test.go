@@ -0,0 +1,119 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/jobs"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	config, err := parser.Parse(strings.NewReader(`
+workflow "Build and Test" {
+  on = "push"
+  resolves = ["test"]
+}
+
+workflow "build-and-test" {
+  on = "pull_request"
+  resolves = ["build"]
+}
+
+action "build" {
+  uses = "docker://alpine:3.8"
+  runs = "sh -c"
+  args = ["make build"]
+  env = { GOOS = "linux" }
+}
+
+action "run tests" {
+  uses = "actions/bin/sh@master"
+  needs = "build"
+  secrets = ["GITHUB_TOKEN"]
+}
+
+action "test" {
+  uses = "./ci/test"
+  needs = "run tests"
+}
+`))
+	require.NoError(t, err)
+
+	files := Convert(config)
+	require.Len(t, files, 2)
+	assert.Equal(t, "build-and-test.yml", files[0].Name)
+	assert.Equal(t, "build-and-test-2.yml", files[1].Name)
+
+	assert.Equal(t, `name: Build and Test
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - name: build
+        uses: docker://alpine:3.8
+        with:
+          args: "-c make build"
+          entrypoint: sh
+        env:
+          GOOS: linux
+  run-tests:
+    name: run tests
+    runs-on: ubuntu-latest
+    needs: [build]
+    steps:
+      - name: run tests
+        uses: actions/bin/sh@master
+        env:
+          GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+  test:
+    runs-on: ubuntu-latest
+    needs: [run-tests]
+    steps:
+      - name: test
+        uses: ./ci/test
+`, String(files[0].Workflow))
+}
+
+func TestWriteEmpty(t *testing.T) {
+	assert.Equal(t, "name: \"\"\njobs: {}\n", String(&jobs.Workflow{}))
+	assert.Equal(t, "name: w\non: [push, \"a,b\"]\njobs:\n  j:\n    runs-on: x\n    steps:\n      - {}\n",
+		String(&jobs.Workflow{Name: "w", On: []string{"push", "a,b"}, Jobs: []*jobs.Job{{ID: "j", RunsOn: "x", Steps: []*jobs.Step{{}}}}}))
+}
+
+func TestWriteSchedules(t *testing.T) {
+	assert.Equal(t, "name: w\non:\n  push:\n  schedule:\n    - cron: \"*/15 * * * *\"\njobs: {}\n",
+		String(&jobs.Workflow{Name: "w", On: []string{"push", "schedule"}, Schedules: []string{"*/15 * * * *"}}))
+}
+
+func TestWriteMatrix(t *testing.T) {
+	assert.Equal(t, "name: w\njobs:\n  j:\n    runs-on: x\n    strategy:\n      matrix:\n        go: [\"1.11\", \"1.12\"]\n        os: [linux]\n    steps:\n      - {}\n",
+		String(&jobs.Workflow{Name: "w", Jobs: []*jobs.Job{{ID: "j", RunsOn: "x", Matrix: map[string][]string{"os": {"linux"}, "go": {"1.11", "1.12"}}, Steps: []*jobs.Step{{}}}}}))
+}
+
+func TestScalar(t *testing.T) {
+	plain := []string{"push", "docker://alpine:3.8", "./path", "owner/repo@v1", "${{ secrets.X }}", "a b"}
+	for _, s := range plain {
+		assert.Equal(t, s, scalar(s))
+	}
+
+	quoted := map[string]string{
+		"":        `""`,
+		"yes":     `"yes"`,
+		"On":      `"On"`,
+		"123":     `"123"`,
+		"-x":      `"-x"`,
+		"a: b":    `"a: b"`,
+		"a #b":    `"a #b"`,
+		"trail ":  `"trail "`,
+		"key:":    `"key:"`,
+		"two\nln": `"two\nln"`,
+		`q"`:      `"q\""`,
+	}
+	for s, expected := range quoted {
+		assert.Equal(t, expected, scalar(s), s)
+	}
+}
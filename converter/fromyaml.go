@@ -0,0 +1,388 @@
+package converter
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/actions/workflow-parser/jobs"
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+)
+
+// FromYAML reads a YAML workflow and converts it to a Configuration, as
+// jobs.ToConfiguration does, so that tools built on the model can look at
+// workflows written for either version of the product.  A workflow
+// without a `name' is called "workflow".
+//
+// Like parser.ParseWithDiagnostics, it returns the configuration along
+// with diagnostics rather than failing on the first problem.  A file
+// that isn't valid YAML, or that has more than one document or sets a
+// key twice, yields an empty configuration and a single FATAL
+// diagnostic, on the line package yaml reports.  The other diagnostics
+// have no position, since package yaml keeps none.  Values of the wrong
+// type are errors.  An event's `types' become filters, so that
+// `pull_request: {types: [opened]}' reads as `pull_request.opened'.
+// Anything else that has no equivalent in the model is dropped with a
+// parser.CodeNotRepresentable warning: steps that run commands,
+// job-level `if' conditions, other event filters, like `branches', and
+// settings like `timeout-minutes'.  A job's `runs-on' is dropped without
+// one, since every job has it.  The diagnostics that parser.Validate
+// finds in the result follow.
+//
+// The error is not nil only if r could not be read.
+func FromYAML(r io.Reader) (*model.Configuration, parser.ErrorList, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root, err := parseYAML(src)
+	if err != nil {
+		ye := err.(*yamlError)
+		e := parser.NewError(nil, parser.CodeSyntax, "%s", ye.message)
+		e.Severity = parser.FATAL
+		e.Pos = ye.pos
+		return &model.Configuration{}, parser.ErrorList{e}, nil
+	}
+
+	d := &decoder{}
+	w, env := d.workflow(root)
+	d.checkNeeds(w)
+	c, err := jobs.ToConfiguration([]*jobs.Workflow{w})
+	if err != nil {
+		// The decoder leaves out everything else ToConfiguration
+		// rejects, so what's left is jobs that turn into actions with
+		// the same identifier.
+		e := parser.NewError(nil, parser.CodeIdentifierRedefined, "%s", err)
+		return &model.Configuration{}, append(d.errs, e), nil
+	}
+	c.Workflows[0].On = d.filterEvents(c.Workflows[0].On)
+	c.Workflows[0].Env = env
+
+	return c, append(d.errs, parser.Validate(c)...), nil
+}
+
+// decoder turns the nodes of a YAML workflow into a jobs.Workflow,
+// collecting diagnostics along the way.
+type decoder struct {
+	errs parser.ErrorList
+
+	// types holds the `types' of each event that lists them, which
+	// jobs.Workflow has no place for.
+	types map[string][]string
+}
+
+func (d *decoder) errorf(code parser.Code, format string, a ...interface{}) {
+	d.errs = append(d.errs, parser.NewError(nil, code, format, a...))
+}
+
+func (d *decoder) drop(format string, a ...interface{}) {
+	d.errs = append(d.errs, parser.NewWarning(nil, parser.CodeNotRepresentable, format, a...))
+}
+
+// expect reports whether n is of the given kind, and an error if not.
+func (d *decoder) expect(n *node, kind nodeKind, what string) bool {
+	if n.kind == kind {
+		return true
+	}
+	d.errorf(parser.CodeTypeMismatch, "Expected %s for %s, got %s", kind, what, n.kind)
+	return false
+}
+
+// workflow decodes the top level of a file, returning its `env' apart,
+// since jobs.Workflow has no place for it.
+func (d *decoder) workflow(root *node) (*jobs.Workflow, map[string]string) {
+	w := &jobs.Workflow{Name: "workflow"}
+	var env map[string]string
+	if root == nil || !d.expect(root, mappingNode, "the workflow") {
+		return w, env
+	}
+	for i, key := range root.keys {
+		value := root.values[i]
+		switch key {
+		case "name":
+			if d.expect(value, scalarNode, "`name'") && value.value != "" {
+				w.Name = value.value
+			}
+		case "on":
+			d.on(w, value)
+		case "env":
+			env = d.stringMap(value, "`env'")
+		case "jobs":
+			if !d.expect(value, mappingNode, "`jobs'") {
+				continue
+			}
+			for j, id := range value.keys {
+				if job := d.job(id, value.values[j]); job != nil {
+					w.Jobs = append(w.Jobs, job)
+				}
+			}
+		default:
+			d.drop("Workflow setting `%s' is not supported by .workflow files", key)
+		}
+	}
+	return w, env
+}
+
+// on decodes the events of a workflow: an event, a list of them, or a
+// mapping from each event to its configuration.
+func (d *decoder) on(w *jobs.Workflow, n *node) {
+	switch n.kind {
+	case scalarNode, sequenceNode:
+		w.On = d.stringList(n, "`on'")
+		return
+	}
+	for i, key := range n.keys {
+		w.On = append(w.On, key)
+		value := n.values[i]
+		if key != model.ScheduleEventType {
+			d.eventFilters(key, value)
+			continue
+		}
+		if !d.expect(value, sequenceNode, "`schedule'") {
+			continue
+		}
+		for _, item := range value.values {
+			if !d.expect(item, mappingNode, "a schedule") {
+				continue
+			}
+			for j, k := range item.keys {
+				if k != "cron" {
+					d.drop("Schedule setting `%s' is not supported by .workflow files", k)
+				} else if d.expect(item.values[j], scalarNode, "`cron'") {
+					w.Schedules = append(w.Schedules, item.values[j].value)
+				}
+			}
+		}
+	}
+}
+
+// eventFilters decodes the configuration of an event other than
+// `schedule', keeping its `types'.
+func (d *decoder) eventFilters(event string, n *node) {
+	if n.kind == scalarNode && n.value == "" {
+		return
+	}
+	if !d.expect(n, mappingNode, "event `"+event+"'") {
+		return
+	}
+	for i, key := range n.keys {
+		if key != "types" {
+			d.drop("Filter `%s' of event `%s' is not supported by .workflow files", key, event)
+			continue
+		}
+		if types := d.stringList(n.values[i], "`types'"); len(types) > 0 {
+			if d.types == nil {
+				d.types = make(map[string][]string)
+			}
+			d.types[event] = types
+		}
+	}
+}
+
+// filterEvents returns events with each event that has `types' replaced
+// by one filtered to each of them.
+func (d *decoder) filterEvents(events []model.Event) []model.Event {
+	if len(d.types) == 0 {
+		return events
+	}
+	ret := make([]model.Event, 0, len(events))
+	for _, event := range events {
+		types, ok := d.types[event.Type]
+		if !ok {
+			ret = append(ret, event)
+			continue
+		}
+		for _, t := range types {
+			ret = append(ret, model.Event{Type: event.Type, Filter: t})
+		}
+	}
+	return ret
+}
+
+// job decodes the job with the given ID.
+func (d *decoder) job(id string, n *node) *jobs.Job {
+	if !d.expect(n, mappingNode, "job `"+id+"'") {
+		return nil
+	}
+	job := &jobs.Job{ID: id}
+	var env map[string]string
+	for i, key := range n.keys {
+		value := n.values[i]
+		switch key {
+		case "name":
+			if d.expect(value, scalarNode, "`name'") {
+				job.Name = value.value
+			}
+		case "runs-on":
+			if value.kind == scalarNode {
+				job.RunsOn = value.value
+			}
+		case "needs":
+			job.Needs = d.stringList(value, "`needs'")
+		case "strategy":
+			job.Matrix = d.strategy(id, value)
+		case "env":
+			env = d.stringMap(value, "`env'")
+		case "steps":
+			if !d.expect(value, sequenceNode, "`steps'") {
+				continue
+			}
+			for j, item := range value.values {
+				if step := d.step(id, j+1, item); step != nil {
+					job.Steps = append(job.Steps, step)
+				}
+			}
+		default:
+			d.drop("Job setting `%s' in job `%s' is not supported by .workflow files", key, id)
+		}
+	}
+
+	// Actions have no job-level environment, so each step gets the job's.
+	if len(env) > 0 {
+		for _, step := range job.Steps {
+			merged := make(map[string]string, len(env)+len(step.Env))
+			for k, v := range env {
+				merged[k] = v
+			}
+			for k, v := range step.Env {
+				merged[k] = v
+			}
+			step.Env = merged
+		}
+	}
+	return job
+}
+
+// strategy decodes a job's `strategy', of which only the matrix has an
+// equivalent.
+func (d *decoder) strategy(id string, n *node) map[string][]string {
+	if !d.expect(n, mappingNode, "`strategy'") {
+		return nil
+	}
+	var ret map[string][]string
+	for i, key := range n.keys {
+		value := n.values[i]
+		if key != "matrix" {
+			d.drop("Strategy setting `%s' in job `%s' is not supported by .workflow files", key, id)
+			continue
+		}
+		if !d.expect(value, mappingNode, "`matrix'") {
+			continue
+		}
+		for j, dim := range value.keys {
+			if dim == "include" || dim == "exclude" {
+				d.drop("Matrix `%s' in job `%s' is not supported by .workflow files", dim, id)
+				continue
+			}
+			values := d.stringList(value.values[j], "matrix dimension `"+dim+"'")
+			if values == nil {
+				continue
+			}
+			if ret == nil {
+				ret = make(map[string][]string)
+			}
+			ret[dim] = values
+		}
+	}
+	return ret
+}
+
+// step decodes the index'th step of a job, or returns nil if it runs a
+// command.
+func (d *decoder) step(id string, index int, n *node) *jobs.Step {
+	if !d.expect(n, mappingNode, "a step") {
+		return nil
+	}
+	step := &jobs.Step{}
+	for i, key := range n.keys {
+		value := n.values[i]
+		switch key {
+		case "name", "if", "uses", "run":
+			if !d.expect(value, scalarNode, "`"+key+"'") {
+				continue
+			}
+			switch key {
+			case "name":
+				step.Name = value.value
+			case "if":
+				step.If = value.value
+			case "uses":
+				step.Uses = value.value
+			case "run":
+				step.Run = value.value
+			}
+		case "with":
+			step.With = d.stringMap(value, "`with'")
+		case "env":
+			step.Env = d.stringMap(value, "`env'")
+		case "continue-on-error":
+			if !d.expect(value, scalarNode, "`continue-on-error'") {
+				continue
+			}
+			switch value.value {
+			case "true":
+				step.ContinueOnError = true
+			case "false":
+			default:
+				d.drop("Expressions for `continue-on-error' are not supported by .workflow files")
+			}
+		case "id":
+			// Steps are identified by name.
+		default:
+			d.drop("Step setting `%s' in step %d of job `%s' is not supported by .workflow files", key, index, id)
+		}
+	}
+	if step.Uses == "" {
+		d.drop("Step %d of job `%s' runs a command, which cannot be represented as an action", index, id)
+		return nil
+	}
+	return step
+}
+
+// checkNeeds reports each `needs' naming a job that doesn't exist, and
+// removes it, so that jobs.ToConfiguration accepts the workflow.
+func (d *decoder) checkNeeds(w *jobs.Workflow) {
+	for _, job := range w.Jobs {
+		var needs []string
+		for _, need := range job.Needs {
+			if w.GetJob(need) == nil {
+				d.errorf(parser.CodeUnknownNeeds, "Job `%s' needs nonexistent job `%s'", job.ID, need)
+				continue
+			}
+			needs = append(needs, need)
+		}
+		job.Needs = needs
+	}
+}
+
+// stringList decodes a scalar or a sequence of scalars.
+func (d *decoder) stringList(n *node, what string) []string {
+	if n.kind == scalarNode {
+		return []string{n.value}
+	}
+	if !d.expect(n, sequenceNode, what) {
+		return nil
+	}
+	ret := make([]string, 0, len(n.values))
+	for _, item := range n.values {
+		if d.expect(item, scalarNode, "an item of "+what) {
+			ret = append(ret, item.value)
+		}
+	}
+	return ret
+}
+
+// stringMap decodes a mapping of scalars.
+func (d *decoder) stringMap(n *node, what string) map[string]string {
+	if !d.expect(n, mappingNode, what) {
+		return nil
+	}
+	ret := make(map[string]string, len(n.keys))
+	for i, key := range n.keys {
+		if d.expect(n.values[i], scalarNode, "`"+key+"' in "+what) {
+			ret[key] = n.values[i].value
+		}
+	}
+	return ret
+}
@@ -0,0 +1,183 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromYAML(t *testing.T) {
+	config, errs, err := FromYAML(strings.NewReader(`# Built by hand
+name: CI
+on:
+  push:
+    branches: [master]
+  pull_request:
+    types: [opened, synchronize]
+  schedule:
+    - cron: "0 0 * * *"
+env:
+  GOFLAGS: -mod=vendor
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+    env: {GOOS: linux}
+    steps:
+      - uses: actions/checkout@v1
+      - name: make
+        run: |
+          make build
+          make test
+      - name: publish
+        uses: docker://alpine:3.8
+        if: github.ref == 'refs/heads/master'
+        with:
+          entrypoint: sh
+          args: '-c "echo done"'
+        env:
+          TOKEN: ${{ secrets.TOKEN }}
+  test:
+    needs: [build, lint]
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        go: ["1.11", 1.10]
+    steps:
+      - uses: ./ci/test
+        continue-on-error: true
+`))
+	require.NoError(t, err)
+
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.Error())
+	}
+	assert.Equal(t, []string{
+		"Filter `branches' of event `push' is not supported by .workflow files [W_NOT_REPRESENTABLE]",
+		"Job setting `timeout-minutes' in job `build' is not supported by .workflow files [W_NOT_REPRESENTABLE]",
+		"Step 2 of job `build' runs a command, which cannot be represented as an action [W_NOT_REPRESENTABLE]",
+		"Job `test' needs nonexistent job `lint' [E_UNKNOWN_NEEDS]",
+	}, messages)
+
+	require.Len(t, config.Workflows, 1)
+	workflow := config.Workflows[0]
+	assert.Equal(t, "CI", workflow.Identifier)
+	assert.Equal(t, []string{"push", "pull_request.opened", "pull_request.synchronize", "schedule(0 0 * * *)"}, eventStrings(workflow.On))
+	assert.Equal(t, []string{"test"}, workflow.Resolves)
+	assert.Equal(t, map[string]string{"GOFLAGS": "-mod=vendor"}, workflow.Env)
+	assert.Equal(t, 1, config.Version)
+
+	require.Len(t, config.Actions, 3)
+	checkout, publish, test := config.Actions[0], config.Actions[1], config.Actions[2]
+	assert.Equal(t, "build / 1", checkout.Identifier)
+	assert.Equal(t, map[string]string{"GOOS": "linux"}, checkout.Env)
+
+	assert.Equal(t, "build / publish", publish.Identifier)
+	assert.Equal(t, []string{"build / 1"}, publish.Needs)
+	assert.Equal(t, "github.ref == 'refs/heads/master'", publish.If)
+	assert.Equal(t, &model.StringCommand{Value: "sh"}, publish.Runs)
+	assert.Equal(t, &model.StringCommand{Value: `-c "echo done"`}, publish.Args)
+	assert.Equal(t, []string{"TOKEN"}, publish.Secrets)
+
+	assert.Equal(t, "test", test.Identifier)
+	assert.Equal(t, []string{"build / publish"}, test.Needs)
+	assert.Equal(t, map[string][]string{"go": {"1.11", "1.10"}}, test.Matrix)
+	assert.True(t, test.ContinueOnError)
+}
+
+func eventStrings(events []model.Event) []string {
+	ret := make([]string, len(events))
+	for i, event := range events {
+		ret[i] = event.String()
+	}
+	return ret
+}
+
+func TestFromYAMLRoundTrip(t *testing.T) {
+	config, err := parser.Parse(strings.NewReader(`
+workflow "Build and Test" {
+  on = "push"
+  resolves = ["test"]
+}
+
+action "build" {
+  uses = "docker://alpine:3.8"
+  runs = "sh -c"
+  args = ["make build"]
+  env = { GOOS = "linux" }
+}
+
+action "test" {
+  uses = "./ci/test"
+  needs = "build"
+  secrets = ["GITHUB_TOKEN"]
+}
+`))
+	require.NoError(t, err)
+
+	files := Convert(config)
+	require.Len(t, files, 1)
+	back, errs, err := FromYAML(strings.NewReader(String(files[0].Workflow)))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	require.Len(t, back.Actions, 2)
+	assert.Equal(t, "build", back.Actions[0].Identifier)
+	assert.Equal(t, &model.StringCommand{Value: "sh"}, back.Actions[0].Runs)
+	assert.Equal(t, &model.StringCommand{Value: "-c make build"}, back.Actions[0].Args)
+	assert.Equal(t, map[string]string{"GOOS": "linux"}, back.Actions[0].Env)
+	assert.Equal(t, []string{"build"}, back.Actions[1].Needs)
+	assert.Equal(t, []string{"GITHUB_TOKEN"}, back.Actions[1].Secrets)
+	assert.Equal(t, []string{"test"}, back.Workflows[0].Resolves)
+}
+
+func TestFromYAMLSyntaxError(t *testing.T) {
+	for src, message := range map[string]string{
+		"on: push\non: pull_request\n": "Line 2: key \"on\" already set in map [E_SYNTAX]",
+		"on: [push\n":                  "Line 1: did not find expected ',' or ']' [E_SYNTAX]",
+		"name: CI\n---\nname: other\n": "files with more than one document are not supported [E_SYNTAX]",
+		"jobs:\n\ta: {}\n":             "Line 2: found character that cannot start any token [E_SYNTAX]",
+	} {
+		config, errs, err := FromYAML(strings.NewReader(src))
+		require.NoError(t, err)
+		assert.Empty(t, config.Workflows, src)
+		if assert.Len(t, errs, 1, src) {
+			assert.Equal(t, parser.Severity(parser.FATAL), errs[0].Severity, src)
+			assert.Equal(t, message, errs[0].Error(), src)
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	root, err := parseYAML([]byte(`
+z: last
+on: push
+y: 1.10
+n: ~
+base: &base {k: v}
+copy: *base
+list: [a, {b: c}]
+`))
+	require.NoError(t, err)
+	require.Equal(t, mappingNode, root.kind)
+	assert.Equal(t, []string{"z", "on", "y", "n", "base", "copy", "list"}, root.keys)
+	assert.Equal(t, "push", root.values[1].value)
+	assert.Equal(t, "1.10", root.values[2].value)
+	assert.Equal(t, node{}, *root.values[3])
+	assert.Equal(t, root.values[4], root.values[5])
+
+	list := root.values[6]
+	require.Equal(t, sequenceNode, list.kind)
+	require.Len(t, list.values, 2)
+	assert.Equal(t, "a", list.values[0].value)
+	assert.Equal(t, []string{"b"}, list.values[1].keys)
+
+	root, err = parseYAML([]byte("# nothing\n"))
+	require.NoError(t, err)
+	assert.Nil(t, root)
+}
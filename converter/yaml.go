@@ -0,0 +1,175 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/actions/workflow-parser/parser"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// node is a YAML value: a scalar, a mapping, or a sequence.  Scalars and
+// keys keep their text as written, so that `1.10' stays "1.10" rather
+// than becoming the number 1.1, and the key `on' stays "on" rather than
+// becoming true, as YAML 1.1 would have it.
+type node struct {
+	kind nodeKind
+
+	// value is a scalar's value, which is empty for null.
+	value string
+
+	// keys and values are a mapping's entries, in order; values alone
+	// are a sequence's items.
+	keys   []string
+	values []*node
+}
+
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	mappingNode
+	sequenceNode
+)
+
+func (k nodeKind) String() string {
+	switch k {
+	case mappingNode:
+		return "mapping"
+	case sequenceNode:
+		return "sequence"
+	default:
+		return "scalar"
+	}
+}
+
+// UnmarshalYAML decodes n from a value of any kind.  Package yaml
+// leaves n as the zero node, an empty scalar, for null.
+func (n *node) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// Decoding into a type that doesn't match the kind of value fails,
+	// without reporting anything else, so these find out what n is.
+	var probe []skip
+	if unmarshal(&probe) == nil {
+		var items []node
+		if err := unmarshal(&items); err != nil {
+			return err
+		}
+		*n = node{kind: sequenceNode}
+		for i := range items {
+			n.values = append(n.values, &items[i])
+		}
+		return nil
+	}
+	var order yaml.MapSlice
+	if unmarshal(&order) == nil {
+		// A MapSlice has the order of the keys, and a map the keys as
+		// written, which are also what a duplicate key error names.
+		var entries map[string]node
+		if err := unmarshal(&entries); err != nil {
+			return err
+		}
+		*n = node{kind: mappingNode}
+		for _, key := range mappingKeys(order, entries) {
+			value := entries[key]
+			n.keys = append(n.keys, key)
+			n.values = append(n.values, &value)
+		}
+		return nil
+	}
+	*n = node{kind: scalarNode}
+	return unmarshal(&n.value)
+}
+
+// skip decodes from any value without looking at it.
+type skip struct{}
+
+func (skip) UnmarshalYAML(func(interface{}) error) error {
+	return nil
+}
+
+// mappingKeys returns the keys of entries, which are as written, in the
+// order of the items of order, whose keys are resolved, so that an item
+// whose key is true may stand for the entry whose key is `on'.
+func mappingKeys(order yaml.MapSlice, entries map[string]node) []string {
+	raw := make([]string, 0, len(entries))
+	for key := range entries {
+		raw = append(raw, key)
+	}
+	sort.Strings(raw)
+
+	used := make(map[string]bool, len(raw))
+	ret := make([]string, 0, len(raw))
+	take := func(key string) {
+		used[key] = true
+		ret = append(ret, key)
+	}
+	for _, item := range order {
+		if s, ok := item.Key.(string); ok && !used[s] {
+			if _, found := entries[s]; found {
+				take(s)
+				continue
+			}
+		}
+		for _, key := range raw {
+			var resolved interface{}
+			if !used[key] && yaml.Unmarshal([]byte(key), &resolved) == nil && reflect.DeepEqual(resolved, item.Key) {
+				take(key)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// yamlError is a syntax error, on a line if package yaml says which.
+type yamlError struct {
+	pos     parser.ErrorPos
+	message string
+}
+
+func (e *yamlError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.pos.Line, e.message)
+}
+
+var yamlErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// newYAMLError converts an error from package yaml, whose messages look
+// like "yaml: line 2: mapping values are not allowed in this context".
+// A type error has a message for each problem; only the first is kept.
+func newYAMLError(err error) *yamlError {
+	message := err.Error()
+	if te, ok := err.(*yaml.TypeError); ok && len(te.Errors) > 0 {
+		message = te.Errors[0]
+	}
+	message = strings.TrimPrefix(message, "yaml: ")
+	if m := yamlErrorLine.FindStringSubmatch(message); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &yamlError{pos: parser.ErrorPos{Line: line}, message: m[2]}
+	}
+	return &yamlError{message: message}
+}
+
+// parseYAML parses src, a single YAML document, which is nil if src holds
+// nothing but comments.  Keys set twice in the same mapping are errors.
+func parseYAML(src []byte) (*node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	dec.SetStrict(true)
+	var root *node
+	if err := dec.Decode(&root); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, newYAMLError(err)
+	}
+	var next interface{}
+	if err := dec.Decode(&next); err != io.EOF {
+		return nil, &yamlError{message: "files with more than one document are not supported"}
+	}
+	return root, nil
+}
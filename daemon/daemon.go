@@ -0,0 +1,275 @@
+// Package daemon implements a long-running process that answers requests
+// about .workflow files over a small JSON-RPC 2.0 protocol, so that
+// editors and services written in other languages can keep one warm
+// parser process instead of starting a new one for every request.
+//
+// Requests and responses are JSON objects, one per line.  Each method
+// takes the text of a .workflow file in its "text" parameter; "complete"
+// and "hover" also take a "position" in it, as LSP positions are given.
+// The "shutdown" method, or the end of the input, stops the server.
+// Errors use the codes of package lsp, which speaks the same JSON-RPC.
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/actions/workflow-parser/lsp"
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/printer"
+)
+
+// maxLineLength bounds the size of a single request.
+const maxLineLength = 16 * 1024 * 1024
+
+// Request is a JSON-RPC request.  Requests without an ID are
+// notifications, and receive no response.
+type Request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response.  Exactly one of Result and Error is
+// set.
+type Response struct {
+	JSONRPC string             `json:"jsonrpc"`
+	ID      *json.RawMessage   `json:"id"`
+	Result  interface{}        `json:"result,omitempty"`
+	Error   *lsp.ResponseError `json:"error,omitempty"`
+}
+
+// TextParams are the parameters of every method that operates on the
+// text of a .workflow file.  URI, if given, names the file in the
+// locations of the diagnostics' related information.
+type TextParams struct {
+	Text string `json:"text"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// PositionParams are the parameters of the "complete" and "hover"
+// methods: the text of a .workflow file, and a zero-based position in it
+// with characters counted in UTF-16 code units.
+type PositionParams struct {
+	Text     string       `json:"text"`
+	Position lsp.Position `json:"position"`
+}
+
+// ParseResult is the result of the "parse" method.  The actions and
+// workflows are reported even if the file has errors, as far as the
+// parser could make sense of them.
+type ParseResult struct {
+	Actions     []*model.Action   `json:"actions"`
+	Workflows   []*model.Workflow `json:"workflows"`
+	Diagnostics []lsp.Diagnostic  `json:"diagnostics"`
+}
+
+// ValidateResult is the result of the "validate" method.  A file is valid
+// if it has no errors; it may still have warnings.
+type ValidateResult struct {
+	Valid       bool             `json:"valid"`
+	Diagnostics []lsp.Diagnostic `json:"diagnostics"`
+}
+
+// FormatResult is the result of the "format" method: the text in
+// canonical form, as printed by printer.Format.
+type FormatResult struct {
+	Text string `json:"text"`
+}
+
+// CompleteResult is the result of the "complete" method: what can be
+// typed at the position, as lsp.CompletionsAt suggests it.
+type CompleteResult struct {
+	Items []lsp.CompletionItem `json:"items"`
+}
+
+// HoverResult is the result of the "hover" method: what to show for
+// the part of the file at the position, as lsp.HoverAt explains it, or
+// nil if there is nothing to say.
+type HoverResult struct {
+	Hover *lsp.Hover `json:"hover"`
+}
+
+type handler func(s *Server, params json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"parse":    handleParse,
+	"validate": handleValidate,
+	"format":   handleFormat,
+	"complete": handleComplete,
+	"hover":    handleHover,
+}
+
+// Server answers JSON-RPC requests.  Its zero value is not usable; use
+// NewServer.
+type Server struct {
+	options []parser.OptionFunc
+}
+
+// NewServer creates a server that parses every file it is sent with the
+// given options.
+func NewServer(options ...parser.OptionFunc) *Server {
+	return &Server{options: options}
+}
+
+// Serve reads requests from r and writes responses to w until r is
+// exhausted or a "shutdown" request arrives.  It returns an error only if
+// reading or writing fails; malformed requests get error responses.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+	enc := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		resp, shutdown := s.handle(line)
+		if resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+		}
+		if shutdown {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers a single request.  It returns a nil response for
+// notifications, and reports whether the server should stop.
+func (s *Server) handle(line []byte) (*Response, bool) {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(nil, lsp.CodeParseError, err.Error()), false
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, lsp.CodeInvalidRequest, "invalid request"), false
+	}
+
+	if req.Method == "shutdown" {
+		return result(req.ID, nil), true
+	}
+
+	h, ok := handlers[req.Method]
+	if !ok {
+		if req.ID == nil {
+			return nil, false
+		}
+		return errorResponse(req.ID, lsp.CodeMethodNotFound, "method not found: "+req.Method), false
+	}
+
+	res, err := h(s, req.Params)
+	if req.ID == nil {
+		return nil, false
+	}
+	if err != nil {
+		if rerr, ok := err.(*lsp.ResponseError); ok {
+			return errorResponse(req.ID, rerr.Code, rerr.Message), false
+		}
+		return errorResponse(req.ID, lsp.CodeInvalidParams, err.Error()), false
+	}
+	return result(req.ID, res), false
+}
+
+func result(id *json.RawMessage, res interface{}) *Response {
+	if res == nil {
+		res = struct{}{}
+	}
+	return &Response{JSONRPC: "2.0", ID: id, Result: res}
+}
+
+func errorResponse(id *json.RawMessage, code int, message string) *Response {
+	return &Response{JSONRPC: "2.0", ID: id, Error: &lsp.ResponseError{Code: code, Message: message}}
+}
+
+func textParams(params json.RawMessage) (*TextParams, error) {
+	var p TextParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return &lsp.ResponseError{Code: lsp.CodeInvalidParams, Message: "missing params"}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &lsp.ResponseError{Code: lsp.CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func handleParse(s *Server, params json.RawMessage) (interface{}, error) {
+	p, err := textParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &ParseResult{Actions: []*model.Action{}, Workflows: []*model.Workflow{}}
+	config, err := parser.Parse(strings.NewReader(p.Text), s.options...)
+	if config != nil {
+		ret.Actions, ret.Workflows = config.Actions, config.Workflows
+	} else if perr, ok := err.(*parser.Error); ok {
+		ret.Actions, ret.Workflows = perr.Actions, perr.Workflows
+	}
+	ret.Diagnostics = lsp.FromError(p.URI, []byte(p.Text), err)
+	return ret, nil
+}
+
+func handleValidate(s *Server, params json.RawMessage) (interface{}, error) {
+	p, err := textParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = parser.Parse(strings.NewReader(p.Text), s.options...)
+	return &ValidateResult{Valid: valid(err), Diagnostics: lsp.FromError(p.URI, []byte(p.Text), err)}, nil
+}
+
+func handleFormat(s *Server, params json.RawMessage) (interface{}, error) {
+	p, err := textParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := printer.Format([]byte(p.Text))
+	if err != nil {
+		return nil, err
+	}
+	return &FormatResult{Text: string(text)}, nil
+}
+
+func handleComplete(s *Server, params json.RawMessage) (interface{}, error) {
+	var p PositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return &CompleteResult{Items: lsp.CompletionsAt([]byte(p.Text), p.Position)}, nil
+}
+
+func handleHover(s *Server, params json.RawMessage) (interface{}, error) {
+	var p PositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return &HoverResult{Hover: lsp.HoverAt([]byte(p.Text), p.Position)}, nil
+}
+
+// valid reports whether a file is usable despite err, that is, whether
+// it has nothing worse than warnings.
+func valid(err error) bool {
+	if perr, ok := err.(*parser.Error); ok {
+		return perr.FirstError(parser.ERROR) == nil
+	}
+	return err == nil
+}
@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/lsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, requests ...string) []map[string]interface{} {
+	var out bytes.Buffer
+	err := NewServer().Serve(strings.NewReader(strings.Join(requests, "\n")), &out)
+	require.NoError(t, err)
+
+	var ret []map[string]interface{}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]interface{}
+		require.NoError(t, dec.Decode(&resp))
+		ret = append(ret, resp)
+	}
+	return ret
+}
+
+func TestParse(t *testing.T) {
+	resps := serve(t, `{"jsonrpc":"2.0","id":1,"method":"parse","params":{"text":"action \"a\" { uses = \"./x\" }\nworkflow \"w\" {\n  on = \"push\"\n  resolves = \"a\"\n}"}}`)
+	require.Len(t, resps, 1)
+	assert.Equal(t, float64(1), resps[0]["id"])
+	result := resps[0]["result"].(map[string]interface{})
+	assert.Len(t, result["actions"], 1)
+	assert.Len(t, result["workflows"], 1)
+	assert.Empty(t, result["diagnostics"])
+}
+
+func TestValidate(t *testing.T) {
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":"a","method":"validate","params":{"text":"action \"a\" {}"}}`,
+		`{"jsonrpc":"2.0","id":"b","method":"validate","params":{"text":"action \"a\" {\n  uses = \"./x\"\n  bananas = 1\n}"}}`,
+	)
+	require.Len(t, resps, 2)
+
+	result := resps[0]["result"].(map[string]interface{})
+	assert.Equal(t, false, result["valid"])
+	require.Len(t, result["diagnostics"], 1)
+	assert.Contains(t, result["diagnostics"].([]interface{})[0].(map[string]interface{})["message"], "`uses' attribute")
+
+	result = resps[1]["result"].(map[string]interface{})
+	assert.Equal(t, true, result["valid"])
+	assert.Len(t, result["diagnostics"], 1)
+}
+
+func TestFormat(t *testing.T) {
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"format","params":{"text":"action \"a\" { uses=\"./x\" } # a"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"format","params":{"text":"action \"a\" {"}}`,
+	)
+	require.Len(t, resps, 2)
+
+	result := resps[0]["result"].(map[string]interface{})
+	assert.Equal(t, "action \"a\" {\n  uses = \"./x\"\n} # a\n", result["text"])
+	assert.Equal(t, float64(lsp.CodeInvalidParams), resps[1]["error"].(map[string]interface{})["code"])
+}
+
+func TestCompleteAndHover(t *testing.T) {
+	const text = `action \"a\" { uses = \"./a\" }\nworkflow \"w\" {\n  on = \"push\"\n  resolves = \"\"\n}`
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"complete","params":{"text":"`+text+`","position":{"line":3,"character":14}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"hover","params":{"text":"`+text+`","position":{"line":0,"character":22}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"hover","params":{"text":"`+text+`","position":{"line":0,"character":0}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"complete"}`,
+	)
+	require.Len(t, resps, 4)
+
+	result := resps[0]["result"].(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{"label": "a", "kind": float64(lsp.CompletionItemReference)}}, result["items"])
+
+	hover := resps[1]["result"].(map[string]interface{})["hover"].(map[string]interface{})
+	assert.Equal(t, "Action in this repository\n- Path: `./a`", hover["contents"].(map[string]interface{})["value"])
+
+	assert.Nil(t, resps[2]["result"].(map[string]interface{})["hover"])
+	assert.Equal(t, float64(lsp.CodeInvalidParams), resps[3]["error"].(map[string]interface{})["code"])
+}
+
+func TestErrors(t *testing.T) {
+	resps := serve(t,
+		`not json`,
+		`{"jsonrpc":"1.0","id":1,"method":"parse"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"bananas"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"parse"}`,
+		`{"jsonrpc":"2.0","method":"bananas"}`,
+	)
+	require.Len(t, resps, 4)
+
+	codes := []float64{lsp.CodeParseError, lsp.CodeInvalidRequest, lsp.CodeMethodNotFound, lsp.CodeInvalidParams}
+	for i, code := range codes {
+		assert.Equal(t, code, resps[i]["error"].(map[string]interface{})["code"], "response %d", i)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"parse","params":{"text":""}}`,
+	)
+	require.Len(t, resps, 1)
+	assert.Equal(t, float64(1), resps[0]["id"])
+}
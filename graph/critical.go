@@ -0,0 +1,75 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/plan"
+)
+
+// Path is the critical path of a workflow: the chain of actions, each
+// needing the one before, that takes longest, and so decides how long
+// the whole workflow takes.  Speeding up any other action doesn't make
+// the workflow finish sooner.
+type Path struct {
+	Workflow *model.Workflow
+
+	// Actions are the actions on the path, in the order they run.
+	Actions []*model.Action
+
+	// Duration is the estimated wall-clock time of the workflow, the sum
+	// of the durations of the actions on the path, assuming every action
+	// starts as soon as the actions it needs finish.
+	Duration time.Duration
+}
+
+// CriticalPaths returns the critical path of each workflow in c, in the
+// order they appear in c, given an estimate of how long each action
+// takes, keyed by identifier.  Actions without an estimate take no time.
+// Where chains take equally long, the path ends at the action that comes
+// first in the order of plan.Stages, and follows the earliest entry in
+// each `needs'.
+//
+// CriticalPaths returns an error if plan.Stages can't order the actions
+// of a workflow, e.g., because they need each other in a cycle.
+func CriticalPaths(c *model.Configuration, durations map[string]time.Duration) ([]Path, error) {
+	ret := make([]Path, 0, len(c.Workflows))
+	for _, workflow := range c.Workflows {
+		stages, err := plan.Stages(c, workflow.Identifier)
+		if err != nil {
+			return nil, err
+		}
+
+		// finish is when each action finishes if it starts as soon as it
+		// can, and prev the action it waits for last.
+		finish := make(map[string]time.Duration)
+		prev := make(map[string]*model.Action)
+		byID := make(map[string]*model.Action)
+		var last *model.Action
+		for _, stage := range stages {
+			for _, action := range stage {
+				var start time.Duration
+				for _, need := range action.Needs {
+					if f, ok := finish[need]; ok && (prev[action.Identifier] == nil || f > start) {
+						start, prev[action.Identifier] = f, byID[need]
+					}
+				}
+				byID[action.Identifier] = action
+				finish[action.Identifier] = start + durations[action.Identifier]
+				if last == nil || finish[action.Identifier] > finish[last.Identifier] {
+					last = action
+				}
+			}
+		}
+
+		path := Path{Workflow: workflow}
+		if last != nil {
+			path.Duration = finish[last.Identifier]
+			for a := last; a != nil; a = prev[a.Identifier] {
+				path.Actions = append([]*model.Action{a}, path.Actions...)
+			}
+		}
+		ret = append(ret, path)
+	}
+	return ret, nil
+}
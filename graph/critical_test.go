@@ -0,0 +1,84 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pathIdentifiers(p Path) []string {
+	var ret []string
+	for _, a := range p.Actions {
+		ret = append(ret, a.Identifier)
+	}
+	return ret
+}
+
+func TestCriticalPaths(t *testing.T) {
+	config, err := parser.Parse(strings.NewReader(`
+workflow "ci" {
+  on = "push"
+  resolves = ["deploy", "lint"]
+}
+
+workflow "empty" {
+  on = "push"
+  resolves = "lint"
+}
+
+action "deploy" {
+  uses = "./deploy"
+  needs = ["test", "build"]
+}
+action "test" {
+  uses = "./test"
+  needs = "build"
+}
+action "build" { uses = "./build" }
+action "lint" { uses = "./lint" }
+`))
+	require.NoError(t, err)
+
+	paths, err := CriticalPaths(config, map[string]time.Duration{
+		"build":  2 * time.Minute,
+		"test":   5 * time.Minute,
+		"deploy": time.Minute,
+		"lint":   7 * time.Minute,
+	})
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	assert.Equal(t, "ci", paths[0].Workflow.Identifier)
+	assert.Equal(t, []string{"build", "test", "deploy"}, pathIdentifiers(paths[0]))
+	assert.Equal(t, 8*time.Minute, paths[0].Duration)
+	assert.Equal(t, []string{"lint"}, pathIdentifiers(paths[1]))
+	assert.Equal(t, 7*time.Minute, paths[1].Duration)
+
+	// Lint now takes longest on its own.
+	paths, err = CriticalPaths(config, map[string]time.Duration{"lint": 10 * time.Minute, "deploy": time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lint"}, pathIdentifiers(paths[0]))
+	assert.Equal(t, 10*time.Minute, paths[0].Duration)
+
+	// Without estimates, every chain ties, so the path is the first action.
+	paths, err = CriticalPaths(config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build"}, pathIdentifiers(paths[0]))
+	assert.Equal(t, time.Duration(0), paths[0].Duration)
+}
+
+func TestCriticalPathsCycle(t *testing.T) {
+	config := &model.Configuration{
+		Actions: []*model.Action{
+			{Identifier: "a", Needs: []string{"b"}},
+			{Identifier: "b", Needs: []string{"a"}},
+		},
+		Workflows: []*model.Workflow{{Identifier: "w", Resolves: []string{"a"}}},
+	}
+	_, err := CriticalPaths(config, nil)
+	assert.EqualError(t, err, "circular dependency on `a': a -> b -> a")
+}
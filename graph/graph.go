@@ -0,0 +1,109 @@
+// Package graph measures the graph that a configuration's actions and
+// workflows form through `needs' and `resolves', for dashboards that
+// track how complex workflows are and for finding what to parallelize.
+package graph
+
+import (
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/plan"
+)
+
+// Stats describes the graph of a configuration.  Its nodes are the
+// actions and workflows, and its edges are the `needs' and `resolves'
+// entries that name an action that exists.
+type Stats struct {
+	Nodes int
+	Edges int
+
+	// Depth is the largest number of stages any workflow's actions run
+	// in, and Width the largest number of actions in any one stage, as
+	// plan.Stages groups them.
+	Depth int
+	Width int
+
+	// Components is the number of groups of nodes connected to each
+	// other, in either direction, but not to the rest.  An action that
+	// nothing needs and that needs nothing is a component on its own.
+	Components int
+}
+
+// Metrics measures the graph of c.  Workflows that plan.Stages can't
+// order, because they need an action that doesn't exist or have a
+// cycle, count as nodes but not toward Depth and Width.
+func Metrics(c *model.Configuration) Stats {
+	ret := Stats{Nodes: len(c.Actions) + len(c.Workflows)}
+
+	// Actions are nodes 0 through len(c.Actions)-1, and workflows follow.
+	index := make(map[string]int, len(c.Actions))
+	for i, action := range c.Actions {
+		if _, ok := index[action.Identifier]; !ok {
+			index[action.Identifier] = i
+		}
+	}
+	components := newUnionFind(ret.Nodes)
+	link := func(from int, to []string) {
+		seen := make(map[string]bool, len(to))
+		for _, id := range to {
+			n, ok := index[id]
+			if !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ret.Edges++
+			components.union(from, n)
+		}
+	}
+	for i, action := range c.Actions {
+		link(i, action.Needs)
+	}
+	for i, workflow := range c.Workflows {
+		link(len(c.Actions)+i, workflow.Resolves)
+	}
+	ret.Components = components.count
+
+	for _, workflow := range c.Workflows {
+		stages, err := plan.Stages(c, workflow.Identifier)
+		if err != nil {
+			continue
+		}
+		if len(stages) > ret.Depth {
+			ret.Depth = len(stages)
+		}
+		for _, stage := range stages {
+			if len(stage) > ret.Width {
+				ret.Width = len(stage)
+			}
+		}
+	}
+	return ret
+}
+
+// unionFind tracks which of n nodes are connected, and how many groups
+// of connected nodes there are.
+type unionFind struct {
+	parent []int
+	count  int
+}
+
+func newUnionFind(n int) *unionFind {
+	u := &unionFind{parent: make([]int, n), count: n}
+	for i := range u.parent {
+		u.parent[i] = i
+	}
+	return u
+}
+
+func (u *unionFind) find(n int) int {
+	for u.parent[n] != n {
+		u.parent[n] = u.parent[u.parent[n]]
+		n = u.parent[n]
+	}
+	return n
+}
+
+func (u *unionFind) union(a, b int) {
+	if ra, rb := u.find(a), u.find(b); ra != rb {
+		u.parent[ra] = rb
+		u.count--
+	}
+}
@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	config, err := parser.Parse(strings.NewReader(`
+workflow "ci" {
+  on = "push"
+  resolves = ["deploy", "lint"]
+}
+
+workflow "other" {
+  on = "push"
+  resolves = "unrelated"
+}
+
+action "deploy" {
+  uses = "./deploy"
+  needs = ["test", "build"]
+}
+action "test" {
+  uses = "./test"
+  needs = "build"
+}
+action "build" { uses = "./build" }
+action "lint" { uses = "./lint" }
+action "unrelated" { uses = "./unrelated" }
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, Stats{Nodes: 7, Edges: 6, Depth: 3, Width: 2, Components: 2}, Metrics(config))
+}
+
+func TestMetricsInvalid(t *testing.T) {
+	config := &model.Configuration{
+		Actions: []*model.Action{
+			{Identifier: "a", Needs: []string{"b", "b"}},
+			{Identifier: "b", Needs: []string{"a"}},
+			{Identifier: "c", Needs: []string{"missing"}},
+			{Identifier: "d"},
+		},
+		Workflows: []*model.Workflow{
+			{Identifier: "cycle", Resolves: []string{"a"}},
+			{Identifier: "missing", Resolves: []string{"c"}},
+			{Identifier: "d", Resolves: []string{"d"}},
+		},
+	}
+	assert.Equal(t, Stats{Nodes: 7, Edges: 5, Depth: 1, Width: 1, Components: 3}, Metrics(config))
+	assert.Equal(t, Stats{}, Metrics(&model.Configuration{}))
+}
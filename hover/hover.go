@@ -0,0 +1,228 @@
+// Package hover explains the part of a .workflow file at a position, for
+// editors to show when the cursor rests on it.
+package hover
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/hashicorp/hcl/hcl/ast"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// Info is what to show for a part of a file.  Contents is Markdown, and
+// Pos and End span the part of the file it describes.
+type Info struct {
+	Contents string
+	Pos, End model.Pos
+}
+
+// At returns information about the part of source at the given 1-based
+// line and column, counting columns in characters as the parser does, or
+// nil if there is nothing to say.  It explains three kinds of values:
+//
+//   - an action's `uses' value, broken down into its parts: the
+//     repository, path, and ref of another repository's action, the
+//     registry, repository, tag, and digest of a Docker image, or the path
+//     of an action in the same repository;
+//   - an entry of `needs' or `resolves', with a summary of the action it
+//     names;
+//   - a workflow's `on' value, with a description of the event.
+//
+// At returns nil if source isn't valid HCL.
+func At(source []byte, line, col int) *Info {
+	file, err := hclparser.Parse(source)
+	if err != nil {
+		return nil
+	}
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil
+	}
+	offset := model.Pos{Line: line, Column: col}.OffsetIn(source)
+
+	for _, item := range list.Items {
+		obj, ok := item.Val.(*ast.ObjectType)
+		if len(item.Keys) != 2 || !ok {
+			continue
+		}
+		kind := keyName(item.Keys[0])
+		for _, attr := range obj.List.Items {
+			if len(attr.Keys) != 1 {
+				continue
+			}
+			t, ok := stringAt(attr.Val, offset)
+			if !ok {
+				continue
+			}
+			value, _ := t.Value().(string)
+
+			var contents string
+			switch name := keyName(attr.Keys[0]); {
+			case kind == "action" && name == "uses":
+				contents = describeUses(model.ParseUses(value))
+			case kind == "action" && name == "needs", kind == "workflow" && name == "resolves":
+				contents = describeAction(source, value)
+			case kind == "workflow" && name == "on":
+				contents = describeEvent(value)
+			default:
+				return nil
+			}
+			return &Info{Contents: contents, Pos: model.PosAt(source, t.Pos.Offset), End: model.PosAt(source, t.Pos.Offset+len(t.Text))}
+		}
+	}
+	return nil
+}
+
+// stringAt returns the string token in node, a value or a list of
+// values, that offset is in.
+func stringAt(node ast.Node, offset int) (token.Token, bool) {
+	switch n := node.(type) {
+	case *ast.LiteralType:
+		t := n.Token
+		if t.Type == token.STRING && t.Pos.Offset <= offset && offset <= t.Pos.Offset+len(t.Text) {
+			return t, true
+		}
+	case *ast.ListType:
+		for _, elem := range n.List {
+			if t, ok := stringAt(elem, offset); ok {
+				return t, true
+			}
+		}
+	}
+	return token.Token{}, false
+}
+
+// describeUses returns a Markdown description of u.
+func describeUses(u model.Uses) string {
+	var sb strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "\n- %s: `%s`", name, value)
+		}
+	}
+
+	switch u := u.(type) {
+	case *model.UsesRepository:
+		sb.WriteString("Action in another repository")
+		field("Repository", u.Repository)
+		field("Path", u.Path)
+		field("Ref", u.Ref)
+	case *model.UsesDockerImage:
+		sb.WriteString("Docker image")
+		registry := u.Host
+		if u.Port != "" {
+			registry += ":" + u.Port
+		}
+		field("Registry", registry)
+		field("Repository", u.Repository)
+		field("Tag", u.Tag)
+		field("Digest", u.Digest)
+	case *model.UsesPath:
+		sb.WriteString("Action in this repository")
+		field("Path", "./"+u.Path)
+	default:
+		sb.WriteString("Invalid `uses` value: expected a path like `./action`, a Docker image like `docker://alpine`, or a repository like `owner/repo@ref`")
+	}
+	return sb.String()
+}
+
+// describeAction returns a Markdown summary of the action named id in
+// source: what it uses, what it needs, and where it is.
+func describeAction(source []byte, id string) string {
+	config, _, err := parser.ParseWithDiagnostics(bytes.NewReader(source))
+	var action *model.Action
+	if err == nil {
+		action = config.GetAction(id)
+	}
+	if action == nil {
+		return fmt.Sprintf("No action `%s` in this file", id)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Action `%s`", id)
+	if action.Pos.IsValid() {
+		fmt.Fprintf(&sb, ", on line %d", action.Pos.Line)
+	}
+	if action.Uses != nil {
+		fmt.Fprintf(&sb, "\n- Uses: `%s`", action.Uses)
+	}
+	if len(action.Needs) > 0 {
+		fmt.Fprintf(&sb, "\n- Needs: `%s`", strings.Join(action.Needs, "`, `"))
+	}
+	return sb.String()
+}
+
+// describeEvent returns a Markdown description of the event in an `on'
+// value, which may have an activity filter or be a schedule.
+func describeEvent(on string) string {
+	if strings.HasPrefix(on, "schedule(") && strings.HasSuffix(on, ")") {
+		return fmt.Sprintf("Event `schedule`: %s\n- Cron: `%s`", eventDescriptions["schedule"], on[len("schedule("):len(on)-1])
+	}
+
+	eventType, activity := on, ""
+	if i := strings.IndexByte(on, '.'); i >= 0 {
+		eventType, activity = on[:i], on[i+1:]
+	}
+	desc, ok := eventDescriptions[strings.ToLower(eventType)]
+	if !ok {
+		return fmt.Sprintf("Unknown event `%s`", eventType)
+	}
+
+	ret := fmt.Sprintf("Event `%s`: %s", strings.ToLower(eventType), desc)
+	if activity != "" {
+		ret += fmt.Sprintf("\n- Activity: `%s`", activity)
+		if !parser.IsAllowedEventFilter(eventType, activity) {
+			ret += " (unknown)"
+		}
+	} else if activities := parser.EventActivities(eventType); len(activities) > 0 {
+		ret += fmt.Sprintf("\n- Activities: `%s`", strings.Join(activities, "`, `"))
+	}
+	return ret
+}
+
+// eventDescriptions says when each event type occurs.
+var eventDescriptions = map[string]string{
+	"check_run":                   "a check run is created, rerequested, completed, or has a requested action.",
+	"check_suite":                 "a check suite is completed, requested, or rerequested.",
+	"commit_comment":              "a commit is commented on.",
+	"create":                      "a branch or tag is created.",
+	"delete":                      "a branch or tag is deleted.",
+	"deployment":                  "a deployment is created.",
+	"deployment_status":           "a deployment's status changes.",
+	"fork":                        "the repository is forked.",
+	"gollum":                      "a wiki page is created or updated.",
+	"issue_comment":               "an issue or pull request is commented on.",
+	"issues":                      "an issue is opened, edited, closed, or otherwise changed.",
+	"label":                       "a label is created, edited, or deleted.",
+	"member":                      "a collaborator is added, removed, or changed.",
+	"milestone":                   "a milestone is created, closed, opened, edited, or deleted.",
+	"page_build":                  "a GitHub Pages site is built.",
+	"project_card":                "a project card is created, edited, moved, converted, or deleted.",
+	"project_column":              "a project column is created, edited, moved, or deleted.",
+	"project":                     "a project is created, edited, closed, reopened, or deleted.",
+	"public":                      "the repository is made public.",
+	"pull_request_review_comment": "a pull request's diff is commented on.",
+	"pull_request_review":         "a pull request review is submitted, edited, or dismissed.",
+	"pull_request":                "a pull request is opened, synchronized, closed, or otherwise changed.",
+	"push":                        "commits or tags are pushed.",
+	"release":                     "a release is published, created, edited, or deleted.",
+	"repository_dispatch":         "a client sends a repository_dispatch request to the API.",
+	"schedule":                    "the workflow runs at the times given by a cron expression.",
+	"status":                      "a commit's status changes.",
+	"watch":                       "the repository is starred.",
+}
+
+// keyName returns the name of an object key, without quotes.
+func keyName(key *ast.ObjectKey) string {
+	if key.Token.Type == token.STRING {
+		if s, ok := key.Token.Value().(string); ok {
+			return s
+		}
+	}
+	return key.Token.Text
+}
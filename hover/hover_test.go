@@ -0,0 +1,66 @@
+package hover
+
+import (
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const src = `workflow "w" {
+  on = "pull_request.opened"
+  resolves = ["b", "missing"]
+}
+workflow "s" {
+  on = "schedule(0 * * * *)"
+  resolves = "a"
+}
+action "a" {
+  uses = "actions/bin/sh@v1"
+  args = "ü"
+}
+action "b" {
+  uses = "./b"
+  needs = "a"
+}
+`
+
+func TestUses(t *testing.T) {
+	info := At([]byte(src), 10, 12)
+	require.NotNil(t, info)
+	assert.Equal(t, "Action in another repository\n- Repository: `actions/bin`\n- Path: `sh`\n- Ref: `v1`", info.Contents)
+	assert.Equal(t, model.Pos{Line: 10, Column: 10, Offset: 161}, info.Pos)
+	assert.Equal(t, model.Pos{Line: 10, Column: 29, Offset: 180}, info.End)
+
+	assert.Nil(t, At([]byte(src), 11, 11))
+	assert.Nil(t, At([]byte(src), 1, 1))
+	assert.Nil(t, At([]byte(`action "a" {`), 1, 1))
+}
+
+func TestNeeds(t *testing.T) {
+	info := At([]byte(src), 15, 12)
+	require.NotNil(t, info)
+	assert.Equal(t, "Action `a`, on line 9\n- Uses: `actions/bin/sh@v1`", info.Contents)
+
+	info = At([]byte(src), 3, 16)
+	require.NotNil(t, info)
+	assert.Equal(t, "Action `b`, on line 13\n- Uses: `./b`\n- Needs: `a`", info.Contents)
+
+	info = At([]byte(src), 3, 22)
+	require.NotNil(t, info)
+	assert.Equal(t, "No action `missing` in this file", info.Contents)
+}
+
+func TestOn(t *testing.T) {
+	info := At([]byte(src), 2, 10)
+	require.NotNil(t, info)
+	assert.Equal(t, "Event `pull_request`: a pull request is opened, synchronized, closed, or otherwise changed.\n- Activity: `opened`", info.Contents)
+
+	info = At([]byte(src), 6, 10)
+	require.NotNil(t, info)
+	assert.Equal(t, "Event `schedule`: the workflow runs at the times given by a cron expression.\n- Cron: `0 * * * *`", info.Contents)
+
+	assert.Equal(t, "Unknown event `bananas`", describeEvent("bananas"))
+	assert.Contains(t, describeEvent("issues"), "- Activities: `opened`, `edited`")
+}
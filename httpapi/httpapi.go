@@ -0,0 +1,156 @@
+// Package httpapi implements an HTTP endpoint that parses .workflow files,
+// for web-based editors that check a file as it is written.
+//
+// A client POSTs the text of a file as the request body, and gets back a
+// JSON object like this:
+//
+//	{
+//	  "valid": false,
+//	  "configuration": {"version": 0, "actions": [...], "workflows": [...]},
+//	  "errors": [
+//	    {"message": "Unknown action attribute `bananas'", "code": "W_UNKNOWN_ATTRIBUTE",
+//	     "severity": "warning", "line": 4, "column": 13, "offset": 52},
+//	    ...
+//	  ]
+//	}
+//
+// The configuration and errors are in the stable JSON encodings of the
+// model and parser packages.  A file is valid if it has nothing worse
+// than warnings; the configuration holds whatever the parser could make
+// sense of either way.  Problems with the file, including its size, are
+// always errors in a 200 response.  Other responses have a JSON object
+// with just a "message".
+//
+// The handler reads the whole body before it parses it, and does not
+// bound how long the client may take to send it; serve it from an
+// http.Server with a ReadTimeout, so that a slow client can't hold a
+// request open.  A request whose body times out gets a 408 Request
+// Timeout response.
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+)
+
+// DefaultMaxBodySize is the largest file a Handler parses unless told
+// otherwise: 512 KiB.
+const DefaultMaxBodySize = 512 * 1024
+
+// DefaultTimeout is how long a Handler spends on a request unless told
+// otherwise.
+const DefaultTimeout = 5 * time.Second
+
+// Response is the body of a successful response.
+type Response struct {
+	Valid         bool                 `json:"valid"`
+	Configuration *model.Configuration `json:"configuration"`
+	Errors        parser.ErrorList     `json:"errors"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// Handler parses the .workflow files POSTed to it.  Its zero value is not
+// usable; use NewHandler.
+type Handler struct {
+	// MaxBodySize is the size, in bytes, of the largest file the handler
+	// parses.  A larger file gets a single FATAL E_FILE_TOO_LARGE error,
+	// and the handler reads no more of it than it needs to tell.  It
+	// overrides any parser.WithMaxFileSize option.  A size of zero or
+	// less disables the limit.
+	MaxBodySize int
+
+	// Timeout bounds the time spent parsing a file, once it has been
+	// read.  Past it, the handler gives up and responds 503 Service
+	// Unavailable.  A timeout of zero or less disables it.  The time
+	// spent reading the file is up to the server's ReadTimeout.
+	Timeout time.Duration
+
+	options []parser.OptionFunc
+}
+
+// NewHandler creates a handler that parses every file it is sent with the
+// given options, and the default size limit and timeout.
+func NewHandler(options ...parser.OptionFunc) *Handler {
+	return &Handler{
+		MaxBodySize: DefaultMaxBodySize,
+		Timeout:     DefaultTimeout,
+		options:     options,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, &errorResponse{"method not allowed: " + r.Method})
+		return
+	}
+
+	body, err := h.readBody(w, r)
+	if err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			writeJSON(w, http.StatusRequestTimeout, &errorResponse{"timed out reading request: " + err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, &errorResponse{"unable to read request: " + err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	options := append([]parser.OptionFunc{}, h.options...)
+	options = append(options, parser.WithMaxFileSize(h.MaxBodySize))
+	config, errs, err := parser.ParseWithDiagnosticsContext(ctx, bytes.NewReader(body), options...)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, &errorResponse{"unable to parse: " + err.Error()})
+		return
+	}
+
+	if errs == nil {
+		errs = parser.ErrorList{}
+	}
+	writeJSON(w, http.StatusOK, &Response{
+		Valid:         len(errs.FilterSeverity(parser.ERROR)) == 0,
+		Configuration: config,
+		Errors:        errs,
+	})
+}
+
+// readBody reads the body of r, up to one byte past MaxBodySize, which is
+// enough for the parser to report the file as too large.  The body is
+// read here, rather than by the parser, so that none of it is read after
+// ServeHTTP returns.
+func (h *Handler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	if h.MaxBodySize <= 0 {
+		return ioutil.ReadAll(r.Body)
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, int64(h.MaxBodySize)+1))
+	if err != nil && len(body) > h.MaxBodySize {
+		// http.MaxBytesReader returns the bytes up to its limit before
+		// the error that says the body is longer.
+		return body, nil
+	}
+	return body, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,163 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func post(h http.Handler, body io.Reader) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", body))
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(parser.WithSeverityOverride(parser.CodeUnknownAttribute, parser.ERROR))
+
+	w := post(h, strings.NewReader(`workflow "w" {
+  on = "push"
+  resolves = ["a"]
+}
+
+action "a" {
+  uses = "./a"
+}
+`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Valid)
+	assert.Empty(t, resp.Errors)
+	require.Len(t, resp.Configuration.Actions, 1)
+	assert.Equal(t, "a", resp.Configuration.Actions[0].Identifier)
+	require.Len(t, resp.Configuration.Workflows, 1)
+	assert.Contains(t, w.Body.String(), `"errors":[]`)
+
+	w = post(h, strings.NewReader(`action "a" {
+  uses = "./a"
+  bananas = 1
+}
+`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp = Response{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Valid, "options should apply")
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, parser.CodeUnknownAttribute, resp.Errors[0].Code)
+	assert.Equal(t, 3, resp.Errors[0].Pos.Line)
+	require.Len(t, resp.Configuration.Actions, 1)
+
+	w = post(h, strings.NewReader(`action "a" {`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp = Response{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Valid)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, parser.CodeSyntax, resp.Errors[0].Code)
+	assert.Empty(t, resp.Configuration.Actions)
+}
+
+func TestHandlerMaxBodySize(t *testing.T) {
+	h := NewHandler(parser.WithMaxFileSize(0))
+	h.MaxBodySize = 16
+
+	w := post(h, strings.NewReader(`action "a" { uses = "./a" }`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Valid)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, parser.CodeFileTooLarge, resp.Errors[0].Code)
+	assert.Equal(t, 16, resp.Errors[0].Pos.Offset)
+}
+
+// blockingReader never returns from Read until it is closed.
+type blockingReader chan struct{}
+
+func (r blockingReader) Read([]byte) (int, error) {
+	<-r
+	return 0, io.ErrUnexpectedEOF
+}
+
+// slowReader returns a byte of text at a time, after waiting for delay.
+// Once the text runs out, it blocks until done is closed.
+type slowReader struct {
+	text  string
+	delay time.Duration
+	done  chan struct{}
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if r.text == "" {
+		<-r.done
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	n := copy(p[:1], r.text)
+	r.text = r.text[n:]
+	return n, nil
+}
+
+func TestHandlerSlowBody(t *testing.T) {
+	s := httptest.NewUnstartedServer(NewHandler())
+	s.Config.ReadTimeout = 200 * time.Millisecond
+	s.Start()
+	defer s.Close()
+
+	done := make(chan struct{})
+	close(done)
+	resp, err := http.Post(s.URL, "text/plain", &slowReader{`action "a" {}`, time.Millisecond, done})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// A body that is still being sent when the server's ReadTimeout passes
+	// gets a response of its own.
+	done = make(chan struct{})
+	defer close(done)
+	resp, err = http.Post(s.URL, "text/plain", &slowReader{`action "a" {`, time.Millisecond, done})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusRequestTimeout, resp.StatusCode)
+	var body errorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Contains(t, body.Message, "timed out reading request: ")
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	h := NewHandler()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`action "a" {}`)).WithContext(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"message": "unable to parse: context canceled"}`, w.Body.String())
+}
+
+func TestHandlerErrors(t *testing.T) {
+	h := NewHandler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+	assert.JSONEq(t, `{"message": "method not allowed: GET"}`, w.Body.String())
+
+	r := make(blockingReader)
+	close(r)
+	w = post(h, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message": "unable to read request: unexpected EOF"}`, w.Body.String())
+}
@@ -0,0 +1,342 @@
+package jobs
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// FromConfiguration converts each workflow in c to a YAML workflow.  Each
+// action the workflow resolves, directly or through `needs', becomes a
+// job with a single step, and `needs' between actions become `needs'
+// between jobs.  Actions that no workflow resolves are dropped.
+//
+// Actions that extend a template get its attributes first, as
+// Configuration.ResolveTemplate says.  The `runs' and `args' attributes
+// become the `entrypoint' and `args' inputs of the step.  Both are
+// strings in YAML workflows, so list-form commands are joined with
+// spaces.  The step's environment holds the variables of the file's and
+// the workflow's `env' blocks as well as the action's, and secrets
+// become environment variables set from the `secrets' context.  An
+// action's matrix becomes the job's matrix, and each of its dimensions
+// an environment variable, like MATRIX_GO, set from the `matrix'
+// context.
+//
+// Event filters, like the `opened' in `pull_request.opened', are dropped,
+// since Workflow lists only event types: the converted workflow runs for
+// every activity of each type.
+func FromConfiguration(c *model.Configuration) []*Workflow {
+	ids := jobIDs(c.Actions)
+
+	ret := make([]*Workflow, 0, len(c.Workflows))
+	for _, w := range c.Workflows {
+		workflow := &Workflow{Name: w.Identifier}
+		for _, event := range w.On {
+			if !containsString(workflow.On, event.Type) {
+				workflow.On = append(workflow.On, event.Type)
+			}
+			if event.Schedule != nil {
+				workflow.Schedules = append(workflow.Schedules, event.Schedule.Expression)
+			}
+		}
+
+		resolved := resolvedActions(c, w.Resolves)
+		for _, action := range c.Actions {
+			if resolved[action.Identifier] {
+				action = c.ResolveTemplate(action)
+				workflow.Jobs = append(workflow.Jobs, jobFromAction(action, c.ResolveEnv(w, action), ids))
+			}
+		}
+
+		ret = append(ret, workflow)
+	}
+
+	return ret
+}
+
+// resolvedActions returns the identifiers of all the actions needed,
+// directly or transitively, to resolve the given actions.
+func resolvedActions(c *model.Configuration, resolves []string) map[string]bool {
+	ret := make(map[string]bool)
+	queue := append([]string(nil), resolves...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if ret[id] {
+			continue
+		}
+		action := c.GetAction(id)
+		if action == nil {
+			continue
+		}
+		ret[id] = true
+		queue = append(queue, action.Needs...)
+	}
+	return ret
+}
+
+var invalidJobIDChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// jobIDs maps each action identifier to a unique, valid job ID.  Job IDs
+// must start with a letter or underscore and contain only alphanumerics,
+// `-', and `_'.
+func jobIDs(actions []*model.Action) map[string]string {
+	ret := make(map[string]string, len(actions))
+	used := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		base := invalidJobIDChars.ReplaceAllString(action.Identifier, "-")
+		if base == "" || !isJobIDStart(base[0]) {
+			base = "_" + base
+		}
+		id := base
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%s-%d", base, i)
+		}
+		used[id] = true
+		ret[action.Identifier] = id
+	}
+	return ret
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isJobIDStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// jobFromAction converts action to a job.  Env holds the variables the
+// action runs with, including those of the file and the workflow.
+func jobFromAction(action *model.Action, env map[string]string, ids map[string]string) *Job {
+	step := &Step{Name: action.Identifier, If: action.If, ContinueOnError: action.ContinueOnError}
+	if action.Uses != nil {
+		step.Uses = action.Uses.String()
+	}
+
+	var args []string
+	if action.Runs != nil {
+		if runs := action.Runs.Split(); len(runs) > 0 {
+			step.With = map[string]string{"entrypoint": runs[0]}
+			args = runs[1:]
+		}
+	}
+	if action.Args != nil {
+		args = append(args, action.Args.Split()...)
+	}
+	if len(args) > 0 {
+		if step.With == nil {
+			step.With = make(map[string]string)
+		}
+		step.With["args"] = strings.Join(args, " ")
+	}
+
+	if len(env) > 0 || len(action.Secrets) > 0 || len(action.Matrix) > 0 {
+		step.Env = make(map[string]string, len(env)+len(action.Secrets)+len(action.Matrix))
+		for k, v := range env {
+			step.Env[k] = v
+		}
+		for _, secret := range action.Secrets {
+			step.Env[secret] = "${{ secrets." + secret + " }}"
+		}
+		for k := range action.Matrix {
+			step.Env[model.MatrixEnvName(k)] = "${{ matrix." + k + " }}"
+		}
+	}
+
+	job := &Job{
+		ID:     ids[action.Identifier],
+		Name:   action.Identifier,
+		RunsOn: DefaultRunsOn,
+		Matrix: action.Matrix,
+		Steps:  []*Step{step},
+	}
+	for _, need := range action.Needs {
+		if id, ok := ids[need]; ok {
+			job.Needs = append(job.Needs, id)
+		}
+	}
+
+	return job
+}
+
+// ToConfiguration converts YAML workflows to a single Configuration.  It
+// is the inverse of FromConfiguration: a job with a single step becomes
+// an action named after the job, and a job with several steps becomes a
+// chain of actions, each needing the one before.  Each workflow resolves
+// the jobs that no other job in it needs.  Actions shared by several
+// workflows must be identical in each of them.
+//
+// A job's matrix becomes the matrix of each of its actions.  The
+// configuration declares the lowest version that has every feature its
+// actions use.
+//
+// It returns an error for anything the .workflow format cannot express,
+// like steps that run a shell command instead of using an action.
+func ToConfiguration(workflows []*Workflow) (*model.Configuration, error) {
+	c := &model.Configuration{}
+
+	for _, w := range workflows {
+		// Work out the action names for every job first, since jobs can
+		// need jobs that are defined after them.
+		names := make(map[string][]string, len(w.Jobs))
+		for _, job := range w.Jobs {
+			names[job.ID] = stepActionNames(job)
+		}
+
+		needed := make(map[string]bool)
+		for _, job := range w.Jobs {
+			actions, err := actionsFromJob(job, names)
+			if err != nil {
+				return nil, fmt.Errorf("workflow `%s': %s", w.Name, err)
+			}
+			for _, action := range actions {
+				if existing := c.GetAction(action.Identifier); existing != nil {
+					if !reflect.DeepEqual(existing, action) {
+						return nil, fmt.Errorf("workflow `%s': action `%s' is defined differently by another workflow", w.Name, action.Identifier)
+					}
+					continue
+				}
+				c.Actions = append(c.Actions, action)
+			}
+			for _, need := range job.Needs {
+				needed[need] = true
+			}
+		}
+
+		workflow := &model.Workflow{Identifier: w.Name}
+		for _, on := range w.On {
+			if on != model.ScheduleEventType {
+				workflow.On = append(workflow.On, model.ParseEvent(on))
+				continue
+			}
+			for _, cron := range w.Schedules {
+				workflow.On = append(workflow.On, model.ParseEvent(model.ScheduleEventType+"("+cron+")"))
+			}
+		}
+		for _, job := range w.Jobs {
+			if !needed[job.ID] && len(names[job.ID]) > 0 {
+				stepNames := names[job.ID]
+				workflow.Resolves = append(workflow.Resolves, stepNames[len(stepNames)-1])
+			}
+		}
+		c.Workflows = append(c.Workflows, workflow)
+	}
+
+	c.Version = c.RequiredVersion()
+	return c, nil
+}
+
+// stepActionNames returns the identifiers of the actions that the steps
+// of a job turn into.
+func stepActionNames(job *Job) []string {
+	name := job.Name
+	if name == "" {
+		name = job.ID
+	}
+	if len(job.Steps) == 1 {
+		return []string{name}
+	}
+
+	ret := make([]string, len(job.Steps))
+	for i, step := range job.Steps {
+		if step.Name != "" {
+			ret[i] = name + " / " + step.Name
+		} else {
+			ret[i] = fmt.Sprintf("%s / %d", name, i+1)
+		}
+	}
+	return ret
+}
+
+var secretRef = regexp.MustCompile(`\A\$\{\{\s*secrets\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}\z`)
+
+var matrixRef = regexp.MustCompile(`\A\$\{\{\s*matrix\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}\z`)
+
+func actionsFromJob(job *Job, names map[string][]string) ([]*model.Action, error) {
+	var needs []string
+	for _, need := range job.Needs {
+		stepNames, ok := names[need]
+		if !ok {
+			return nil, fmt.Errorf("job `%s' needs unknown job `%s'", job.ID, need)
+		}
+		if len(stepNames) > 0 {
+			needs = append(needs, stepNames[len(stepNames)-1])
+		}
+	}
+
+	stepNames := names[job.ID]
+	ret := make([]*model.Action, 0, len(job.Steps))
+	for i, step := range job.Steps {
+		if step.Uses == "" {
+			return nil, fmt.Errorf("step %d of job `%s' runs a command, which cannot be represented as an action", i+1, job.ID)
+		}
+
+		action := &model.Action{
+			Identifier:      stepNames[i],
+			Uses:            model.ParseUses(step.Uses),
+			Needs:           needs,
+			If:              step.If,
+			ContinueOnError: step.ContinueOnError,
+			Matrix:          job.Matrix,
+		}
+
+		keys := sortedKeys(step.With)
+		for _, k := range keys {
+			v := step.With[k]
+			switch k {
+			case "entrypoint":
+				action.Runs = &model.StringCommand{Value: v}
+			case "args":
+				action.Args = &model.StringCommand{Value: v}
+			default:
+				// the runner passes other inputs as INPUT_<NAME>
+				setEnv(action, "INPUT_"+strings.ToUpper(k), v)
+			}
+		}
+
+		for _, k := range sortedKeys(step.Env) {
+			v := step.Env[k]
+			if m := secretRef.FindStringSubmatch(v); m != nil && m[1] == k {
+				action.Secrets = append(action.Secrets, k)
+				continue
+			}
+			if m := matrixRef.FindStringSubmatch(v); m != nil && model.MatrixEnvName(m[1]) == k {
+				if _, ok := job.Matrix[m[1]]; ok {
+					continue
+				}
+			}
+			setEnv(action, k, v)
+		}
+
+		ret = append(ret, action)
+		needs = []string{action.Identifier}
+	}
+
+	return ret, nil
+}
+
+func setEnv(action *model.Action, k, v string) {
+	if action.Env == nil {
+		action.Env = make(map[string]string)
+	}
+	action.Env[k] = v
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
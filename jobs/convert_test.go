@@ -0,0 +1,168 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromConfiguration(t *testing.T) {
+	c := &model.Configuration{
+		Actions: []*model.Action{
+			{Identifier: "Build it", Uses: &model.UsesDockerImage{Image: "golang", Repository: "golang"}, Runs: &model.StringCommand{Value: "make build"}},
+			{
+				Identifier: "test",
+				Uses:       &model.UsesRepository{Repository: "actions/bin", Path: "sh", Ref: "master"},
+				Needs:      []string{"Build it"},
+				Args:       &model.ListCommand{Values: []string{"make", "test"}},
+				Env:        map[string]string{"GOOS": "linux"},
+				Secrets:    []string{"TOKEN"},
+			},
+			{Identifier: "unused", Uses: &model.UsesPath{Path: "x"}},
+		},
+		Workflows: []*model.Workflow{
+			{Identifier: "ci", On: model.ParseEvents("push"), Resolves: []string{"test"}},
+		},
+	}
+
+	workflows := FromConfiguration(c)
+	require.Len(t, workflows, 1)
+	w := workflows[0]
+	assert.Equal(t, "ci", w.Name)
+	assert.Equal(t, []string{"push"}, w.On)
+	require.Len(t, w.Jobs, 2)
+
+	assert.Equal(t, &Job{
+		ID:     "Build-it",
+		Name:   "Build it",
+		RunsOn: DefaultRunsOn,
+		Steps: []*Step{{
+			Name: "Build it",
+			Uses: "docker://golang",
+			With: map[string]string{"entrypoint": "make", "args": "build"},
+		}},
+	}, w.Jobs[0])
+	assert.Equal(t, &Job{
+		ID:     "test",
+		Name:   "test",
+		RunsOn: DefaultRunsOn,
+		Needs:  []string{"Build-it"},
+		Steps: []*Step{{
+			Name: "test",
+			Uses: "actions/bin/sh@master",
+			With: map[string]string{"args": "make test"},
+			Env:  map[string]string{"GOOS": "linux", "TOKEN": "${{ secrets.TOKEN }}"},
+		}},
+	}, w.Jobs[1])
+	assert.Nil(t, w.GetJob("unused"))
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := &model.Configuration{
+		Actions: []*model.Action{
+			{Identifier: "a", Uses: &model.UsesPath{Path: "a"}, Runs: &model.StringCommand{Value: "run"}, Args: &model.StringCommand{Value: "x y"}},
+			{Identifier: "b", Uses: &model.UsesPath{Path: "b"}, Needs: []string{"a"}, Secrets: []string{"S"}},
+			{Identifier: "c", Uses: &model.UsesDockerImage{Image: "alpine", Repository: "alpine"}, Needs: []string{"a"}, Env: map[string]string{"K": "V"}},
+		},
+		Workflows: []*model.Workflow{
+			{Identifier: "one", On: model.ParseEvents("push"), Resolves: []string{"b", "c"}},
+			{Identifier: "two", On: model.ParseEvents("release"), Resolves: []string{"c"}},
+		},
+	}
+
+	c2, err := ToConfiguration(FromConfiguration(c))
+	require.NoError(t, err)
+	assert.Equal(t, c, c2)
+}
+
+func TestToConfigurationMultipleSteps(t *testing.T) {
+	c, err := ToConfiguration([]*Workflow{{
+		Name: "ci",
+		On:   []string{"push"},
+		Jobs: []*Job{
+			{
+				ID:    "test",
+				Needs: []string{"build"},
+				Steps: []*Step{
+					{Name: "checkout", Uses: "actions/checkout@v1"},
+					{Uses: "docker://golang", With: map[string]string{"entrypoint": "go", "args": "test", "go-version": "1.12"}},
+				},
+			},
+			{ID: "build", Name: "Build", Steps: []*Step{{Uses: "./build"}}},
+		},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*model.Action{
+		{Identifier: "test / checkout", Uses: &model.UsesRepository{Repository: "actions/checkout", Ref: "v1"}, Needs: []string{"Build"}},
+		{
+			Identifier: "test / 2",
+			Uses:       &model.UsesDockerImage{Image: "golang", Repository: "golang"},
+			Needs:      []string{"test / checkout"},
+			Runs:       &model.StringCommand{Value: "go"},
+			Args:       &model.StringCommand{Value: "test"},
+			Env:        map[string]string{"INPUT_GO-VERSION": "1.12"},
+		},
+		{Identifier: "Build", Uses: &model.UsesPath{Path: "build"}},
+	}, c.Actions)
+	assert.Equal(t, []*model.Workflow{{Identifier: "ci", On: model.ParseEvents("push"), Resolves: []string{"test / 2"}}}, c.Workflows)
+}
+
+func TestMatrix(t *testing.T) {
+	c := &model.Configuration{
+		Actions: []*model.Action{{
+			Identifier: "test",
+			Uses:       &model.UsesDockerImage{Image: "golang", Repository: "golang"},
+			Matrix:     map[string][]string{"go": {"1.11", "1.12"}},
+		}},
+		Workflows: []*model.Workflow{{Identifier: "ci", On: model.ParseEvents("push"), Resolves: []string{"test"}}},
+	}
+	workflows := FromConfiguration(c)
+	require.Len(t, workflows, 1)
+	job := workflows[0].Jobs[0]
+	assert.Equal(t, map[string][]string{"go": {"1.11", "1.12"}}, job.Matrix)
+	assert.Equal(t, map[string]string{"MATRIX_GO": "${{ matrix.go }}"}, job.Steps[0].Env)
+
+	back, err := ToConfiguration(workflows)
+	require.NoError(t, err)
+	assert.Equal(t, c.Actions, back.Actions)
+}
+
+func TestMultipleEvents(t *testing.T) {
+	c, err := ToConfiguration([]*Workflow{{Name: "w", On: []string{"push", "release"}}})
+	require.NoError(t, err)
+	w := c.Workflows[0]
+	assert.Equal(t, model.ParseEvents("push", "release"), w.On)
+
+	assert.Equal(t, []string{"push", "release"}, FromConfiguration(c)[0].On)
+}
+
+func TestSchedules(t *testing.T) {
+	c := &model.Configuration{Workflows: []*model.Workflow{{
+		Identifier: "w",
+		On:         model.ParseEvents("push", "schedule(0 * * * *)", "schedule(30 2 * * 1)"),
+	}}}
+	w := FromConfiguration(c)[0]
+	assert.Equal(t, []string{"push", "schedule"}, w.On)
+	assert.Equal(t, []string{"0 * * * *", "30 2 * * 1"}, w.Schedules)
+
+	c2, err := ToConfiguration([]*Workflow{w})
+	require.NoError(t, err)
+	assert.Equal(t, c.Workflows[0].On, c2.Workflows[0].On)
+}
+
+func TestToConfigurationUnsupported(t *testing.T) {
+	_, err := ToConfiguration([]*Workflow{{Name: "w", Jobs: []*Job{{ID: "j", Steps: []*Step{{Run: "make"}}}}}})
+	assert.EqualError(t, err, "workflow `w': step 1 of job `j' runs a command, which cannot be represented as an action")
+
+	_, err = ToConfiguration([]*Workflow{{Name: "w", Jobs: []*Job{{ID: "j", Needs: []string{"k"}}}}})
+	assert.EqualError(t, err, "workflow `w': job `j' needs unknown job `k'")
+
+	_, err = ToConfiguration([]*Workflow{
+		{Name: "w", Jobs: []*Job{{ID: "j", Steps: []*Step{{Uses: "./a"}}}}},
+		{Name: "x", Jobs: []*Job{{ID: "j", Steps: []*Step{{Uses: "./b"}}}}},
+	})
+	assert.EqualError(t, err, "workflow `x': action `j' is defined differently by another workflow")
+}
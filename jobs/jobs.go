@@ -0,0 +1,55 @@
+// Package jobs represents the YAML workflow syntax of GitHub Actions v2,
+// in which a workflow is made of jobs and each job is made of steps.  It
+// converts between that representation and model.Configuration, so tools
+// can work with workflows written for either version of the product.
+package jobs
+
+// DefaultRunsOn is the runner label given to jobs converted from actions,
+// which don't say what kind of machine they run on.
+const DefaultRunsOn = "ubuntu-latest"
+
+// Workflow is a single YAML workflow file.  If On includes "schedule",
+// Schedules holds the cron expression of each schedule.
+type Workflow struct {
+	Name      string
+	On        []string
+	Schedules []string
+	Jobs      []*Job
+}
+
+// Job is a single entry in a workflow's `jobs' map.  ID is the key of
+// the entry, and Needs holds the IDs of other jobs.  Matrix holds the
+// values of each dimension of the job's `strategy.matrix', if it has one.
+type Job struct {
+	ID     string
+	Name   string
+	RunsOn string
+	Needs  []string
+	Matrix map[string][]string
+	Steps  []*Step
+}
+
+// Step is a single entry in a job's `steps' list.  A step either uses an
+// action, in which case With holds the action's inputs, or runs a shell
+// command.
+type Step struct {
+	Name            string
+	If              string
+	Uses            string
+	Run             string
+	With            map[string]string
+	Env             map[string]string
+	ContinueOnError bool
+}
+
+// GetJob looks up a job by ID.
+//
+// If the job is not found, nil is returned.
+func (w *Workflow) GetJob(id string) *Job {
+	for _, job := range w.Jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package lock
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GitHub is the base URL of repositories on github.com, for
+// NewGitResolver.
+const GitHub = "https://github.com/"
+
+// NewGitResolver returns a Resolver that lists the refs of baseURL
+// followed by the repository name with ListRemote.
+func NewGitResolver(baseURL string) Resolver {
+	return ResolverFunc(func(repository, ref string) (string, error) {
+		if IsSHA(ref) {
+			return ref, nil
+		}
+		refs, err := ListRemote(baseURL + repository)
+		if err != nil {
+			return "", err
+		}
+		return refs.Resolve(ref)
+	})
+}
+
+// RemoteRefs are the refs of a remote repository.
+type RemoteRefs struct {
+	// Refs maps full ref names, like `refs/heads/master', to the commit
+	// SHAs they name.  Annotated tags name the commit they point to.
+	Refs map[string]string
+
+	// Head is the full name of the default branch, or blank if the
+	// remote didn't say.
+	Head string
+}
+
+// ListRemote runs `git ls-remote' to list the refs of the repository at
+// url.  It needs git on the PATH, and credentials for private
+// repositories configured the usual way.
+func ListRemote(url string) (*RemoteRefs, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "ls-remote", "--symref", url)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", url, msg)
+		}
+		return nil, err
+	}
+	return parseRemoteRefs(out), nil
+}
+
+// parseRemoteRefs parses the output of `git ls-remote --symref'.
+func parseRemoteRefs(out []byte) *RemoteRefs {
+	ret := &RemoteRefs{Refs: make(map[string]string)}
+	peeled := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 3 && fields[0] == "ref:" && fields[2] == "HEAD":
+			ret.Head = fields[1]
+		case len(fields) == 2 && strings.HasSuffix(fields[1], "^{}"):
+			peeled[strings.TrimSuffix(fields[1], "^{}")] = fields[0]
+		case len(fields) == 2:
+			ret.Refs[fields[1]] = fields[0]
+		}
+	}
+	for name, sha := range peeled {
+		ret.Refs[name] = sha
+	}
+	return ret
+}
+
+// Resolve returns the commit that ref, a tag or branch name, names.  A
+// tag wins over a branch with the same name.
+func (r *RemoteRefs) Resolve(ref string) (string, error) {
+	for _, name := range []string{"refs/tags/" + ref, "refs/heads/" + ref, ref} {
+		if sha, ok := r.Refs[name]; ok {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("no branch or tag `%s'", ref)
+}
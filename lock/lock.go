@@ -0,0 +1,210 @@
+// Package lock pins the repositories that actions use to commit SHAs, so
+// that a workflow keeps running the same code when a branch or tag
+// moves.  Lock resolves every `uses = "owner/repo@ref"' attribute,
+// rewrites it to the SHA, and returns a Lockfile recording what each ref
+// resolved to.  Verify checks a configuration against a Lockfile later,
+// e.g. in CI.
+//
+//	config, err := parser.ParseFile("main.workflow")
+//	...
+//	l, err := lock.Lock(config, lock.NewGitResolver(lock.GitHub))
+//	...
+//	err = l.Write(w)
+package lock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// Resolver finds the commit a ref names in a repository.
+//
+// Resolve returns the full SHA of the commit that ref, a branch, tag, or
+// SHA, names in repository, e.g. `actions/bin'.
+type Resolver interface {
+	Resolve(repository, ref string) (string, error)
+}
+
+// ResolverFunc adapts an ordinary function to the Resolver interface.
+type ResolverFunc func(repository, ref string) (string, error)
+
+// Resolve calls f(repository, ref).
+func (f ResolverFunc) Resolve(repository, ref string) (string, error) {
+	return f(repository, ref)
+}
+
+// Lockfile maps each ref a configuration used, written as `owner/repo@ref',
+// to the commit SHA it resolved to.
+type Lockfile struct {
+	Refs map[string]string `json:"refs"`
+}
+
+var commitSHA = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// IsSHA returns whether ref is a full commit SHA.
+func IsSHA(ref string) bool {
+	return commitSHA.MatchString(ref)
+}
+
+// Lock resolves the ref of every action in c that uses another repository,
+// replaces it with the commit SHA, and returns the refs and SHAs as a
+// Lockfile.  Refs that are already SHAs are recorded as they are, without
+// calling r.  Each distinct ref is resolved once.  If any ref fails to
+// resolve, Lock returns the error and leaves c unchanged.
+func Lock(c *model.Configuration, r Resolver) (*Lockfile, error) {
+	l := &Lockfile{Refs: make(map[string]string)}
+	for _, action := range c.Actions {
+		repo, ok := action.Uses.(*model.UsesRepository)
+		if !ok {
+			continue
+		}
+		key := refKey(repo.Repository, repo.Ref)
+		if _, ok := l.Refs[key]; ok {
+			continue
+		}
+		sha := repo.Ref
+		if !IsSHA(sha) {
+			var err error
+			sha, err = r.Resolve(repo.Repository, repo.Ref)
+			if err != nil {
+				return nil, fmt.Errorf("action `%s': cannot resolve `%s': %s", action.Identifier, key, err)
+			}
+			if !IsSHA(sha) {
+				return nil, fmt.Errorf("action `%s': `%s' resolved to `%s', which is not a commit SHA", action.Identifier, key, sha)
+			}
+		}
+		l.Refs[key] = sha
+	}
+
+	for _, action := range c.Actions {
+		if repo, ok := action.Uses.(*model.UsesRepository); ok {
+			action.Uses = &model.UsesRepository{
+				Repository: repo.Repository,
+				Path:       repo.Path,
+				Ref:        l.Refs[refKey(repo.Repository, repo.Ref)],
+			}
+		}
+	}
+	return l, nil
+}
+
+// DriftError is returned by Verify when a configuration doesn't match its
+// lockfile.
+type DriftError struct {
+	Problems []string
+}
+
+func (e *DriftError) Error() string {
+	return "workflow does not match the lockfile: " + strings.Join(e.Problems, "; ")
+}
+
+// Verify checks that c matches l, returning a *DriftError if it doesn't.
+// Every repository ref in c must be in l, or be a SHA that some ref in l
+// resolved to, and every ref in l must still be used.  If r is not nil,
+// refs that are not SHAs are also resolved again, and must still resolve
+// to the SHA in l.
+func Verify(c *model.Configuration, l *Lockfile, r Resolver) error {
+	var problems []string
+	used := make(map[string]bool)
+	checked := make(map[string]bool)
+	for _, action := range c.Actions {
+		repo, ok := action.Uses.(*model.UsesRepository)
+		if !ok {
+			continue
+		}
+		key := refKey(repo.Repository, repo.Ref)
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		if IsSHA(repo.Ref) {
+			if locked := l.lockedTo(repo.Repository, repo.Ref); len(locked) > 0 {
+				for _, k := range locked {
+					used[k] = true
+				}
+				continue
+			}
+		}
+		sha, ok := l.Refs[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("action `%s' uses `%s', which is not in the lockfile", action.Identifier, key))
+			continue
+		}
+		used[key] = true
+		if r == nil || IsSHA(repo.Ref) {
+			continue
+		}
+		current, err := r.Resolve(repo.Repository, repo.Ref)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("cannot resolve `%s': %s", key, err))
+		} else if !strings.EqualFold(current, sha) {
+			problems = append(problems, fmt.Sprintf("`%s' resolves to %s, but the lockfile has %s", key, current, sha))
+		}
+	}
+
+	for _, key := range l.keys() {
+		if !used[key] {
+			problems = append(problems, fmt.Sprintf("lockfile entry `%s' is not used", key))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &DriftError{Problems: problems}
+	}
+	return nil
+}
+
+// lockedTo returns the refs in l for repository that resolved to sha, in
+// sorted order.
+func (l *Lockfile) lockedTo(repository, sha string) []string {
+	var ret []string
+	for _, key := range l.keys() {
+		if strings.HasPrefix(key, repository+"@") && strings.EqualFold(l.Refs[key], sha) {
+			ret = append(ret, key)
+		}
+	}
+	return ret
+}
+
+// keys returns the refs in l in sorted order.
+func (l *Lockfile) keys() []string {
+	ret := make([]string, 0, len(l.Refs))
+	for key := range l.Refs {
+		ret = append(ret, key)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Read decodes a Lockfile written by Write.
+func Read(r io.Reader) (*Lockfile, error) {
+	var l Lockfile
+	if err := json.NewDecoder(r).Decode(&l); err != nil {
+		return nil, err
+	}
+	if l.Refs == nil {
+		l.Refs = make(map[string]string)
+	}
+	return &l, nil
+}
+
+// Write encodes l as indented JSON, with the refs sorted.
+func (l *Lockfile) Write(w io.Writer) error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+func refKey(repository, ref string) string {
+	return repository + "@" + ref
+}
@@ -0,0 +1,170 @@
+package lock
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/printer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	shaMaster = "1111111111111111111111111111111111111111"
+	shaV1     = "2222222222222222222222222222222222222222"
+	shaPinned = "3333333333333333333333333333333333333333"
+)
+
+const src = `workflow "w" {
+  on = "push"
+  resolves = ["a", "b", "c", "d", "e"]
+}
+
+action "a" {
+  uses = "owner/repo@master"
+}
+
+action "b" {
+  uses = "owner/repo/path@master"
+}
+
+action "c" {
+  uses = "other/repo@v1"
+}
+
+action "d" {
+  uses = "pinned/repo@3333333333333333333333333333333333333333"
+}
+
+action "e" {
+  uses = "./local"
+}
+`
+
+// fakeResolver resolves refs from a map, counting its calls.
+type fakeResolver struct {
+	refs  map[string]string
+	calls int
+}
+
+func (f *fakeResolver) Resolve(repository, ref string) (string, error) {
+	f.calls++
+	sha, ok := f.refs[repository+"@"+ref]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return sha, nil
+}
+
+func newResolver() *fakeResolver {
+	return &fakeResolver{refs: map[string]string{
+		"owner/repo@master": shaMaster,
+		"other/repo@v1":     shaV1,
+	}}
+}
+
+func parse(t *testing.T) *model.Configuration {
+	c, err := parser.Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	return c
+}
+
+func TestLock(t *testing.T) {
+	c := parse(t)
+	r := newResolver()
+	l, err := Lock(c, r)
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.calls)
+	assert.Equal(t, map[string]string{
+		"owner/repo@master":        shaMaster,
+		"other/repo@v1":            shaV1,
+		"pinned/repo@" + shaPinned: shaPinned,
+	}, l.Refs)
+
+	out := printer.String(c)
+	assert.Contains(t, out, `uses = "owner/repo@`+shaMaster+`"`)
+	assert.Contains(t, out, `uses = "owner/repo/path@`+shaMaster+`"`)
+	assert.Contains(t, out, `uses = "other/repo@`+shaV1+`"`)
+	assert.Contains(t, out, `uses = "./local"`)
+
+	var buf bytes.Buffer
+	require.NoError(t, l.Write(&buf))
+	assert.Equal(t, `{
+  "refs": {
+    "other/repo@v1": "`+shaV1+`",
+    "owner/repo@master": "`+shaMaster+`",
+    "pinned/repo@`+shaPinned+`": "`+shaPinned+`"
+  }
+}
+`, buf.String())
+
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, l, read)
+}
+
+func TestLockError(t *testing.T) {
+	c := parse(t)
+	r := newResolver()
+	delete(r.refs, "other/repo@v1")
+	_, err := Lock(c, r)
+	require.Error(t, err)
+	assert.Equal(t, "action `c': cannot resolve `other/repo@v1': not found", err.Error())
+	assert.Equal(t, "owner/repo@master", c.Actions[0].Uses.String())
+}
+
+func TestVerify(t *testing.T) {
+	original := parse(t)
+	locked := parse(t)
+	l, err := Lock(locked, newResolver())
+	require.NoError(t, err)
+
+	// Both the rewritten configuration and the original match the lockfile.
+	assert.NoError(t, Verify(locked, l, nil))
+	assert.NoError(t, Verify(locked, l, newResolver()))
+	assert.NoError(t, Verify(original, l, nil))
+	assert.NoError(t, Verify(original, l, newResolver()))
+
+	// The branch moved.
+	moved := newResolver()
+	moved.refs["owner/repo@master"] = shaPinned
+	assert.NoError(t, Verify(locked, l, moved))
+	err = Verify(original, l, moved)
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"`owner/repo@master' resolves to " + shaPinned + ", but the lockfile has " + shaMaster,
+	}, err.(*DriftError).Problems)
+
+	// The workflow changed.
+	original.Actions[2].Uses = &model.UsesRepository{Repository: "other/repo", Ref: "v2"}
+	err = Verify(original, l, nil)
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"action `c' uses `other/repo@v2', which is not in the lockfile",
+		"lockfile entry `other/repo@v1' is not used",
+	}, err.(*DriftError).Problems)
+}
+
+func TestRemoteRefs(t *testing.T) {
+	refs := parseRemoteRefs([]byte("ref: refs/heads/master\tHEAD\n" +
+		shaMaster + "\tHEAD\n" +
+		shaMaster + "\trefs/heads/master\n" +
+		shaV1 + "\trefs/tags/v1\n" +
+		shaPinned + "\trefs/tags/v1^{}\n"))
+	assert.Equal(t, "refs/heads/master", refs.Head)
+
+	sha, err := refs.Resolve("master")
+	require.NoError(t, err)
+	assert.Equal(t, shaMaster, sha)
+
+	sha, err = refs.Resolve("v1")
+	require.NoError(t, err)
+	assert.Equal(t, shaPinned, sha)
+
+	_, err = refs.Resolve("v2")
+	assert.EqualError(t, err, "no branch or tag `v2'")
+}
@@ -0,0 +1,60 @@
+package lsp
+
+import (
+	"github.com/actions/workflow-parser/parser"
+)
+
+// CodeActionQuickFix is the LSP kind for code actions that fix a
+// diagnostic.
+const CodeActionQuickFix = "quickfix"
+
+// TextEdit replaces a range of a document with new text.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// WorkspaceEdit is a set of changes to documents, keyed by document URI.
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// CodeAction is a change an editor can offer to make, e.g., as a quick
+// fix for a diagnostic.
+type CodeAction struct {
+	Title       string         `json:"title"`
+	Kind        string         `json:"kind,omitempty"`
+	Diagnostics []Diagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool           `json:"isPreferred,omitempty"`
+	Edit        *WorkspaceEdit `json:"edit,omitempty"`
+}
+
+// CodeActions returns a quick fix for each error that has a Fix, with
+// the fix's edits applied to src, the document at uri.  Each code action
+// carries the diagnostic it fixes, as Diagnostics would convert it.
+func CodeActions(uri string, src []byte, errs []*parser.ParseError) []CodeAction {
+	doc := newDocument(src)
+	ret := []CodeAction{}
+	for _, e := range errs {
+		if e.Fix == nil {
+			continue
+		}
+
+		edits := make([]TextEdit, 0, len(e.Fix.Edits))
+		for _, edit := range e.Fix.Edits {
+			edits = append(edits, TextEdit{
+				Range:   Range{Start: doc.editPosition(edit.Start), End: doc.editPosition(edit.End)},
+				NewText: edit.NewText,
+			})
+		}
+
+		ret = append(ret, CodeAction{
+			Title:       e.Fix.Title,
+			Kind:        CodeActionQuickFix,
+			Diagnostics: []Diagnostic{doc.diagnostic(uri, e)},
+			IsPreferred: true,
+			Edit:        &WorkspaceEdit{Changes: map[string][]TextEdit{uri: edits}},
+		})
+	}
+	return ret
+}
@@ -0,0 +1,42 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeActions(t *testing.T) {
+	src := `
+action "a" {
+  uses = "./x"
+  need = "b"
+  bananas = "yes"
+}
+action "b" { uses = "./y" }`
+	_, err := parser.Parse(strings.NewReader(src))
+	require.IsType(t, &parser.Error{}, err)
+
+	const uri = "file:///repo/.github/main.workflow"
+	actions := CodeActions(uri, []byte(src), err.(*parser.Error).Errors)
+	require.Len(t, actions, 1)
+
+	action := actions[0]
+	assert.Equal(t, "Rename `need' to `needs'", action.Title)
+	assert.Equal(t, CodeActionQuickFix, action.Kind)
+	require.Len(t, action.Diagnostics, 1)
+	assert.Equal(t, "Unknown action attribute `need'", action.Diagnostics[0].Message)
+	assert.Equal(t, &WorkspaceEdit{Changes: map[string][]TextEdit{
+		uri: {{
+			Range:   Range{Start: Position{Line: 3, Character: 2}, End: Position{Line: 3, Character: 6}},
+			NewText: "needs",
+		}},
+	}}, action.Edit)
+}
+
+func TestCodeActionsNone(t *testing.T) {
+	assert.Empty(t, CodeActions("file:///x", nil, nil))
+}
@@ -0,0 +1,44 @@
+package lsp
+
+import (
+	"github.com/actions/workflow-parser/complete"
+)
+
+// CompletionItemKind is the kind of thing a CompletionItem names.
+type CompletionItemKind int
+
+// The completion item kinds defined by the LSP specification that
+// CompletionsAt uses.
+const (
+	CompletionItemProperty  CompletionItemKind = 10
+	CompletionItemReference CompletionItemKind = 18
+	CompletionItemEvent     CompletionItemKind = 23
+)
+
+// CompletionItem is a suggestion for the text at a position.
+type CompletionItem struct {
+	Label string             `json:"label"`
+	Kind  CompletionItemKind `json:"kind,omitempty"`
+}
+
+// CompletionsAt returns the suggestions for the text at pos in src, as
+// complete.At finds them.  Attributes are properties, actions are
+// references, and events are events.
+func CompletionsAt(src []byte, pos Position) []CompletionItem {
+	line, col := newDocument(src).lineColumn(pos)
+	candidates := complete.At(src, line, col)
+	ret := make([]CompletionItem, 0, len(candidates))
+	for _, c := range candidates {
+		item := CompletionItem{Label: c.Label}
+		switch c.Kind {
+		case complete.Attribute:
+			item.Kind = CompletionItemProperty
+		case complete.Action:
+			item.Kind = CompletionItemReference
+		case complete.Event:
+			item.Kind = CompletionItemEvent
+		}
+		ret = append(ret, item)
+	}
+	return ret
+}
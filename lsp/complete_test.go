@@ -0,0 +1,24 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionsAt(t *testing.T) {
+	src := []byte(`action "😀" { uses = "./a" }
+action "b" {
+  uses = "./b"
+  needs = ["😀", ""]
+}
+workflow "w" {
+  on = "pus"
+}`)
+	// The cursor is between the quotes after "😀", which is two UTF-16
+	// code units long.
+	assert.Equal(t, []CompletionItem{{Label: "😀", Kind: CompletionItemReference}}, CompletionsAt(src, Position{Line: 3, Character: 17}))
+	assert.Equal(t, []CompletionItem{{Label: "push", Kind: CompletionItemEvent}}, CompletionsAt(src, Position{Line: 6, Character: 11}))
+	assert.Equal(t, CompletionItemProperty, CompletionsAt(src, Position{Line: 2, Character: 0})[0].Kind)
+	assert.Empty(t, CompletionsAt(src, Position{Line: 0, Character: 0}))
+}
@@ -0,0 +1,30 @@
+package lsp
+
+import (
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+)
+
+// Definition returns the range of the identifier of the action that the
+// `needs' or `resolves' entry at pos refers to, or of the action or
+// workflow itself if pos is on its identifier.  It returns false if pos is
+// not on an identifier, the action doesn't exist, or the source has syntax
+// errors.
+func Definition(src []byte, pos Position) (Range, bool) {
+	doc := newDocument(src)
+	file, err := hclparser.Parse(src)
+	if err != nil {
+		return Range{}, false
+	}
+
+	occs := occurrences(file)
+	target, ok := occurrenceAt(occs, doc.offset(pos))
+	if !ok {
+		return Range{}, false
+	}
+	for _, occ := range occs {
+		if occ.decl && occ.kind == target.kind && occ.name == target.name {
+			return doc.tokenRange(occ.tok), true
+		}
+	}
+	return Range{}, false
+}
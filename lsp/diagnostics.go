@@ -0,0 +1,166 @@
+// Package lsp converts the parser's diagnostics into Language Server
+// Protocol structures, so that language servers for .workflow files don't
+// each have to maintain their own mapping.  The types in this package
+// marshal to JSON as described in the LSP specification.
+package lsp
+
+import (
+	"github.com/actions/workflow-parser/parser"
+	"github.com/hashicorp/hcl/hcl/scanner"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// Source is the value of Diagnostic.Source for diagnostics produced by
+// this package.
+const Source = "workflow-parser"
+
+// Position is a zero-based line and character offset in a document.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of a document, from Start up to but not including End.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range inside a particular document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// DiagnosticSeverity is the severity of a Diagnostic.
+type DiagnosticSeverity int
+
+// The diagnostic severities defined by the LSP specification.
+const (
+	SeverityError       DiagnosticSeverity = 1
+	SeverityWarning     DiagnosticSeverity = 2
+	SeverityInformation DiagnosticSeverity = 3
+	SeverityHint        DiagnosticSeverity = 4
+)
+
+// DiagnosticRelatedInformation points at another location that is
+// relevant to a diagnostic.
+type DiagnosticRelatedInformation struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
+}
+
+// Diagnostic is a problem found in a document.
+type Diagnostic struct {
+	Range              Range                          `json:"range"`
+	Severity           DiagnosticSeverity             `json:"severity,omitempty"`
+	Code               string                         `json:"code,omitempty"`
+	Source             string                         `json:"source,omitempty"`
+	Message            string                         `json:"message"`
+	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+// Diagnostics converts the errors the parser found in src, the document
+// at uri, to LSP diagnostics.  Each diagnostic covers the token its error
+// is reported at, with columns counted in UTF-16 code units, as LSP
+// expects.  Its related information comes from the error's Related
+// positions and, for an error that stands for several, from the
+// positions of the others, leaving out any in other files.
+func Diagnostics(uri string, src []byte, errs []*parser.ParseError) []Diagnostic {
+	doc := newDocument(src)
+	ret := make([]Diagnostic, 0, len(errs))
+	for _, e := range errs {
+		ret = append(ret, doc.diagnostic(uri, e))
+	}
+	return ret
+}
+
+// FromError converts an error returned by parser.Parse for src, the
+// document at uri, to LSP diagnostics.  A *parser.Error yields one
+// diagnostic per error it contains; any other error yields a single
+// diagnostic at the top of the document.  A nil error yields no
+// diagnostics.
+func FromError(uri string, src []byte, err error) []Diagnostic {
+	switch e := err.(type) {
+	case nil:
+		return []Diagnostic{}
+	case *parser.Error:
+		return Diagnostics(uri, src, e.Errors)
+	default:
+		return []Diagnostic{{
+			Severity: SeverityError,
+			Source:   Source,
+			Message:  err.Error(),
+		}}
+	}
+}
+
+func (d *document) diagnostic(uri string, e *parser.ParseError) Diagnostic {
+	ret := Diagnostic{
+		Range:    d.errorRange(e.Pos),
+		Severity: severity(e.Severity),
+		Code:     string(e.Code),
+		Source:   Source,
+		Message:  e.Message(),
+	}
+	for _, r := range e.Related {
+		if r.Pos.File == e.Pos.File {
+			ret.RelatedInformation = append(ret.RelatedInformation, DiagnosticRelatedInformation{
+				Location: Location{URI: uri, Range: d.errorRange(r.Pos)},
+				Message:  r.Message,
+			})
+		}
+	}
+	// The first of Positions is the error's own.
+	for i := 1; i < len(e.Positions); i++ {
+		if pos := e.Positions[i]; pos.File == e.Pos.File {
+			ret.RelatedInformation = append(ret.RelatedInformation, DiagnosticRelatedInformation{
+				Location: Location{URI: uri, Range: d.errorRange(pos)},
+				Message:  "Also reported here",
+			})
+		}
+	}
+	return ret
+}
+
+// errorRange returns the range of the token at pos, or an empty range at
+// pos if there is no token there.  An error without a position is put at
+// the top of the document.
+func (d *document) errorRange(pos parser.ErrorPos) Range {
+	if pos.Line <= 0 {
+		return Range{}
+	}
+	start := d.position(pos.Offset)
+	if pos.Offset >= len(d.src) {
+		return Range{Start: start, End: start}
+	}
+
+	s := scanner.New(d.src[pos.Offset:])
+	s.Error = func(token.Pos, string) {}
+	t := s.Scan()
+	if t.Type == token.EOF || t.Type == token.ILLEGAL || t.Pos.Offset != 0 {
+		return Range{Start: start, End: start}
+	}
+	return Range{Start: start, End: d.position(pos.Offset + len(t.Text))}
+}
+
+// editPosition returns the LSP position of a fix's edit position.
+func (d *document) editPosition(pos parser.ErrorPos) Position {
+	if pos.Line <= 0 {
+		return Position{}
+	}
+	return d.position(pos.Offset)
+}
+
+// severity maps parser severities onto LSP severities.  LSP has no
+// equivalent of FATAL, so it is reported as an error.
+func severity(sev parser.Severity) DiagnosticSeverity {
+	switch sev {
+	case parser.WARNING:
+		return SeverityWarning
+	case parser.ERROR, parser.FATAL:
+		return SeverityError
+	default:
+		return SeverityInformation
+	}
+}
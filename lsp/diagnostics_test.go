@@ -0,0 +1,130 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testURI = "file:///repo/.github/main.workflow"
+
+func TestFromError(t *testing.T) {
+	src := `
+action "a" {
+  uses = "./x"
+  bananas = "yes"
+}
+action "b" {}`
+	_, err := parser.Parse(strings.NewReader(src))
+	diags := FromError(testURI, []byte(src), err)
+	require.Len(t, diags, 2)
+
+	assert.Equal(t, Diagnostic{
+		Range:    Range{Start: Position{Line: 3, Character: 12}, End: Position{Line: 3, Character: 17}},
+		Severity: SeverityWarning,
+		Code:     "W_UNKNOWN_ATTRIBUTE",
+		Source:   Source,
+		Message:  "Unknown action attribute `bananas'",
+	}, diags[0])
+	assert.Equal(t, SeverityError, diags[1].Severity)
+	assert.Equal(t, 5, diags[1].Range.Start.Line)
+
+	b, err := json.Marshal(diags[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"range": {"start": {"line": 3, "character": 12}, "end": {"line": 3, "character": 17}},
+		"severity": 2,
+		"code": "W_UNKNOWN_ATTRIBUTE",
+		"source": "workflow-parser",
+		"message": "Unknown action attribute `+"`bananas'"+`"
+	}`, string(b))
+}
+
+func TestFromErrorOther(t *testing.T) {
+	assert.Empty(t, FromError(testURI, nil, nil))
+	assert.Equal(t, []Diagnostic{{Severity: SeverityError, Source: Source, Message: "boom"}}, FromError(testURI, nil, errors.New("boom")))
+}
+
+func TestFatalIsError(t *testing.T) {
+	src := `action "a" {`
+	_, err := parser.Parse(strings.NewReader(src))
+	diags := FromError(testURI, []byte(src), err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, SeverityError, diags[0].Severity)
+}
+
+func TestDiagnosticsUTF16(t *testing.T) {
+	// "😀" is one character, but two UTF-16 code units.
+	src := `action "😀" {
+  uses = "./x"
+  env = { A = "😀" }
+  bananas = "😀"
+}`
+	_, err := parser.Parse(strings.NewReader(src))
+	diags := FromError(testURI, []byte(src), err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, Range{Start: Position{Line: 3, Character: 12}, End: Position{Line: 3, Character: 16}}, diags[0].Range)
+}
+
+func TestDiagnosticsRelated(t *testing.T) {
+	src := `action "a" {
+  uses = "./a"
+  needs = "b"
+}
+action "b" {
+  uses = "./b"
+  needs = "a"
+}
+workflow "w" {
+  on = "push"
+  resolves = "a"
+}`
+	_, errs, err := parser.ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	diags := Diagnostics(testURI, []byte(src), errs)
+	require.Len(t, diags, 1)
+	assert.Equal(t, []DiagnosticRelatedInformation{
+		{
+			Location: Location{URI: testURI, Range: Range{Start: Position{Line: 2, Character: 10}, End: Position{Line: 2, Character: 13}}},
+			Message:  "`a' needs `b'",
+		},
+		{
+			Location: Location{URI: testURI, Range: Range{Start: Position{Line: 6, Character: 10}, End: Position{Line: 6, Character: 13}}},
+			Message:  "`b' needs `a'",
+		},
+	}, diags[0].RelatedInformation)
+
+	src = `action "b" { uses = "./b" }
+action "b" { uses = "./c" }`
+	_, errs, err = parser.ParseWithDiagnostics(strings.NewReader(src), parser.WithoutChecks(parser.CheckUnused))
+	require.NoError(t, err)
+	diags = Diagnostics(testURI, []byte(src), errs)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Identifier `b' redefined", diags[0].Message)
+	assert.Equal(t, []DiagnosticRelatedInformation{{
+		Location: Location{URI: testURI, Range: Range{Start: Position{Line: 0, Character: 7}, End: Position{Line: 0, Character: 10}}},
+		Message:  "First defined here",
+	}}, diags[0].RelatedInformation)
+}
+
+func TestDiagnosticsDeduplicated(t *testing.T) {
+	src := `action "a" {
+  uses = "./a"
+  x = "1"
+}
+action "b" {
+  uses = "./b"
+  x = "2"
+}`
+	_, errs, err := parser.ParseWithDiagnostics(strings.NewReader(src), parser.WithDeduplication(5), parser.WithoutChecks(parser.CheckUnused))
+	require.NoError(t, err)
+	diags := Diagnostics(testURI, []byte(src), errs)
+	require.Len(t, diags, 1)
+	require.Len(t, diags[0].RelatedInformation, 1)
+	assert.Equal(t, Position{Line: 6, Character: 6}, diags[0].RelatedInformation[0].Location.Range.Start)
+}
@@ -0,0 +1,93 @@
+package lsp
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// document is a source buffer, with an index of where each line starts,
+// for converting between byte offsets and LSP positions.  LSP counts
+// characters in UTF-16 code units.
+type document struct {
+	src        []byte
+	lineStarts []int
+}
+
+func newDocument(src []byte) *document {
+	d := &document{src: src, lineStarts: []int{0}}
+	for i, c := range src {
+		if c == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	return d
+}
+
+// position returns the LSP position of a byte offset.
+func (d *document) position(offset int) Position {
+	if offset > len(d.src) {
+		offset = len(d.src)
+	}
+	line := sort.SearchInts(d.lineStarts, offset+1) - 1
+	return Position{Line: line, Character: utf16Len(d.src[d.lineStarts[line]:offset])}
+}
+
+// offset returns the byte offset of an LSP position.  Positions past the
+// end of a line are clamped to the end of that line.
+func (d *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lineStarts) {
+		return len(d.src)
+	}
+	end := len(d.src)
+	if pos.Line+1 < len(d.lineStarts) {
+		end = d.lineStarts[pos.Line+1] - 1
+	}
+	offset, units := d.lineStarts[pos.Line], 0
+	for offset < end && units < pos.Character {
+		r, size := utf8.DecodeRune(d.src[offset:])
+		units += utf16RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// lineColumn returns the 1-based line and column, counted in characters
+// as the parser counts them, of an LSP position.
+func (d *document) lineColumn(pos Position) (int, int) {
+	offset := d.offset(pos)
+	line := d.position(offset).Line
+	return line + 1, utf8.RuneCount(d.src[d.lineStarts[line]:offset]) + 1
+}
+
+// tokenRange returns the range covered by an HCL token.
+func (d *document) tokenRange(t token.Token) Range {
+	return Range{
+		Start: d.position(t.Pos.Offset),
+		End:   d.position(t.Pos.Offset + len(t.Text)),
+	}
+}
+
+func utf16Len(b []byte) int {
+	n := 0
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		n += utf16RuneLen(r)
+		b = b[size:]
+	}
+	return n
+}
+
+// utf16RuneLen returns the number of UTF-16 code units r takes: two for
+// a rune outside the Basic Multilingual Plane, and one otherwise,
+// including for utf8.RuneError, which stands for an invalid byte.
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
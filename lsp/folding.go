@@ -0,0 +1,78 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/hcl/scanner"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// FoldingRangeComment is the LSP kind for folding ranges that cover
+// comments.
+const FoldingRangeComment = "comment"
+
+// FoldingRange is a range of lines an editor can collapse.  The lines are
+// zero-based, and EndLine is the last line that gets hidden.
+type FoldingRange struct {
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Kind      string `json:"kind,omitempty"`
+}
+
+// FoldingRanges returns the ranges of a .workflow source buffer that an
+// editor can collapse: action and workflow blocks, lists and objects that
+// span several lines, and runs of comment lines.  Blocks fold up to the
+// line before their closing bracket, so the bracket stays visible.
+//
+// FoldingRanges only looks at brackets and comments, so it works on
+// files with syntax errors too.  Unterminated blocks don't fold.
+func FoldingRanges(src []byte) []FoldingRange {
+	ret := []FoldingRange{}
+	var open []token.Pos
+
+	commentStart, commentEnd := -1, -1
+	flushComments := func() {
+		if commentEnd > commentStart {
+			ret = append(ret, FoldingRange{StartLine: commentStart, EndLine: commentEnd, Kind: FoldingRangeComment})
+		}
+		commentStart, commentEnd = -1, -1
+	}
+
+	s := scanner.New(src)
+	s.Error = func(token.Pos, string) {}
+	for {
+		t := s.Scan()
+		if t.Type == token.EOF || t.Type == token.ILLEGAL {
+			break
+		}
+
+		if t.Type == token.COMMENT {
+			first := t.Pos.Line - 1
+			last := first + strings.Count(strings.TrimRight(t.Text, "\n"), "\n")
+			if commentEnd < 0 || first > commentEnd+1 {
+				flushComments()
+				commentStart = first
+			}
+			commentEnd = last
+			continue
+		}
+		flushComments()
+
+		switch t.Type {
+		case token.LBRACE, token.LBRACK:
+			open = append(open, t.Pos)
+		case token.RBRACE, token.RBRACK:
+			if len(open) == 0 {
+				continue
+			}
+			start := open[len(open)-1]
+			open = open[:len(open)-1]
+			if end := t.Pos.Line - 2; end > start.Line-1 {
+				ret = append(ret, FoldingRange{StartLine: start.Line - 1, EndLine: end})
+			}
+		}
+	}
+	flushComments()
+
+	return ret
+}
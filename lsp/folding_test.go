@@ -0,0 +1,44 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldingRanges(t *testing.T) {
+	src := []byte(`# This workflow
+# builds things.
+workflow "ci" {
+  on = "push"
+  resolves = [
+    "a",
+    "b",
+  ]
+}
+
+action "a" { uses = "./a" }
+
+action "b" {
+  uses = "./b"
+  env = {
+    A = "1"
+  }
+  /* a
+     comment */
+}
+`)
+	assert.Equal(t, []FoldingRange{
+		{StartLine: 0, EndLine: 1, Kind: FoldingRangeComment},
+		{StartLine: 4, EndLine: 6},
+		{StartLine: 2, EndLine: 7},
+		{StartLine: 14, EndLine: 15},
+		{StartLine: 17, EndLine: 18, Kind: FoldingRangeComment},
+		{StartLine: 12, EndLine: 18},
+	}, FoldingRanges(src))
+}
+
+func TestFoldingRangesUnterminated(t *testing.T) {
+	src := []byte("action \"a\" {\n  uses = \"./a\"\n  env = {\n    A = \"1\"\n  }\n")
+	assert.Equal(t, []FoldingRange{{StartLine: 2, EndLine: 3}}, FoldingRanges(src))
+}
@@ -0,0 +1,36 @@
+package lsp
+
+import (
+	"github.com/actions/workflow-parser/hover"
+)
+
+// MarkupKindMarkdown is the LSP kind of Markdown content.
+const MarkupKindMarkdown = "markdown"
+
+// MarkupContent is text for an editor to display, in the given markup.
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Hover is information to show when the cursor rests on part of a
+// document.
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+// HoverAt returns information about the part of src at pos, as
+// hover.At explains it, or nil if there is nothing to say.
+func HoverAt(src []byte, pos Position) *Hover {
+	doc := newDocument(src)
+	line, col := doc.lineColumn(pos)
+	info := hover.At(src, line, col)
+	if info == nil {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: MarkupKindMarkdown, Value: info.Contents},
+		Range:    &Range{Start: doc.position(info.Pos.Offset), End: doc.position(info.End.Offset)},
+	}
+}
@@ -0,0 +1,62 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoverAt(t *testing.T) {
+	src := []byte(`action "a" {
+  uses = "docker://gcr.io:443/team/image:1.0"
+}
+action "b" {
+  uses = "./tools/b"
+  args = "./tools/b"
+}
+action "c" {
+  uses = "nope"
+}
+`)
+	h := HoverAt(src, Position{Line: 1, Character: 10})
+	require.NotNil(t, h)
+	assert.Equal(t, MarkupKindMarkdown, h.Contents.Kind)
+	assert.Equal(t, "Docker image\n- Registry: `gcr.io:443`\n- Repository: `team/image`\n- Tag: `1.0`", h.Contents.Value)
+	assert.Equal(t, Range{Start: Position{Line: 1, Character: 9}, End: Position{Line: 1, Character: 45}}, *h.Range)
+
+	h = HoverAt(src, Position{Line: 4, Character: 12})
+	require.NotNil(t, h)
+	assert.Equal(t, "Action in this repository\n- Path: `./tools/b`", h.Contents.Value)
+
+	h = HoverAt(src, Position{Line: 8, Character: 12})
+	require.NotNil(t, h)
+	assert.Contains(t, h.Contents.Value, "Invalid `uses` value")
+
+	assert.Nil(t, HoverAt(src, Position{Line: 5, Character: 12}))
+	assert.Nil(t, HoverAt(src, Position{Line: 0, Character: 0}))
+	assert.Nil(t, HoverAt([]byte(`action "a" {`), Position{}))
+}
+
+func TestDefinition(t *testing.T) {
+	src := []byte(`workflow "w" {
+  resolves = ["b"]
+}
+action "a" {}
+action "b" {
+  needs = ["a", "missing"]
+}
+`)
+	r, ok := Definition(src, Position{Line: 1, Character: 16})
+	require.True(t, ok)
+	assert.Equal(t, Range{Start: Position{Line: 4, Character: 7}, End: Position{Line: 4, Character: 10}}, r)
+
+	r, ok = Definition(src, Position{Line: 5, Character: 12})
+	require.True(t, ok)
+	assert.Equal(t, Range{Start: Position{Line: 3, Character: 7}, End: Position{Line: 3, Character: 10}}, r)
+
+	_, ok = Definition(src, Position{Line: 5, Character: 18})
+	assert.False(t, ok)
+	_, ok = Definition(src, Position{Line: 3, Character: 0})
+	assert.False(t, ok)
+}
@@ -0,0 +1,162 @@
+package lsp
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// symbolKind distinguishes the two namespaces of identifiers in a
+// .workflow file.
+type symbolKind int
+
+const (
+	actionSymbol symbolKind = iota
+	workflowSymbol
+)
+
+// occurrence is a string token that names an action or workflow, either
+// in its declaration or in a `needs' or `resolves' reference.
+type occurrence struct {
+	tok  token.Token
+	kind symbolKind
+	name string
+	decl bool
+}
+
+// occurrences lists every declaration of, and reference to, an action or
+// workflow in a parsed file, in source order.
+func occurrences(file *ast.File) []occurrence {
+	var ret []occurrence
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil
+	}
+
+	for _, item := range list.Items {
+		if len(item.Keys) != 2 {
+			continue
+		}
+		var kind symbolKind
+		switch keyName(item.Keys[0]) {
+		case "action":
+			kind = actionSymbol
+		case "workflow":
+			kind = workflowSymbol
+		default:
+			continue
+		}
+		if t := item.Keys[1].Token; t.Type == token.STRING {
+			ret = append(ret, occurrence{tok: t, kind: kind, name: keyName(item.Keys[1]), decl: true})
+		}
+
+		obj, ok := item.Val.(*ast.ObjectType)
+		if !ok {
+			continue
+		}
+		for _, attr := range obj.List.Items {
+			if len(attr.Keys) != 1 {
+				continue
+			}
+			name := keyName(attr.Keys[0])
+			if (kind == actionSymbol && name == "needs") || (kind == workflowSymbol && name == "resolves") {
+				ret = append(ret, stringOccurrences(attr.Val)...)
+			}
+		}
+	}
+
+	return ret
+}
+
+// stringOccurrences returns the strings in a `needs' or `resolves'
+// value, which can be a single string or a list of them.
+func stringOccurrences(node ast.Node) []occurrence {
+	var ret []occurrence
+	switch n := node.(type) {
+	case *ast.LiteralType:
+		if n.Token.Type == token.STRING {
+			if s, ok := n.Token.Value().(string); ok {
+				ret = append(ret, occurrence{tok: n.Token, kind: actionSymbol, name: s})
+			}
+		}
+	case *ast.ListType:
+		for _, elem := range n.List {
+			ret = append(ret, stringOccurrences(elem)...)
+		}
+	}
+	return ret
+}
+
+// occurrenceAt returns the occurrence under the cursor, if any.
+func occurrenceAt(occs []occurrence, offset int) (occurrence, bool) {
+	for _, occ := range occs {
+		if occ.tok.Pos.Offset <= offset && offset <= occ.tok.Pos.Offset+len(occ.tok.Text) {
+			return occ, true
+		}
+	}
+	return occurrence{}, false
+}
+
+// PrepareRename checks whether the identifier at pos can be renamed.  If
+// so, it returns the range of the identifier, not including its quotes.
+// Only the names of actions and workflows, and references to them in
+// `needs' and `resolves', can be renamed.
+func PrepareRename(src []byte, pos Position) (Range, error) {
+	doc := newDocument(src)
+	file, err := hclparser.Parse(src)
+	if err != nil {
+		return Range{}, fmt.Errorf("cannot rename in a file with syntax errors")
+	}
+
+	occ, ok := occurrenceAt(occurrences(file), doc.offset(pos))
+	if !ok {
+		return Range{}, fmt.Errorf("only action and workflow names can be renamed")
+	}
+	return innerRange(doc, occ.tok), nil
+}
+
+// Rename renames the action or workflow whose identifier is at pos,
+// returning edits that update its declaration and every `needs' and
+// `resolves' reference to it.  Renaming fails if the new name is already
+// used by another action or workflow.
+func Rename(src []byte, pos Position, newName string) ([]TextEdit, error) {
+	doc := newDocument(src)
+	file, err := hclparser.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("cannot rename in a file with syntax errors")
+	}
+
+	occs := occurrences(file)
+	target, ok := occurrenceAt(occs, doc.offset(pos))
+	if !ok {
+		return nil, fmt.Errorf("only action and workflow names can be renamed")
+	}
+	if newName == "" {
+		return nil, fmt.Errorf("name cannot be blank")
+	}
+	if newName == target.name {
+		return []TextEdit{}, nil
+	}
+
+	edits := []TextEdit{}
+	for _, occ := range occs {
+		if occ.name == newName {
+			return nil, fmt.Errorf("identifier `%s' is already used", newName)
+		}
+		if occ.name == target.name && occ.kind == target.kind {
+			edits = append(edits, TextEdit{Range: doc.tokenRange(occ.tok), NewText: strconv.Quote(newName)})
+		}
+	}
+	return edits, nil
+}
+
+// innerRange returns the range of a string token, without its quotes.
+func innerRange(doc *document, t token.Token) Range {
+	return Range{
+		Start: doc.position(t.Pos.Offset + 1),
+		End:   doc.position(t.Pos.Offset + len(t.Text) - 1),
+	}
+}
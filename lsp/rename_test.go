@@ -0,0 +1,64 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var renameSource = []byte(`workflow "ci" {
+  on = "push"
+  resolves = ["test", "lint"]
+}
+
+action "build" { uses = "./build" }
+
+action "test" {
+  uses = "./test"
+  needs = "build"
+}
+
+action "lint" {
+  uses = "./lint"
+  needs = ["build"]
+}
+`)
+
+func TestPrepareRename(t *testing.T) {
+	r, err := PrepareRename(renameSource, Position{Line: 5, Character: 10})
+	require.NoError(t, err)
+	assert.Equal(t, Range{Start: Position{Line: 5, Character: 8}, End: Position{Line: 5, Character: 13}}, r)
+
+	r, err = PrepareRename(renameSource, Position{Line: 9, Character: 11})
+	require.NoError(t, err)
+	assert.Equal(t, Range{Start: Position{Line: 9, Character: 11}, End: Position{Line: 9, Character: 16}}, r)
+
+	_, err = PrepareRename(renameSource, Position{Line: 8, Character: 4})
+	assert.EqualError(t, err, "only action and workflow names can be renamed")
+
+	_, err = PrepareRename([]byte(`action "a" {`), Position{Line: 0, Character: 9})
+	assert.EqualError(t, err, "cannot rename in a file with syntax errors")
+}
+
+func TestRename(t *testing.T) {
+	edits, err := Rename(renameSource, Position{Line: 14, Character: 13}, "compile")
+	require.NoError(t, err)
+	assert.Equal(t, []TextEdit{
+		{Range: Range{Start: Position{Line: 5, Character: 7}, End: Position{Line: 5, Character: 14}}, NewText: `"compile"`},
+		{Range: Range{Start: Position{Line: 9, Character: 10}, End: Position{Line: 9, Character: 17}}, NewText: `"compile"`},
+		{Range: Range{Start: Position{Line: 14, Character: 11}, End: Position{Line: 14, Character: 18}}, NewText: `"compile"`},
+	}, edits)
+
+	edits, err = Rename(renameSource, Position{Line: 0, Character: 10}, `c "i"`)
+	require.NoError(t, err)
+	assert.Equal(t, []TextEdit{
+		{Range: Range{Start: Position{Line: 0, Character: 9}, End: Position{Line: 0, Character: 13}}, NewText: `"c \"i\""`},
+	}, edits)
+
+	_, err = Rename(renameSource, Position{Line: 5, Character: 10}, "lint")
+	assert.EqualError(t, err, "identifier `lint' is already used")
+
+	_, err = Rename(renameSource, Position{Line: 5, Character: 10}, "")
+	assert.EqualError(t, err, "name cannot be blank")
+}
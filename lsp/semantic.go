@@ -0,0 +1,273 @@
+package lsp
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/scanner"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// SemanticTokenType classifies a SemanticToken.  Its value is an index
+// into SemanticTokenLegend.
+type SemanticTokenType int
+
+// The kinds of token that SemanticTokens reports.
+const (
+	// TokenKeyword is a top-level keyword: action, workflow, or version.
+	TokenKeyword SemanticTokenType = iota
+
+	// TokenBlockName is the name of an action or workflow, either where
+	// it is declared or where it is referenced by `needs' or `resolves'.
+	TokenBlockName
+
+	// TokenAttribute is the name of an attribute, like `uses' or `on'.
+	TokenAttribute
+
+	// TokenEventType is the value of a workflow's `on' attribute.
+	TokenEventType
+
+	// TokenUsesReference is the value of an action's `uses' attribute.
+	TokenUsesReference
+
+	// TokenSecretName is an entry in an action's `secrets' list.
+	TokenSecretName
+
+	// TokenEnvName is the name of an environment variable in `env'.
+	TokenEnvName
+
+	// TokenString is any other string.
+	TokenString
+
+	// TokenNumber is a number.
+	TokenNumber
+
+	// TokenComment is a comment.
+	TokenComment
+)
+
+// SemanticTokenLegend maps each SemanticTokenType to the standard LSP
+// token type that editors should highlight it as.  Servers advertise it
+// in their semanticTokensProvider capability.
+var SemanticTokenLegend = SemanticTokensLegend{
+	TokenTypes: []string{
+		TokenKeyword:       "keyword",
+		TokenBlockName:     "class",
+		TokenAttribute:     "property",
+		TokenEventType:     "enumMember",
+		TokenUsesReference: "namespace",
+		TokenSecretName:    "variable",
+		TokenEnvName:       "variable",
+		TokenString:        "string",
+		TokenNumber:        "number",
+		TokenComment:       "comment",
+	},
+	TokenModifiers: []string{},
+}
+
+// SemanticTokensLegend lists the token types and modifiers a server uses.
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+// SemanticToken is a classified span of a single line of the source.
+type SemanticToken struct {
+	Line      int
+	Character int
+	Length    int
+	Type      SemanticTokenType
+}
+
+// SemanticTokens classifies the tokens in a .workflow source buffer.
+// Tokens are returned in order, and tokens that span several lines, like
+// block comments, are split into one token per line.  If the source has
+// syntax errors, only comments, strings, and numbers are classified.
+func SemanticTokens(src []byte) []SemanticToken {
+	c := &classifier{doc: newDocument(src)}
+	if file, err := hclparser.Parse(src); err == nil {
+		c.file(file)
+	} else {
+		c.lexical(src)
+	}
+
+	sort.SliceStable(c.tokens, func(i, j int) bool {
+		a, b := c.tokens[i], c.tokens[j]
+		return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+	})
+	return c.tokens
+}
+
+// EncodeSemanticTokens encodes tokens in the relative, five integers per
+// token format of an LSP SemanticTokens result.
+func EncodeSemanticTokens(tokens []SemanticToken) []uint32 {
+	ret := make([]uint32, 0, 5*len(tokens))
+	prevLine, prevChar := 0, 0
+	for _, t := range tokens {
+		deltaChar := t.Character
+		if t.Line == prevLine {
+			deltaChar -= prevChar
+		}
+		ret = append(ret, uint32(t.Line-prevLine), uint32(deltaChar), uint32(t.Length), uint32(t.Type), 0)
+		prevLine, prevChar = t.Line, t.Character
+	}
+	return ret
+}
+
+type classifier struct {
+	doc    *document
+	tokens []SemanticToken
+}
+
+func (c *classifier) add(t token.Token, typ SemanticTokenType) {
+	c.addText(t.Pos.Offset, t.Text, typ)
+}
+
+// addText adds a token for text found at offset, splitting it at line
+// breaks.
+func (c *classifier) addText(offset int, text string, typ SemanticTokenType) {
+	for {
+		line := text
+		nl := bytes.IndexByte([]byte(text), '\n')
+		if nl >= 0 {
+			line = text[:nl]
+		}
+		if line != "" {
+			pos := c.doc.position(offset)
+			c.tokens = append(c.tokens, SemanticToken{
+				Line:      pos.Line,
+				Character: pos.Character,
+				Length:    utf16Len([]byte(line)),
+				Type:      typ,
+			})
+		}
+		if nl < 0 {
+			return
+		}
+		offset += nl + 1
+		text = text[nl+1:]
+	}
+}
+
+func (c *classifier) file(file *ast.File) {
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			c.addText(comment.Start.Offset, comment.Text, TokenComment)
+		}
+	}
+
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return
+	}
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+		c.add(item.Keys[0].Token, TokenKeyword)
+		for _, key := range item.Keys[1:] {
+			c.add(key.Token, TokenBlockName)
+		}
+		obj, ok := item.Val.(*ast.ObjectType)
+		if !ok {
+			c.value(item.Val, TokenNumber)
+			continue
+		}
+		for _, attr := range obj.List.Items {
+			c.attribute(attr)
+		}
+	}
+}
+
+// attribute classifies an attribute of an action or workflow block.
+func (c *classifier) attribute(item *ast.ObjectItem) {
+	if len(item.Keys) == 0 {
+		return
+	}
+	for _, key := range item.Keys {
+		c.add(key.Token, TokenAttribute)
+	}
+
+	switch keyName(item.Keys[0]) {
+	case "on":
+		c.value(item.Val, TokenEventType)
+	case "uses":
+		c.value(item.Val, TokenUsesReference)
+	case "needs", "resolves":
+		c.value(item.Val, TokenBlockName)
+	case "secrets":
+		c.value(item.Val, TokenSecretName)
+	case "env":
+		if obj, ok := item.Val.(*ast.ObjectType); ok {
+			for _, env := range obj.List.Items {
+				for _, key := range env.Keys {
+					c.add(key.Token, TokenEnvName)
+				}
+				c.value(env.Val, TokenString)
+			}
+			return
+		}
+		c.value(item.Val, TokenString)
+	default:
+		c.value(item.Val, TokenString)
+	}
+}
+
+// value classifies the string literals in a value, on their own or in a
+// list, as typ.  Other literals are classified by their own type.
+func (c *classifier) value(node ast.Node, typ SemanticTokenType) {
+	switch n := node.(type) {
+	case *ast.LiteralType:
+		switch n.Token.Type {
+		case token.STRING, token.HEREDOC:
+			c.add(n.Token, typ)
+		case token.NUMBER, token.FLOAT:
+			c.add(n.Token, TokenNumber)
+		case token.BOOL:
+			c.add(n.Token, TokenKeyword)
+		}
+	case *ast.ListType:
+		for _, elem := range n.List {
+			c.value(elem, typ)
+		}
+	case *ast.ObjectType:
+		for _, item := range n.List.Items {
+			for _, key := range item.Keys {
+				c.add(key.Token, TokenAttribute)
+			}
+			c.value(item.Val, TokenString)
+		}
+	}
+}
+
+// lexical classifies the tokens of a source buffer that could not be
+// parsed, without any knowledge of its structure.
+func (c *classifier) lexical(src []byte) {
+	s := scanner.New(src)
+	s.Error = func(token.Pos, string) {}
+	for {
+		t := s.Scan()
+		switch t.Type {
+		case token.EOF, token.ILLEGAL:
+			return
+		case token.COMMENT:
+			c.add(t, TokenComment)
+		case token.STRING, token.HEREDOC:
+			c.add(t, TokenString)
+		case token.NUMBER, token.FLOAT:
+			c.add(t, TokenNumber)
+		}
+	}
+}
+
+// keyName returns the name of an object key, without quotes.
+func keyName(key *ast.ObjectKey) string {
+	if key.Token.Type == token.STRING {
+		if s, ok := key.Token.Value().(string); ok {
+			return s
+		}
+	}
+	return key.Token.Text
+}
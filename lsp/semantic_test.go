@@ -0,0 +1,62 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemanticTokens(t *testing.T) {
+	src := []byte(`# build it
+workflow "ci" {
+  on = "push"
+  resolves = ["b"]
+}
+
+action "b" {
+  uses = "docker://alpine"
+  env = { GOOS = "linux" }
+  secrets = ["TOKEN"]
+}
+`)
+	assert.Equal(t, []SemanticToken{
+		{Line: 0, Character: 0, Length: 10, Type: TokenComment},
+		{Line: 1, Character: 0, Length: 8, Type: TokenKeyword},
+		{Line: 1, Character: 9, Length: 4, Type: TokenBlockName},
+		{Line: 2, Character: 2, Length: 2, Type: TokenAttribute},
+		{Line: 2, Character: 7, Length: 6, Type: TokenEventType},
+		{Line: 3, Character: 2, Length: 8, Type: TokenAttribute},
+		{Line: 3, Character: 14, Length: 3, Type: TokenBlockName},
+		{Line: 6, Character: 0, Length: 6, Type: TokenKeyword},
+		{Line: 6, Character: 7, Length: 3, Type: TokenBlockName},
+		{Line: 7, Character: 2, Length: 4, Type: TokenAttribute},
+		{Line: 7, Character: 9, Length: 17, Type: TokenUsesReference},
+		{Line: 8, Character: 2, Length: 3, Type: TokenAttribute},
+		{Line: 8, Character: 10, Length: 4, Type: TokenEnvName},
+		{Line: 8, Character: 17, Length: 7, Type: TokenString},
+		{Line: 9, Character: 2, Length: 7, Type: TokenAttribute},
+		{Line: 9, Character: 13, Length: 7, Type: TokenSecretName},
+	}, SemanticTokens(src))
+}
+
+func TestSemanticTokensSyntaxError(t *testing.T) {
+	src := []byte("/* multi\nline */ action \"a\" {\n  uses = 42")
+	assert.Equal(t, []SemanticToken{
+		{Line: 0, Character: 0, Length: 8, Type: TokenComment},
+		{Line: 1, Character: 0, Length: 7, Type: TokenComment},
+		{Line: 1, Character: 15, Length: 3, Type: TokenString},
+		{Line: 2, Character: 9, Length: 2, Type: TokenNumber},
+	}, SemanticTokens(src))
+}
+
+func TestEncodeSemanticTokens(t *testing.T) {
+	assert.Equal(t, []uint32{
+		0, 2, 3, uint32(TokenKeyword), 0,
+		0, 5, 1, uint32(TokenString), 0,
+		2, 1, 4, uint32(TokenComment), 0,
+	}, EncodeSemanticTokens([]SemanticToken{
+		{Line: 0, Character: 2, Length: 3, Type: TokenKeyword},
+		{Line: 0, Character: 7, Length: 1, Type: TokenString},
+		{Line: 2, Character: 1, Length: 4, Type: TokenComment},
+	}))
+}
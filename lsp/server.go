@@ -0,0 +1,503 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/actions/workflow-parser/parser"
+)
+
+// Error codes defined by the JSON-RPC 2.0 and LSP specifications.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+)
+
+// LSP sync kinds: how clients send changes to a document.
+const (
+	// TextDocumentSyncFull sends the whole text on every change.
+	TextDocumentSyncFull = 1
+
+	// TextDocumentSyncIncremental sends the range each change replaces
+	// and its new text.
+	TextDocumentSyncIncremental = 2
+)
+
+// message is a JSON-RPC request, notification, or response.  Requests
+// and responses have an ID; notifications don't.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *ResponseError   `json:"error,omitempty"`
+}
+
+// ResponseError is the error member of a failed JSON-RPC response.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *ResponseError) Error() string {
+	return e.Message
+}
+
+// TextDocumentIdentifier names a document by its URI.
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// TextDocumentPositionParams are the parameters of requests about a
+// position in a document, e.g., "textDocument/hover".
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// The message types of the "window/logMessage" notification.
+const (
+	MessageTypeError   = 1
+	MessageTypeWarning = 2
+	MessageTypeInfo    = 3
+	MessageTypeLog     = 4
+)
+
+// LogMessageParams are the parameters of the "window/logMessage"
+// notification.
+type LogMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+// PublishDiagnosticsParams are the parameters of the
+// "textDocument/publishDiagnostics" notification.
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type didOpenParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   TextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Range *Range `json:"range"`
+		Text  string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// documentParams are the parameters of requests and notifications about
+// a whole document.
+type documentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type codeActionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        *Range                 `json:"range"`
+}
+
+type renameParams struct {
+	TextDocumentPositionParams
+	NewName string `json:"newName"`
+}
+
+type handler func(s *Server, params json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"initialize":                       handleInitialize,
+	"textDocument/didOpen":             handleDidOpen,
+	"textDocument/didChange":           handleDidChange,
+	"textDocument/didClose":            handleDidClose,
+	"textDocument/hover":               handleHover,
+	"textDocument/definition":          handleDefinition,
+	"textDocument/prepareRename":       handlePrepareRename,
+	"textDocument/rename":              handleRename,
+	"textDocument/codeAction":          handleCodeAction,
+	"textDocument/foldingRange":        handleFoldingRange,
+	"textDocument/semanticTokens/full": handleSemanticTokens,
+	"shutdown":                         handleShutdown,
+}
+
+// Server is a language server for .workflow files, speaking LSP over a
+// pair of streams.  It keeps each open document parsed, reparsing only
+// the blocks each change touches, and publishes diagnostics for it
+// whenever it changes.  Its zero value is not usable;
+// use NewServer.
+type Server struct {
+	options  []parser.OptionFunc
+	docs     map[string]*parser.Document
+	w        io.Writer
+	shutdown bool
+}
+
+// NewServer creates a server that parses every document with the given
+// options.  It recovers from syntax errors, so that a document being
+// typed still gets diagnostics for its other blocks.
+func NewServer(options ...parser.OptionFunc) *Server {
+	options = append([]parser.OptionFunc{parser.WithSyntaxRecovery()}, options...)
+	return &Server{options: options, docs: make(map[string]*parser.Document)}
+}
+
+// Serve reads LSP messages from r and writes responses and notifications
+// to w until r is exhausted or an "exit" notification arrives.  It
+// returns an error only if reading or writing fails; malformed requests
+// get error responses.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+	for {
+		body, err := readMessage(br)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		resp, exit := s.handle(body)
+		if resp != nil {
+			if err := s.write(resp); err != nil {
+				return err
+			}
+		}
+		if exit {
+			return nil
+		}
+	}
+}
+
+// handle answers a single message.  It returns a nil response for
+// notifications, and reports whether the server should exit.
+func (s *Server) handle(body []byte) (*message, bool) {
+	var req message
+	if err := json.Unmarshal(body, &req); err != nil {
+		return errorResponse(nil, CodeParseError, err.Error()), false
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.ID == nil {
+			return nil, false
+		}
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid request"), false
+	}
+	if req.Method == "exit" {
+		return nil, true
+	}
+	if s.shutdown && req.ID != nil {
+		return errorResponse(req.ID, CodeInvalidRequest, "server is shut down"), false
+	}
+
+	h, ok := handlers[req.Method]
+	if !ok {
+		if req.ID == nil {
+			return nil, false
+		}
+		return errorResponse(req.ID, CodeMethodNotFound, "method not found: "+req.Method), false
+	}
+
+	res, err := h(s, req.Params)
+	if req.ID == nil {
+		return nil, false
+	}
+	if err != nil {
+		if rerr, ok := err.(*ResponseError); ok {
+			return errorResponse(req.ID, rerr.Code, rerr.Message), false
+		}
+		return errorResponse(req.ID, CodeInvalidParams, err.Error()), false
+	}
+
+	// A response must have a result, even if it is null.
+	data, err := json.Marshal(res)
+	if err != nil {
+		return errorResponse(req.ID, CodeInvalidParams, err.Error()), false
+	}
+	return &message{JSONRPC: "2.0", ID: req.ID, Result: data}, false
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(&message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// write sends a message with its Content-Length header.
+func (s *Server) write(m *message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+// readMessage reads the headers and body of one message.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for n := 0; ; n++ {
+		line, err := r.ReadString('\n')
+		if err == io.EOF && n == 0 && line == "" {
+			return nil, io.EOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 || !strings.EqualFold(strings.TrimSpace(line[:i]), "Content-Length") {
+			continue
+		}
+		if length, err = strconv.Atoi(strings.TrimSpace(line[i+1:])); err != nil || length < 0 {
+			return nil, fmt.Errorf("invalid header `%s'", line)
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func errorResponse(id *json.RawMessage, code int, msg string) *message {
+	return &message{JSONRPC: "2.0", ID: id, Error: &ResponseError{Code: code, Message: msg}}
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return &ResponseError{Code: CodeInvalidParams, Message: "missing params"}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// document returns the text of the open document at uri.
+func (s *Server) document(uri string) ([]byte, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: "unknown document: " + uri}
+	}
+	return doc.Source, nil
+}
+
+// publish sends the diagnostics of the document at uri.
+func (s *Server) publish(uri string) error {
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: uri, Diagnostics: Diagnostics(uri, s.docs[uri].Source, s.docs[uri].Errors)})
+}
+
+func handleInitialize(s *Server, params json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync":     TextDocumentSyncIncremental,
+			"hoverProvider":        true,
+			"definitionProvider":   true,
+			"renameProvider":       map[string]bool{"prepareProvider": true},
+			"codeActionProvider":   map[string][]string{"codeActionKinds": {CodeActionQuickFix}},
+			"foldingRangeProvider": true,
+			"semanticTokensProvider": map[string]interface{}{
+				"legend": SemanticTokenLegend,
+				"full":   true,
+			},
+		},
+		"serverInfo": map[string]string{"name": "workflow-parser"},
+	}, nil
+}
+
+func handleShutdown(s *Server, params json.RawMessage) (interface{}, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func handleDidOpen(s *Server, params json.RawMessage) (interface{}, error) {
+	var p didOpenParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	s.docs[p.TextDocument.URI] = parser.ParseDocument([]byte(p.TextDocument.Text), s.options...)
+	return nil, s.publish(p.TextDocument.URI)
+}
+
+func handleDidChange(s *Server, params json.RawMessage) (interface{}, error) {
+	var p didChangeParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	uri := p.TextDocument.URI
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: "unknown document: " + uri}
+	}
+	for _, change := range p.ContentChanges {
+		if change.Range == nil {
+			doc = parser.ParseDocument([]byte(change.Text), s.options...)
+			continue
+		}
+		src := newDocument(doc.Source)
+		edit := parser.TextEdit{
+			Start:   parser.ErrorPos{Offset: src.offset(change.Range.Start)},
+			End:     parser.ErrorPos{Offset: src.offset(change.Range.End)},
+			NewText: change.Text,
+		}
+		next, err := parser.Reparse(doc, edit)
+		if err != nil {
+			// didChange is a notification, so there is no one to return
+			// the error to.  Log it, and parse the whole text with the
+			// edit applied instead, to stay in sync with the client.
+			msg := fmt.Sprintf("%s: reparsing the whole document: %v", uri, err)
+			if err := s.notify("window/logMessage", &LogMessageParams{Type: MessageTypeWarning, Message: msg}); err != nil {
+				return nil, err
+			}
+			next = parser.ParseDocument(applyEdit(doc.Source, edit), s.options...)
+		}
+		doc = next
+	}
+	s.docs[uri] = doc
+	return nil, s.publish(uri)
+}
+
+// applyEdit returns src with edit applied, as Reparse would apply it,
+// but accepting an edit whose start and end are swapped.
+func applyEdit(src []byte, edit parser.TextEdit) []byte {
+	start, end := edit.Start.Offset, edit.End.Offset
+	if start > end {
+		start, end = end, start
+	}
+	ret := make([]byte, 0, len(src)-(end-start)+len(edit.NewText))
+	ret = append(ret, src[:start]...)
+	ret = append(ret, edit.NewText...)
+	return append(ret, src[end:]...)
+}
+
+func handleDidClose(s *Server, params json.RawMessage) (interface{}, error) {
+	var p documentParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	delete(s.docs, p.TextDocument.URI)
+	return nil, s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+}
+
+func handleHover(s *Server, params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	src, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	if h := HoverAt(src, p.Position); h != nil {
+		return h, nil
+	}
+	return nil, nil
+}
+
+func handleDefinition(s *Server, params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	src, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := Definition(src, p.Position); ok {
+		return &Location{URI: p.TextDocument.URI, Range: r}, nil
+	}
+	return nil, nil
+}
+
+func handlePrepareRename(s *Server, params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	src, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	if r, err := PrepareRename(src, p.Position); err == nil {
+		return &r, nil
+	}
+	return nil, nil
+}
+
+func handleRename(s *Server, params json.RawMessage) (interface{}, error) {
+	var p renameParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	src, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	edits, err := Rename(src, p.Position, p.NewName)
+	if err != nil {
+		return nil, &ResponseError{Code: CodeInvalidRequest, Message: err.Error()}
+	}
+	return &WorkspaceEdit{Changes: map[string][]TextEdit{p.TextDocument.URI: edits}}, nil
+}
+
+func handleCodeAction(s *Server, params json.RawMessage) (interface{}, error) {
+	var p codeActionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if _, err := s.document(p.TextDocument.URI); err != nil {
+		return nil, err
+	}
+	doc := s.docs[p.TextDocument.URI]
+	errs := doc.Errors
+	if p.Range != nil {
+		errs = errs.ForLineRange(p.Range.Start.Line+1, p.Range.End.Line+1)
+	}
+	return CodeActions(p.TextDocument.URI, doc.Source, errs), nil
+}
+
+func handleFoldingRange(s *Server, params json.RawMessage) (interface{}, error) {
+	var p documentParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	src, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	return FoldingRanges(src), nil
+}
+
+func handleSemanticTokens(s *Server, params json.RawMessage) (interface{}, error) {
+	var p documentParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	src, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]uint32{"data": EncodeSemanticTokens(SemanticTokens(src))}, nil
+}
@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lspServe(t *testing.T, messages ...string) []map[string]interface{} {
+	var in bytes.Buffer
+	for _, m := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+	var out bytes.Buffer
+	require.NoError(t, NewServer().Serve(&in, &out))
+
+	var ret []map[string]interface{}
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &m))
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+func TestServer(t *testing.T) {
+	open := `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.workflow","text":"action \"a\" {\n  uses = \"actions/bin/sh@master\"\n}\naction \"b\" {\n  needs = \"a\"\n}\n"}}}`
+	change := `{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.workflow"},"contentChanges":[{"text":"action \"a\" {\n  uses = \"actions/bin/sh@master\"\n}\naction \"b\" {\n  uses = \"./b\"\n  needs = \"a\"\n}\n"}]}}`
+	msgs := lspServe(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		open,
+		change,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///a.workflow"},"position":{"line":1,"character":12}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"textDocument/definition","params":{"textDocument":{"uri":"file:///a.workflow"},"position":{"line":5,"character":12}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///a.workflow"},"position":{"line":0,"character":0}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"bananas"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"shutdown"}`,
+	)
+	require.Len(t, msgs, 8)
+
+	caps := msgs[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Equal(t, true, caps["hoverProvider"])
+	assert.Equal(t, true, caps["definitionProvider"])
+
+	assert.Equal(t, "textDocument/publishDiagnostics", msgs[1]["method"])
+	diags := msgs[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].(map[string]interface{})["message"], "`uses' attribute")
+	assert.Empty(t, msgs[2]["params"].(map[string]interface{})["diagnostics"])
+
+	hover := msgs[3]["result"].(map[string]interface{})
+	assert.Contains(t, hover["contents"].(map[string]interface{})["value"], "- Repository: `actions/bin`")
+
+	loc := msgs[4]["result"].(map[string]interface{})
+	assert.Equal(t, "file:///a.workflow", loc["uri"])
+	assert.Equal(t, map[string]interface{}{"line": float64(0), "character": float64(7)}, loc["range"].(map[string]interface{})["start"])
+
+	assert.Contains(t, msgs[5], "result")
+	assert.Nil(t, msgs[5]["result"])
+	assert.Equal(t, float64(CodeMethodNotFound), msgs[6]["error"].(map[string]interface{})["code"])
+	assert.Contains(t, msgs[7], "result")
+}
+
+func TestServerUnknownDocument(t *testing.T) {
+	msgs := lspServe(t, `{"jsonrpc":"2.0","id":1,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///x"},"position":{"line":0,"character":0}}}`)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, float64(CodeInvalidParams), msgs[0]["error"].(map[string]interface{})["code"])
+}
+
+func TestReadMessageHeaders(t *testing.T) {
+	_, err := readMessage(bufio.NewReader(strings.NewReader("Content-Type: x\r\n\r\n{}")))
+	assert.Error(t, err)
+}
+
+func TestServerIncrementalChange(t *testing.T) {
+	msgs := lspServe(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.workflow","text":"action \"a\" {\n  uses = \"./a\"\n}\naction \"b\" {\n  uses = \"./b\"\n  needs = \"a\"\n}\n"}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.workflow"},"contentChanges":[{"range":{"start":{"line":5,"character":11},"end":{"line":5,"character":12}},"text":"c"}]}}`,
+		`{"jsonrpc":"2.0","id":1,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///a.workflow"},"position":{"line":5,"character":12}}}`,
+	)
+	require.Len(t, msgs, 3)
+	assert.Empty(t, msgs[0]["params"].(map[string]interface{})["diagnostics"])
+	diags := msgs[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].(map[string]interface{})["message"], "needs nonexistent action `c'")
+	assert.Contains(t, msgs[2]["result"].(map[string]interface{})["contents"].(map[string]interface{})["value"], "No action `c`")
+}
+
+func TestServerReparseError(t *testing.T) {
+	msgs := lspServe(t,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.workflow","text":"action \"a\" {\n  uses = \"./a\"\n}\naction \"b\" {\n  uses = \"./b\"\n  needs = \"a\"\n}\n"}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.workflow"},"contentChanges":[{"range":{"start":{"line":5,"character":12},"end":{"line":5,"character":11}},"text":"c"}]}}`,
+	)
+	require.Len(t, msgs, 3)
+	assert.Equal(t, "window/logMessage", msgs[1]["method"])
+	assert.Contains(t, msgs[1]["params"].(map[string]interface{})["message"], "file:///a.workflow: reparsing the whole document: edit from offset")
+
+	assert.Equal(t, "textDocument/publishDiagnostics", msgs[2]["method"])
+	diags := msgs[2]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].(map[string]interface{})["message"], "needs nonexistent action `c'")
+}
+
+func TestServerCodeActionRange(t *testing.T) {
+	open := `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.workflow","text":"action \"a\" {\n  uses = \"./a\"\n  secret = [\"X\"]\n}\naction \"b\" {\n  uses = \"./b\"\n  arg = \"x\"\n}\n"}}}`
+	msgs := lspServe(t,
+		open,
+		`{"jsonrpc":"2.0","id":1,"method":"textDocument/codeAction","params":{"textDocument":{"uri":"file:///a.workflow"},"range":{"start":{"line":6,"character":0},"end":{"line":6,"character":5}},"context":{"diagnostics":[]}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/codeAction","params":{"textDocument":{"uri":"file:///a.workflow"}}}`,
+	)
+	require.Len(t, msgs, 3)
+
+	actions := msgs[1]["result"].([]interface{})
+	require.Len(t, actions, 1)
+	assert.Equal(t, "Rename `arg' to `args'", actions[0].(map[string]interface{})["title"])
+
+	assert.Len(t, msgs[2]["result"].([]interface{}), 2)
+}
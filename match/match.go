@@ -0,0 +1,57 @@
+// Package match works out which workflows a webhook event triggers, for
+// dry runs and for testing .workflow files without pushing them.
+package match
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/webhook"
+)
+
+// WorkflowsForEvent returns the workflows in c that would run for event,
+// in the order they appear in c.
+//
+// A workflow runs if one of its events has the type of event and, if the
+// workflow's event is filtered to an activity, like
+// `pull_request.opened', the activity of event too, as
+// model.IsMatchingEventType decides.  A scheduled workflow runs for a
+// "schedule" event with the same cron expression, or for any "schedule"
+// event that doesn't say which one fired.  .workflow files can't filter
+// on branches, so the branch of event doesn't matter.
+func WorkflowsForEvent(c *model.Configuration, event webhook.Event) []*model.Workflow {
+	var ret []*model.Workflow
+	for _, w := range c.Workflows {
+		for _, e := range w.On {
+			if matches(e, event) {
+				ret = append(ret, w)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// matches reports whether a workflow subscribed to e runs for event.
+func matches(e model.Event, event webhook.Event) bool {
+	if !e.Matches(event.String()) {
+		return false
+	}
+	if e.Schedule == nil || event.Schedule == "" {
+		return true
+	}
+	return sameSchedule(e.Schedule, event.Schedule)
+}
+
+// sameSchedule reports whether s runs at the same times as the cron
+// expression expr, even if they're written differently.
+func sameSchedule(s *model.Schedule, expr string) bool {
+	other, err := model.ParseSchedule(expr)
+	if err != nil || s.Minute == nil {
+		return strings.Join(strings.Fields(s.Expression), " ") == strings.Join(strings.Fields(expr), " ")
+	}
+	return reflect.DeepEqual(
+		[][]int{s.Minute, s.Hour, s.DayOfMonth, s.Month, s.DayOfWeek},
+		[][]int{other.Minute, other.Hour, other.DayOfMonth, other.Month, other.DayOfWeek})
+}
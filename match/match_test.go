@@ -0,0 +1,46 @@
+package match
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func identifiers(workflows []*model.Workflow) []string {
+	var ret []string
+	for _, w := range workflows {
+		ret = append(ret, w.Identifier)
+	}
+	return ret
+}
+
+func TestWorkflowsForEvent(t *testing.T) {
+	c, err := parser.Parse(strings.NewReader(`
+		workflow "push" { on = "push" resolves = "a" }
+		workflow "opened" { on = "pull_request.opened" resolves = "a" }
+		workflow "any pr" { on = ["pull_request", "push"] resolves = "a" }
+		workflow "nightly" { on = "schedule(0 0 * * *)" resolves = "a" }
+		workflow "hourly" { on = "schedule(0 * * * *)" resolves = "a" }
+		action "a" { uses = "./a" }
+	`))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		event webhook.Event
+		want  []string
+	}{
+		{webhook.Event{Type: "push", Branch: "main"}, []string{"push", "any pr"}},
+		{webhook.Event{Type: "pull_request", Action: "opened"}, []string{"opened", "any pr"}},
+		{webhook.Event{Type: "pull_request", Action: "closed"}, []string{"any pr"}},
+		{webhook.Event{Type: "issues", Action: "opened"}, nil},
+		{webhook.Event{Type: "schedule", Schedule: "0  0 * * SUN-SAT"}, []string{"nightly"}},
+		{webhook.Event{Type: "schedule"}, []string{"nightly", "hourly"}},
+	} {
+		assert.Equal(t, tc.want, identifiers(WorkflowsForEvent(c, tc.event)), "%+v", tc.event)
+	}
+}
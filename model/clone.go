@@ -0,0 +1,405 @@
+package model
+
+// Clone returns a deep copy of c, which can be changed without changing
+// c, including its positions and comments.
+func (c *Configuration) Clone() *Configuration {
+	if c == nil {
+		return nil
+	}
+	ret := &Configuration{
+		Version:           c.Version,
+		Env:               cloneEnv(c.Env),
+		Comments:          c.Comments.Clone(),
+		AttributeComments: cloneAttributeComments(c.AttributeComments),
+	}
+	if c.Actions != nil {
+		ret.Actions = make([]*Action, len(c.Actions))
+		for i, a := range c.Actions {
+			ret.Actions[i] = a.Clone()
+		}
+	}
+	if c.Workflows != nil {
+		ret.Workflows = make([]*Workflow, len(c.Workflows))
+		for i, w := range c.Workflows {
+			ret.Workflows[i] = w.Clone()
+		}
+	}
+	if c.Templates != nil {
+		ret.Templates = make([]*Action, len(c.Templates))
+		for i, t := range c.Templates {
+			ret.Templates[i] = t.Clone()
+		}
+	}
+	return ret
+}
+
+// Equal reports whether c and other have the same version, the same
+// actions, workflows, and templates, in the same order, the same `env'
+// block, and the same comments.  Like the Equal methods of
+// the types it holds, it ignores positions, which say where things were
+// in a file rather than what they are, and doesn't distinguish nil from
+// empty slices and maps.
+func (c *Configuration) Equal(other *Configuration) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	if len(c.Actions) != len(other.Actions) ||
+		len(c.Workflows) != len(other.Workflows) ||
+		len(c.Templates) != len(other.Templates) {
+		return false
+	}
+	for i, a := range c.Actions {
+		if !a.Equal(other.Actions[i]) {
+			return false
+		}
+	}
+	for i, w := range c.Workflows {
+		if !w.Equal(other.Workflows[i]) {
+			return false
+		}
+	}
+	for i, t := range c.Templates {
+		if !t.Equal(other.Templates[i]) {
+			return false
+		}
+	}
+	return c.Version == other.Version &&
+		envEqual(c.Env, other.Env) &&
+		c.Comments.Equal(other.Comments) &&
+		attributeCommentsEqual(c.AttributeComments, other.AttributeComments)
+}
+
+// Clone returns a deep copy of a.
+func (a *Action) Clone() *Action {
+	if a == nil {
+		return nil
+	}
+	ret := *a
+	ret.Uses = cloneUses(a.Uses)
+	ret.Runs = cloneCommand(a.Runs)
+	ret.Args = cloneCommand(a.Args)
+	ret.Needs = cloneStrings(a.Needs)
+	ret.Secrets = cloneStrings(a.Secrets)
+	ret.Env = cloneEnv(a.Env)
+	ret.Matrix = cloneMatrix(a.Matrix)
+	ret.Positions = clonePositions(a.Positions)
+	ret.Comments = a.Comments.Clone()
+	ret.AttributeComments = cloneAttributeComments(a.AttributeComments)
+	return &ret
+}
+
+// Equal reports whether a and other have the same attributes and
+// comments, ignoring positions.  The forms of `runs' and `args', a
+// string, a list, and a heredoc, are different even if they split the
+// same way.
+func (a *Action) Equal(other *Action) bool {
+	if a == nil || other == nil {
+		return a == other
+	}
+	if a.Identifier != other.Identifier ||
+		a.Description != other.Description ||
+		a.Extends != other.Extends ||
+		!usesEqual(a.Uses, other.Uses) ||
+		!commandEqual(a.Runs, other.Runs) ||
+		!commandEqual(a.Args, other.Args) ||
+		!stringsEqual(a.Needs, other.Needs) ||
+		!stringsEqual(a.Secrets, other.Secrets) ||
+		a.If != other.If ||
+		a.ContinueOnError != other.ContinueOnError ||
+		!envEqual(a.Env, other.Env) ||
+		!matrixEqual(a.Matrix, other.Matrix) {
+		return false
+	}
+	return a.Comments.Equal(other.Comments) &&
+		attributeCommentsEqual(a.AttributeComments, other.AttributeComments)
+}
+
+// Clone returns a deep copy of w.
+func (w *Workflow) Clone() *Workflow {
+	if w == nil {
+		return nil
+	}
+	ret := *w
+	if w.On != nil {
+		ret.On = make([]Event, len(w.On))
+		for i, e := range w.On {
+			ret.On[i] = e.Clone()
+		}
+	}
+	ret.Resolves = cloneStrings(w.Resolves)
+	ret.Env = cloneEnv(w.Env)
+	ret.Positions = clonePositions(w.Positions)
+	ret.Comments = w.Comments.Clone()
+	ret.AttributeComments = cloneAttributeComments(w.AttributeComments)
+	return &ret
+}
+
+// Equal reports whether w and other have the same attributes and
+// comments, ignoring positions.
+func (w *Workflow) Equal(other *Workflow) bool {
+	if w == nil || other == nil {
+		return w == other
+	}
+	if w.Identifier != other.Identifier ||
+		w.Description != other.Description ||
+		len(w.On) != len(other.On) ||
+		!stringsEqual(w.Resolves, other.Resolves) ||
+		!envEqual(w.Env, other.Env) {
+		return false
+	}
+	for i, e := range w.On {
+		if !e.Equal(other.On[i]) {
+			return false
+		}
+	}
+	return w.Comments.Equal(other.Comments) &&
+		attributeCommentsEqual(w.AttributeComments, other.AttributeComments)
+}
+
+// Clone returns a deep copy of e.
+func (e Event) Clone() Event {
+	e.Schedule = e.Schedule.Clone()
+	return e
+}
+
+// Equal reports whether e and other are written the same way and have
+// the same type, filter, and schedule.
+func (e Event) Equal(other Event) bool {
+	return e.Raw == other.Raw && e.Type == other.Type && e.Filter == other.Filter && e.Schedule.Equal(other.Schedule)
+}
+
+// Clone returns a deep copy of s.
+func (s *Schedule) Clone() *Schedule {
+	if s == nil {
+		return nil
+	}
+	return &Schedule{
+		Expression: s.Expression,
+		Minute:     cloneInts(s.Minute),
+		Hour:       cloneInts(s.Hour),
+		DayOfMonth: cloneInts(s.DayOfMonth),
+		Month:      cloneInts(s.Month),
+		DayOfWeek:  cloneInts(s.DayOfWeek),
+	}
+}
+
+// Equal reports whether s and other have the same expression and
+// values.  Expressions written differently are different even if they
+// run at the same times.
+func (s *Schedule) Equal(other *Schedule) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	return s.Expression == other.Expression &&
+		intsEqual(s.Minute, other.Minute) &&
+		intsEqual(s.Hour, other.Hour) &&
+		intsEqual(s.DayOfMonth, other.DayOfMonth) &&
+		intsEqual(s.Month, other.Month) &&
+		intsEqual(s.DayOfWeek, other.DayOfWeek)
+}
+
+// Clone returns a deep copy of c.
+func (c Comments) Clone() Comments {
+	c.Lead = cloneStrings(c.Lead)
+	c.Trailing = cloneStrings(c.Trailing)
+	return c
+}
+
+// Equal reports whether c and other hold the same comments.
+func (c Comments) Equal(other Comments) bool {
+	return c.Line == other.Line && stringsEqual(c.Lead, other.Lead) && stringsEqual(c.Trailing, other.Trailing)
+}
+
+// cloneUses returns a copy of u.  Every implementation of Uses holds
+// only strings, so a shallow copy is deep.
+func cloneUses(u Uses) Uses {
+	switch u := u.(type) {
+	case *UsesDockerImage:
+		ret := *u
+		return &ret
+	case *UsesRepository:
+		ret := *u
+		return &ret
+	case *UsesPath:
+		ret := *u
+		return &ret
+	case *UsesInvalid:
+		ret := *u
+		return &ret
+	default:
+		return u
+	}
+}
+
+// usesEqual reports whether a and b are the same kind of `uses' value
+// with the same fields.
+func usesEqual(a, b Uses) bool {
+	switch a := a.(type) {
+	case *UsesDockerImage:
+		b, ok := b.(*UsesDockerImage)
+		return ok && *a == *b
+	case *UsesRepository:
+		b, ok := b.(*UsesRepository)
+		return ok && *a == *b
+	case *UsesPath:
+		b, ok := b.(*UsesPath)
+		return ok && *a == *b
+	case *UsesInvalid:
+		b, ok := b.(*UsesInvalid)
+		return ok && *a == *b
+	default:
+		return a == b
+	}
+}
+
+func cloneCommand(c Command) Command {
+	switch c := c.(type) {
+	case *StringCommand:
+		return &StringCommand{Value: c.Value}
+	case *ListCommand:
+		return &ListCommand{Values: cloneStrings(c.Values)}
+	case *HeredocCommand:
+		ret := *c
+		return &ret
+	default:
+		return c
+	}
+}
+
+func commandEqual(a, b Command) bool {
+	switch a := a.(type) {
+	case *StringCommand:
+		b, ok := b.(*StringCommand)
+		return ok && a.Value == b.Value
+	case *ListCommand:
+		b, ok := b.(*ListCommand)
+		return ok && stringsEqual(a.Values, b.Values)
+	case *HeredocCommand:
+		b, ok := b.(*HeredocCommand)
+		return ok && *a == *b
+	default:
+		return a == b
+	}
+}
+
+func clonePositions(m map[string]Pos) map[string]Pos {
+	if m == nil {
+		return nil
+	}
+	ret := make(map[string]Pos, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
+
+func cloneAttributeComments(m map[string]Comments) map[string]Comments {
+	if m == nil {
+		return nil
+	}
+	ret := make(map[string]Comments, len(m))
+	for k, v := range m {
+		ret[k] = v.Clone()
+	}
+	return ret
+}
+
+// attributeCommentsEqual compares AttributeComments, treating a missing
+// entry like one with no comments.
+func attributeCommentsEqual(a, b map[string]Comments) bool {
+	for k, v := range a {
+		if !v.Equal(b[k]) {
+			return false
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; !ok && !v.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneEnv(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	ret := make(map[string]string, len(env))
+	for k, v := range env {
+		ret[k] = v
+	}
+	return ret
+}
+
+func cloneMatrix(matrix map[string][]string) map[string][]string {
+	if matrix == nil {
+		return nil
+	}
+	ret := make(map[string][]string, len(matrix))
+	for k, v := range matrix {
+		ret[k] = cloneStrings(v)
+	}
+	return ret
+}
+
+func matrixEqual(a, b map[string][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !stringsEqual(v, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func envEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneInts(s []int) []int {
+	if s == nil {
+		return nil
+	}
+	return append([]int(nil), s...)
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cloneConfig() *Configuration {
+	return &Configuration{
+		Actions: []*Action{
+			{
+				Identifier:        "a",
+				Uses:              ParseUses("docker://alpine:3.8"),
+				Runs:              &ListCommand{Values: []string{"sh", "-c"}},
+				Args:              &StringCommand{Value: "echo hi"},
+				Needs:             []string{"b"},
+				Env:               map[string]string{"A": "1"},
+				Secrets:           []string{"TOKEN"},
+				Pos:               Pos{Line: 3},
+				Positions:         map[string]Pos{"uses": {Line: 4}},
+				Comments:          Comments{Lead: []string{"# a"}},
+				AttributeComments: map[string]Comments{"env.A": {Line: "# one"}},
+			},
+			{Identifier: "b", Uses: ParseUses("./b")},
+		},
+		Workflows: []*Workflow{
+			{
+				Identifier: "w",
+				On:         ParseEvents("schedule(0 * * * *)"),
+				Resolves:   []string{"a"},
+				Pos:        Pos{Line: 1},
+			},
+		},
+		Comments: Comments{Trailing: []string{"# end"}},
+	}
+}
+
+func TestClone(t *testing.T) {
+	c := cloneConfig()
+	clone := c.Clone()
+	require.True(t, c.Equal(clone))
+	assert.Equal(t, c, clone)
+
+	clone.Actions[0].Needs[0] = "x"
+	clone.Actions[0].Env["A"] = "2"
+	clone.Actions[0].Runs.(*ListCommand).Values[0] = "bash"
+	clone.Actions[0].Uses.(*UsesDockerImage).Tag = "latest"
+	clone.Actions[0].Positions["uses"] = Pos{}
+	clone.Actions[0].AttributeComments["env.A"] = Comments{}
+	clone.Actions[0].Comments.Lead[0] = "# x"
+	clone.Workflows[0].On[0].Schedule.Minute[0] = 30
+	clone.Comments.Trailing[0] = "# changed"
+	assert.Equal(t, cloneConfig(), c)
+
+	assert.Nil(t, (*Configuration)(nil).Clone())
+	assert.Equal(t, &Configuration{}, (&Configuration{}).Clone())
+}
+
+func TestEqual(t *testing.T) {
+	c := cloneConfig()
+	assert.True(t, c.Equal(cloneConfig()))
+	assert.False(t, c.Equal(nil))
+	assert.True(t, (*Configuration)(nil).Equal(nil))
+
+	// positions don't matter, nor nil versus empty
+	other := cloneConfig()
+	other.Actions[0].Pos = Pos{Line: 10}
+	other.Actions[0].Positions = nil
+	other.Actions[1].Needs = []string{}
+	other.Actions[1].AttributeComments = map[string]Comments{"uses": {}}
+	assert.True(t, c.Equal(other))
+
+	changes := []func(c *Configuration){
+		func(c *Configuration) { c.Actions[0].Identifier = "z" },
+		func(c *Configuration) { c.Actions[0].Uses = ParseUses("docker://alpine:3.9") },
+		func(c *Configuration) { c.Actions[1].Uses = ParseUses("owner/repo@b") },
+		func(c *Configuration) { c.Actions[0].Args = &ListCommand{Values: []string{"echo", "hi"}} },
+		func(c *Configuration) { c.Actions[0].Runs = nil },
+		func(c *Configuration) { c.Actions[0].Env["B"] = "2" },
+		func(c *Configuration) { c.Actions[0].Env = map[string]string{"A": "2"} },
+		func(c *Configuration) { c.Actions[0].Secrets = nil },
+		func(c *Configuration) { c.Actions[0].Comments.Line = "# x" },
+		func(c *Configuration) { c.Actions[0].AttributeComments["env.A"] = Comments{} },
+		func(c *Configuration) { c.Actions = c.Actions[:1] },
+		func(c *Configuration) { c.Workflows[0].On = ParseEvents("schedule(1 * * * *)") },
+		func(c *Configuration) { c.Workflows[0].On = append(c.Workflows[0].On, ParseEvent("push")) },
+		func(c *Configuration) { c.Workflows[0].Resolves = append(c.Workflows[0].Resolves, "b") },
+		func(c *Configuration) { c.Comments = Comments{} },
+	}
+	for i, change := range changes {
+		other := cloneConfig()
+		change(other)
+		assert.False(t, c.Equal(other), "change %d", i)
+		assert.False(t, other.Equal(c), "change %d", i)
+	}
+}
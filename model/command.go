@@ -5,9 +5,11 @@ import (
 )
 
 // Command represents the optional "runs" and "args" attributes.
-// Each one takes one of two forms:
+// Each one takes one of three forms:
 //   - runs="entrypoint arg1 arg2 ..."
 //   - runs=[ "entrypoint", "arg1", "arg2", ... ]
+//   - runs=<<EOF, then "entrypoint arg1 arg2 ..." on any number of lines,
+//     then EOF
 type Command interface {
 	isCommand()
 	Split() []string
@@ -26,8 +28,24 @@ type ListCommand struct {
 	Values []string
 }
 
-func (s *StringCommand) isCommand() {}
-func (l *ListCommand) isCommand()   {}
+// HeredocCommand represents the heredoc form of the "runs" or "args"
+// attribute, for commands too long to read on one line.
+//   - runs=<<EOF
+//     entrypoint arg1
+//     arg2 ...
+//     EOF
+//
+// Value is the text between the markers, as written, without its final
+// newline, and with the indentation removed for the `<<-EOF' form.
+// Marker is the word that ends the text, like `EOF'.
+type HeredocCommand struct {
+	Value  string
+	Marker string
+}
+
+func (s *StringCommand) isCommand()  {}
+func (l *ListCommand) isCommand()    {}
+func (h *HeredocCommand) isCommand() {}
 
 func (s *StringCommand) Split() []string {
 	return strings.Fields(s.Value)
@@ -36,3 +54,10 @@ func (s *StringCommand) Split() []string {
 func (l *ListCommand) Split() []string {
 	return l.Values
 }
+
+// Split splits the text at whitespace, like StringCommand, so each line
+// break separates arguments as a space would.  As in a shell, a
+// backslash at the end of a line joins it to the next.
+func (h *HeredocCommand) Split() []string {
+	return strings.Fields(strings.Replace(h.Value, "\\\n", "", -1))
+}
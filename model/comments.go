@@ -0,0 +1,25 @@
+package model
+
+// Comments holds the comments attached to part of a .workflow file, as
+// written, including their `#', `//', or `/* */' markers, so that tools
+// that rewrite a file can keep them.  The parser attaches every comment
+// in a file to something: the block or attribute that follows it, the
+// one that ends on the same line, or the end of the enclosing block or
+// file.
+type Comments struct {
+	// Lead holds the comments before the block or attribute.
+	Lead []string
+
+	// Line is the comment after the block or attribute on its last line,
+	// if any.
+	Line string
+
+	// Trailing holds the comments at the end of a block, env object, or
+	// file, after everything else in it.
+	Trailing []string
+}
+
+// IsEmpty reports whether there are no comments.
+func (c Comments) IsEmpty() bool {
+	return len(c.Lead) == 0 && c.Line == "" && len(c.Trailing) == 0
+}
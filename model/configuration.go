@@ -1,30 +1,113 @@
 package model
 
-import (
-	"strings"
-)
-
 // Configuration is a parsed main.workflow file
 type Configuration struct {
+	// Version is the version of the language the configuration is
+	// written in, from its `version' statement, or 0 if it has none.  An
+	// action may use a Feature only if Version is at least that of the
+	// Feature; see RequiredVersion.
+	Version int
+
 	Actions   []*Action
 	Workflows []*Workflow
+
+	// Templates holds the file's `template' blocks, which have the
+	// attributes of an action, except `needs' and `extends', for actions
+	// to extend.  See ResolveTemplate.
+	Templates []*Action
+
+	// Env holds the variables of the file's top-level `env' block, which
+	// apply to every action.  See ResolveEnv.
+	Env map[string]string
+
+	// Comments holds the comments that don't belong to any action or
+	// workflow: Lead for those around the `version' statement, and
+	// Trailing for those at the end of the file.  AttributeComments holds
+	// those attached to the `env' block, keyed by `env' or `env.NAME' as
+	// in Action.  They are set by the parser.
+	Comments          Comments
+	AttributeComments map[string]Comments
 }
 
 // Action represents a single "action" stanza in a .workflow file.
 type Action struct {
-	Identifier string
+	Identifier  string
+	Description string
+
+	// Extends is the identifier of the template the action takes the
+	// attributes it doesn't set from, if any.  See ResolveTemplate.
+	Extends string
+
 	Uses       Uses
 	Runs, Args Command
 	Needs      []string
 	Env        map[string]string
 	Secrets    []string
+
+	// If is the condition under which the action runs, an expression like
+	// "github.ref == 'refs/heads/master'", as written.  The action always
+	// runs if it is empty.
+	If string
+
+	// ContinueOnError says that the workflow goes on even if the action
+	// fails, as if it had succeeded.
+	ContinueOnError bool
+
+	// Matrix maps the name of each dimension of the action's matrix to
+	// its values, in the order they are listed.  An action with a matrix
+	// stands for one action for each combination of values; see
+	// ExpandMatrix.
+	Matrix map[string][]string
+
+	// Pos is the position of the action's block, End that of its closing
+	// brace, and Positions holds the position of each attribute in it,
+	// keyed by attribute name.  They are set by the parser.
+	Pos       Pos
+	End       Pos
+	Positions map[string]Pos
+
+	// Comments holds the comments attached to the action's block, and
+	// AttributeComments those attached to each attribute that has any,
+	// keyed by attribute name, or by `env.NAME' for a variable in the env
+	// block and `matrix.NAME' for a dimension of the matrix.  They are set
+	// by the parser.
+	Comments          Comments
+	AttributeComments map[string]Comments
 }
 
 // Workflow represents a single "workflow" stanza in a .workflow file.
 type Workflow struct {
 	Identifier string
-	On         string
-	Resolves   []string
+
+	// Description is a human-friendly summary of the workflow, for UIs to
+	// show instead of its identifier.  Actions have one too.
+	Description string
+
+	// On lists the events that trigger the workflow, from its `on'
+	// attribute, which names either a single event, like
+	// `on = "pull_request.opened"', or a list of them, like
+	// `on = [ "push", "pull_request" ]'.
+	On       []Event
+	Resolves []string
+
+	// Env holds variables for the actions the workflow resolves, directly
+	// or through `needs', which override those of the file's `env' block.
+	// See ResolveEnv.
+	Env map[string]string
+
+	// Pos is the position of the workflow's block, End that of its
+	// closing brace, and Positions holds the position of each attribute
+	// in it, keyed by attribute name.  They are set by the parser.
+	Pos       Pos
+	End       Pos
+	Positions map[string]Pos
+
+	// Comments holds the comments attached to the workflow's block, and
+	// AttributeComments those attached to each attribute that has any,
+	// keyed by attribute name, or by `env.NAME' for a variable in the env
+	// block.  They are set by the parser.
+	Comments          Comments
+	AttributeComments map[string]Comments
 }
 
 // GetAction looks up action by identifier.
@@ -52,13 +135,25 @@ func (c *Configuration) GetWorkflow(id string) *Workflow {
 }
 
 // GetWorkflows gets all Workflow structures that match a given type of event.
-// e.g., GetWorkflows("push")
+// e.g., GetWorkflows("push") or GetWorkflows("pull_request.opened")
 func (c *Configuration) GetWorkflows(eventType string) []*Workflow {
 	var ret []*Workflow
 	for _, workflow := range c.Workflows {
-		if strings.EqualFold(workflow.On, eventType) {
+		if workflow.IsTriggeredBy(eventType) {
 			ret = append(ret, workflow)
 		}
 	}
 	return ret
 }
+
+// IsTriggeredBy reports whether any of the workflow's events matches an
+// incoming event, which is either a bare type, like "push", or a type
+// and activity, like "pull_request.opened".  See IsMatchingEventType.
+func (w *Workflow) IsTriggeredBy(eventType string) bool {
+	for _, event := range w.On {
+		if event.Matches(eventType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package model
+
+import "fmt"
+
+// Dependencies returns the actions that the action actionID needs,
+// directly or through the needs of the actions it needs, in the order
+// they appear in c.  It fails if there is no such action, or if any of
+// those actions needs one that doesn't exist.  An action that is part of
+// a circular dependency is among its own dependencies.
+func (c *Configuration) Dependencies(actionID string) ([]*Action, error) {
+	if c.GetAction(actionID) == nil {
+		return nil, fmt.Errorf("no action `%s'", actionID)
+	}
+	ids := c.needsClosure(actionID)
+	actions := c.actionMap()
+	for _, a := range c.Actions {
+		if a.Identifier != actionID && !ids[a.Identifier] {
+			continue
+		}
+		for _, need := range a.Needs {
+			if actions[need] == nil {
+				return nil, fmt.Errorf("action `%s' needs nonexistent action `%s'", a.Identifier, need)
+			}
+		}
+	}
+	return c.filterActions(ids), nil
+}
+
+// Dependents returns the actions that need the action actionID, directly
+// or through the needs of other actions, in the order they appear in c.
+// It fails if there is no such action.
+func (c *Configuration) Dependents(actionID string) ([]*Action, error) {
+	if c.GetAction(actionID) == nil {
+		return nil, fmt.Errorf("no action `%s'", actionID)
+	}
+
+	neededBy := make(map[string][]string)
+	for _, a := range c.Actions {
+		for _, need := range a.Needs {
+			neededBy[need] = append(neededBy[need], a.Identifier)
+		}
+	}
+	return c.filterActions(closure(actionID, func(id string) []string {
+		return neededBy[id]
+	})), nil
+}
+
+// DependsOn reports whether the action actionID needs the action
+// dependencyID, either directly or through the needs of the actions it
+// needs.  Needs that name nonexistent actions are not followed, and
+// circular dependencies are tolerated.
+func (c *Configuration) DependsOn(actionID, dependencyID string) bool {
+	return c.needsClosure(actionID)[dependencyID]
+}
+
+// needsClosure returns the set of identifiers that actionID needs,
+// directly or transitively.
+func (c *Configuration) needsClosure(actionID string) map[string]bool {
+	actions := c.actionMap()
+	return closure(actionID, func(id string) []string {
+		if a := actions[id]; a != nil {
+			return a.Needs
+		}
+		return nil
+	})
+}
+
+// actionMap maps the identifier of each action in c to the first action
+// with that identifier.
+func (c *Configuration) actionMap() map[string]*Action {
+	ret := make(map[string]*Action, len(c.Actions))
+	for _, a := range c.Actions {
+		if _, ok := ret[a.Identifier]; !ok {
+			ret[a.Identifier] = a
+		}
+	}
+	return ret
+}
+
+// filterActions returns the actions in c whose identifiers are in ids.
+func (c *Configuration) filterActions(ids map[string]bool) []*Action {
+	ret := make([]*Action, 0, len(ids))
+	for _, a := range c.Actions {
+		if ids[a.Identifier] {
+			ret = append(ret, a)
+		}
+	}
+	return ret
+}
+
+// closure returns the set of identifiers reachable from start by
+// following edges, not including start unless it is on a cycle.
+func closure(start string, edges func(string) []string) map[string]bool {
+	ret := make(map[string]bool)
+	queue := []string{start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range edges(id) {
+			if !ret[next] {
+				ret[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return ret
+}
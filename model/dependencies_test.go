@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependsOn(t *testing.T) {
+	c := &Configuration{
+		Actions: []*Action{
+			{Identifier: "a", Needs: []string{"b"}},
+			{Identifier: "b", Needs: []string{"c", "missing"}},
+			{Identifier: "c"},
+			{Identifier: "x", Needs: []string{"y"}},
+			{Identifier: "y", Needs: []string{"x"}},
+		},
+	}
+
+	assert.True(t, c.DependsOn("a", "b"))
+	assert.True(t, c.DependsOn("a", "c"))
+	assert.True(t, c.DependsOn("a", "missing"))
+	assert.False(t, c.DependsOn("c", "a"))
+	assert.False(t, c.DependsOn("a", "a"))
+	assert.False(t, c.DependsOn("nope", "a"))
+	assert.True(t, c.DependsOn("x", "x"))
+}
+
+func identifiers(actions []*Action) []string {
+	ret := make([]string, len(actions))
+	for i, a := range actions {
+		ret[i] = a.Identifier
+	}
+	return ret
+}
+
+func TestDependencies(t *testing.T) {
+	c := &Configuration{
+		Actions: []*Action{
+			{Identifier: "deploy", Needs: []string{"test", "lint"}},
+			{Identifier: "lint", Needs: []string{"setup"}},
+			{Identifier: "test", Needs: []string{"build"}},
+			{Identifier: "build", Needs: []string{"setup"}},
+			{Identifier: "setup"},
+			{Identifier: "broken", Needs: []string{"missing"}},
+		},
+	}
+
+	deps, err := c.Dependencies("deploy")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lint", "test", "build", "setup"}, identifiers(deps))
+
+	deps, err = c.Dependencies("setup")
+	require.NoError(t, err)
+	assert.Empty(t, deps)
+
+	_, err = c.Dependencies("broken")
+	assert.EqualError(t, err, "action `broken' needs nonexistent action `missing'")
+	_, err = c.Dependencies("nope")
+	assert.EqualError(t, err, "no action `nope'")
+
+	dependents, err := c.Dependents("setup")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy", "lint", "test", "build"}, identifiers(dependents))
+
+	dependents, err = c.Dependents("deploy")
+	require.NoError(t, err)
+	assert.Empty(t, dependents)
+
+	_, err = c.Dependents("nope")
+	assert.EqualError(t, err, "no action `nope'")
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The parts of a Docker image reference, following the grammar of the
+// Docker distribution project's reference package.
+var (
+	dockerDomainComponent = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	dockerDomain          = regexp.MustCompile(`^` + dockerDomainComponent + `(?:\.` + dockerDomainComponent + `)*(?::([0-9]+))?$`)
+	dockerPathComponent   = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*$`)
+	dockerTag             = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	dockerDigest          = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+)
+
+// dockerNameMaxLength is the longest a repository name, including its
+// registry, may be.
+const dockerNameMaxLength = 255
+
+// ParseDockerImage parses a Docker image reference, the part of a `uses'
+// value after `docker://', like `myreg.io:5000/team/img:1.2@sha256:...'.
+// The result always has Image set to ref; if ref is not a valid
+// reference, the other fields are blank and the error says why.
+func ParseDockerImage(ref string) (*UsesDockerImage, error) {
+	ret := &UsesDockerImage{Image: ref}
+	parsed, err := parseDockerReference(ref)
+	if err != nil {
+		return ret, err
+	}
+	parsed.Image = ref
+	return parsed, nil
+}
+
+func parseDockerReference(ref string) (*UsesDockerImage, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("image reference is blank")
+	}
+
+	ret := &UsesDockerImage{}
+	name := ref
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name, ret.Digest = name[:i], name[i+1:]
+		if !dockerDigest.MatchString(ret.Digest) {
+			return nil, fmt.Errorf("invalid digest `%s'", ret.Digest)
+		}
+	}
+	// A colon after the last slash starts the tag; any other colon is
+	// part of the registry's port.
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name, ret.Tag = name[:i], name[i+1:]
+		if !dockerTag.MatchString(ret.Tag) {
+			return nil, fmt.Errorf("invalid tag `%s'", ret.Tag)
+		}
+	}
+	if len(name) > dockerNameMaxLength {
+		return nil, fmt.Errorf("repository name is longer than %d characters", dockerNameMaxLength)
+	}
+
+	// The first component names a registry if it looks like a host name,
+	// rather than a path component, and more components follow.
+	components := strings.Split(name, "/")
+	if first := components[0]; len(components) > 1 && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		m := dockerDomain.FindStringSubmatch(first)
+		if m == nil {
+			return nil, fmt.Errorf("invalid registry `%s'", first)
+		}
+		ret.Host = first
+		if m[1] != "" {
+			ret.Host, ret.Port = first[:len(first)-len(m[1])-1], m[1]
+		}
+		components = components[1:]
+	}
+	for _, c := range components {
+		if !dockerPathComponent.MatchString(c) {
+			return nil, fmt.Errorf("invalid repository name `%s'", strings.Join(components, "/"))
+		}
+	}
+	ret.Repository = strings.Join(components, "/")
+	return ret, nil
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ResolveEnv returns the environment variables the action a runs with in
+// the workflow w: those of the file's `env' block, overridden by those of
+// w's, overridden by a's own.  W may be nil, for an action that runs
+// outside any workflow.  The result is a new map, or nil if no variables
+// are set.
+func (c *Configuration) ResolveEnv(w *Workflow, a *Action) map[string]string {
+	var ret map[string]string
+	merge := func(env map[string]string) {
+		for k, v := range env {
+			if ret == nil {
+				ret = make(map[string]string)
+			}
+			ret[k] = v
+		}
+	}
+	merge(c.Env)
+	if w != nil {
+		merge(w.Env)
+	}
+	merge(a.Env)
+	return ret
+}
+
+// MergeEnv merges the file's `env' block and each workflow's into the Env
+// of the actions they apply to, then clears them, for consumers that only
+// look at actions.  The file's variables apply to every action, and a
+// workflow's to the actions it resolves, directly or through `needs'.  It
+// fails, without changing c, if two workflows that resolve the same
+// action set a variable the action doesn't set to different values.
+func (c *Configuration) MergeEnv() error {
+	merged := make([]map[string]string, len(c.Actions))
+	for i, a := range c.Actions {
+		env := c.ResolveEnv(nil, a)
+		from := make(map[string]*Workflow)
+		for _, w := range c.Workflows {
+			if len(w.Env) == 0 || !c.workflowRuns(w)[a.Identifier] {
+				continue
+			}
+			for _, k := range envKeys(w.Env) {
+				v := w.Env[k]
+				if _, ok := a.Env[k]; ok {
+					continue
+				}
+				if other := from[k]; other != nil && env[k] != v {
+					return fmt.Errorf("workflows `%s' and `%s' set `%s' to different values for action `%s'", other.Identifier, w.Identifier, k, a.Identifier)
+				}
+				if env == nil {
+					env = make(map[string]string)
+				}
+				env[k], from[k] = v, w
+			}
+		}
+		merged[i] = env
+	}
+
+	for i, a := range c.Actions {
+		a.Env = merged[i]
+	}
+	for _, w := range c.Workflows {
+		w.Env = nil
+	}
+	c.Env = nil
+	return nil
+}
+
+// workflowRuns returns the set of identifiers of the actions that w
+// resolves, directly or through `needs'.
+func (c *Configuration) workflowRuns(w *Workflow) map[string]bool {
+	ret := make(map[string]bool)
+	for _, id := range w.Resolves {
+		ret[id] = true
+		for need := range c.needsClosure(id) {
+			ret[need] = true
+		}
+	}
+	return ret
+}
+
+func envKeys(env map[string]string) []string {
+	ret := make([]string, 0, len(env))
+	for k := range env {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envConfiguration() *Configuration {
+	return &Configuration{
+		Env: map[string]string{"GOPATH": "/go", "CI": "false"},
+		Actions: []*Action{
+			{Identifier: "build", Env: map[string]string{"GOOS": "linux"}},
+			{Identifier: "test", Needs: []string{"build"}, Env: map[string]string{"CI": "maybe"}},
+			{Identifier: "other"},
+		},
+		Workflows: []*Workflow{
+			{Identifier: "ci", Resolves: []string{"test"}, Env: map[string]string{"CI": "true", "STAGE": "ci"}},
+			{Identifier: "nightly", Resolves: []string{"build"}, Env: map[string]string{"STAGE": "ci"}},
+		},
+	}
+}
+
+func TestResolveEnv(t *testing.T) {
+	c := envConfiguration()
+	ci := c.Workflows[0]
+	assert.Equal(t, map[string]string{"GOPATH": "/go", "CI": "true", "STAGE": "ci", "GOOS": "linux"}, c.ResolveEnv(ci, c.Actions[0]))
+	assert.Equal(t, map[string]string{"GOPATH": "/go", "CI": "maybe", "STAGE": "ci"}, c.ResolveEnv(ci, c.Actions[1]))
+	assert.Equal(t, map[string]string{"GOPATH": "/go", "CI": "false"}, c.ResolveEnv(nil, c.Actions[2]))
+	assert.Nil(t, (&Configuration{}).ResolveEnv(nil, &Action{}))
+
+	// The result is a copy.
+	c.ResolveEnv(ci, c.Actions[0])["GOOS"] = "darwin"
+	assert.Equal(t, "linux", c.Actions[0].Env["GOOS"])
+}
+
+func TestMergeEnv(t *testing.T) {
+	c := envConfiguration()
+	require.NoError(t, c.MergeEnv())
+	assert.Nil(t, c.Env)
+	assert.Nil(t, c.Workflows[0].Env)
+	assert.Equal(t, map[string]string{"GOPATH": "/go", "CI": "true", "STAGE": "ci", "GOOS": "linux"}, c.Actions[0].Env)
+	assert.Equal(t, map[string]string{"GOPATH": "/go", "CI": "maybe", "STAGE": "ci"}, c.Actions[1].Env)
+	assert.Equal(t, map[string]string{"GOPATH": "/go", "CI": "false"}, c.Actions[2].Env)
+
+	c = envConfiguration()
+	c.Workflows[1].Env["STAGE"] = "nightly"
+	err := c.MergeEnv()
+	assert.EqualError(t, err, "workflows `ci' and `nightly' set `STAGE' to different values for action `build'")
+	assert.Equal(t, map[string]string{"GOOS": "linux"}, c.Actions[0].Env)
+	assert.NotNil(t, c.Env)
+}
@@ -0,0 +1,151 @@
+package model
+
+import "strings"
+
+// Event is one of the events that trigger a workflow, as listed in its
+// `on' attribute.  An event can be filtered to a single activity of its
+// type, like `pull_request.opened', in which case Filter holds the part
+// after the dot.  A scheduled event, like `schedule(0 * * * *)', has the
+// type "schedule" and its cron expression in Schedule.
+//
+// Raw is the value as written, if the event was parsed from one, so that
+// schedulers can read the type, filter, and schedule without parsing it
+// again, and tools that write the event back keep its spelling.
+type Event struct {
+	Raw      string
+	Type     string
+	Filter   string
+	Schedule *Schedule
+}
+
+// ScheduleEventType is the type of scheduled events.
+const ScheduleEventType = "schedule"
+
+// ParseEvent splits an `on' value like "pull_request.opened" into its
+// type and filter, and parses the cron expression of a scheduled event.
+// If the expression is invalid, the fields of Schedule are nil.  Raw is
+// set to s.
+func ParseEvent(s string) Event {
+	if expr, ok := scheduleExpression(s); ok {
+		schedule, err := ParseSchedule(expr)
+		if err != nil {
+			schedule = &Schedule{Expression: expr}
+		}
+		return Event{Raw: s, Type: ScheduleEventType, Schedule: schedule}
+	}
+	typ, filter := splitEvent(s)
+	return Event{Raw: s, Type: typ, Filter: filter}
+}
+
+// ParseEvents parses each of a list of `on' values, as ParseEvent does.
+func ParseEvents(list ...string) []Event {
+	ret := make([]Event, len(list))
+	for i, s := range list {
+		ret[i] = ParseEvent(s)
+	}
+	return ret
+}
+
+// splitEvent splits an `on' value into its type and filter, without
+// parsing the cron expression of a scheduled event.
+func splitEvent(s string) (string, string) {
+	if _, ok := scheduleExpression(s); ok {
+		return s[:len(ScheduleEventType)], ""
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// String returns e as it is written in an `on' attribute: Raw, if set,
+// and otherwise the value that ParseEvent would parse into e.
+func (e Event) String() string {
+	if e.Raw != "" {
+		return e.Raw
+	}
+	if e.Schedule != nil {
+		return e.Type + "(" + e.Schedule.Expression + ")"
+	}
+	if e.Filter == "" {
+		return e.Type
+	}
+	return e.Type + "." + e.Filter
+}
+
+// scheduleExpression returns the cron expression in an `on' value like
+// "schedule(0 * * * *)".
+func scheduleExpression(s string) (string, bool) {
+	const prefix = ScheduleEventType + "("
+	if len(s) < len(prefix)+1 || !equalFoldASCII(s[:len(prefix)], prefix) || s[len(s)-1] != ')' {
+		return "", false
+	}
+	return s[len(prefix) : len(s)-1], true
+}
+
+// EventTypes returns the type of each event, without filters.
+func EventTypes(events []Event) []string {
+	ret := make([]string, len(events))
+	for i, e := range events {
+		ret[i] = e.Type
+	}
+	return ret
+}
+
+// EventStrings returns each event as it is written in an `on' attribute.
+func EventStrings(events []Event) []string {
+	ret := make([]string, len(events))
+	for i, e := range events {
+		ret[i] = e.String()
+	}
+	return ret
+}
+
+// IsMatchingEventType returns true if a workflow with the given `on'
+// value should run for an incoming event.  The incoming event is either
+// a bare type, like "push", or a type and the activity from the
+// webhook's `action' field, like "pull_request.opened".  An unfiltered
+// `on' value matches any activity of its type, and a filtered one, like
+// "pull_request.opened", matches only that activity.  Event types and
+// filters are compared case-insensitively.  No allocation is done, so
+// this is cheap enough to call for every workflow on every incoming
+// webhook.
+func IsMatchingEventType(flowOn, eventType string) bool {
+	typ, filter := splitEvent(flowOn)
+	return matchEvent(typ, filter, eventType)
+}
+
+// Matches reports whether a workflow subscribed to e should run for an
+// incoming event, with the same semantics as IsMatchingEventType.
+func (e Event) Matches(eventType string) bool {
+	return matchEvent(e.Type, e.Filter, eventType)
+}
+
+func matchEvent(typ, filter, eventType string) bool {
+	hookType, hookAction := splitEvent(eventType)
+	if !equalFoldASCII(typ, hookType) {
+		return false
+	}
+	return filter == "" || equalFoldASCII(filter, hookAction)
+}
+
+func equalFoldASCII(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		if lowerASCII(a[i]) != lowerASCII(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// lowerASCII lowercases an ASCII letter.  Event types are ASCII, so
+// there's no need for full Unicode case folding.
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
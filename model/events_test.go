@@ -0,0 +1,94 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsMatchingEventType(t *testing.T) {
+	assert.True(t, IsMatchingEventType("push", "push"))
+	assert.True(t, IsMatchingEventType("PUSH", "push"))
+	assert.True(t, IsMatchingEventType("Pull_Request", "pull_request"))
+	assert.False(t, IsMatchingEventType("push", "pull_request"))
+	assert.False(t, IsMatchingEventType("push", "pus"))
+	assert.False(t, IsMatchingEventType("", "push"))
+
+	// filters
+	assert.True(t, IsMatchingEventType("pull_request", "pull_request.opened"))
+	assert.True(t, IsMatchingEventType("pull_request.opened", "pull_request.opened"))
+	assert.True(t, IsMatchingEventType("Pull_Request.Opened", "pull_request.opened"))
+	assert.False(t, IsMatchingEventType("pull_request.opened", "pull_request.closed"))
+	assert.False(t, IsMatchingEventType("pull_request.opened", "pull_request"))
+	assert.False(t, IsMatchingEventType("pull_request.opened", "issues.opened"))
+}
+
+func TestParseEvent(t *testing.T) {
+	assert.Equal(t, Event{Raw: "push", Type: "push"}, ParseEvent("push"))
+	assert.Equal(t, Event{Raw: "pull_request.opened", Type: "pull_request", Filter: "opened"}, ParseEvent("pull_request.opened"))
+	assert.Equal(t, "pull_request.opened", ParseEvent("pull_request.opened").String())
+	assert.Equal(t, "push", Event{Type: "push"}.String())
+
+	// The type of a scheduled event is always lowercase, but Raw keeps
+	// the event as written.
+	e := ParseEvent("Schedule(0 * * * *)")
+	assert.Equal(t, ScheduleEventType, e.Type)
+	assert.Equal(t, "Schedule(0 * * * *)", e.String())
+	e.Raw = ""
+	assert.Equal(t, "schedule(0 * * * *)", e.String())
+}
+
+func TestParseEvents(t *testing.T) {
+	assert.Empty(t, ParseEvents())
+
+	events := ParseEvents("Pull_Request.opened", "schedule(*/30 * * * *)")
+	require.Len(t, events, 2)
+	assert.Equal(t, Event{Raw: "Pull_Request.opened", Type: "Pull_Request", Filter: "opened"}, events[0])
+	assert.Equal(t, ScheduleEventType, events[1].Type)
+	require.NotNil(t, events[1].Schedule)
+	assert.Equal(t, []int{0, 30}, events[1].Schedule.Minute)
+	assert.Equal(t, []string{"Pull_Request.opened", "schedule(*/30 * * * *)"}, EventStrings(events))
+}
+
+func TestScheduleEvents(t *testing.T) {
+	e := ParseEvent("schedule(*/15 * * * *)")
+	assert.Equal(t, ScheduleEventType, e.Type)
+	require.NotNil(t, e.Schedule)
+	assert.Equal(t, []int{0, 15, 30, 45}, e.Schedule.Minute)
+	assert.Equal(t, "schedule(*/15 * * * *)", e.String())
+	assert.True(t, e.Matches("schedule"))
+	assert.True(t, IsMatchingEventType("schedule(*/15 * * * *)", "schedule"))
+
+	e = ParseEvent("schedule(bogus)")
+	assert.Equal(t, &Schedule{Expression: "bogus"}, e.Schedule)
+	assert.Equal(t, "schedule(bogus)", e.String())
+
+	assert.Nil(t, ParseEvent("schedule").Schedule)
+}
+
+func TestGetWorkflows(t *testing.T) {
+	c := &Configuration{
+		Workflows: []*Workflow{
+			{Identifier: "a", On: ParseEvents("push")},
+			{Identifier: "b", On: ParseEvents("Pull_Request")},
+			{Identifier: "c", On: ParseEvents("PUSH")},
+			{Identifier: "d", On: ParseEvents("release", "push")},
+		},
+	}
+	assert.Equal(t, []*Workflow{c.Workflows[0], c.Workflows[2], c.Workflows[3]}, c.GetWorkflows("push"))
+	assert.Equal(t, []*Workflow{c.Workflows[1]}, c.GetWorkflows("pull_request"))
+	assert.Equal(t, []*Workflow{c.Workflows[3]}, c.GetWorkflows("release"))
+	assert.Empty(t, c.GetWorkflows("fork"))
+	assert.Empty(t, (&Workflow{}).On)
+}
+
+func BenchmarkIsMatchingEventType(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsMatchingEventType("pull_request", "pull_request")
+		IsMatchingEventType("Pull_Request", "pull_request")
+		IsMatchingEventType("push", "pull_request")
+		IsMatchingEventType("pull_request.opened", "pull_request.closed")
+	}
+}
@@ -0,0 +1,309 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The JSON encoding of a Configuration is stable: fields are only ever
+// added, never renamed or removed.  It looks like this:
+//
+//	{
+//	  "version": 1,
+//	  "actions": [
+//	    {
+//	      "identifier": "build",
+//	      "description": "Build the image",
+//	      "extends": "go",
+//	      "uses": {"kind": "repository", "raw": "actions/docker/cli@master",
+//	               "repository": "actions/docker", "path": "cli", "ref": "master"},
+//	      "runs": "sh -c",
+//	      "args": ["make", "build"],
+//	      "needs": ["lint"],
+//	      "env": {"GOOS": "linux"},
+//	      "matrix": {"goarch": ["amd64", "arm64"]},
+//	      "secrets": ["GITHUB_TOKEN"],
+//	      "if": "github.ref == 'refs/heads/master'",
+//	      "continue_on_error": true
+//	    }
+//	  ],
+//	  "workflows": [
+//	    {"identifier": "ci", "on": "push", "resolves": ["build"], "env": {"CI": "true"}},
+//	    {"identifier": "pr", "on": ["push", "pull_request.opened"]}
+//	  ],
+//	  "templates": [
+//	    {"identifier": "go", "uses": {"kind": "docker", "raw": "docker://golang", "image": "golang"}}
+//	  ],
+//	  "env": {"GOPATH": "/go"}
+//	}
+//
+// A workflow's `on' is a string for a single event and a list of strings
+// for several.  Events are written as in the .workflow file, with any
+// filter after a dot.
+// The `kind' of uses is one of "path", "docker", "repository", or
+// "invalid", and the other fields of uses depend on it; `raw' is always
+// present.  The `runs' and `args' attributes are a string or a list of
+// strings, as in the .workflow file; heredocs are strings, so they read
+// back as the string form.  Attributes that are not set are
+// omitted, except that `actions', `workflows', and `identifier' are
+// always present.
+
+// The values of the `kind' field in the JSON encoding of Uses.
+const (
+	UsesKindPath       = "path"
+	UsesKindDocker     = "docker"
+	UsesKindRepository = "repository"
+	UsesKindInvalid    = "invalid"
+)
+
+type configurationJSON struct {
+	Version   int               `json:"version,omitempty"`
+	Actions   []*Action         `json:"actions"`
+	Workflows []*Workflow       `json:"workflows"`
+	Templates []*Action         `json:"templates,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+type actionJSON struct {
+	Identifier      string              `json:"identifier"`
+	Description     string              `json:"description,omitempty"`
+	Extends         string              `json:"extends,omitempty"`
+	Uses            *usesJSON           `json:"uses,omitempty"`
+	Runs            json.RawMessage     `json:"runs,omitempty"`
+	Args            json.RawMessage     `json:"args,omitempty"`
+	Needs           []string            `json:"needs,omitempty"`
+	Env             map[string]string   `json:"env,omitempty"`
+	Matrix          map[string][]string `json:"matrix,omitempty"`
+	Secrets         []string            `json:"secrets,omitempty"`
+	If              string              `json:"if,omitempty"`
+	ContinueOnError bool                `json:"continue_on_error,omitempty"`
+}
+
+type workflowJSON struct {
+	Identifier  string            `json:"identifier"`
+	Description string            `json:"description,omitempty"`
+	On          json.RawMessage   `json:"on,omitempty"`
+	Resolves    []string          `json:"resolves,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+}
+
+type usesJSON struct {
+	Kind       string `json:"kind"`
+	Raw        string `json:"raw"`
+	Image      string `json:"image,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Ref        string `json:"ref,omitempty"`
+}
+
+// MarshalJSON encodes c in the stable JSON format described above.
+func (c Configuration) MarshalJSON() ([]byte, error) {
+	ret := configurationJSON{Version: c.Version, Actions: c.Actions, Workflows: c.Workflows, Templates: c.Templates, Env: c.Env}
+	if ret.Actions == nil {
+		ret.Actions = []*Action{}
+	}
+	if ret.Workflows == nil {
+		ret.Workflows = []*Workflow{}
+	}
+	return json.Marshal(ret)
+}
+
+// UnmarshalJSON decodes c from the stable JSON format described above.
+func (c *Configuration) UnmarshalJSON(b []byte) error {
+	var cj configurationJSON
+	if err := json.Unmarshal(b, &cj); err != nil {
+		return err
+	}
+	c.Version, c.Actions, c.Workflows, c.Templates, c.Env = cj.Version, cj.Actions, cj.Workflows, cj.Templates, cj.Env
+	return nil
+}
+
+// MarshalJSON encodes a in the stable JSON format described above.
+func (a Action) MarshalJSON() ([]byte, error) {
+	aj := actionJSON{
+		Identifier:      a.Identifier,
+		Description:     a.Description,
+		Extends:         a.Extends,
+		Needs:           a.Needs,
+		Env:             a.Env,
+		Matrix:          a.Matrix,
+		Secrets:         a.Secrets,
+		If:              a.If,
+		ContinueOnError: a.ContinueOnError,
+	}
+	if a.Uses != nil {
+		aj.Uses = usesToJSON(a.Uses)
+	}
+
+	var err error
+	if aj.Runs, err = commandToJSON(a.Runs); err != nil {
+		return nil, err
+	}
+	if aj.Args, err = commandToJSON(a.Args); err != nil {
+		return nil, err
+	}
+	return json.Marshal(aj)
+}
+
+// UnmarshalJSON decodes a from the stable JSON format described above.
+func (a *Action) UnmarshalJSON(b []byte) error {
+	var aj actionJSON
+	if err := json.Unmarshal(b, &aj); err != nil {
+		return err
+	}
+
+	*a = Action{
+		Identifier:      aj.Identifier,
+		Description:     aj.Description,
+		Extends:         aj.Extends,
+		Needs:           aj.Needs,
+		Env:             aj.Env,
+		Matrix:          aj.Matrix,
+		Secrets:         aj.Secrets,
+		If:              aj.If,
+		ContinueOnError: aj.ContinueOnError,
+	}
+	if aj.Uses != nil {
+		uses, err := usesFromJSON(aj.Uses)
+		if err != nil {
+			return err
+		}
+		a.Uses = uses
+	}
+
+	var err error
+	if a.Runs, err = commandFromJSON("runs", aj.Runs); err != nil {
+		return err
+	}
+	if a.Args, err = commandFromJSON("args", aj.Args); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON encodes w in the stable JSON format described above.
+func (w Workflow) MarshalJSON() ([]byte, error) {
+	wj := workflowJSON{
+		Identifier:  w.Identifier,
+		Description: w.Description,
+		Resolves:    w.Resolves,
+		Env:         w.Env,
+	}
+	var err error
+	switch len(w.On) {
+	case 0:
+	case 1:
+		wj.On, err = json.Marshal(w.On[0].String())
+	default:
+		wj.On, err = json.Marshal(EventStrings(w.On))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(wj)
+}
+
+// UnmarshalJSON decodes w from the stable JSON format described above.
+func (w *Workflow) UnmarshalJSON(b []byte) error {
+	var wj workflowJSON
+	if err := json.Unmarshal(b, &wj); err != nil {
+		return err
+	}
+	on, err := eventsFromJSON(wj.On)
+	if err != nil {
+		return err
+	}
+	*w = Workflow{
+		Identifier:  wj.Identifier,
+		Description: wj.Description,
+		On:          on,
+		Resolves:    wj.Resolves,
+		Env:         wj.Env,
+	}
+	return nil
+}
+
+func eventsFromJSON(b json.RawMessage) ([]Event, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		return ParseEvents(s), nil
+	}
+	var values []string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("`on' must be a string or a list of strings")
+	}
+	return ParseEvents(values...), nil
+}
+
+func usesToJSON(u Uses) *usesJSON {
+	ret := &usesJSON{Raw: u.String()}
+	switch u := u.(type) {
+	case *UsesPath:
+		ret.Kind, ret.Path = UsesKindPath, u.Path
+	case *UsesDockerImage:
+		ret.Kind, ret.Image = UsesKindDocker, u.Image
+	case *UsesRepository:
+		ret.Kind, ret.Repository, ret.Path, ret.Ref = UsesKindRepository, u.Repository, u.Path, u.Ref
+	default:
+		ret.Kind = UsesKindInvalid
+	}
+	return ret
+}
+
+func usesFromJSON(uj *usesJSON) (Uses, error) {
+	switch uj.Kind {
+	case UsesKindPath:
+		return &UsesPath{Path: uj.Path}, nil
+	case UsesKindDocker:
+		image, _ := ParseDockerImage(uj.Image)
+		return image, nil
+	case UsesKindRepository:
+		return &UsesRepository{Repository: uj.Repository, Path: uj.Path, Ref: uj.Ref}, nil
+	case UsesKindInvalid:
+		return &UsesInvalid{Raw: uj.Raw}, nil
+	case "":
+		// Accept a bare raw value, for hand-written input.
+		return ParseUses(uj.Raw), nil
+	default:
+		return nil, fmt.Errorf("unknown kind of uses `%s'", uj.Kind)
+	}
+}
+
+func commandToJSON(c Command) (json.RawMessage, error) {
+	switch c := c.(type) {
+	case nil:
+		return nil, nil
+	case *StringCommand:
+		return json.Marshal(c.Value)
+	case *HeredocCommand:
+		return json.Marshal(c.Value)
+	case *ListCommand:
+		values := c.Values
+		if values == nil {
+			values = []string{}
+		}
+		return json.Marshal(values)
+	default:
+		return json.Marshal(c.Split())
+	}
+}
+
+func commandFromJSON(name string, b json.RawMessage) (Command, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		return &StringCommand{Value: s}, nil
+	}
+	var values []string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("`%s' must be a string or a list of strings", name)
+	}
+	return &ListCommand{Values: values}, nil
+}
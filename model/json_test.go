@@ -0,0 +1,94 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationJSON(t *testing.T) {
+	c := &Configuration{
+		Actions: []*Action{
+			{
+				Identifier:      "build",
+				Description:     "Build the image",
+				Uses:            &UsesRepository{Repository: "actions/docker", Path: "cli", Ref: "master"},
+				Runs:            &StringCommand{Value: "sh -c"},
+				Args:            &ListCommand{Values: []string{"make", "build"}},
+				Needs:           []string{"lint"},
+				Env:             map[string]string{"GOOS": "linux"},
+				Secrets:         []string{"GITHUB_TOKEN"},
+				If:              "github.ref == 'refs/heads/master'",
+				ContinueOnError: true,
+			},
+			{Identifier: "lint", Uses: &UsesPath{Path: "lint"}},
+			{Identifier: "image", Uses: &UsesDockerImage{Image: "alpine", Repository: "alpine"}},
+			{Identifier: "bad", Uses: &UsesInvalid{Raw: "nope"}},
+		},
+		Workflows: []*Workflow{
+			{Identifier: "ci", On: ParseEvents("push"), Resolves: []string{"build"}, Env: map[string]string{"CI": "true"}},
+			{Identifier: "pr", On: ParseEvents("push", "pull_request.opened")},
+		},
+		Env: map[string]string{"GOPATH": "/go"},
+	}
+
+	b, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+	  "actions": [
+	    {
+	      "identifier": "build",
+	      "description": "Build the image",
+	      "uses": {"kind": "repository", "raw": "actions/docker/cli@master", "repository": "actions/docker", "path": "cli", "ref": "master"},
+	      "runs": "sh -c",
+	      "args": ["make", "build"],
+	      "needs": ["lint"],
+	      "env": {"GOOS": "linux"},
+	      "secrets": ["GITHUB_TOKEN"],
+	      "if": "github.ref == 'refs/heads/master'",
+	      "continue_on_error": true
+	    },
+	    {"identifier": "lint", "uses": {"kind": "path", "raw": "./lint", "path": "lint"}},
+	    {"identifier": "image", "uses": {"kind": "docker", "raw": "docker://alpine", "image": "alpine"}},
+	    {"identifier": "bad", "uses": {"kind": "invalid", "raw": "nope"}}
+	  ],
+	  "workflows": [
+	    {"identifier": "ci", "on": "push", "resolves": ["build"], "env": {"CI": "true"}},
+	    {"identifier": "pr", "on": ["push", "pull_request.opened"]}
+	  ],
+	  "env": {"GOPATH": "/go"}
+	}`, string(b))
+
+	var decoded Configuration
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, c, &decoded)
+}
+
+func TestConfigurationJSONEmpty(t *testing.T) {
+	b, err := json.Marshal(Configuration{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"actions": [], "workflows": []}`, string(b))
+}
+
+func TestActionUnmarshalJSON(t *testing.T) {
+	var a Action
+	require.NoError(t, json.Unmarshal([]byte(`{"identifier": "a", "uses": {"raw": "docker://alpine"}}`), &a))
+	assert.Equal(t, Action{Identifier: "a", Uses: &UsesDockerImage{Image: "alpine", Repository: "alpine"}}, a)
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"uses": {"kind": "bananas"}}`), &a), "unknown kind of uses `bananas'")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"runs": 7}`), &a), "`runs' must be a string or a list of strings")
+}
+
+func TestWorkflowUnmarshalJSON(t *testing.T) {
+	var w Workflow
+	require.NoError(t, json.Unmarshal([]byte(`{"identifier": "w", "on": "schedule(0 * * * *)"}`), &w))
+	require.Len(t, w.On, 1)
+	assert.Equal(t, "0 * * * *", w.On[0].Schedule.Expression)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"identifier": "w"}`), &w))
+	assert.Empty(t, w.On)
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"on": 7}`), &w), "`on' must be a string or a list of strings")
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatrixCombinations returns every combination of the values in the
+// action's matrix, each mapping a dimension of the matrix to one of its
+// values.  Later dimensions, in alphabetical order, vary fastest, and the
+// values of each dimension come in the order they are listed.  It returns
+// nil if the action has no matrix, and no combinations if any dimension
+// has no values.
+func (a *Action) MatrixCombinations() []map[string]string {
+	if len(a.Matrix) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(a.Matrix))
+	for k := range a.Matrix {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ret := []map[string]string{{}}
+	for _, k := range keys {
+		next := make([]map[string]string, 0, len(ret)*len(a.Matrix[k]))
+		for _, combination := range ret {
+			for _, v := range a.Matrix[k] {
+				m := make(map[string]string, len(combination)+1)
+				for ck, cv := range combination {
+					m[ck] = cv
+				}
+				m[k] = v
+				next = append(next, m)
+			}
+		}
+		ret = next
+	}
+	return ret
+}
+
+// MatrixEnvName returns the environment variable that holds the value of
+// the matrix dimension key in each action ExpandMatrix makes, like
+// MATRIX_GO for `go'.
+func MatrixEnvName(key string) string {
+	return "MATRIX_" + strings.ToUpper(key)
+}
+
+// ExpandMatrix returns a copy of c in which each action with a matrix is
+// replaced by an action for each of its MatrixCombinations, in order.
+// Each has the original's attributes, with the combination's values set
+// in its env as MatrixEnvName says, and an identifier naming them, like
+// `test (go=1.12, os=linux)'.  Every `needs' and `resolves' reference to
+// the original refers to all of them instead.  It fails if one of the new
+// identifiers is already used, or if a matrix has a dimension with no
+// values.  Positions and comments are kept, so diagnostics about an
+// expanded action point at the original.
+func (c *Configuration) ExpandMatrix() (*Configuration, error) {
+	ret := c.Clone()
+	used := make(map[string]bool, len(c.Actions)+len(c.Workflows))
+	for _, a := range c.Actions {
+		used[a.Identifier] = true
+	}
+	for _, w := range c.Workflows {
+		used[w.Identifier] = true
+	}
+
+	expanded := make(map[string][]string)
+	actions := make([]*Action, 0, len(ret.Actions))
+	for _, a := range ret.Actions {
+		if len(a.Matrix) == 0 {
+			actions = append(actions, a)
+			continue
+		}
+		combinations := a.MatrixCombinations()
+		if len(combinations) == 0 {
+			return nil, fmt.Errorf("matrix of action `%s' has a dimension with no values", a.Identifier)
+		}
+		var ids []string
+		for _, combination := range combinations {
+			e := a.Clone()
+			e.Identifier = MatrixIdentifier(a.Identifier, combination)
+			if used[e.Identifier] {
+				return nil, fmt.Errorf("expanding the matrix of action `%s' makes `%s', which is already used", a.Identifier, e.Identifier)
+			}
+			used[e.Identifier] = true
+			e.Matrix = nil
+			if e.Env == nil {
+				e.Env = make(map[string]string, len(combination))
+			}
+			for k, v := range combination {
+				e.Env[MatrixEnvName(k)] = v
+			}
+			actions = append(actions, e)
+			ids = append(ids, e.Identifier)
+		}
+		expanded[a.Identifier] = ids
+	}
+	ret.Actions = actions
+
+	for _, a := range ret.Actions {
+		a.Needs = expandReferences(a.Needs, expanded)
+	}
+	for _, w := range ret.Workflows {
+		w.Resolves = expandReferences(w.Resolves, expanded)
+	}
+	return ret, nil
+}
+
+// MatrixIdentifier returns the identifier of the action for combination
+// in the matrix of the action id.
+func MatrixIdentifier(id string, combination map[string]string) string {
+	keys := make([]string, 0, len(combination))
+	for k := range combination {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + combination[k]
+	}
+	return id + " (" + strings.Join(parts, ", ") + ")"
+}
+
+// expandReferences replaces each identifier in ids that expanded maps
+// with the identifiers it maps to.
+func expandReferences(ids []string, expanded map[string][]string) []string {
+	var ret []string
+	changed := false
+	for _, id := range ids {
+		if e, ok := expanded[id]; ok {
+			ret = append(ret, e...)
+			changed = true
+		} else {
+			ret = append(ret, id)
+		}
+	}
+	if !changed {
+		return ids
+	}
+	return ret
+}
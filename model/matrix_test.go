@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixCombinations(t *testing.T) {
+	a := &Action{Matrix: map[string][]string{"os": {"linux", "darwin"}, "go": {"1.11", "1.12"}}}
+	assert.Equal(t, []map[string]string{
+		{"go": "1.11", "os": "linux"},
+		{"go": "1.11", "os": "darwin"},
+		{"go": "1.12", "os": "linux"},
+		{"go": "1.12", "os": "darwin"},
+	}, a.MatrixCombinations())
+
+	assert.Nil(t, (&Action{}).MatrixCombinations())
+	assert.Empty(t, (&Action{Matrix: map[string][]string{"go": {"1.12"}, "os": nil}}).MatrixCombinations())
+}
+
+func TestExpandMatrix(t *testing.T) {
+	c := &Configuration{
+		Actions: []*Action{
+			{Identifier: "build"},
+			{
+				Identifier: "test",
+				Needs:      []string{"build"},
+				Env:        map[string]string{"CI": "true"},
+				Matrix:     map[string][]string{"go": {"1.11", "1.12"}, "os": {"linux"}},
+			},
+			{Identifier: "deploy", Needs: []string{"test"}},
+		},
+		Workflows: []*Workflow{{Identifier: "ci", Resolves: []string{"deploy", "test"}}},
+	}
+	expanded, err := c.ExpandMatrix()
+	require.NoError(t, err)
+
+	assert.Equal(t, []*Action{
+		{Identifier: "build"},
+		{
+			Identifier: "test (go=1.11, os=linux)",
+			Needs:      []string{"build"},
+			Env:        map[string]string{"CI": "true", "MATRIX_GO": "1.11", "MATRIX_OS": "linux"},
+		},
+		{
+			Identifier: "test (go=1.12, os=linux)",
+			Needs:      []string{"build"},
+			Env:        map[string]string{"CI": "true", "MATRIX_GO": "1.12", "MATRIX_OS": "linux"},
+		},
+		{Identifier: "deploy", Needs: []string{"test (go=1.11, os=linux)", "test (go=1.12, os=linux)"}},
+	}, expanded.Actions)
+	assert.Equal(t, []string{"deploy", "test (go=1.11, os=linux)", "test (go=1.12, os=linux)"}, expanded.Workflows[0].Resolves)
+
+	// The original is unchanged.
+	assert.Len(t, c.Actions, 3)
+	assert.Equal(t, []string{"test"}, c.Actions[2].Needs)
+	assert.Equal(t, map[string]string{"CI": "true"}, c.Actions[1].Env)
+
+	c.Actions[0].Identifier = "test (go=1.12, os=linux)"
+	_, err = c.ExpandMatrix()
+	assert.EqualError(t, err, "expanding the matrix of action `test' makes `test (go=1.12, os=linux)', which is already used")
+
+	c.Actions[1].Matrix["os"] = nil
+	_, err = c.ExpandMatrix()
+	assert.EqualError(t, err, "matrix of action `test' has a dimension with no values")
+}
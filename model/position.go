@@ -0,0 +1,72 @@
+package model
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// Pos is a position in a .workflow file.  Line and Column are 1-based,
+// and Offset is the byte offset from the beginning of the file.  File is
+// the name of the file, if the parser was given one.  The zero value
+// means the position is unknown, as it is for anything not read from a
+// file.
+type Pos struct {
+	File   string
+	Line   int
+	Column int
+	Offset int
+}
+
+// IsValid reports whether the position is known.
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+// PosAt returns the position of a byte offset in src, with the column
+// counted in characters, as the parser counts it.  Offsets past the end
+// of src are clamped to it.
+func PosAt(src []byte, offset int) Pos {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	return Pos{
+		Line:   bytes.Count(src[:offset], []byte("\n")) + 1,
+		Column: utf8.RuneCount(src[lineStart:offset]) + 1,
+		Offset: offset,
+	}
+}
+
+// OffsetIn returns the byte offset in src of the position's Line and
+// Column, ignoring its Offset.  Columns past the end of a line are
+// clamped to the end of that line, and lines past the end of src to the
+// end of src.
+func (p Pos) OffsetIn(src []byte) int {
+	offset := 0
+	for l := 1; l < p.Line; l++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return len(src)
+		}
+		offset += i + 1
+	}
+	for c := 1; c < p.Column && offset < len(src) && src[offset] != '\n'; c++ {
+		_, size := utf8.DecodeRune(src[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// AttributePos returns the position of the named attribute, e.g., "uses"
+// or "env", in the action's block.  It returns the zero Pos if the
+// attribute isn't set or the action wasn't read from a file.
+func (a *Action) AttributePos(name string) Pos {
+	return a.Positions[name]
+}
+
+// AttributePos returns the position of the named attribute, e.g., "on"
+// or "resolves", in the workflow's block.  It returns the zero Pos if
+// the attribute isn't set or the workflow wasn't read from a file.
+func (w *Workflow) AttributePos(name string) Pos {
+	return w.Positions[name]
+}
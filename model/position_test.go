@@ -0,0 +1,20 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPosAt(t *testing.T) {
+	src := []byte("ab\nü = 1\n")
+	assert.Equal(t, Pos{Line: 1, Column: 1}, PosAt(src, 0))
+	assert.Equal(t, Pos{Line: 2, Column: 2, Offset: 5}, PosAt(src, 5))
+	assert.Equal(t, Pos{Line: 3, Column: 1, Offset: 10}, PosAt(src, 99))
+
+	for _, offset := range []int{0, 2, 3, 5, 9, 10} {
+		assert.Equal(t, offset, PosAt(src, offset).OffsetIn(src))
+	}
+	assert.Equal(t, 2, Pos{Line: 1, Column: 9}.OffsetIn(src))
+	assert.Equal(t, 10, Pos{Line: 7, Column: 1}.OffsetIn(src))
+}
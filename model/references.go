@@ -0,0 +1,50 @@
+package model
+
+import "sort"
+
+// ReferencedRepositories returns the repositories the actions in c use,
+// like "actions/bin", without paths or refs, sorted and with each listed
+// once.  RepositoryCounts says how many actions use each.
+func (c *Configuration) ReferencedRepositories() []string {
+	return sortedKeys(c.RepositoryCounts())
+}
+
+// ReferencedImages returns the Docker images the actions in c use, as
+// written after `docker://', like "alpine:3.8", sorted and with each
+// listed once.  ImageCounts says how many actions use each.
+func (c *Configuration) ReferencedImages() []string {
+	return sortedKeys(c.ImageCounts())
+}
+
+// RepositoryCounts returns the number of actions in c that use each
+// repository, keyed as in ReferencedRepositories.
+func (c *Configuration) RepositoryCounts() map[string]int {
+	ret := make(map[string]int)
+	for _, a := range c.Actions {
+		if u, ok := a.Uses.(*UsesRepository); ok {
+			ret[u.Repository]++
+		}
+	}
+	return ret
+}
+
+// ImageCounts returns the number of actions in c that use each Docker
+// image, keyed as in ReferencedImages.
+func (c *Configuration) ImageCounts() map[string]int {
+	ret := make(map[string]int)
+	for _, a := range c.Actions {
+		if u, ok := a.Uses.(*UsesDockerImage); ok {
+			ret[u.Image]++
+		}
+	}
+	return ret
+}
+
+func sortedKeys(m map[string]int) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
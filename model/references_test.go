@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferences(t *testing.T) {
+	c := &Configuration{
+		Actions: []*Action{
+			{Identifier: "a", Uses: ParseUses("actions/bin/sh@master")},
+			{Identifier: "b", Uses: ParseUses("docker://alpine:3.8")},
+			{Identifier: "c", Uses: ParseUses("actions/bin/curl@v1")},
+			{Identifier: "d", Uses: ParseUses("./local")},
+			{Identifier: "e", Uses: ParseUses("actions/checkout@v1")},
+			{Identifier: "f", Uses: ParseUses("docker://alpine:3.8")},
+			{Identifier: "g", Uses: ParseUses("docker://golang")},
+			{Identifier: "h"},
+		},
+	}
+	assert.Equal(t, []string{"actions/bin", "actions/checkout"}, c.ReferencedRepositories())
+	assert.Equal(t, map[string]int{"actions/bin": 2, "actions/checkout": 1}, c.RepositoryCounts())
+	assert.Equal(t, []string{"alpine:3.8", "golang"}, c.ReferencedImages())
+	assert.Equal(t, map[string]int{"alpine:3.8": 2, "golang": 1}, c.ImageCounts())
+
+	empty := &Configuration{}
+	assert.Empty(t, empty.ReferencedRepositories())
+	assert.NotNil(t, empty.ReferencedImages())
+}
@@ -0,0 +1,87 @@
+package model
+
+import "fmt"
+
+// RenameAction changes the identifier of the action oldID to newID, and
+// every `needs' and `resolves' reference to it.  It fails, without
+// changing anything, if there is no action oldID, or if newID is blank or
+// already identifies an action or workflow.  Actions and workflows are
+// checked together since they share a namespace by default.
+func (c *Configuration) RenameAction(oldID, newID string) error {
+	action := c.GetAction(oldID)
+	if action == nil {
+		return fmt.Errorf("no action `%s'", oldID)
+	}
+	if oldID == newID {
+		return nil
+	}
+	if err := c.checkRename(newID); err != nil {
+		return err
+	}
+
+	action.Identifier = newID
+	for _, a := range c.Actions {
+		replaceString(a.Needs, oldID, newID)
+	}
+	for _, w := range c.Workflows {
+		replaceString(w.Resolves, oldID, newID)
+	}
+	return nil
+}
+
+// RenameWorkflow changes the identifier of the workflow oldID to newID.
+// Nothing refers to workflows by identifier, so there are no references
+// to update.  It fails like RenameAction does.
+func (c *Configuration) RenameWorkflow(oldID, newID string) error {
+	workflow := c.GetWorkflow(oldID)
+	if workflow == nil {
+		return fmt.Errorf("no workflow `%s'", oldID)
+	}
+	if oldID == newID {
+		return nil
+	}
+	if err := c.checkRename(newID); err != nil {
+		return err
+	}
+
+	workflow.Identifier = newID
+	return nil
+}
+
+// checkRename returns an error if id can't be given to an action or
+// workflow, saying where the one that already has it is defined.
+func (c *Configuration) checkRename(id string) error {
+	if id == "" {
+		return fmt.Errorf("identifiers cannot be blank")
+	}
+	if a := c.GetAction(id); a != nil {
+		return fmt.Errorf("action `%s' already exists%s", id, definedAt(a.Pos))
+	}
+	if w := c.GetWorkflow(id); w != nil {
+		return fmt.Errorf("workflow `%s' already exists%s", id, definedAt(w.Pos))
+	}
+	return nil
+}
+
+// definedAt describes pos for an error message, or returns an empty
+// string if it isn't known.
+func definedAt(pos Pos) string {
+	switch {
+	case !pos.IsValid():
+		return ""
+	case pos.File != "":
+		return fmt.Sprintf(" at %s:%d", pos.File, pos.Line)
+	default:
+		return fmt.Sprintf(" at line %d", pos.Line)
+	}
+}
+
+// replaceString replaces each occurrence of old in list with new, in
+// place.
+func replaceString(list []string, old, new string) {
+	for i, v := range list {
+		if v == old {
+			list[i] = new
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renameConfig() *Configuration {
+	return &Configuration{
+		Actions: []*Action{
+			{Identifier: "a", Pos: Pos{File: "main.workflow", Line: 5}},
+			{Identifier: "b", Needs: []string{"a"}},
+			{Identifier: "c", Needs: []string{"a", "b"}},
+		},
+		Workflows: []*Workflow{
+			{Identifier: "w", Resolves: []string{"c", "a"}, Pos: Pos{Line: 1}},
+		},
+	}
+}
+
+func TestRenameAction(t *testing.T) {
+	c := renameConfig()
+	require.NoError(t, c.RenameAction("a", "setup"))
+	assert.NotNil(t, c.GetAction("setup"))
+	assert.Nil(t, c.GetAction("a"))
+	assert.Equal(t, []string{"setup"}, c.Actions[1].Needs)
+	assert.Equal(t, []string{"setup", "b"}, c.Actions[2].Needs)
+	assert.Equal(t, []string{"c", "setup"}, c.Workflows[0].Resolves)
+
+	require.NoError(t, c.RenameAction("b", "b"))
+	assert.EqualError(t, c.RenameAction("a", "x"), "no action `a'")
+	assert.EqualError(t, c.RenameAction("b", ""), "identifiers cannot be blank")
+	assert.EqualError(t, c.RenameAction("b", "setup"), "action `setup' already exists at main.workflow:5")
+	assert.EqualError(t, c.RenameAction("b", "w"), "workflow `w' already exists at line 1")
+	assert.Equal(t, []string{"setup", "b"}, c.Actions[2].Needs)
+}
+
+func TestRenameWorkflow(t *testing.T) {
+	c := renameConfig()
+	require.NoError(t, c.RenameWorkflow("w", "ci"))
+	assert.NotNil(t, c.GetWorkflow("ci"))
+
+	assert.EqualError(t, c.RenameWorkflow("w", "x"), "no workflow `w'")
+	assert.EqualError(t, c.RenameWorkflow("ci", "b"), "action `b' already exists")
+}
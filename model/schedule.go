@@ -0,0 +1,142 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schedule is the cron expression of a scheduled event, written as
+// `on = "schedule(*/15 * * * *)"'.  Each field lists, in increasing
+// order, the values at which the workflow runs.  The fields are nil if
+// the expression is invalid; the parser reports those.
+type Schedule struct {
+	Expression string
+
+	Minute     []int
+	Hour       []int
+	DayOfMonth []int
+	Month      []int
+	DayOfWeek  []int
+}
+
+// cronField describes one of the five fields of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = [...]cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 6, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// ParseSchedule parses a standard five-field cron expression: minute,
+// hour, day of month, month, and day of week.  Each field is `*' or a
+// comma-separated list of values and ranges like `1-5', either of which
+// can have a step like `*/15'.  Months and days of the week can also be
+// given by their three-letter English names, and 7 is Sunday.
+func ParseSchedule(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	ret := &Schedule{Expression: expr}
+	values := [...]*[]int{&ret.Minute, &ret.Hour, &ret.DayOfMonth, &ret.Month, &ret.DayOfWeek}
+	for i, field := range fields {
+		v, err := cronFields[i].parse(field)
+		if err != nil {
+			return nil, err
+		}
+		*values[i] = v
+	}
+	return ret, nil
+}
+
+// parse returns the values listed by s.
+func (f *cronField) parse(s string) ([]int, error) {
+	set := make(map[int]bool)
+	for _, item := range strings.Split(s, ",") {
+		rng, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			rng = item[:i]
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step `%s' in %s field", item[i+1:], f.name)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			i := strings.IndexByte(rng, '-')
+			var err error
+			if lo, err = f.value(rng[:i]); err != nil {
+				return nil, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return nil, err
+			}
+			if lo > hi {
+				return nil, fmt.Errorf("invalid range `%s' in %s field", rng, f.name)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return nil, err
+			}
+			// `5/15' means every 15 starting at 5; a bare `5' is just 5.
+			if step == 1 {
+				hi = lo
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[f.normalize(v)] = true
+		}
+	}
+
+	ret := make([]int, 0, len(set))
+	for v := range set {
+		ret = append(ret, v)
+	}
+	sort.Ints(ret)
+	return ret, nil
+}
+
+// value parses a single number or name.
+func (f *cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	max := f.max
+	if f.name == "day of week" {
+		max = 7
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || s[0] == '+' || s[0] == '-' {
+		return 0, fmt.Errorf("invalid value `%s' in %s field", s, f.name)
+	}
+	if v < f.min || v > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d in %s field", v, f.min, max, f.name)
+	}
+	return v, nil
+}
+
+// normalize maps 7, the alternate spelling of Sunday, to 0.
+func (f *cronField) normalize(v int) int {
+	if f.name == "day of week" && v == 7 {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	s, err := ParseSchedule("*/15 0-6/2 1,15 jan-mar MON-FRI")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 15, 30, 45}, s.Minute)
+	assert.Equal(t, []int{0, 2, 4, 6}, s.Hour)
+	assert.Equal(t, []int{1, 15}, s.DayOfMonth)
+	assert.Equal(t, []int{1, 2, 3}, s.Month)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, s.DayOfWeek)
+
+	s, err = ParseSchedule("  5/20  *  * * 7 ")
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 25, 45}, s.Minute)
+	assert.Len(t, s.Hour, 24)
+	assert.Len(t, s.DayOfMonth, 31)
+	assert.Equal(t, []int{0}, s.DayOfWeek)
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                 "expected 5 fields, got 0",
+		"* * * *":          "expected 5 fields, got 4",
+		"* * * * * *":      "expected 5 fields, got 6",
+		"60 * * * *":       "value 60 out of range 0-59 in minute field",
+		"* 24 * * *":       "value 24 out of range 0-23 in hour field",
+		"* * 0 * *":        "value 0 out of range 1-31 in day of month field",
+		"* * * foo *":      "invalid value `foo' in month field",
+		"* * * * 8":        "value 8 out of range 0-7 in day of week field",
+		"*/0 * * * *":      "invalid step `0' in minute field",
+		"*/x * * * *":      "invalid step `x' in minute field",
+		"5-1 * * * *":      "invalid range `5-1' in minute field",
+		"1,,2 * * * *":     "invalid value `' in minute field",
+		"+1 * * * *":       "invalid value `+1' in minute field",
+		"* * * * mon-fri/": "invalid step `' in day of week field",
+	}
+	for expr, msg := range tests {
+		_, err := ParseSchedule(expr)
+		assert.EqualError(t, err, msg, "expression %q", expr)
+	}
+}
@@ -0,0 +1,106 @@
+package model
+
+import "fmt"
+
+// GetTemplate looks up a template by identifier.
+//
+// If the template is not found, nil is returned.
+func (c *Configuration) GetTemplate(id string) *Action {
+	for _, template := range c.Templates {
+		if template.Identifier == id {
+			return template
+		}
+	}
+	return nil
+}
+
+// ResolveTemplate returns a copy of a with the attributes of the template
+// it extends filled in, and Extends cleared.  A's own `uses', `runs',
+// `args', `if', and `matrix' replace the template's; its env variables
+// override the template's one by one; its secrets are added to the
+// template's; and it continues on error if either does.  The template's
+// description and comments are its own, and aren't copied.  If a extends
+// no template, or one that c doesn't have, ResolveTemplate returns a
+// copy of a as it is.
+func (c *Configuration) ResolveTemplate(a *Action) *Action {
+	ret := a.Clone()
+	t := c.GetTemplate(a.Extends)
+	if a.Extends == "" || t == nil {
+		return ret
+	}
+	ret.Extends = ""
+
+	if ret.Uses == nil {
+		ret.Uses = cloneUses(t.Uses)
+	}
+	if ret.Runs == nil {
+		ret.Runs = cloneCommand(t.Runs)
+	}
+	if ret.Args == nil {
+		ret.Args = cloneCommand(t.Args)
+	}
+	if ret.If == "" {
+		ret.If = t.If
+	}
+	if ret.Matrix == nil {
+		ret.Matrix = cloneMatrix(t.Matrix)
+	}
+	ret.ContinueOnError = ret.ContinueOnError || t.ContinueOnError
+
+	if len(t.Env) > 0 {
+		env := cloneEnv(t.Env)
+		for k, v := range a.Env {
+			env[k] = v
+		}
+		ret.Env = env
+	}
+	if len(t.Secrets) > 0 {
+		secrets := cloneStrings(t.Secrets)
+		for _, s := range a.Secrets {
+			if !containsString(secrets, s) {
+				secrets = append(secrets, s)
+			}
+		}
+		ret.Secrets = secrets
+	}
+
+	// Attributes that come from the template are where the template
+	// has them.
+	for name, pos := range t.Positions {
+		if _, ok := ret.Positions[name]; !ok {
+			if ret.Positions == nil {
+				ret.Positions = make(map[string]Pos)
+			}
+			ret.Positions[name] = pos
+		}
+	}
+	return ret
+}
+
+// ApplyTemplates replaces each action that extends a template with the
+// result of ResolveTemplate, then clears Templates, for consumers that
+// only look at actions.  It fails, without changing c, if an action
+// extends a template that c doesn't have.
+func (c *Configuration) ApplyTemplates() error {
+	for _, a := range c.Actions {
+		if a.Extends != "" && c.GetTemplate(a.Extends) == nil {
+			return fmt.Errorf("action `%s' extends unknown template `%s'", a.Identifier, a.Extends)
+		}
+	}
+	for i, a := range c.Actions {
+		if a.Extends != "" {
+			c.Actions[i] = c.ResolveTemplate(a)
+		}
+	}
+	c.Templates = nil
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
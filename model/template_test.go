@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateConfiguration() *Configuration {
+	return &Configuration{
+		Templates: []*Action{{
+			Identifier:      "go",
+			Description:     "Go in Docker",
+			Uses:            &UsesDockerImage{Image: "golang", Repository: "golang"},
+			Args:            &ListCommand{Values: []string{"version"}},
+			Env:             map[string]string{"GOPATH": "/go", "GOOS": "linux"},
+			Secrets:         []string{"TOKEN"},
+			ContinueOnError: true,
+			Positions:       map[string]Pos{"uses": {Line: 2}, "args": {Line: 3}},
+		}},
+		Actions: []*Action{
+			{
+				Identifier: "test",
+				Extends:    "go",
+				Args:       &ListCommand{Values: []string{"test", "./..."}},
+				Env:        map[string]string{"GOOS": "darwin"},
+				Secrets:    []string{"OTHER", "TOKEN"},
+				Positions:  map[string]Pos{"args": {Line: 9}},
+			},
+			{Identifier: "plain", Uses: &UsesPath{Path: "plain"}},
+		},
+	}
+}
+
+func TestResolveTemplate(t *testing.T) {
+	c := templateConfiguration()
+	a := c.ResolveTemplate(c.Actions[0])
+	assert.Equal(t, &Action{
+		Identifier:      "test",
+		Uses:            &UsesDockerImage{Image: "golang", Repository: "golang"},
+		Args:            &ListCommand{Values: []string{"test", "./..."}},
+		Env:             map[string]string{"GOPATH": "/go", "GOOS": "darwin"},
+		Secrets:         []string{"TOKEN", "OTHER"},
+		ContinueOnError: true,
+		Positions:       map[string]Pos{"uses": {Line: 2}, "args": {Line: 9}},
+	}, a)
+
+	// The result is a copy.
+	a.Env["GOPATH"] = "/tmp"
+	assert.Equal(t, "/go", c.Templates[0].Env["GOPATH"])
+	assert.Equal(t, map[string]string{"GOOS": "darwin"}, c.Actions[0].Env)
+
+	assert.Equal(t, c.Actions[1], c.ResolveTemplate(c.Actions[1]))
+	unknown := &Action{Identifier: "x", Extends: "nope"}
+	assert.Equal(t, unknown, c.ResolveTemplate(unknown))
+}
+
+func TestApplyTemplates(t *testing.T) {
+	c := templateConfiguration()
+	c.Actions = append(c.Actions, &Action{Identifier: "bad", Extends: "nope"})
+	assert.EqualError(t, c.ApplyTemplates(), "action `bad' extends unknown template `nope'")
+	assert.Len(t, c.Templates, 1)
+	assert.Equal(t, "go", c.Actions[0].Extends)
+
+	c.Actions = c.Actions[:2]
+	require.NoError(t, c.ApplyTemplates())
+	assert.Nil(t, c.Templates)
+	assert.Equal(t, "", c.Actions[0].Extends)
+	assert.Equal(t, "docker://golang", c.Actions[0].Uses.String())
+}
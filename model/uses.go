@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 )
 
 type Uses interface {
@@ -9,9 +10,20 @@ type Uses interface {
 	isUses()
 }
 
-// UsesDockerImage represents `uses = "docker://<image>"`
+// UsesDockerImage represents `uses = "docker://<image>"`.  Image is the
+// reference as written, and the other fields are its parts, as parsed by
+// ParseDockerImage: for `myreg.io:5000/team/img:1.2@sha256:...', Host is
+// `myreg.io', Port is `5000', Repository is `team/img', Tag is `1.2',
+// and Digest is `sha256:...'.  Parts that are missing, or that couldn't
+// be parsed because Image is invalid, are blank.
 type UsesDockerImage struct {
 	Image string
+
+	Host       string
+	Port       string
+	Repository string
+	Tag        string
+	Digest     string
 }
 
 // UsesRepository represents `uses = "<owner>/<repo>[/<path>]@<ref>"`
@@ -55,3 +67,38 @@ func (u *UsesPath) String() string {
 func (u *UsesInvalid) String() string {
 	return u.Raw
 }
+
+// ParseUses converts the value of a `uses' attribute into a Uses.  Values
+// that are not a path, a Docker image, or owner/repo@ref become
+// UsesInvalid.
+func ParseUses(raw string) Uses {
+	if raw == "" {
+		return &UsesInvalid{}
+	}
+
+	if strings.HasPrefix(raw, "./") {
+		return &UsesPath{Path: strings.TrimPrefix(raw, "./")}
+	}
+
+	if strings.HasPrefix(raw, "docker://") {
+		image, _ := ParseDockerImage(strings.TrimPrefix(raw, "docker://"))
+		return image
+	}
+
+	// owner/repo[/path]@ref, with exactly one `@'
+	at := strings.IndexByte(raw, '@')
+	if at < 0 || strings.IndexByte(raw[at+1:], '@') >= 0 {
+		return &UsesInvalid{Raw: raw}
+	}
+	repo, ref := raw[:at], raw[at+1:]
+	slash := strings.IndexByte(repo, '/')
+	if slash < 0 {
+		return &UsesInvalid{Raw: raw}
+	}
+	usesRepo := &UsesRepository{Repository: repo, Ref: ref}
+	if path := strings.IndexByte(repo[slash+1:], '/'); path >= 0 {
+		usesRepo.Repository = repo[:slash+1+path]
+		usesRepo.Path = repo[slash+1+path+1:]
+	}
+	return usesRepo
+}
@@ -2,9 +2,11 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUsesStringer(t *testing.T) {
@@ -42,3 +44,68 @@ func TestUsesStringer(t *testing.T) {
 		assert.Equal(t, tc.expected, tc.uses.String())
 	}
 }
+
+func TestParseUses(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected Uses
+	}{
+		{"./", &UsesPath{}},
+		{"./a/b", &UsesPath{Path: "a/b"}},
+		{"docker://alpine:3.8", &UsesDockerImage{Image: "alpine:3.8", Repository: "alpine", Tag: "3.8"}},
+		{"docker://Alpine", &UsesDockerImage{Image: "Alpine"}},
+		{"owner/repo@v1", &UsesRepository{Repository: "owner/repo", Ref: "v1"}},
+		{"owner/repo/a/b@v1", &UsesRepository{Repository: "owner/repo", Path: "a/b", Ref: "v1"}},
+		{"", &UsesInvalid{}},
+		{"foo", &UsesInvalid{Raw: "foo"}},
+		{"foo@bar", &UsesInvalid{Raw: "foo@bar"}},
+		{"a/b@c@d", &UsesInvalid{Raw: "a/b@c@d"}},
+	}
+
+	for _, tc := range cases {
+		uses := ParseUses(tc.raw)
+		assert.Equal(t, tc.expected, uses, tc.raw)
+		if _, ok := uses.(*UsesInvalid); !ok {
+			assert.Equal(t, tc.raw, uses.String())
+		}
+	}
+}
+
+func TestParseDockerImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("0123456789abcdef", 4)
+	cases := []struct {
+		ref      string
+		expected UsesDockerImage
+	}{
+		{"alpine", UsesDockerImage{Repository: "alpine"}},
+		{"library/alpine:3.8", UsesDockerImage{Repository: "library/alpine", Tag: "3.8"}},
+		{"gcr.io/project/img", UsesDockerImage{Host: "gcr.io", Repository: "project/img"}},
+		{"localhost/img:latest", UsesDockerImage{Host: "localhost", Repository: "img", Tag: "latest"}},
+		{"myreg.io:5000/team/img:1.2@" + digest, UsesDockerImage{Host: "myreg.io", Port: "5000", Repository: "team/img", Tag: "1.2", Digest: digest}},
+		{"img@" + digest, UsesDockerImage{Repository: "img", Digest: digest}},
+		{"a.b_c__d-e---f", UsesDockerImage{Repository: "a.b_c__d-e---f"}},
+	}
+	for _, tc := range cases {
+		image, err := ParseDockerImage(tc.ref)
+		require.NoError(t, err, tc.ref)
+		tc.expected.Image = tc.ref
+		assert.Equal(t, &tc.expected, image, tc.ref)
+	}
+
+	errors := map[string]string{
+		"":                       "image reference is blank",
+		"Alpine":                 "invalid repository name `Alpine'",
+		"alpine:":                "invalid tag `'",
+		"alpine:-x":              "invalid tag `-x'",
+		"alpine@sha256:abc":      "invalid digest `sha256:abc'",
+		"my_reg.io/img":          "invalid registry `my_reg.io'",
+		"a//b":                   "invalid repository name `a//b'",
+		"a..b":                   "invalid repository name `a..b'",
+		strings.Repeat("a", 256): "repository name is longer than 255 characters",
+	}
+	for ref, msg := range errors {
+		image, err := ParseDockerImage(ref)
+		assert.EqualError(t, err, msg, ref)
+		assert.Equal(t, &UsesDockerImage{Image: ref}, image)
+	}
+}
@@ -0,0 +1,183 @@
+package v0
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// FromModel converts a Configuration from package model to v0.  It
+// returns an error if c uses anything v0 cannot represent: a version
+// other than 0, templates, `env' blocks outside actions, descriptions,
+// `extends', `if', `continue_on_error', matrices, or a workflow with
+// more than one event.  Heredocs become strings, which split into the
+// same arguments.  Positions and comments are dropped.
+func FromModel(c *model.Configuration) (*Configuration, error) {
+	switch {
+	case c.Version != 0:
+		return nil, fmt.Errorf("version %d is not supported in v0", c.Version)
+	case len(c.Templates) > 0:
+		return nil, fmt.Errorf("templates are not supported in v0")
+	case len(c.Env) > 0:
+		return nil, fmt.Errorf("file-level env is not supported in v0")
+	}
+
+	ret := &Configuration{
+		Actions:   make([]*Action, 0, len(c.Actions)),
+		Workflows: make([]*Workflow, 0, len(c.Workflows)),
+	}
+	for _, a := range c.Actions {
+		action, err := actionFromModel(a)
+		if err != nil {
+			return nil, err
+		}
+		ret.Actions = append(ret.Actions, action)
+	}
+	for _, w := range c.Workflows {
+		workflow, err := workflowFromModel(w)
+		if err != nil {
+			return nil, err
+		}
+		ret.Workflows = append(ret.Workflows, workflow)
+	}
+	return ret, nil
+}
+
+// ToModel converts c to a Configuration from package model.  The
+// conversion never loses information.
+func (c *Configuration) ToModel() *model.Configuration {
+	ret := &model.Configuration{
+		Actions:   make([]*model.Action, 0, len(c.Actions)),
+		Workflows: make([]*model.Workflow, 0, len(c.Workflows)),
+	}
+	for _, a := range c.Actions {
+		ret.Actions = append(ret.Actions, &model.Action{
+			Identifier: a.Identifier,
+			Uses:       usesToModel(a.Uses),
+			Runs:       commandToModel(a.Runs),
+			Args:       commandToModel(a.Args),
+			Needs:      a.Needs,
+			Env:        a.Env,
+			Secrets:    a.Secrets,
+		})
+	}
+	for _, w := range c.Workflows {
+		workflow := &model.Workflow{
+			Identifier: w.Identifier,
+			Resolves:   w.Resolves,
+		}
+		if w.On != "" {
+			workflow.On = model.ParseEvents(w.On)
+		}
+		ret.Workflows = append(ret.Workflows, workflow)
+	}
+	return ret
+}
+
+func actionFromModel(a *model.Action) (*Action, error) {
+	unsupported := func(name string) error {
+		return fmt.Errorf("`%s' in action `%s' is not supported in v0", name, a.Identifier)
+	}
+	switch {
+	case a.Description != "":
+		return nil, unsupported("description")
+	case a.Extends != "":
+		return nil, unsupported("extends")
+	case a.If != "":
+		return nil, unsupported("if")
+	case a.ContinueOnError:
+		return nil, unsupported("continue_on_error")
+	case len(a.Matrix) > 0:
+		return nil, unsupported("matrix")
+	}
+
+	return &Action{
+		Identifier: a.Identifier,
+		Uses:       usesFromModel(a.Uses),
+		Runs:       commandFromModel(a.Runs),
+		Args:       commandFromModel(a.Args),
+		Needs:      a.Needs,
+		Env:        a.Env,
+		Secrets:    a.Secrets,
+	}, nil
+}
+
+func workflowFromModel(w *model.Workflow) (*Workflow, error) {
+	unsupported := func(name string) error {
+		return fmt.Errorf("`%s' in workflow `%s' is not supported in v0", name, w.Identifier)
+	}
+	switch {
+	case w.Description != "":
+		return nil, unsupported("description")
+	case len(w.Env) > 0:
+		return nil, unsupported("env")
+	case len(w.On) > 1:
+		return nil, fmt.Errorf("more than one event in workflow `%s' is not supported in v0", w.Identifier)
+	}
+
+	ret := &Workflow{
+		Identifier: w.Identifier,
+		Resolves:   w.Resolves,
+	}
+	if len(w.On) > 0 {
+		ret.On = w.On[0].String()
+	}
+	return ret, nil
+}
+
+func usesFromModel(uses model.Uses) Uses {
+	switch u := uses.(type) {
+	case *model.UsesDockerImage:
+		return &UsesDockerImage{Image: u.Image}
+	case *model.UsesRepository:
+		return &UsesRepository{Repository: u.Repository, Path: u.Path, Ref: u.Ref}
+	case *model.UsesPath:
+		return &UsesPath{Path: u.Path}
+	case *model.UsesInvalid:
+		return &UsesInvalid{Raw: u.Raw}
+	default:
+		return nil
+	}
+}
+
+func usesToModel(uses Uses) model.Uses {
+	switch u := uses.(type) {
+	case *UsesDockerImage:
+		image, _ := model.ParseDockerImage(u.Image)
+		return image
+	case *UsesRepository:
+		return &model.UsesRepository{Repository: u.Repository, Path: u.Path, Ref: u.Ref}
+	case *UsesPath:
+		return &model.UsesPath{Path: u.Path}
+	case *UsesInvalid:
+		return &model.UsesInvalid{Raw: u.Raw}
+	default:
+		return nil
+	}
+}
+
+func commandFromModel(cmd model.Command) Command {
+	switch c := cmd.(type) {
+	case *model.StringCommand:
+		return &StringCommand{Value: c.Value}
+	case *model.ListCommand:
+		return &ListCommand{Values: c.Values}
+	case *model.HeredocCommand:
+		// Join continued lines, as HeredocCommand.Split does.
+		return &StringCommand{Value: strings.Replace(c.Value, "\\\n", "", -1)}
+	default:
+		return nil
+	}
+}
+
+func commandToModel(cmd Command) model.Command {
+	switch c := cmd.(type) {
+	case *StringCommand:
+		return &model.StringCommand{Value: c.Value}
+	case *ListCommand:
+		return &model.ListCommand{Values: c.Values}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package v0
+
+import (
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	c := &Configuration{
+		Actions: []*Action{
+			{Identifier: "a", Uses: &UsesDockerImage{Image: "alpine:3.9"}, Runs: &StringCommand{Value: "ls"}},
+			{Identifier: "b", Uses: &UsesRepository{Repository: "o/r", Path: "p", Ref: "v1"}, Needs: []string{"a"}},
+			{Identifier: "c", Uses: &UsesPath{Path: "x"}, Args: &ListCommand{Values: []string{"-v"}}, Env: map[string]string{"K": "V"}, Secrets: []string{"S"}},
+			{Identifier: "d", Uses: &UsesInvalid{Raw: "foo"}},
+			{Identifier: "e"},
+		},
+		Workflows: []*Workflow{
+			{Identifier: "w", On: "pull_request.opened", Resolves: []string{"b", "c"}},
+			{Identifier: "x"},
+		},
+	}
+
+	m := c.ToModel()
+	require.Len(t, m.Actions, 5)
+	assert.Equal(t, "3.9", m.Actions[0].Uses.(*model.UsesDockerImage).Tag)
+	assert.Equal(t, model.ParseEvents("pull_request.opened"), m.Workflows[0].On)
+	assert.Empty(t, m.Workflows[1].On)
+
+	back, err := FromModel(m)
+	require.NoError(t, err)
+	assert.Equal(t, c, back)
+}
+
+func TestFromModelHeredoc(t *testing.T) {
+	c, err := FromModel(&model.Configuration{Actions: []*model.Action{
+		{Identifier: "a", Runs: &model.HeredocCommand{Value: "make \\\ntest\nlint", Marker: "EOF"}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, &StringCommand{Value: "make test\nlint"}, c.Actions[0].Runs)
+	assert.Equal(t, []string{"make", "test", "lint"}, c.Actions[0].Runs.Split())
+}
+
+func TestFromModelUnsupported(t *testing.T) {
+	for _, test := range []struct {
+		c   *model.Configuration
+		err string
+	}{
+		{&model.Configuration{Version: 1}, "version 1 is not supported in v0"},
+		{&model.Configuration{Templates: []*model.Action{{Identifier: "t"}}}, "templates are not supported in v0"},
+		{&model.Configuration{Env: map[string]string{"A": "b"}}, "file-level env is not supported in v0"},
+		{
+			&model.Configuration{Actions: []*model.Action{{Identifier: "a", If: "true"}}},
+			"`if' in action `a' is not supported in v0",
+		},
+		{
+			&model.Configuration{Actions: []*model.Action{{Identifier: "a", Matrix: map[string][]string{"k": {"v"}}}}},
+			"`matrix' in action `a' is not supported in v0",
+		},
+		{
+			&model.Configuration{Workflows: []*model.Workflow{{Identifier: "w", Description: "d"}}},
+			"`description' in workflow `w' is not supported in v0",
+		},
+		{
+			&model.Configuration{Workflows: []*model.Workflow{{
+				Identifier: "w",
+				On:         []model.Event{{Type: "push"}, {Type: "release"}},
+			}}},
+			"more than one event in workflow `w' is not supported in v0",
+		},
+	} {
+		_, err := FromModel(test.c)
+		assert.EqualError(t, err, test.err)
+	}
+}
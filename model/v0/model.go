@@ -0,0 +1,142 @@
+// Package v0 is the data model for `version = 0` workflow files, as it
+// was before package model started to grow.  Its types are its own, and
+// never change, so importers that want to stay on this version of the
+// model while package model evolves can import v0 instead, and convert
+// with FromModel and ToModel.
+package v0
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Configuration is a parsed main.workflow file
+type Configuration struct {
+	Actions   []*Action
+	Workflows []*Workflow
+}
+
+// Action represents a single "action" stanza in a .workflow file.
+type Action struct {
+	Identifier string
+	Uses       Uses
+	Runs, Args Command
+	Needs      []string
+	Env        map[string]string
+	Secrets    []string
+}
+
+// Workflow represents a single "workflow" stanza in a .workflow file.
+// On is the event that triggers it, as written, like "push" or
+// "pull_request.opened".
+type Workflow struct {
+	Identifier string
+	On         string
+	Resolves   []string
+}
+
+// GetAction returns the action with the given identifier, or nil.
+func (c *Configuration) GetAction(id string) *Action {
+	for _, action := range c.Actions {
+		if action.Identifier == id {
+			return action
+		}
+	}
+	return nil
+}
+
+// GetWorkflow returns the workflow with the given identifier, or nil.
+func (c *Configuration) GetWorkflow(id string) *Workflow {
+	for _, workflow := range c.Workflows {
+		if workflow.Identifier == id {
+			return workflow
+		}
+	}
+	return nil
+}
+
+// Uses represents the "uses" attribute of an action.
+type Uses interface {
+	fmt.Stringer
+	isUses()
+}
+
+// UsesDockerImage represents `uses = "docker://<image>"`
+type UsesDockerImage struct {
+	Image string
+}
+
+// UsesRepository represents `uses = "<owner>/<repo>[/<path>]@<ref>"`
+type UsesRepository struct {
+	Repository string
+	Path       string
+	Ref        string
+}
+
+// UsesPath represents `uses = "./<path>"`
+type UsesPath struct {
+	Path string
+}
+
+// UsesInvalid represents any invalid `uses = "<raw>"` value
+type UsesInvalid struct {
+	Raw string
+}
+
+func (u *UsesDockerImage) isUses() {}
+func (u *UsesRepository) isUses()  {}
+func (u *UsesPath) isUses()        {}
+func (u *UsesInvalid) isUses()     {}
+
+func (u *UsesDockerImage) String() string {
+	return fmt.Sprintf("docker://%s", u.Image)
+}
+
+func (u *UsesRepository) String() string {
+	if u.Path == "" {
+		return fmt.Sprintf("%s@%s", u.Repository, u.Ref)
+	}
+
+	return fmt.Sprintf("%s/%s@%s", u.Repository, u.Path, u.Ref)
+}
+
+func (u *UsesPath) String() string {
+	return fmt.Sprintf("./%s", u.Path)
+}
+
+func (u *UsesInvalid) String() string {
+	return u.Raw
+}
+
+// Command represents the optional "runs" and "args" attributes.
+// Each one takes one of two forms:
+//   - runs="entrypoint arg1 arg2 ..."
+//   - runs=[ "entrypoint", "arg1", "arg2", ... ]
+type Command interface {
+	isCommand()
+	Split() []string
+}
+
+// StringCommand represents the string based form of the "runs" or "args"
+// attribute.
+//   - runs="entrypoint arg1 arg2 ..."
+type StringCommand struct {
+	Value string
+}
+
+// ListCommand represents the list based form of the "runs" or "args" attribute.
+//   - runs=[ "entrypoint", "arg1", "arg2", ... ]
+type ListCommand struct {
+	Values []string
+}
+
+func (s *StringCommand) isCommand() {}
+func (l *ListCommand) isCommand()   {}
+
+func (s *StringCommand) Split() []string {
+	return strings.Fields(s.Value)
+}
+
+func (l *ListCommand) Split() []string {
+	return l.Values
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"fmt"
+
+	"github.com/actions/workflow-parser/model"
+	v0 "github.com/actions/workflow-parser/model/v0"
+)
+
+// FromModel converts a Configuration from package model to v1.  The
+// conversion never loses information, other than positions and
+// comments.
+func FromModel(c *model.Configuration) *Configuration {
+	ret := &Configuration{
+		Version:   c.Version,
+		Actions:   make([]*Action, 0, len(c.Actions)),
+		Workflows: make([]*Workflow, 0, len(c.Workflows)),
+		Env:       c.Env,
+	}
+
+	for _, a := range c.Actions {
+		ret.Actions = append(ret.Actions, actionFromModel(a))
+	}
+	for _, t := range c.Templates {
+		ret.Templates = append(ret.Templates, actionFromModel(t))
+	}
+
+	for _, w := range c.Workflows {
+		workflow := &Workflow{
+			Identifier:  w.Identifier,
+			Description: w.Description,
+			Resolves:    w.Resolves,
+			Env:         w.Env,
+		}
+		for _, event := range w.On {
+			workflow.On = append(workflow.On, Event{Raw: event.Raw, Type: event.Type, Filter: event.Filter, Schedule: event.Schedule})
+		}
+		ret.Workflows = append(ret.Workflows, workflow)
+	}
+
+	return ret
+}
+
+// ToModel converts a v1 Configuration to package model.  It returns an
+// error if the Configuration uses anything package model cannot
+// represent, which is only includes.
+func (c *Configuration) ToModel() (*model.Configuration, error) {
+	if len(c.Includes) > 0 {
+		return nil, fmt.Errorf("includes are not supported in package model")
+	}
+
+	ret := &model.Configuration{
+		Version:   c.Version,
+		Actions:   make([]*model.Action, 0, len(c.Actions)),
+		Workflows: make([]*model.Workflow, 0, len(c.Workflows)),
+		Env:       c.Env,
+	}
+
+	for _, a := range c.Actions {
+		ret.Actions = append(ret.Actions, a.toModel())
+	}
+	for _, t := range c.Templates {
+		ret.Templates = append(ret.Templates, t.toModel())
+	}
+
+	for _, w := range c.Workflows {
+		workflow := &model.Workflow{
+			Identifier:  w.Identifier,
+			Description: w.Description,
+			Resolves:    w.Resolves,
+			Env:         w.Env,
+		}
+		for _, event := range w.On {
+			workflow.On = append(workflow.On, eventToModel(event))
+		}
+		ret.Workflows = append(ret.Workflows, workflow)
+	}
+
+	return ret, nil
+}
+
+// FromV0 converts a v0 Configuration to v1.  The conversion never loses
+// information.
+func FromV0(c *v0.Configuration) *Configuration {
+	return FromModel(c.ToModel())
+}
+
+// ToV0 converts a v1 Configuration to v0.  It returns an error if the
+// Configuration uses anything v0 cannot represent, like includes or any
+// of the attributes that v0.FromModel rejects.
+func (c *Configuration) ToV0() (*v0.Configuration, error) {
+	if len(c.Includes) > 0 {
+		return nil, fmt.Errorf("includes are not supported in v0")
+	}
+	m, err := c.ToModel()
+	if err != nil {
+		return nil, err
+	}
+	return v0.FromModel(m)
+}
+
+func actionFromModel(a *model.Action) *Action {
+	return &Action{
+		Identifier:      a.Identifier,
+		Description:     a.Description,
+		Extends:         a.Extends,
+		Uses:            usesFromModel(a.Uses),
+		Runs:            a.Runs,
+		Args:            a.Args,
+		Needs:           a.Needs,
+		Env:             a.Env,
+		Matrix:          a.Matrix,
+		Secrets:         a.Secrets,
+		If:              a.If,
+		ContinueOnError: a.ContinueOnError,
+	}
+}
+
+func (a *Action) toModel() *model.Action {
+	return &model.Action{
+		Identifier:      a.Identifier,
+		Description:     a.Description,
+		Extends:         a.Extends,
+		Uses:            a.Uses.toModel(),
+		Runs:            a.Runs,
+		Args:            a.Args,
+		Needs:           a.Needs,
+		Env:             a.Env,
+		Matrix:          a.Matrix,
+		Secrets:         a.Secrets,
+		If:              a.If,
+		ContinueOnError: a.ContinueOnError,
+	}
+}
+
+func eventToModel(e Event) model.Event {
+	return model.Event{Raw: e.Raw, Type: e.Type, Filter: e.Filter, Schedule: e.Schedule}
+}
+
+func usesFromModel(uses model.Uses) Uses {
+	switch u := uses.(type) {
+	case *model.UsesDockerImage:
+		return Uses{Kind: UsesDockerImage, Raw: u.String(), Image: u.Image}
+	case *model.UsesRepository:
+		return Uses{Kind: UsesRepository, Raw: u.String(), Repository: u.Repository, Path: u.Path, Ref: u.Ref}
+	case *model.UsesPath:
+		return Uses{Kind: UsesPath, Raw: u.String(), Path: u.Path}
+	case *model.UsesInvalid:
+		return Uses{Kind: UsesInvalid, Raw: u.Raw}
+	default:
+		return Uses{}
+	}
+}
+
+// toModel converts u to its package model equivalent.  The zero Uses,
+// which represents a missing `uses' attribute, becomes nil.
+func (u Uses) toModel() model.Uses {
+	switch u.Kind {
+	case UsesDockerImage:
+		image, _ := model.ParseDockerImage(u.Image)
+		return image
+	case UsesRepository:
+		return &model.UsesRepository{Repository: u.Repository, Path: u.Path, Ref: u.Ref}
+	case UsesPath:
+		return &model.UsesPath{Path: u.Path}
+	default:
+		if u == (Uses{}) {
+			return nil
+		}
+		return &model.UsesInvalid{Raw: u.Raw}
+	}
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	v0 "github.com/actions/workflow-parser/model/v0"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	c := &v0.Configuration{
+		Actions: []*v0.Action{
+			{Identifier: "a", Uses: &v0.UsesDockerImage{Image: "alpine"}, Runs: &v0.StringCommand{Value: "ls"}},
+			{Identifier: "b", Uses: &v0.UsesRepository{Repository: "o/r", Path: "p", Ref: "v1"}, Needs: []string{"a"}},
+			{Identifier: "c", Uses: &v0.UsesPath{Path: "x"}, Env: map[string]string{"K": "V"}, Secrets: []string{"S"}},
+			{Identifier: "d", Uses: &v0.UsesInvalid{Raw: "foo"}},
+			{Identifier: "e"},
+		},
+		Workflows: []*v0.Workflow{
+			{Identifier: "w", On: "push", Resolves: []string{"b", "c"}},
+			{Identifier: "x"},
+		},
+	}
+
+	c1 := FromV0(c)
+	require.Len(t, c1.Actions, 5)
+	assert.Equal(t, Uses{Kind: UsesDockerImage, Raw: "docker://alpine", Image: "alpine"}, c1.Actions[0].Uses)
+	assert.Equal(t, Uses{Kind: UsesRepository, Raw: "o/r/p@v1", Repository: "o/r", Path: "p", Ref: "v1"}, c1.Actions[1].Uses)
+	assert.Equal(t, "./x", c1.Actions[2].Uses.String())
+	assert.Equal(t, UsesInvalid, c1.Actions[3].Uses.Kind)
+	assert.Equal(t, []Event{{Raw: "push", Type: "push"}}, c1.Workflows[0].On)
+	assert.Empty(t, c1.Workflows[1].On)
+
+	c0, err := c1.ToV0()
+	require.NoError(t, err)
+	assert.Equal(t, c, c0)
+}
+
+func TestToV0Unsupported(t *testing.T) {
+	_, err := (&Configuration{Includes: []string{"a.workflow"}}).ToV0()
+	assert.EqualError(t, err, "includes are not supported in v0")
+
+	_, err = (&Configuration{Actions: []*Action{{Identifier: "a", If: "true"}}}).ToV0()
+	assert.EqualError(t, err, "`if' in action `a' is not supported in v0")
+
+	_, err = (&Configuration{Includes: []string{"a.workflow"}}).ToModel()
+	assert.EqualError(t, err, "includes are not supported in package model")
+}
+
+func TestEventFilters(t *testing.T) {
+	c1 := &Configuration{Workflows: []*Workflow{
+		{Identifier: "w", On: []Event{{Type: "pull_request", Filter: "opened"}}},
+		{Identifier: "x", On: []Event{{Type: "push"}, {Type: "issues", Filter: "closed"}}},
+	}}
+	m, err := c1.ToModel()
+	require.NoError(t, err)
+	assert.Equal(t, []model.Event{{Type: "pull_request", Filter: "opened"}}, m.Workflows[0].On)
+	assert.Equal(t, []model.Event{{Type: "push"}, {Type: "issues", Filter: "closed"}}, m.Workflows[1].On)
+	assert.Equal(t, c1.Workflows, FromModel(m).Workflows)
+
+	c0, err := (&Configuration{Workflows: c1.Workflows[:1]}).ToV0()
+	require.NoError(t, err)
+	assert.Equal(t, "pull_request.opened", c0.Workflows[0].On)
+}
+
+func TestFromModel(t *testing.T) {
+	m := &model.Configuration{
+		Version: 1,
+		Env:     map[string]string{"A": "b"},
+		Actions: []*model.Action{
+			{Identifier: "a", If: "true", Matrix: map[string][]string{"k": {"v"}}, Extends: "t"},
+		},
+		Templates: []*model.Action{{Identifier: "t", Uses: &model.UsesPath{Path: "x"}}},
+	}
+	c1 := FromModel(m)
+	assert.Equal(t, 1, c1.Version)
+	assert.Equal(t, "true", c1.Actions[0].If)
+	require.Len(t, c1.Templates, 1)
+
+	back, err := c1.ToModel()
+	require.NoError(t, err)
+	assert.Equal(t, m.Actions, back.Actions)
+	assert.Equal(t, m.Templates, back.Templates)
+	assert.Equal(t, m.Env, back.Env)
+}
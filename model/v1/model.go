@@ -0,0 +1,99 @@
+// Package v1 is the next version of the workflow data model.  It is not
+// produced by the parser yet; it exists so that tools can start working
+// with the new shape of the model, converting from and to package model,
+// or the frozen v0, as needed.
+//
+// Compared to package model:
+//   - Uses is a single struct with a Kind, rather than an interface
+//   - a configuration can include other workflow files
+package v1
+
+import (
+	"github.com/actions/workflow-parser/model"
+)
+
+// Configuration is a parsed .workflow file.
+type Configuration struct {
+	// Version is the version of the language the file is written in.
+	Version int
+
+	Actions   []*Action
+	Workflows []*Workflow
+
+	// Includes lists the other .workflow files whose actions and
+	// workflows are part of this configuration.
+	Includes []string
+
+	// Env holds the variables of the file's top-level `env' block.
+	Env map[string]string
+
+	// Templates holds the file's `template' blocks.
+	Templates []*Action
+}
+
+// Action represents a single "action" stanza in a .workflow file.
+type Action struct {
+	Identifier      string
+	Description     string
+	Extends         string
+	Uses            Uses
+	Runs, Args      model.Command
+	Needs           []string
+	Env             map[string]string
+	Matrix          map[string][]string
+	Secrets         []string
+	If              string
+	ContinueOnError bool
+}
+
+// Workflow represents a single "workflow" stanza in a .workflow file.
+type Workflow struct {
+	Identifier  string
+	Description string
+	On          []Event
+	Resolves    []string
+	Env         map[string]string
+}
+
+// Event is a single event a workflow subscribes to, e.g., "push",
+// "pull_request.opened", or "schedule(0 * * * *)".  Raw is the value as
+// written, as in model.Event.
+type Event struct {
+	Raw      string
+	Type     string
+	Filter   string
+	Schedule *model.Schedule
+}
+
+// UsesKind identifies the form of a `uses' attribute.
+type UsesKind int
+
+const (
+	// UsesInvalid is any `uses' value that doesn't fit another form.
+	UsesInvalid UsesKind = iota
+
+	// UsesDockerImage is `uses = "docker://<image>"`.
+	UsesDockerImage
+
+	// UsesRepository is `uses = "<owner>/<repo>[/<path>]@<ref>"`.
+	UsesRepository
+
+	// UsesPath is `uses = "./<path>"`.
+	UsesPath
+)
+
+// Uses represents the "uses" attribute of an action.  Which fields are
+// set depends on Kind; Raw is always set to the value as written.
+type Uses struct {
+	Kind       UsesKind
+	Raw        string
+	Image      string
+	Repository string
+	Path       string
+	Ref        string
+}
+
+// String returns the value of the `uses' attribute, as written.
+func (u Uses) String() string {
+	return u.Raw
+}
@@ -0,0 +1,66 @@
+package model
+
+// LatestVersion is the newest version of the .workflow language, the
+// highest a file's `version' statement may declare.
+const LatestVersion = 1
+
+// Feature is an action attribute that only later versions of the
+// language have.
+type Feature struct {
+	// Attribute is the name of the attribute, like "matrix".
+	Attribute string
+
+	// Version is the first version of the language that has it.
+	Version int
+}
+
+// features lists the attributes that need more than version 0, in the
+// order attributes are printed, along with how to tell whether an action
+// uses each.
+var features = []struct {
+	Feature
+	used func(a *Action) bool
+}{
+	{Feature{"if", 1}, func(a *Action) bool { return a.If != "" }},
+	{Feature{"matrix", 1}, func(a *Action) bool { return a.Matrix != nil }},
+}
+
+// Features returns the features that a uses, in the order attributes are
+// printed.
+func (a *Action) Features() []Feature {
+	var ret []Feature
+	for _, f := range features {
+		if f.used(a) {
+			ret = append(ret, f.Feature)
+		}
+	}
+	return ret
+}
+
+// FeatureVersion returns the first version of the language that has the
+// action attribute, which is 0 for attributes every version has.
+func FeatureVersion(attribute string) int {
+	for _, f := range features {
+		if f.Attribute == attribute {
+			return f.Version
+		}
+	}
+	return 0
+}
+
+// RequiredVersion returns the lowest version of the language that has
+// every feature the actions and templates of c use, which is 0 if they
+// use none.
+func (c *Configuration) RequiredVersion() int {
+	ret := 0
+	for _, list := range [][]*Action{c.Actions, c.Templates} {
+		for _, a := range list {
+			for _, f := range a.Features() {
+				if f.Version > ret {
+					ret = f.Version
+				}
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredVersion(t *testing.T) {
+	c := &Configuration{Actions: []*Action{{Identifier: "a"}}}
+	assert.Equal(t, 0, c.RequiredVersion())
+	assert.Nil(t, c.Actions[0].Features())
+
+	c.Templates = []*Action{{Identifier: "t", If: "success()"}}
+	c.Actions[0].Matrix = map[string][]string{"go": {"1.11"}}
+	assert.Equal(t, 1, c.RequiredVersion())
+	assert.Equal(t, []Feature{{Attribute: "matrix", Version: 1}}, c.Actions[0].Features())
+}
+
+func TestFeatureVersion(t *testing.T) {
+	assert.Equal(t, 1, FeatureVersion("matrix"))
+	assert.Equal(t, 0, FeatureVersion("uses"))
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// Baseline is a record of known diagnostics, so that a repository can
+// adopt stricter checks without fixing every existing problem first.
+// Parsing WithBaseline drops the diagnostics it records and reports only
+// new ones.  Diagnostics are matched by file, code, and message, not by
+// position, so that they survive unrelated edits that move them; a
+// baseline that records the same diagnostic twice absorbs two copies of
+// it.
+//
+// A Baseline is stored as JSON:
+//
+//	{
+//	  "diagnostics": [
+//	    {"file": "main.workflow", "code": "W_UNKNOWN_ATTRIBUTE",
+//	     "message": "Unknown action attribute `bananas'"}
+//	  ]
+//	}
+type Baseline struct {
+	Diagnostics []BaselineEntry `json:"diagnostics"`
+}
+
+// BaselineEntry is a diagnostic recorded in a Baseline.
+type BaselineEntry struct {
+	File    string `json:"file,omitempty"`
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewBaseline records errs, e.g., the diagnostics returned by
+// ParseWithDiagnostics or the Errors of a *Error.
+func NewBaseline(errs ErrorList) *Baseline {
+	ret := &Baseline{Diagnostics: make([]BaselineEntry, 0, len(errs))}
+	for _, e := range errs {
+		ret.Diagnostics = append(ret.Diagnostics, BaselineEntry{File: e.Pos.File, Code: e.Code, Message: e.message})
+	}
+	return ret
+}
+
+// ReadBaseline decodes a Baseline written by Write.
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Write encodes b as indented JSON.
+func (b *Baseline) Write(w io.Writer) error {
+	out, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+// filter returns the errors that b doesn't record.
+func (b *Baseline) filter(errs ErrorList) ErrorList {
+	known := make(map[BaselineEntry]int, len(b.Diagnostics))
+	for _, entry := range b.Diagnostics {
+		known[entry]++
+	}
+
+	ret := errs[:0]
+	for _, e := range errs {
+		entry := BaselineEntry{File: e.Pos.File, Code: e.Code, Message: e.message}
+		if known[entry] > 0 {
+			known[entry]--
+			continue
+		}
+		ret = append(ret, e)
+	}
+	return ret
+}
+
+// WithBaseline reads the Baseline in the file at path and drops the
+// diagnostics it records.  If the file can't be read, the parser reports
+// that as an error instead.  Syntax errors, which stop the parser before
+// it gets that far, are never dropped.
+func WithBaseline(path string) OptionFunc {
+	b, err := readBaselineFile(path)
+	if err != nil {
+		return func(ps *Parser) {
+			ps.baselineErr = newError(ErrorPos{File: path}, CodeBaselineInvalid, "Cannot read baseline: %s", err)
+		}
+	}
+	return WithBaselineData(b)
+}
+
+// WithBaselineData is like WithBaseline, but takes a Baseline already in
+// memory.
+func WithBaselineData(b *Baseline) OptionFunc {
+	return func(ps *Parser) {
+		ps.baseline = b
+	}
+}
+
+func readBaselineFile(path string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBaseline(f)
+}
+
+// applyBaseline drops the errors recorded in the baseline, if any.
+func (p *Parser) applyBaseline() {
+	if p.baselineErr != nil {
+		p.appendError(p.baselineErr)
+	}
+	if p.baseline != nil {
+		p.errors = p.baseline.filter(p.errors)
+	}
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	before := `action "a" {
+  uses = "./a"
+  bananas = "yes"
+}
+action "b" {
+  uses = "./b"
+  bananas = "yes"
+}
+`
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(before))
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+
+	var buf bytes.Buffer
+	require.NoError(t, NewBaseline(errs).Write(&buf))
+	assert.Equal(t, `{
+  "diagnostics": [
+    {
+      "code": "W_UNKNOWN_ATTRIBUTE",
+      "message": "Unknown action attribute `+"`bananas'"+`"
+    },
+    {
+      "code": "W_UNKNOWN_ATTRIBUTE",
+      "message": "Unknown action attribute `+"`bananas'"+`"
+    }
+  ]
+}
+`, buf.String())
+
+	dir := writeFiles(t, map[string]string{"baseline.json": buf.String()})
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "baseline.json")
+
+	// The known problems have moved, and there is a new one.
+	after := "\n\n" + before + `action "c" {
+  uses = "./c"
+  bananas = "yes"
+  apples = "no"
+}
+`
+	_, err = Parse(strings.NewReader(before), WithBaseline(path))
+	assert.NoError(t, err)
+
+	_, errs, err = ParseWithDiagnostics(strings.NewReader(after), WithBaseline(path))
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "Line 13: Unknown action attribute `bananas' [W_UNKNOWN_ATTRIBUTE]", errs[0].Error())
+	assert.Equal(t, "Line 14: Unknown action attribute `apples' [W_UNKNOWN_ATTRIBUTE]", errs[1].Error())
+}
+
+func TestBaselineMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parser")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(`action "a" { uses = "./a" }`), WithBaseline(filepath.Join(dir, "missing.json")))
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, CodeBaselineInvalid, errs[0].Code)
+	assert.Contains(t, errs[0].Message(), "Cannot read baseline")
+}
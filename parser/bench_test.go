@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchmarkWorkflow builds a valid .workflow file with n actions chained
+// together by `needs', resolved by a single workflow.
+func benchmarkWorkflow(n int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "workflow \"ci\" {\n  on = \"push\"\n  resolves = [\"action%d\"]\n}\n", n-1)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "action \"action%d\" {\n", i)
+		sb.WriteString("  uses = \"actions/bin/sh@master\"\n")
+		if i > 0 {
+			fmt.Fprintf(&sb, "  needs = [\"action%d\"]\n", i-1)
+		}
+		sb.WriteString("  runs = \"make test\"\n")
+		sb.WriteString("  env = {\n    GOOS = \"linux\"\n    GOARCH = \"amd64\"\n  }\n")
+		sb.WriteString("  secrets = [\"GITHUB_TOKEN\", \"DEPLOY_KEY\"]\n")
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+func benchmarkParse(b *testing.B, n int) {
+	src := benchmarkWorkflow(n)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(strings.NewReader(src)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse10(b *testing.B)  { benchmarkParse(b, 10) }
+func BenchmarkParse100(b *testing.B) { benchmarkParse(b, 100) }
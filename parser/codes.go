@@ -0,0 +1,253 @@
+package parser
+
+// Code is a stable, machine-readable identifier for the rule that
+// produced a ParseError.  Codes for problems reported as errors start
+// with `E_', and codes for problems reported as warnings start with
+// `W_'.  Codes are never renamed or reused, so CI systems can filter and
+// suppress errors by code.
+type Code string
+
+// Error returns c itself.  Codes are errors only so that they can be the
+// target of errors.Is; see ParseError.Is.
+func (c Code) Error() string {
+	return string(c)
+}
+
+// Codes for problems with the syntax or structure of a file.
+const (
+	// CodeInvalidUTF8 reports a file that is not well-formed UTF-8.
+	CodeInvalidUTF8 Code = "E_INVALID_UTF8"
+
+	// CodeFileTooLarge reports a file larger than the limit set by
+	// WithMaxFileSize.
+	CodeFileTooLarge Code = "E_FILE_TOO_LARGE"
+
+	// CodeTooManyBlocks reports more actions and workflows than the limit
+	// set by WithMaxBlocks.
+	CodeTooManyBlocks Code = "E_TOO_MANY_BLOCKS"
+
+	// CodeSyntax reports a file that is not valid HCL.
+	CodeSyntax Code = "E_SYNTAX"
+
+	// CodeInternal reports a bug in the parser.
+	CodeInternal Code = "E_INTERNAL"
+
+	// CodeInvalidDeclaration reports a toplevel declaration or an
+	// attribute that doesn't have the form the file format requires.
+	CodeInvalidDeclaration Code = "E_INVALID_DECLARATION"
+
+	// CodeVersionNotFirst reports a `version' declaration that is not the
+	// first declaration in the file.
+	CodeVersionNotFirst Code = "E_VERSION_NOT_FIRST"
+
+	// CodeUnsupportedVersion reports a `version' this parser doesn't
+	// support.
+	CodeUnsupportedVersion Code = "E_UNSUPPORTED_VERSION"
+
+	// CodeRequiresVersion reports an attribute that the version the file
+	// declares doesn't have yet, like `matrix' in a file without
+	// `version = 1'.
+	CodeRequiresVersion Code = "E_REQUIRES_VERSION"
+
+	// CodeInvalidIdentifier reports an action or workflow identifier that
+	// is not a quoted string.
+	CodeInvalidIdentifier Code = "E_INVALID_IDENTIFIER"
+
+	// CodeIdentifierRedefined reports two actions or workflows with the
+	// same identifier.
+	CodeIdentifierRedefined Code = "E_IDENTIFIER_REDEFINED"
+
+	// CodeIdentifierCase reports two actions or workflows whose
+	// identifiers differ only in case.  See WithCaseCollisionWarnings.
+	CodeIdentifierCase Code = "W_IDENTIFIER_CASE"
+
+	// CodeIdentifierTooLong reports an action or workflow identifier
+	// longer than the limit set by WithMaxIdentifierLength.
+	CodeIdentifierTooLong Code = "E_IDENTIFIER_TOO_LONG"
+
+	// CodeIdentifierPattern reports an action or workflow identifier
+	// that doesn't match the pattern set by WithIdentifierPattern.
+	CodeIdentifierPattern Code = "E_IDENTIFIER_PATTERN"
+
+	// CodeTypeMismatch reports an attribute with the wrong type of value,
+	// e.g., a number where a string is expected.
+	CodeTypeMismatch Code = "E_TYPE_MISMATCH"
+
+	// CodeBlankValue reports an attribute whose value is an empty string.
+	CodeBlankValue Code = "E_BLANK_VALUE"
+
+	// CodeUnknownAttribute reports an attribute that actions or workflows
+	// don't have.
+	CodeUnknownAttribute Code = "W_UNKNOWN_ATTRIBUTE"
+
+	// CodeAttributeRedefined reports an attribute set more than once in
+	// the same block.
+	CodeAttributeRedefined Code = "W_ATTRIBUTE_REDEFINED"
+
+	// CodeTooManyErrors replaces the diagnostics past the limit set by
+	// WithMaxErrors.  Its severity is that of the most severe of them.
+	CodeTooManyErrors Code = "E_TOO_MANY_ERRORS"
+
+	// CodeBaselineInvalid reports a baseline file that can't be read.
+	// See WithBaseline.
+	CodeBaselineInvalid Code = "E_BASELINE_INVALID"
+)
+
+// Codes for problems with actions.
+const (
+	// CodeUsesMissing reports an action without a `uses' attribute.
+	CodeUsesMissing Code = "E_USES_MISSING"
+
+	// CodeUsesInvalid reports a `uses' attribute that is not a path, a
+	// Docker image, or a repository.
+	CodeUsesInvalid Code = "E_USES_INVALID"
+
+	// CodeImageInvalid reports a `uses' attribute naming a Docker image
+	// with a malformed reference.
+	CodeImageInvalid Code = "E_IMAGE_INVALID"
+
+	// CodeUnpinnedRef reports an action that uses another repository at
+	// a branch or other ref that can move.  See WithRequirePinnedRefs.
+	CodeUnpinnedRef Code = "W_UNPINNED_REF"
+
+	// CodeInconsistentRef reports an action that uses a repository at a
+	// different ref than an earlier action does.  See WithConsistentRefs.
+	CodeInconsistentRef Code = "W_INCONSISTENT_REF"
+
+	// CodeUsesUnresolved reports a `uses' attribute naming a repository,
+	// path, or image that the UsesResolver could not find.  See
+	// WithUsesResolver.
+	CodeUsesUnresolved Code = "E_USES_UNRESOLVED"
+
+	// CodeUsesDenied reports a `uses' attribute that the UsesPolicy does
+	// not permit.  See WithUsesPolicy.
+	CodeUsesDenied Code = "E_USES_DENIED"
+
+	// CodeUnknownNeeds reports a `needs' attribute naming an action that
+	// doesn't exist.
+	CodeUnknownNeeds Code = "E_UNKNOWN_NEEDS"
+
+	// CodeCircularDependency reports actions that need each other,
+	// directly or indirectly.
+	CodeCircularDependency Code = "E_CIRCULAR_DEPENDENCY"
+
+	// CodeTooManySecrets reports a file with more unique secrets than
+	// actions may use.
+	CodeTooManySecrets Code = "E_TOO_MANY_SECRETS"
+
+	// CodeTooManyActions reports a file with more actions than the limit
+	// set by WithMaxActions.
+	CodeTooManyActions Code = "E_TOO_MANY_ACTIONS"
+
+	// CodeExpressionSyntax reports a malformed or unterminated variable
+	// reference, like `${HOME', or expression, like `${{ github. }}'.
+	// See CheckExpressions.
+	CodeExpressionSyntax Code = "E_EXPRESSION_SYNTAX"
+
+	// CodeUnknownContext reports an expression that starts with an
+	// object other than the known contexts, like `github' and `secrets'.
+	CodeUnknownContext Code = "W_UNKNOWN_CONTEXT"
+
+	// CodeUnknownFunction reports an expression that calls a function
+	// other than the known ones, like `contains' and `success'.
+	CodeUnknownFunction Code = "W_UNKNOWN_FUNCTION"
+
+	// CodeDescriptionTooLong reports a description longer than
+	// WithMaxDescriptionLength allows.
+	CodeDescriptionTooLong Code = "E_DESCRIPTION_TOO_LONG"
+
+	// CodeInvalidMatrix reports a `matrix' attribute that cannot be
+	// expanded, like one with a dimension that has no values.
+	CodeInvalidMatrix Code = "E_INVALID_MATRIX"
+
+	// CodeMatrixTooLarge reports a `matrix' attribute with more than 256
+	// combinations of values.
+	CodeMatrixTooLarge Code = "E_MATRIX_TOO_LARGE"
+
+	// CodeUnknownTemplate reports an action that extends a template that
+	// doesn't exist.
+	CodeUnknownTemplate Code = "E_UNKNOWN_TEMPLATE"
+
+	// CodeInvalidTemplate reports a template with an attribute templates
+	// can't have, like `needs'.
+	CodeInvalidTemplate Code = "E_INVALID_TEMPLATE"
+
+	// CodeIncludeFailed reports an `include' pattern that matches no
+	// files, or names a file that can't be read.
+	CodeIncludeFailed Code = "E_INCLUDE_FAILED"
+
+	// CodeTooManyActionSecrets reports an action with more unique secrets
+	// than WithMaxSecretsPerAction allows.
+	CodeTooManyActionSecrets Code = "E_TOO_MANY_ACTION_SECRETS"
+
+	// CodeSecretEnvConflict reports a secret with the same name as an
+	// environment variable of the same action.
+	CodeSecretEnvConflict Code = "E_SECRET_ENV_CONFLICT"
+
+	// CodeSecretRedefined reports a secret listed twice in one action.
+	CodeSecretRedefined Code = "W_SECRET_REDEFINED"
+
+	// CodeEnvRedefined reports an environment variable set twice in one
+	// action.
+	CodeEnvRedefined Code = "W_ENV_REDEFINED"
+
+	// CodeEnvValueTooLong reports an environment variable longer than
+	// the maximum set by WithMaxEnvValueLength.
+	CodeEnvValueTooLong Code = "E_ENV_VALUE_TOO_LONG"
+
+	// CodeEnvTooLarge reports an environment larger than the maximum set
+	// by WithMaxEnvSize.
+	CodeEnvTooLarge Code = "E_ENV_TOO_LARGE"
+
+	// CodeReservedEnvName reports an environment variable or secret whose
+	// name begins with `GITHUB_'.
+	CodeReservedEnvName Code = "W_RESERVED_ENV_NAME"
+
+	// CodeUnusedAction reports an action that no workflow resolves,
+	// directly or through `needs'.
+	CodeUnusedAction Code = "W_UNUSED_ACTION"
+
+	// CodeInvalidEnvName reports an environment variable or secret whose
+	// name has characters other than letters, digits, and underscores.
+	CodeInvalidEnvName Code = "W_INVALID_ENV_NAME"
+)
+
+// Codes for problems with workflows.
+const (
+	// CodeOnMissing reports a workflow without an `on' attribute.
+	CodeOnMissing Code = "E_ON_MISSING"
+
+	// CodeUnknownEvent reports an `on' attribute naming an event that
+	// doesn't exist.
+	CodeUnknownEvent Code = "E_UNKNOWN_EVENT"
+
+	// CodeUnknownEventFilter reports an event filter, like the `opened'
+	// in `pull_request.opened', that is not an activity of its event.
+	CodeUnknownEventFilter Code = "E_UNKNOWN_EVENT_FILTER"
+
+	// CodeInvalidSchedule reports a `schedule' event without a valid cron
+	// expression.
+	CodeInvalidSchedule Code = "E_INVALID_SCHEDULE"
+
+	// CodeEventRedefined reports an event listed twice in the same `on'
+	// attribute.
+	CodeEventRedefined Code = "W_EVENT_REDEFINED"
+
+	// CodeUnknownResolves reports a `resolves' attribute naming an action
+	// that doesn't exist.
+	CodeUnknownResolves Code = "E_UNKNOWN_RESOLVES"
+
+	// CodeRedundantResolves reports a `resolves' attribute naming an
+	// action that another action it names already needs.
+	CodeRedundantResolves Code = "W_REDUNDANT_RESOLVES"
+)
+
+// Codes for problems converting files in other formats to a
+// Configuration.
+const (
+	// CodeNotRepresentable reports part of a file in another format, like
+	// the YAML workflows that converter.FromYAML reads, that has no
+	// equivalent in actions and workflows, like a step that runs a shell
+	// command.
+	CodeNotRepresentable Code = "W_NOT_REPRESENTABLE"
+)
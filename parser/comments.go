@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// commentMap holds the comments of a file, attached to the items of its
+// AST.  HCL only attaches comments that directly precede or follow an
+// item, so the parser attaches the rest itself, to keep all of them.
+type commentMap struct {
+	items    map[*ast.ObjectItem]*model.Comments
+	trailing []string
+
+	pending []*ast.Comment
+}
+
+// newCommentMap attaches each comment in file to an item: the first item
+// after it, the item that ends on the same line before it, or, if there
+// is neither in the same block, the end of the block or file.  Comments
+// inside a list are attached to the attribute whose value it is, as lead
+// comments.
+func newCommentMap(file *ast.File) *commentMap {
+	m := &commentMap{items: make(map[*ast.ObjectItem]*model.Comments)}
+	for _, group := range file.Comments {
+		m.pending = append(m.pending, group.List...)
+	}
+	if len(m.pending) == 0 {
+		return m
+	}
+	sort.Slice(m.pending, func(i, j int) bool {
+		return m.pending[i].Start.Offset < m.pending[j].Start.Offset
+	})
+
+	if list, ok := file.Node.(*ast.ObjectList); ok {
+		m.trailing = m.list(list, -1)
+	} else {
+		m.trailing = m.take(-1)
+	}
+	return m
+}
+
+// get returns the comments attached to item.
+func (m *commentMap) get(item *ast.ObjectItem) model.Comments {
+	if c := m.items[item]; c != nil {
+		return *c
+	}
+	return model.Comments{}
+}
+
+// take removes and returns the pending comments before offset, or all of
+// them if offset is negative.
+func (m *commentMap) take(offset int) []string {
+	var ret []string
+	for len(m.pending) > 0 && (offset < 0 || m.pending[0].Start.Offset < offset) {
+		ret = append(ret, m.pending[0].Text)
+		m.pending = m.pending[1:]
+	}
+	return ret
+}
+
+// list attaches comments to the items of list, returning those after the
+// last item.  Limit is the offset of the end of the enclosing block, or
+// -1 at the top level.
+func (m *commentMap) list(list *ast.ObjectList, limit int) []string {
+	for i, item := range list.Items {
+		c := &model.Comments{Lead: m.take(item.Pos().Offset)}
+
+		var end token.Pos
+		switch val := item.Val.(type) {
+		case *ast.ObjectType:
+			c.Trailing = m.list(val.List, val.Rbrace.Offset)
+			end = val.Rbrace
+		case *ast.ListType:
+			c.Lead = append(c.Lead, m.take(val.Rbrack.Offset)...)
+			end = val.Rbrack
+		case *ast.LiteralType:
+			e := tokenEnd(val.Token)
+			end = token.Pos{Line: e.Line, Offset: e.Offset}
+			if e.Column == 1 {
+				// Heredocs end with a newline.
+				end.Line--
+			}
+		default:
+			end = item.Val.Pos()
+		}
+
+		next := limit
+		if i+1 < len(list.Items) {
+			next = list.Items[i+1].Pos().Offset
+		}
+		if len(m.pending) > 0 && m.pending[0].Start.Line == end.Line && (next < 0 || m.pending[0].Start.Offset < next) {
+			c.Line = m.pending[0].Text
+			m.pending = m.pending[1:]
+		}
+
+		if !c.IsEmpty() {
+			m.items[item] = c
+		}
+	}
+	return m.take(limit)
+}
+
+// blockComments returns the comments attached to a block and to each of
+// its attributes, as model.Action and model.Workflow hold them.
+func (p *Parser) blockComments(item *ast.ObjectItem, obj *ast.ObjectType) (model.Comments, map[string]model.Comments) {
+	var attrs map[string]model.Comments
+	// Most attributes have no comments, so their names are only built
+	// for those that do.
+	add := func(prefix string, item *ast.ObjectItem) {
+		if c := p.comments.get(item); !c.IsEmpty() {
+			if attrs == nil {
+				attrs = make(map[string]model.Comments)
+			}
+			attrs[prefix+keyString(item.Keys[0].Token)] = c
+		}
+	}
+
+	for _, attr := range obj.List.Items {
+		add("", attr)
+		if obj, ok := attr.Val.(*ast.ObjectType); ok {
+			if name := keyString(attr.Keys[0].Token); name == "env" || name == "matrix" {
+				prefix := name + "."
+				for _, v := range obj.List.Items {
+					add(prefix, v)
+				}
+			}
+		}
+	}
+	return p.comments.get(item), attrs
+}
+
+// envBlockComments returns the comments attached to a top-level `env'
+// block and to each of its variables, as model.Configuration holds them.
+func (p *Parser) envBlockComments(item *ast.ObjectItem) map[string]model.Comments {
+	attrs := make(map[string]model.Comments)
+	if c := p.comments.get(item); !c.IsEmpty() {
+		attrs["env"] = c
+	}
+	if env, ok := item.Val.(*ast.ObjectType); ok {
+		for _, v := range env.List.Items {
+			if c := p.comments.get(v); !c.IsEmpty() {
+				attrs["env."+keyString(v.Keys[0].Token)] = c
+			}
+		}
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// keyString returns the name a key token spells, without reporting
+// errors as identString does.
+func keyString(t token.Token) string {
+	if t.Type == token.STRING {
+		return tokenString(t)
+	}
+	return t.Text
+}
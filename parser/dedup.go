@@ -0,0 +1,52 @@
+package parser
+
+// WithDeduplication collapses diagnostics with the same code and message,
+// e.g., the same unknown attribute in many actions, into the first of
+// them.  Its Count says how many there were, and Positions lists where
+// the first n of them are.  A limit of zero or less keeps only the
+// position of the first.
+func WithDeduplication(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.dedup = true
+		ps.dedupPositions = n
+	}
+}
+
+// dedupErrors returns errors, which must be sorted, with each diagnostic
+// that has the same code and message as an earlier one folded into that
+// one, if WithDeduplication asked for it.  The diagnostics it changes are
+// copies, since incremental parsing shares them between Documents.
+func (p *Parser) dedupErrors(errors ErrorList) ErrorList {
+	if !p.dedup {
+		return errors
+	}
+
+	type key struct {
+		code    Code
+		message string
+	}
+	seen := make(map[key]int, len(errors))
+	ret := make(ErrorList, 0, len(errors))
+	for _, e := range errors {
+		k := key{e.Code, e.message}
+		i, ok := seen[k]
+		if !ok {
+			seen[k] = len(ret)
+			ret = append(ret, e)
+			continue
+		}
+
+		first := ret[i]
+		if first.Count == 0 {
+			c := *first
+			c.Count = 1
+			c.Positions = []ErrorPos{c.Pos}
+			first, ret[i] = &c, &c
+		}
+		first.Count++
+		if len(first.Positions) < p.dedupPositions {
+			first.Positions = append(first.Positions, e.Pos)
+		}
+	}
+	return ret
+}
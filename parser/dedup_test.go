@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dedupSource = `action "a" { uses = "./a" foo = "1" }
+action "b" { uses = "./b" foo = "2" }
+action "c" { uses = "./c" foo = "3" }
+action "d" { uses = "./d" bar = "4" }
+`
+
+func TestDeduplication(t *testing.T) {
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(dedupSource), WithDeduplication(2))
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+
+	assert.Equal(t, 3, errs[0].Count)
+	assert.Equal(t, []int{1, 2}, []int{errs[0].Positions[0].Line, errs[0].Positions[1].Line})
+	assert.Equal(t, "Line 1: Unknown action attribute `foo' [W_UNKNOWN_ATTRIBUTE] (3 times: lines 1, 2, ...)", errs[0].Error())
+
+	assert.Equal(t, 0, errs[1].Count)
+	assert.Nil(t, errs[1].Positions)
+	assert.Equal(t, "Line 4: Unknown action attribute `bar' [W_UNKNOWN_ATTRIBUTE]", errs[1].Error())
+
+	_, errs, err = ParseWithDiagnostics(strings.NewReader(dedupSource))
+	require.NoError(t, err)
+	assert.Len(t, errs, 4)
+}
+
+func TestDeduplicationJSON(t *testing.T) {
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(dedupSource), WithDeduplication(5))
+	require.NoError(t, err)
+
+	b, err := json.Marshal(errs[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"count":3,"positions":[{"line":1,"column":33,"offset":32},`)
+
+	var decoded ParseError
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, 3, decoded.Count)
+	assert.Equal(t, errs[0].Positions, decoded.Positions)
+}
+
+func TestDeduplicationReparse(t *testing.T) {
+	d := ParseDocument([]byte(dedupSource), WithDeduplication(5))
+	require.Len(t, d.Errors, 2)
+	assert.Equal(t, 3, d.Errors[0].Count)
+
+	d, err := Reparse(d, edit(t, d.Source, `bar = "4"`, `foo = "4"`))
+	require.NoError(t, err)
+	require.Len(t, d.Errors, 1)
+	assert.Equal(t, 4, d.Errors[0].Count)
+	assert.Len(t, d.Errors[0].Positions, 4)
+}
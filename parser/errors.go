@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
@@ -15,11 +16,19 @@ type Error struct {
 	Errors    []*ParseError
 	Actions   []*model.Action
 	Workflows []*model.Workflow
+
+	// cause is set when parsing stopped early, e.g., because the context
+	// passed to ParseContext was canceled.
+	cause error
 }
 
 func (e *Error) Error() string {
 	buffer := bytes.NewBuffer(nil)
 	buffer.WriteString(e.message)
+	if e.cause != nil {
+		buffer.WriteString(": ")
+		buffer.WriteString(e.cause.Error())
+	}
 	for _, pe := range e.Errors {
 		buffer.WriteString("\n  ")
 		buffer.WriteString(pe.Error())
@@ -27,6 +36,41 @@ func (e *Error) Error() string {
 	return buffer.String()
 }
 
+// Unwrap returns the reason parsing stopped early, if any, so that for a
+// parse canceled through its context, errors.Is(err, context.Canceled)
+// is true.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether any of the diagnostics in e matches target, so that,
+// e.g., errors.Is(err, CodeSyntax) says whether there is a syntax error.
+func (e *Error) Is(target error) bool {
+	for _, pe := range e.Errors {
+		if pe.Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the most severe diagnostics in e that matches
+// target, so that errors.As(err, &pe), with pe a *ParseError, finds the
+// first FATAL diagnostic, if there is one.
+func (e *Error) As(target interface{}) bool {
+	bySeverity := make(ErrorList, len(e.Errors))
+	copy(bySeverity, e.Errors)
+	sort.SliceStable(bySeverity, func(i, j int) bool {
+		return bySeverity[i].Severity > bySeverity[j].Severity
+	})
+	for _, pe := range bySeverity {
+		if errors.As(pe, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // FirstError searches a Configuration for the first error at or above a
 // given severity level.  Checking the return value against nil is a good
 // way to see if the file has any errors at or above the given severity.
@@ -46,67 +90,158 @@ func (e *Error) FirstError(severity Severity) error {
 // (HCL) or semantic (.workflow) in nature.  There are fields for location
 // (File, Line, Column), severity, and base error string.  The `Error()`
 // function on this type concatenates whatever bits of the location are
-// available with the message.  The severity is only used for filtering.
+// available with the message and the code.  The severity is only used for
+// filtering.
 type ParseError struct {
 	message  string
 	Pos      ErrorPos
 	Severity Severity
+
+	// Code identifies the rule that produced the error.  Unlike the
+	// message, it never changes, so tools can filter on it.
+	Code Code
+
+	// Fix, if not nil, is a suggested change to the source that resolves
+	// the error.
+	Fix *Fix
+
+	// Cycle, for a circular dependency, lists the identifiers of the
+	// actions in the cycle, each of which needs the next, with the last
+	// needing the first.
+	Cycle []string
+
+	// Count, for a diagnostic that stands for several with the same code
+	// and message, is how many there were, itself included, and
+	// Positions lists where the first of them are.  Both are only set
+	// with WithDeduplication, and only if there was more than one.
+	Count     int
+	Positions []ErrorPos
+
+	// Related points at other places in the source that explain the
+	// error, like where a redefined identifier or attribute was first
+	// defined, or the `needs' of each action in a dependency cycle.
+	Related []RelatedPos
+
+	// subject is the part of the configuration a Rule reported the error
+	// about, used to fill in Pos.
+	subject interface{}
 }
 
 // ErrorPos represents the location of an error in a user's workflow
-// file(s).
+// file(s).  Offset is the byte offset from the beginning of the file.
 type ErrorPos struct {
 	File   string
 	Line   int
 	Column int
+	Offset int
+}
+
+// RelatedPos is a place in the source related to a ParseError, with a
+// message saying how.
+type RelatedPos struct {
+	Pos     ErrorPos
+	Message string
 }
 
 // newFatal creates a new error at the FATAL level, indicating that the
 // file is so broken it should not be displayed.
-func newFatal(pos ErrorPos, format string, a ...interface{}) *ParseError {
+func newFatal(pos ErrorPos, code Code, format string, a ...interface{}) *ParseError {
 	return &ParseError{
 		message:  fmt.Sprintf(format, a...),
 		Pos:      pos,
+		Code:     code,
 		Severity: FATAL,
 	}
 }
 
 // newError creates a new error at the ERROR level, indicating that the
 // file can be displayed but cannot be run.
-func newError(pos ErrorPos, format string, a ...interface{}) *ParseError {
+func newError(pos ErrorPos, code Code, format string, a ...interface{}) *ParseError {
 	return &ParseError{
 		message:  fmt.Sprintf(format, a...),
 		Pos:      pos,
+		Code:     code,
 		Severity: ERROR,
 	}
 }
 
 // newWarning creates a new error at the WARNING level, indicating that
 // the file might be runnable but might not execute as intended.
-func newWarning(pos ErrorPos, format string, a ...interface{}) *ParseError {
+func newWarning(pos ErrorPos, code Code, format string, a ...interface{}) *ParseError {
 	return &ParseError{
 		message:  fmt.Sprintf(format, a...),
 		Pos:      pos,
+		Code:     code,
 		Severity: WARNING,
 	}
 }
 
+// Is reports whether target is e's Code, so that errors.Is can check an
+// error from Parse for a particular problem, e.g.,
+// errors.Is(err, CodeCircularDependency).
+func (e *ParseError) Is(target error) bool {
+	code, ok := target.(Code)
+	return ok && code != "" && code == e.Code
+}
+
+// Message returns the error message, without any position information.
+func (e *ParseError) Message() string {
+	return e.message
+}
+
 func (e *ParseError) Error() string {
+	if e.Pos.File == "" && e.Pos.Line == 0 && e.Code == "" {
+		return e.message
+	}
+
 	var sb strings.Builder
+	if e.Pos.File != "" {
+		sb.WriteString(e.Pos.File) // nolint: errcheck
+		sb.WriteString(": ")       // nolint: errcheck
+	}
 	if e.Pos.Line != 0 {
 		sb.WriteString("Line ")                  // nolint: errcheck
 		sb.WriteString(strconv.Itoa(e.Pos.Line)) // nolint: errcheck
 		sb.WriteString(": ")                     // nolint: errcheck
 	}
-	if sb.Len() > 0 {
-		sb.WriteString(e.message) // nolint: errcheck
-		return sb.String()
+	sb.WriteString(e.message) // nolint: errcheck
+	if e.Code != "" {
+		sb.WriteString(" [")           // nolint: errcheck
+		sb.WriteString(string(e.Code)) // nolint: errcheck
+		sb.WriteString("]")            // nolint: errcheck
 	}
-	return e.message
+	if e.Count > 1 {
+		fmt.Fprintf(&sb, " (%d times: %s)", e.Count, e.occurrences()) // nolint: errcheck
+	}
+	return sb.String()
 }
 
+// occurrences describes where the diagnostics folded into e are, e.g.,
+// "lines 3, 7, 11, ...".
+func (e *ParseError) occurrences() string {
+	parts := make([]string, 0, len(e.Positions)+1)
+	for _, pos := range e.Positions {
+		if pos.File != e.Pos.File {
+			parts = append(parts, pos.File+" line "+strconv.Itoa(pos.Line))
+		} else {
+			parts = append(parts, strconv.Itoa(pos.Line))
+		}
+	}
+	if e.Count > len(e.Positions) {
+		parts = append(parts, "...")
+	}
+	return "lines " + strings.Join(parts, ", ")
+}
+
+// The values of WARNING, ERROR, and FATAL are those they have always
+// had.  INFO came later, and is below them; zero is no severity.
 const (
-	_ = iota
+	// INFO indicates something worth pointing out that is not a mistake.
+	// The parser reports nothing at this level on its own; see
+	// WithSeverityOverride.
+	INFO = iota - 1
+
+	_
 
 	// WARNING indicates a mistake that might affect correctness
 	WARNING
@@ -119,18 +254,137 @@ const (
 )
 
 // Severity represents the level of an error encountered while parsing a
-// workflow file.  See the comments for WARNING, ERROR, and FATAL, above.
+// workflow file.  See the comments for INFO, WARNING, ERROR, and FATAL,
+// above.
 type Severity int
 
-type errorList []*ParseError
+var severities = [...]Severity{INFO, WARNING, ERROR, FATAL}
 
-func (a errorList) Len() int           { return len(a) }
-func (a errorList) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a errorList) Less(i, j int) bool { return a[i].Pos.Line < a[j].Pos.Line }
+// String returns the name of s: "info", "warning", "error", or "fatal".
+func (s Severity) String() string {
+	switch s {
+	case INFO:
+		return "info"
+	case WARNING:
+		return "warning"
+	case ERROR:
+		return "error"
+	case FATAL:
+		return "fatal"
+	}
+	return "Severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ParseSeverity returns the severity with the given name, as String
+// returns it, ignoring case.
+func ParseSeverity(name string) (Severity, error) {
+	for _, sev := range severities {
+		if strings.EqualFold(sev.String(), name) {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity `%s'; expected info, warning, error, or fatal", name)
+}
 
-// sortErrors sorts the errors reported by the parser.  Do this after
-// parsing is complete.  The sort is stable, so order is preserved within
-// a single line: left to right, syntax errors before validation errors.
-func (errors errorList) sort() {
+// MarshalText encodes s as its name, so that it appears by name in JSON,
+// YAML, and other formats that use encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	if s < INFO || s == 0 || s > FATAL {
+		return nil, fmt.Errorf("invalid severity %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes s from its name, as ParseSeverity does.
+func (s *Severity) UnmarshalText(b []byte) error {
+	sev, err := ParseSeverity(string(b))
+	if err != nil {
+		return err
+	}
+	*s = sev
+	return nil
+}
+
+// ErrorList is the list of diagnostics for a file, as returned by
+// ParseWithDiagnostics.
+type ErrorList []*ParseError
+
+func (a ErrorList) Len() int      { return len(a) }
+func (a ErrorList) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ErrorList) Less(i, j int) bool {
+	if a[i].Pos.File != a[j].Pos.File {
+		return a[i].Pos.File < a[j].Pos.File
+	}
+	return a[i].Pos.Line < a[j].Pos.Line
+}
+
+// sort sorts the errors reported by the parser, by file and then by
+// line.  Do this after parsing is complete.  The sort is stable, so order
+// is preserved within a single line: left to right, syntax errors before
+// validation errors.
+func (errors ErrorList) sort() {
 	sort.Stable(errors)
 }
+
+// FilterSeverity returns the errors at or above min, in order.
+func (errors ErrorList) FilterSeverity(min Severity) ErrorList {
+	return errors.filter(func(e *ParseError) bool {
+		return e.Severity >= min
+	})
+}
+
+// ForLineRange returns the errors on lines first through last, inclusive,
+// in order.  Lines are numbered from 1, as in ErrorPos.
+func (errors ErrorList) ForLineRange(first, last int) ErrorList {
+	return errors.filter(func(e *ParseError) bool {
+		return e.Pos.Line >= first && e.Pos.Line <= last
+	})
+}
+
+// ByCode returns the errors with the given code, in order.
+func (errors ErrorList) ByCode(code Code) ErrorList {
+	return errors.filter(func(e *ParseError) bool {
+		return e.Code == code
+	})
+}
+
+// HasFatal returns whether any of the errors is FATAL, meaning the file
+// can't even be displayed.
+func (errors ErrorList) HasFatal() bool {
+	for _, e := range errors {
+		if e.Severity >= FATAL {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the errors for which keep returns true.
+func (errors ErrorList) filter(keep func(*ParseError) bool) ErrorList {
+	n := 0
+	for _, e := range errors {
+		if keep(e) {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	ret := make(ErrorList, 0, n)
+	for _, e := range errors {
+		if keep(e) {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// hasProblems returns whether any of the errors is more severe than INFO.
+func (errors ErrorList) hasProblems() bool {
+	for _, e := range errors {
+		if e.Severity > INFO {
+			return true
+		}
+	}
+	return false
+}
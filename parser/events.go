@@ -1,15 +1,36 @@
 package parser
 
 import (
+	"sort"
 	"strings"
+	"sync"
 )
 
-// isAllowedEventType returns true if the event type is supported.
-func isAllowedEventType(eventType string) bool {
-	_, ok := eventTypeWhitelist[strings.ToLower(eventType)]
+// IsAllowedEventType returns true if the event type is supported.  The
+// comparison is case-insensitive.
+func IsAllowedEventType(eventType string) bool {
+	var buf [maxEventTypeLen]byte
+	if len(eventType) > len(buf) {
+		return false
+	}
+	// Lowercase into a stack buffer rather than calling strings.ToLower.
+	// The compiler doesn't allocate for a map index of string(bytes), so
+	// this lookup is allocation-free no matter how the input is cased.
+	for i := 0; i < len(eventType); i++ {
+		c := eventType[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		buf[i] = c
+	}
+	_, ok := eventTypeWhitelist[string(buf[:len(eventType)])]
 	return ok
 }
 
+// maxEventTypeLen is at least the length of the longest entry in
+// eventTypeWhitelist.
+const maxEventTypeLen = 64
+
 // https://developer.github.com/actions/creating-workflows/workflow-configuration-options/#events-supported-in-workflow-files
 var eventTypeWhitelist = map[string]struct{}{
 	"check_run":                   {},
@@ -37,6 +58,119 @@ var eventTypeWhitelist = map[string]struct{}{
 	"push":                        {},
 	"release":                     {},
 	"repository_dispatch":         {},
+	"schedule":                    {},
 	"status":                      {},
 	"watch":                       {},
 }
+
+// IsAllowedEventFilter returns true if filter is one of the activities
+// of eventType, i.e., a value of the `action' field of its webhook
+// payload.  Events without activities, like `push', can't be filtered.
+// The comparison is case-insensitive.
+func IsAllowedEventFilter(eventType, filter string) bool {
+	for _, activity := range eventActivities[strings.ToLower(eventType)] {
+		if strings.EqualFold(activity, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// EventActivities returns the activities eventType can be filtered by,
+// as in `pull_request.opened', or nil if it has none.  The event type is
+// case-insensitive.
+func EventActivities(eventType string) []string {
+	activities := eventActivities[strings.ToLower(eventType)]
+	if activities == nil {
+		return nil
+	}
+	return append([]string(nil), activities...)
+}
+
+// https://developer.github.com/webhooks/#events
+var eventActivities = map[string][]string{
+	"check_run":                   {"created", "rerequested", "completed", "requested_action"},
+	"check_suite":                 {"completed", "requested", "rerequested"},
+	"commit_comment":              {"created"},
+	"deployment_status":           {"created"},
+	"issue_comment":               {"created", "edited", "deleted"},
+	"issues":                      {"opened", "edited", "deleted", "transferred", "pinned", "unpinned", "closed", "reopened", "assigned", "unassigned", "labeled", "unlabeled", "milestoned", "demilestoned"},
+	"label":                       {"created", "edited", "deleted"},
+	"member":                      {"added", "removed", "edited"},
+	"milestone":                   {"created", "closed", "opened", "edited", "deleted"},
+	"project_card":                {"created", "edited", "moved", "converted", "deleted"},
+	"project_column":              {"created", "edited", "moved", "deleted"},
+	"project":                     {"created", "edited", "closed", "reopened", "deleted"},
+	"pull_request_review_comment": {"created", "edited", "deleted"},
+	"pull_request_review":         {"submitted", "edited", "dismissed"},
+	"pull_request":                {"assigned", "unassigned", "review_requested", "review_request_removed", "labeled", "unlabeled", "opened", "edited", "closed", "ready_for_review", "locked", "unlocked", "reopened", "synchronize"},
+	"release":                     {"published", "unpublished", "created", "edited", "deleted", "prereleased"},
+	"watch":                       {"started"},
+}
+
+// EventRegistry is a set of allowed event types, for servers that need
+// a different set than the built-in one, possibly per request.  It is
+// safe for concurrent use: one goroutine can add or remove types while
+// others parse with it.  Pass it to the parser with WithEventRegistry.
+type EventRegistry struct {
+	mu    sync.RWMutex
+	types map[string]struct{}
+}
+
+// NewEventRegistry returns a registry that allows exactly the given
+// event types.
+func NewEventRegistry(eventTypes ...string) *EventRegistry {
+	r := &EventRegistry{types: make(map[string]struct{}, len(eventTypes))}
+	r.Add(eventTypes...)
+	return r
+}
+
+// DefaultEventRegistry returns a registry that allows the same event
+// types as IsAllowedEventType.  Changing it doesn't affect
+// IsAllowedEventType or other registries.
+func DefaultEventRegistry() *EventRegistry {
+	r := &EventRegistry{types: make(map[string]struct{}, len(eventTypeWhitelist))}
+	for t := range eventTypeWhitelist {
+		r.types[t] = struct{}{}
+	}
+	return r
+}
+
+// Add allows the given event types.
+func (r *EventRegistry) Add(eventTypes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range eventTypes {
+		r.types[strings.ToLower(t)] = struct{}{}
+	}
+}
+
+// Remove disallows the given event types.
+func (r *EventRegistry) Remove(eventTypes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range eventTypes {
+		delete(r.types, strings.ToLower(t))
+	}
+}
+
+// IsAllowed returns true if the event type is in the registry.  The
+// comparison is case-insensitive.
+func (r *EventRegistry) IsAllowed(eventType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.types[strings.ToLower(eventType)]
+	return ok
+}
+
+// Types returns the allowed event types, in sorted order.
+func (r *EventRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ret := make([]string, 0, len(r.types))
+	for t := range r.types {
+		ret = append(ret, t)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,19 +14,78 @@ func TestIsAllowedEventType(t *testing.T) {
 		"push",
 		"PUSH",
 		"pull_request",
+		"Pull_Request",
 	}
 
 	for _, s := range allowed {
-		assert.True(t, isAllowedEventType(s), "should allow %q", s)
+		assert.True(t, IsAllowedEventType(s), "should allow %q", s)
 	}
 
 	// This is also not exhaustive. We want to have this done by universe, after all.
 	notAllowed := []string{
 		"installation",
 		"randommashingofkeyboard",
+		"",
+		"push_push_push_push_push_push_push_push_push_push_push_push_push_push",
 	}
 
 	for _, s := range notAllowed {
-		assert.False(t, isAllowedEventType(s), "should not allow %q", s)
+		assert.False(t, IsAllowedEventType(s), "should not allow %q", s)
+	}
+}
+
+func TestIsAllowedEventFilter(t *testing.T) {
+	assert.True(t, IsAllowedEventFilter("pull_request", "opened"))
+	assert.True(t, IsAllowedEventFilter("Pull_Request", "Synchronize"))
+	assert.True(t, IsAllowedEventFilter("issues", "labeled"))
+	assert.False(t, IsAllowedEventFilter("pull_request", "published"))
+	assert.False(t, IsAllowedEventFilter("push", "opened"))
+	assert.False(t, IsAllowedEventFilter("pull_request", ""))
+}
+
+func TestEventRegistry(t *testing.T) {
+	r := NewEventRegistry("push", "Deploy")
+	assert.True(t, r.IsAllowed("PUSH"))
+	assert.True(t, r.IsAllowed("deploy"))
+	assert.False(t, r.IsAllowed("pull_request"))
+
+	r.Add("pull_request")
+	r.Remove("push")
+	assert.Equal(t, []string{"deploy", "pull_request"}, r.Types())
+
+	d := DefaultEventRegistry()
+	assert.True(t, d.IsAllowed("push"))
+	d.Remove("push")
+	assert.False(t, d.IsAllowed("push"))
+	assert.True(t, IsAllowedEventType("push"))
+	assert.True(t, DefaultEventRegistry().IsAllowed("push"))
+}
+
+func TestEventRegistryConcurrent(t *testing.T) {
+	r := DefaultEventRegistry()
+	src := `workflow "w" { on = "deploy" }`
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.Add("deploy")
+			r.Remove("deploy")
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = Parse(strings.NewReader(src), WithEventRegistry(r))
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkIsAllowedEventType(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		IsAllowedEventType("pull_request")
+		IsAllowedEventType("Pull_Request")
+		IsAllowedEventType("installation")
 	}
 }
@@ -0,0 +1,470 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+// expressionContexts are the objects an expression like
+// `${{ github.sha }}' can start with.
+var expressionContexts = map[string]bool{
+	"github":   true,
+	"env":      true,
+	"secrets":  true,
+	"job":      true,
+	"steps":    true,
+	"runner":   true,
+	"strategy": true,
+	"matrix":   true,
+	"needs":    true,
+	"inputs":   true,
+}
+
+// expressionFunctions are the functions an expression can call, in lower
+// case, since function names are not case-sensitive.
+var expressionFunctions = map[string]bool{
+	"contains":   true,
+	"startswith": true,
+	"endswith":   true,
+	"format":     true,
+	"join":       true,
+	"tojson":     true,
+	"fromjson":   true,
+	"hashfiles":  true,
+	"success":    true,
+	"always":     true,
+	"cancelled":  true,
+	"failure":    true,
+}
+
+// variableReference matches what may come between the braces of a
+// variable reference like `${HOME}': a name, a positional parameter, or
+// a special parameter, optionally with `#' before it for its length and
+// followed by an operator like `:-default'.
+var variableReference = regexp.MustCompile(`^#?([a-zA-Z_][a-zA-Z0-9_]*|[0-9]+|[@*#?$!-])([:#%/^,]|$)`)
+
+// checkExpressions checks the `if' condition of every action and
+// template, and the `${...}' variable references and `${{...}}'
+// expressions in its `runs', `args', and `env' values and in the `env'
+// blocks of the workflows and the file.
+func (p *Parser) checkExpressions() {
+	for _, k := range envKeys(p.env) {
+		p.checkEnvInterpolation("the file", k, p.env[k], func() ast.Node { return p.fileEnvNode(k) })
+	}
+	for _, workflow := range p.workflows {
+		owner := "workflow `" + workflow.Identifier + "'"
+		for _, k := range envKeys(workflow.Env) {
+			node := p.posMap[&workflow.Env]
+			p.checkEnvInterpolation(owner, k, workflow.Env[k], func() ast.Node { return p.envValueNode(node, k) })
+		}
+	}
+	for _, action := range p.actions {
+		p.checkActionExpressions("action `"+action.Identifier+"'", action)
+	}
+	for _, template := range p.templates {
+		p.checkActionExpressions("template `"+template.Identifier+"'", template)
+	}
+}
+
+// checkActionExpressions checks the `if' condition of an action or
+// template, and the expressions in its `runs', `args', and `env' values.
+func (p *Parser) checkActionExpressions(owner string, action *model.Action) {
+	p.checkCondition(owner, action.If, p.posMap[&action.If])
+	p.checkCommandExpressions(owner, "runs", action.Runs, p.posMap[&action.Runs])
+	p.checkCommandExpressions(owner, "args", action.Args, p.posMap[&action.Args])
+	for _, k := range envKeys(action.Env) {
+		node := p.posMap[&action.Env]
+		p.checkEnvInterpolation(owner, k, action.Env[k], func() ast.Node { return p.envValueNode(node, k) })
+	}
+}
+
+// checkEnvInterpolation checks the value of the variable k in an `env'
+// block of owner.  Most values refer to nothing, so the attribute name
+// and the value's node, which node returns, are only found for those
+// that do.
+func (p *Parser) checkEnvInterpolation(owner, k, value string, node func() ast.Node) {
+	if strings.Contains(value, "${") {
+		p.checkInterpolation(owner, "env."+k, value, node())
+	}
+}
+
+// checkCondition checks the `if' condition of owner, found at node.  The
+// condition is an expression, optionally wrapped in `${{' and `}}'.
+func (p *Parser) checkCondition(owner, cond string, node ast.Node) {
+	if cond == "" {
+		return
+	}
+	expr := strings.TrimSpace(cond)
+	if strings.HasPrefix(expr, "${{") && strings.HasSuffix(expr, "}}") && expressionEnd(expr[3:]) == len(expr)-5 {
+		expr = expr[3 : len(expr)-2]
+	}
+	refs, err := parseExpression(expr)
+	if err != nil {
+		p.addError(node, CodeExpressionSyntax, "Malformed condition `%s' in `if' of %s: %s", cond, owner, err)
+		return
+	}
+	p.checkReferences(owner, "if", refs, node)
+}
+
+func (p *Parser) checkCommandExpressions(owner, name string, cmd model.Command, node ast.Node) {
+	switch cmd := cmd.(type) {
+	case *model.StringCommand:
+		p.checkInterpolation(owner, name, cmd.Value, node)
+	case *model.HeredocCommand:
+		p.checkInterpolation(owner, name, cmd.Value, node)
+	case *model.ListCommand:
+		for i, v := range cmd.Values {
+			elem := node
+			if list, ok := node.(*ast.ListType); ok && i < len(list.List) {
+				elem = list.List[i]
+			}
+			p.checkInterpolation(owner, name, v, elem)
+		}
+	}
+}
+
+// checkInterpolation checks each `${...}' and `${{...}}' in s, the value
+// of the named attribute of owner, like "action `build'", found at node.
+func (p *Parser) checkInterpolation(owner, name, s string, node ast.Node) {
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			return
+		}
+		s = s[i+2:]
+
+		if !strings.HasPrefix(s, "{") {
+			end := strings.IndexByte(s, '}')
+			if end < 0 {
+				p.addError(node, CodeExpressionSyntax, "Unterminated variable reference `${%s' in `%s' of %s", s, name, owner)
+				return
+			}
+			if !variableReference.MatchString(s[:end]) {
+				p.addError(node, CodeExpressionSyntax, "Malformed variable reference `${%s}' in `%s' of %s", s[:end], name, owner)
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		end := expressionEnd(s[1:])
+		if end < 0 {
+			p.addError(node, CodeExpressionSyntax, "Unterminated expression `${%s' in `%s' of %s", s, name, owner)
+			return
+		}
+		text := s[1 : end+1]
+		s = s[end+3:]
+		refs, err := parseExpression(text)
+		if err != nil {
+			p.addError(node, CodeExpressionSyntax, "Malformed expression `${{%s}}' in `%s' of %s: %s", text, name, owner, err)
+			continue
+		}
+		p.checkReferences(owner, name, refs, node)
+	}
+}
+
+// checkReferences warns about the unknown contexts and functions that an
+// expression in the named attribute of owner, found at node, refers to.
+func (p *Parser) checkReferences(owner, name string, refs *exprRefs, node ast.Node) {
+	for _, c := range refs.contexts {
+		if !expressionContexts[c] {
+			p.addWarning(node, CodeUnknownContext, "Unknown context `%s' in `%s' of %s", c, name, owner)
+		}
+	}
+	for _, f := range refs.functions {
+		if !expressionFunctions[strings.ToLower(f)] {
+			p.addWarning(node, CodeUnknownFunction, "Unknown function `%s' in `%s' of %s", f, name, owner)
+		}
+	}
+}
+
+// envKeys returns the names of the variables in env, sorted.
+func envKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// expressionEnd returns the offset of the `}}' that ends the expression
+// at the start of s, skipping strings, or -1 if there is none.
+func expressionEnd(s string) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(s[i:], "}}"):
+			return i
+		}
+	}
+	return -1
+}
+
+// envValueNode returns the value of the variable k in an `env' object,
+// or node itself if it can't be found.
+func (p *Parser) envValueNode(node ast.Node, k string) ast.Node {
+	obj, ok := node.(*ast.ObjectType)
+	if !ok {
+		return node
+	}
+	for _, item := range obj.List.Items {
+		if len(item.Keys) == 1 && p.identString(item.Keys[0].Token) == k {
+			return item.Val
+		}
+	}
+	return node
+}
+
+// exprRefs lists what an expression refers to: the contexts its
+// property accesses start with and the functions it calls, each once, in
+// order.
+type exprRefs struct {
+	contexts  []string
+	functions []string
+}
+
+// parseExpression checks the syntax of an expression, the text between
+// `${{' and `}}', returning what it refers to.  Expressions are made of
+// literals (strings in single quotes, numbers, true, false, and null),
+// property accesses like `github.event.action' or `steps[0]', function
+// calls like `contains(a, b)', the operators `!', `==', `!=', `<', `<=',
+// `>', `>=', `&&', and `||', and parentheses.
+func parseExpression(s string) (*exprRefs, error) {
+	ep := &exprParser{src: s, refs: &exprRefs{}}
+	ep.next()
+	if ep.tok == "" && ep.err == nil {
+		return nil, fmt.Errorf("empty expression")
+	}
+	if err := ep.or(); err != nil {
+		return nil, err
+	}
+	if ep.tok != "" || ep.err != nil {
+		return nil, ep.unexpected()
+	}
+	return ep.refs, nil
+}
+
+// exprParser is a recursive descent parser for expressions.  Tok is the
+// current token, or "" at the end.
+type exprParser struct {
+	src  string
+	pos  int
+	tok  string
+	kind exprToken
+	err  error
+	refs *exprRefs
+}
+
+type exprToken int
+
+const (
+	exprPunct exprToken = iota
+	exprIdent
+	exprLiteral
+)
+
+var exprNumber = regexp.MustCompile(`^(0x[0-9a-fA-F]+|[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?)`)
+
+// next reads the next token.
+func (ep *exprParser) next() {
+	for ep.pos < len(ep.src) && strings.IndexByte(" \t\r\n", ep.src[ep.pos]) >= 0 {
+		ep.pos++
+	}
+	start := ep.pos
+	if start == len(ep.src) {
+		ep.tok, ep.kind = "", exprPunct
+		return
+	}
+
+	c := ep.src[start]
+	switch {
+	case c == '\'':
+		i := start + 1
+		for ; i < len(ep.src); i++ {
+			if ep.src[i] == '\'' {
+				if i+1 < len(ep.src) && ep.src[i+1] == '\'' {
+					i++
+					continue
+				}
+				break
+			}
+		}
+		if i == len(ep.src) {
+			ep.err = fmt.Errorf("unterminated string")
+			ep.pos, ep.tok, ep.kind = len(ep.src), "", exprPunct
+			return
+		}
+		ep.pos, ep.kind = i+1, exprLiteral
+	case c == '_' || isLetter(c):
+		i := start + 1
+		for i < len(ep.src) && (ep.src[i] == '_' || ep.src[i] == '-' || isLetter(ep.src[i]) || isDigit(ep.src[i])) {
+			i++
+		}
+		ep.pos, ep.kind = i, exprIdent
+	case isDigit(c) || c == '-' && start+1 < len(ep.src) && isDigit(ep.src[start+1]):
+		i := start
+		if c == '-' {
+			i++
+		}
+		ep.pos, ep.kind = i+len(exprNumber.FindString(ep.src[i:])), exprLiteral
+	default:
+		ep.pos, ep.kind = start+1, exprPunct
+		for _, op := range []string{"==", "!=", "<=", ">=", "&&", "||"} {
+			if strings.HasPrefix(ep.src[start:], op) {
+				ep.pos = start + 2
+			}
+		}
+	}
+	ep.tok = ep.src[start:ep.pos]
+	if ep.kind == exprIdent && (ep.tok == "true" || ep.tok == "false" || ep.tok == "null") {
+		ep.kind = exprLiteral
+	}
+}
+
+// accept reads the current token if it is the punctuation tok.
+func (ep *exprParser) accept(tok string) bool {
+	if ep.kind == exprPunct && ep.tok == tok {
+		ep.next()
+		return true
+	}
+	return false
+}
+
+func (ep *exprParser) unexpected() error {
+	if ep.err != nil {
+		return ep.err
+	}
+	if ep.tok == "" {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected `%s'", ep.tok)
+}
+
+func (ep *exprParser) or() error {
+	if err := ep.and(); err != nil {
+		return err
+	}
+	for ep.accept("||") {
+		if err := ep.and(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ep *exprParser) and() error {
+	if err := ep.comparison(); err != nil {
+		return err
+	}
+	for ep.accept("&&") {
+		if err := ep.comparison(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ep *exprParser) comparison() error {
+	if err := ep.unary(); err != nil {
+		return err
+	}
+	for ep.accept("==") || ep.accept("!=") || ep.accept("<") || ep.accept("<=") || ep.accept(">") || ep.accept(">=") {
+		if err := ep.unary(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ep *exprParser) unary() error {
+	if ep.accept("!") {
+		return ep.unary()
+	}
+	return ep.primary()
+}
+
+func (ep *exprParser) primary() error {
+	switch {
+	case ep.accept("("):
+		if err := ep.or(); err != nil {
+			return err
+		}
+		if !ep.accept(")") {
+			return ep.unexpected()
+		}
+	case ep.kind == exprLiteral:
+		ep.next()
+		return nil
+	case ep.kind == exprIdent:
+		name := ep.tok
+		ep.next()
+		if ep.accept("(") {
+			ep.refs.functions = appendUnique(ep.refs.functions, name)
+			if !ep.accept(")") {
+				for {
+					if err := ep.or(); err != nil {
+						return err
+					}
+					if ep.accept(")") {
+						break
+					}
+					if !ep.accept(",") {
+						return ep.unexpected()
+					}
+				}
+			}
+		} else {
+			ep.refs.contexts = appendUnique(ep.refs.contexts, name)
+		}
+	default:
+		return ep.unexpected()
+	}
+	return ep.properties()
+}
+
+// properties reads the property accesses after a context or a function
+// call, like `.event.action', `.*', or `[0]'.
+func (ep *exprParser) properties() error {
+	for {
+		switch {
+		case ep.accept("."):
+			if ep.kind != exprIdent && !(ep.kind == exprPunct && ep.tok == "*") {
+				return ep.unexpected()
+			}
+			ep.next()
+		case ep.accept("["):
+			if err := ep.or(); err != nil {
+				return err
+			}
+			if !ep.accept("]") {
+				return ep.unexpected()
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
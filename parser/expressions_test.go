@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressions(t *testing.T) {
+	src := `action "a" {
+  uses = "./a"
+  runs = "echo ${HOME} ${1} ${#PATH} ${NAME:-x} $${{ github.sha }}"
+  args = ["${{ contains(github.event.head_commit.message, '[skip ci]') }}", "${{ steps[0].outputs.x == 'it''s' && !env.CI }}"]
+  env = {
+    A = "${{ secrets.TOKEN }}"
+  }
+}
+`
+	_, err := Parse(strings.NewReader(src))
+	require.NoError(t, err)
+
+	src = `action "a" {
+  uses = "./a"
+  runs = "echo ${HOME"
+  args = ["${1x}", "${{ github. }}", "${{ 'x }}", "${{ vars.x || job.status }}"]
+  env = {
+    A = "${{ github.sha }"
+    B = "${{ }}"
+  }
+}
+`
+	_, diags, err := ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, diags, 7)
+	assert.Equal(t, "Line 3: Unterminated variable reference `${HOME' in `runs' of action `a' [E_EXPRESSION_SYNTAX]", diags[0].Error())
+	assert.Equal(t, "Line 4: Malformed variable reference `${1x}' in `args' of action `a' [E_EXPRESSION_SYNTAX]", diags[1].Error())
+	assert.Equal(t, "Line 4: Malformed expression `${{ github. }}' in `args' of action `a': unexpected end of expression [E_EXPRESSION_SYNTAX]", diags[2].Error())
+	assert.Equal(t, 20, diags[2].Pos.Column)
+	assert.Equal(t, "Line 4: Unterminated expression `${{ 'x }}' in `args' of action `a' [E_EXPRESSION_SYNTAX]", diags[3].Error())
+	assert.Equal(t, "Line 4: Unknown context `vars' in `args' of action `a' [W_UNKNOWN_CONTEXT]", diags[4].Error())
+	assert.Equal(t, Severity(WARNING), diags[4].Severity)
+	assert.Equal(t, "Line 6: Unterminated expression `${{ github.sha }' in `env.A' of action `a' [E_EXPRESSION_SYNTAX]", diags[5].Error())
+	assert.Equal(t, "Line 7: Malformed expression `${{ }}' in `env.B' of action `a': empty expression [E_EXPRESSION_SYNTAX]", diags[6].Error())
+
+	_, err = Parse(strings.NewReader(src), WithoutChecks(CheckExpressions))
+	require.NoError(t, err)
+}
+
+func TestParseExpression(t *testing.T) {
+	refs, err := parseExpression("format('{0}', github.ref) != '' || success() && matrix.os[1] >= -1.5e3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github", "matrix"}, refs.contexts)
+	assert.Equal(t, []string{"format", "success"}, refs.functions)
+
+	for s, msg := range map[string]string{
+		"a b":          "unexpected `b'",
+		"(a":           "unexpected end of expression",
+		"f(a,)":        "unexpected `)'",
+		"a.1":          "unexpected `1'",
+		"'unfinished":  "unterminated string",
+		"a == 'b":      "unterminated string",
+		"a[0":          "unexpected end of expression",
+		"a ==":         "unexpected end of expression",
+		"github.sha ?": "unexpected `?'",
+	} {
+		_, err := parseExpression(s)
+		if assert.Error(t, err, s) {
+			assert.Equal(t, msg, err.Error(), s)
+		}
+	}
+}
+
+func TestCondition(t *testing.T) {
+	src := `version = 1
+workflow "ci" {
+  on = "push"
+  resolves = ["a", "b"]
+}
+action "a" {
+  uses = "./a"
+  if = "github.ref == 'refs/heads/master' && Success()"
+}
+action "b" {
+  uses = "./b"
+  if = "${{ always() }}"
+}
+`
+	config, err := Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, "github.ref == 'refs/heads/master' && Success()", config.Actions[0].If)
+	assert.Equal(t, 8, config.Actions[0].AttributePos("if").Line)
+	assert.Equal(t, "${{ always() }}", config.Actions[1].If)
+
+	src = `version = 1
+action "a" {
+  uses = "./a"
+  if = "startsWith(github.ref, 'refs/tags/') || sometimes(vars.x)"
+}
+action "b" {
+  uses = "./b"
+  if = "github.ref =="
+}
+action "c" {
+  uses = "./c"
+  if = ""
+}
+`
+	_, diags, err := ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, diags, 4)
+	assert.Equal(t, "Line 4: Unknown context `vars' in `if' of action `a' [W_UNKNOWN_CONTEXT]", diags[0].Error())
+	assert.Equal(t, "Line 4: Unknown function `sometimes' in `if' of action `a' [W_UNKNOWN_FUNCTION]", diags[1].Error())
+	assert.Equal(t, "Line 8: Malformed condition `github.ref ==' in `if' of action `b': unexpected end of expression [E_EXPRESSION_SYNTAX]", diags[2].Error())
+	assert.Equal(t, "Line 12: `if' value in action `c' cannot be blank [E_BLANK_VALUE]", diags[3].Error())
+}
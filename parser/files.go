@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// ParseFile parses the .workflow file at path.  It is like Parse, except
+// that the File of each position, in diagnostics and in the model, is set
+// to path.
+func ParseFile(path string, options ...OptionFunc) (*model.Configuration, error) {
+	return ParseFiles([]string{path}, options...)
+}
+
+// ParseReader parses a .workflow file read from r.  It is like Parse,
+// except that the File of each position, in diagnostics and in the
+// model, is set to name, e.g., `<stdin>'.
+func ParseReader(name string, r io.Reader, options ...OptionFunc) (*model.Configuration, error) {
+	ctx := context.Background()
+	config, errors, err := parseWithDiagnostics(ctx, []source{{name: name, r: r}}, options...)
+	return parseResult(ctx, config, errors, err)
+}
+
+// ParseFiles parses several .workflow files as a single configuration,
+// holding the actions and workflows of all of them, in order.  Actions
+// can need, and workflows resolve, actions in any of the files, and
+// identifiers must be unique across all of them.  As with ParseFile, the
+// File of each position is set to the path of the file it is in.  Files
+// named by `include' statements are read relative to the directory of
+// the file that includes them, and come after the files before them.
+func ParseFiles(paths []string, options ...OptionFunc) (*model.Configuration, error) {
+	sources := make([]source, 0, len(paths))
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		dir := filepath.Dir(path)
+		sources = append(sources, source{name: path, r: file, fsys: os.DirFS(dir), dir: ".", base: dir})
+	}
+
+	ctx := context.Background()
+	config, errors, err := parseWithDiagnostics(ctx, sources, options...)
+	return parseResult(ctx, config, errors, err)
+}
+
+// FileResult is the result of parsing one of the files found by
+// ParseDir or ParseFS.  Config and Err are as returned by ParseFile.
+type FileResult struct {
+	Path   string
+	Config *model.Configuration
+	Err    error
+}
+
+// ParseDir parses every .workflow file in dir and its subdirectories, as
+// found by DiscoverWorkflows.  It returns a result for each file, in
+// lexical order by path.  The error is not nil only if dir could not be
+// walked; problems with individual files are in their results.
+func ParseDir(dir string, options ...OptionFunc) ([]FileResult, error) {
+	paths, err := DiscoverWorkflows(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]FileResult, 0, len(paths))
+	for _, path := range paths {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		config, err := ParseFile(path, options...)
+		ret = append(ret, FileResult{Path: path, Config: config, Err: err})
+	}
+	return ret, nil
+}
+
+// ParseFS parses .workflow files in fsys, like a repository snapshot, an
+// embedded directory, or a zip archive, without touching the operating
+// system's filesystem.  It parses the files matching any of the patterns,
+// in the syntax of fs.Glob, or every file found by DiscoverWorkflows if
+// there are none.  Each file is parsed on its own, along with the files
+// its `include' statements name, which are read from fsys, and the File
+// of each position is set to its slash-separated path in fsys.  It returns a
+// result for each file, in lexical order by path.  The error is not nil
+// only if a pattern is malformed or fsys could not be walked.
+func ParseFS(fsys fs.FS, patterns []string, options ...OptionFunc) ([]FileResult, error) {
+	var paths []string
+	if len(patterns) == 0 {
+		var err error
+		if paths, err = DiscoverWorkflows(fsys); err != nil {
+			return nil, err
+		}
+	} else {
+		seen := make(map[string]bool)
+		for _, pattern := range patterns {
+			matches, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range matches {
+				if !seen[name] {
+					seen[name] = true
+					paths = append(paths, name)
+				}
+			}
+		}
+		sort.Strings(paths)
+	}
+
+	ret := make([]FileResult, 0, len(paths))
+	for _, name := range paths {
+		config, err := parseFSFile(fsys, name, options...)
+		ret = append(ret, FileResult{Path: name, Config: config, Err: err})
+	}
+	return ret, nil
+}
+
+func parseFSFile(fsys fs.FS, name string, options ...OptionFunc) (*model.Configuration, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ctx := context.Background()
+	src := source{name: name, r: file, fsys: fsys, dir: path.Dir(name)}
+	config, errors, err := parseWithDiagnostics(ctx, []source{src}, options...)
+	return parseResult(ctx, config, errors, err)
+}
+
+// DiscoverWorkflows returns the slash-separated paths of every .workflow
+// file in fsys, like .github/main.workflow, in lexical order.  It looks
+// in every directory, including hidden ones like .github, except .git.
+func DiscoverWorkflows(fsys fs.FS) ([]string, error) {
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" && name != "." {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(name) == ".workflow" {
+			paths = append(paths, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,242 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates a temporary directory holding the given files, keyed
+// by slash-separated path, and returns its name.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "parser")
+	require.NoError(t, err)
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestParseFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"good.workflow": `action "a" { uses = "./a" }`,
+		"bad.workflow":  "action \"a\" {\n  uses = \"./a\"\n  needs = \"b\"\n}",
+	})
+	defer os.RemoveAll(dir)
+
+	config, err := ParseFile(filepath.Join(dir, "good.workflow"))
+	require.NoError(t, err)
+	assert.Len(t, config.Actions, 1)
+
+	bad := filepath.Join(dir, "bad.workflow")
+	config, err = ParseFile(bad)
+	assert.Nil(t, config)
+	pe := extractParserError(t, err)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, bad, pe.Errors[0].Pos.File)
+	assert.Equal(t, 3, pe.Errors[0].Pos.Line)
+	assert.Equal(t, bad+": Line 3: Action `a' needs nonexistent action `b' [E_UNKNOWN_NEEDS]", pe.Errors[0].Error())
+
+	_, err = ParseFile(filepath.Join(dir, "missing.workflow"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParseReader(t *testing.T) {
+	_, err := ParseReader("<stdin>", strings.NewReader("action \"a\" {\n  uses = \"./a\"\n  needs = \"b\"\n}"))
+	pe := extractParserError(t, err)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, "<stdin>: Line 3: Action `a' needs nonexistent action `b' [E_UNKNOWN_NEEDS]", pe.Errors[0].Error())
+
+	config, err := ParseReader("<stdin>", strings.NewReader(`action "a" { uses = "./a" }`))
+	require.NoError(t, err)
+	assert.Equal(t, "<stdin>", config.Actions[0].Pos.File)
+}
+
+func TestParseDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".github/main.workflow":  `action "a" { uses = "./a" }`,
+		".github/other.workflow": `action "a" { uses = 1 }`,
+		"sub/third.workflow":     `workflow "w" { on = "push" }`,
+		".git/ignored.workflow":  `action "a" {`,
+		"README.md":              `not a workflow`,
+	})
+	defer os.RemoveAll(dir)
+
+	results, err := ParseDir(dir)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, filepath.Join(dir, ".github", "main.workflow"), results[0].Path)
+	assert.NoError(t, results[0].Err)
+	assert.Len(t, results[0].Config.Actions, 1)
+
+	assert.Equal(t, filepath.Join(dir, ".github", "other.workflow"), results[1].Path)
+	pe := extractParserError(t, results[1].Err)
+	assert.Equal(t, results[1].Path, pe.Errors[0].Pos.File)
+
+	assert.Equal(t, filepath.Join(dir, "sub", "third.workflow"), results[2].Path)
+	assert.NoError(t, results[2].Err)
+
+	_, err = ParseDir(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestDiscoverWorkflows(t *testing.T) {
+	fsys := fstest.MapFS{
+		".github/main.workflow":    {Data: []byte(`action "a" { uses = "./a" }`)},
+		"services/api/ci.workflow": {},
+		"services/api.workflow":    {},
+		".git/ignored.workflow":    {},
+		"README.md":                {},
+	}
+	paths, err := DiscoverWorkflows(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".github/main.workflow", "services/api.workflow", "services/api/ci.workflow"}, paths)
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".github/main.workflow": {Data: []byte(`action "a" { uses = "./a" }`)},
+		"ci/bad.workflow":       {Data: []byte("action \"a\" {\n  uses = \"./a\"\n  needs = \"b\"\n}")},
+		"ci/other.txt":          {Data: []byte(`not a workflow`)},
+	}
+
+	results, err := ParseFS(fsys, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, ".github/main.workflow", results[0].Path)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, ".github/main.workflow", results[0].Config.Actions[0].Pos.File)
+	assert.Equal(t, "ci/bad.workflow", results[1].Path)
+	pe := extractParserError(t, results[1].Err)
+	assert.Equal(t, "ci/bad.workflow: Line 3: Action `a' needs nonexistent action `b' [E_UNKNOWN_NEEDS]", pe.Errors[0].Error())
+
+	results, err = ParseFS(fsys, []string{"ci/*", "*/bad.workflow"}, WithSuppressErrors())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "ci/bad.workflow", results[0].Path)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "ci/other.txt", results[1].Path)
+	assert.Error(t, results[1].Err)
+
+	_, err = ParseFS(fsys, []string{"["})
+	assert.Error(t, err)
+}
+
+func TestParseFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.workflow":      `workflow "w" { on = "push" resolves = "deploy" }`,
+		"b.workflow":      "action \"build\" { uses = \"./build\" }\naction \"deploy\" {\n  uses = \"./deploy\"\n  needs = \"build\"\n}",
+		"dup.workflow":    "\naction \"build\" { uses = \"./other\" }",
+		"broken.workflow": `action "a" {`,
+		"0.workflow":      `workflow "x" { on = "push" resolves = "nope" }`,
+		"v1.workflow":     "version = 1\naction \"v1\" {\n  uses = \"./v1\"\n  if = \"success()\"\n}",
+		"v0.workflow":     "action \"v0\" {\n  uses = \"./v0\"\n  if = \"success()\"\n}",
+	})
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.workflow"), filepath.Join(dir, "b.workflow")
+
+	config, err := ParseFiles([]string{a, b})
+	require.NoError(t, err)
+	assert.Len(t, config.Workflows, 1)
+	assert.Len(t, config.Actions, 2)
+	assert.Equal(t, b, config.GetAction("deploy").Pos.File)
+	assert.Equal(t, 2, config.GetAction("deploy").Pos.Line)
+	assert.Equal(t, a, config.Workflows[0].AttributePos("resolves").File)
+
+	dup := filepath.Join(dir, "dup.workflow")
+	config, err = ParseFiles([]string{dup, a, b})
+	assert.Nil(t, config)
+	pe := extractParserError(t, err)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, b, pe.Errors[0].Pos.File)
+	assert.Equal(t, 1, pe.Errors[0].Pos.Line)
+	assert.Equal(t, CodeIdentifierRedefined, pe.Errors[0].Code)
+	assert.Equal(t, "Identifier `build' redefined, first defined in "+dup, pe.Errors[0].Message())
+	assert.Len(t, pe.Actions, 3)
+
+	// Diagnostics are sorted by file, then by line.
+	zero := filepath.Join(dir, "0.workflow")
+	_, err = ParseFiles([]string{dup, b, zero}, WithoutChecks(CheckUnused))
+	pe = extractParserError(t, err)
+	require.Len(t, pe.Errors, 2)
+	assert.Equal(t, zero, pe.Errors[0].Pos.File)
+	assert.Equal(t, CodeUnknownResolves, pe.Errors[0].Code)
+	assert.Equal(t, b, pe.Errors[1].Pos.File)
+	assert.Equal(t, CodeIdentifierRedefined, pe.Errors[1].Code)
+
+	broken := filepath.Join(dir, "broken.workflow")
+	_, err = ParseFiles([]string{a, broken})
+	pe = extractParserError(t, err)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, broken, pe.Errors[0].Pos.File)
+	assert.Equal(t, Severity(FATAL), pe.Errors[0].Severity)
+
+	// Each file declares its own version.
+	v0, v1 := filepath.Join(dir, "v0.workflow"), filepath.Join(dir, "v1.workflow")
+	_, err = ParseFiles([]string{v1, v0}, WithoutChecks(CheckUnused))
+	pe = extractParserError(t, err)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, v0, pe.Errors[0].Pos.File)
+	assert.Equal(t, CodeRequiresVersion, pe.Errors[0].Code)
+	assert.Equal(t, v0, pe.Errors[0].Fix.Edits[0].Start.File)
+}
+
+func TestInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		".github/main.workflow":      {Data: []byte("include = [\"./ci/*.workflow\", \"*.workflow\"]\n\nworkflow \"ci\" {\n  on = \"push\"\n  resolves = \"deploy\"\n}\n")},
+		".github/ci/build.workflow":  {Data: []byte("action \"build\" {\n  uses = \"./build\"\n}\n")},
+		".github/ci/deploy.workflow": {Data: []byte("include = \"build.workflow\"\n\naction \"deploy\" {\n  uses = \"./deploy\"\n  needs = [\"build\", \"nope\"]\n}\n")},
+	}
+
+	results, err := ParseFS(fsys, []string{".github/main.workflow"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	pe := extractParserError(t, results[0].Err)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, ".github/ci/deploy.workflow: Line 5: Action `deploy' needs nonexistent action `nope' [E_UNKNOWN_NEEDS]", pe.Errors[0].Error())
+	require.Len(t, pe.Actions, 2)
+	assert.Equal(t, ".github/ci/build.workflow", pe.Actions[0].Pos.File)
+	assert.Equal(t, "deploy", pe.Actions[1].Identifier)
+
+	fsys[".github/ci/deploy.workflow"].Data = []byte("action \"deploy\" {\n  uses = \"./deploy\"\n  needs = \"build\"\n}\n")
+	config, err := ParseReader(".github/main.workflow", strings.NewReader(string(fsys[".github/main.workflow"].Data)), WithIncludeFS(fsys))
+	require.NoError(t, err)
+	assert.Len(t, config.Actions, 2)
+	assert.Len(t, config.Workflows, 1)
+
+	dir := writeFiles(t, map[string]string{
+		"main.workflow":      "include = \"lib/*.workflow\"\naction \"a\" {\n  uses = \"./a\"\n  needs = \"b\"\n}\n",
+		"lib/b.workflow":     "action \"b\" {\n  uses = \"./b\"\n}\n",
+		"broken.workflow":    "include = [\"none/*.workflow\", \"../x.workflow\", \"[\", 1]\n",
+		"lib/other.txt":      "not a workflow",
+		"lib/empty.workflow": "",
+	})
+	defer os.RemoveAll(dir)
+
+	config, err = ParseFile(filepath.Join(dir, "main.workflow"))
+	require.NoError(t, err)
+	require.Len(t, config.Actions, 2)
+	assert.Equal(t, filepath.Join(dir, "lib", "b.workflow"), config.Actions[1].Pos.File)
+
+	_, diags, err := ParseWithDiagnostics(strings.NewReader(`include = "x.workflow"`))
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Line 1: Cannot include `x.workflow' without a file system to read it from; see WithIncludeFS [E_INCLUDE_FAILED]", diags[0].Error())
+
+	_, err = ParseFile(filepath.Join(dir, "broken.workflow"))
+	pe = extractParserError(t, err)
+	require.Len(t, pe.Errors, 4)
+	assert.Equal(t, "Pattern `none/*.workflow' in `include' matches no files", pe.Errors[0].Message())
+	assert.Equal(t, "Pattern `../x.workflow' in `include' is outside the directory files are included from", pe.Errors[1].Message())
+	assert.Equal(t, "Malformed pattern `[' in `include': syntax error in pattern", pe.Errors[2].Message())
+	assert.Equal(t, "Expected string, got number", pe.Errors[3].Message())
+}
@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// Fix is a suggested change to a .workflow file that resolves an error,
+// e.g., renaming a misspelled attribute.
+type Fix struct {
+	// Title describes the fix, e.g., "Rename `need' to `needs'".
+	Title string
+	Edits []TextEdit
+}
+
+// TextEdit replaces the text from Start up to, but not including, End
+// with NewText.  Start and End carry both byte offsets and line/column
+// positions.
+type TextEdit struct {
+	Start   ErrorPos
+	End     ErrorPos
+	NewText string
+}
+
+var actionAttributes = []string{"description", "extends", "uses", "needs", "if", "runs", "args", "env", "matrix", "secrets", "continue_on_error"}
+var workflowAttributes = []string{"description", "on", "resolves", "env"}
+
+// addUnknownAttribute warns about an unknown attribute in an action or
+// workflow block.  If the attribute looks like a misspelling of a known
+// one, the warning carries a Fix that renames it.
+func (p *Parser) addUnknownAttribute(key *ast.ObjectKey, val ast.Node, nodeType, name string, known []string) {
+	var e *ParseError
+	if nodeType == "action" {
+		e = p.addWarning(val, CodeUnknownAttribute, "Unknown action attribute `%s'", name)
+	} else {
+		e = p.addWarning(val, CodeUnknownAttribute, "Unknown workflow attribute `%s'", name)
+	}
+	if e == nil || name == "" {
+		return
+	}
+
+	if suggestion := closestMatch(name, known); suggestion != "" {
+		e.Fix = renameKey(key, suggestion)
+	}
+}
+
+// renameKey returns a Fix that replaces the key of an attribute,
+// preserving its quoting.
+func renameKey(key *ast.ObjectKey, newName string) *Fix {
+	text := key.Token.Text
+	start, end := posFromToken(key.Token), tokenEnd(key.Token)
+
+	newText := newName
+	if strings.HasPrefix(text, `"`) {
+		newText = `"` + newName + `"`
+	}
+
+	return &Fix{
+		Title: "Rename `" + strings.Trim(text, `"`) + "' to `" + newName + "'",
+		Edits: []TextEdit{{Start: start, End: end, NewText: newText}},
+	}
+}
+
+// closestMatch returns the candidate that name is most likely a
+// misspelling of, or "" if none is close enough.
+func closestMatch(name string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(name), c)
+		if d < bestDist && d*2 <= len(name) {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j] + 1
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+			if prev[j-1]+cost < cur[j] {
+				cur[j] = prev[j-1] + cost
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// quoteKey returns a Fix that puts double quotes around an unquoted
+// block identifier.
+func quoteKey(key *ast.ObjectKey) *Fix {
+	text := key.Token.Text
+	return &Fix{
+		Title: "Quote identifier `" + text + "'",
+		Edits: []TextEdit{{Start: posFromToken(key.Token), End: tokenEnd(key.Token), NewText: `"` + text + `"`}},
+	}
+}
+
+// removeAttribute returns a Fix that deletes an attribute, from the start
+// of its key to the end of its value.
+func removeAttribute(key *ast.ObjectKey, val ast.Node) *Fix {
+	literal, ok := val.(*ast.LiteralType)
+	if !ok {
+		return nil
+	}
+	name := strings.Trim(key.Token.Text, `"`)
+	return &Fix{
+		Title: "Remove blank `" + name + "'",
+		Edits: []TextEdit{{Start: posFromToken(key.Token), End: tokenEnd(literal.Token)}},
+	}
+}
+
+// removeListElement returns a Fix that deletes the i'th element of a
+// list, along with the comma before it.  The list must have n elements,
+// all literals, and i must be at least 1; otherwise, it returns nil.
+func removeListElement(node ast.Node, n, i int, title string) *Fix {
+	list, ok := node.(*ast.ListType)
+	if !ok || len(list.List) != n || i < 1 || i >= n {
+		return nil
+	}
+	prev, ok1 := list.List[i-1].(*ast.LiteralType)
+	elem, ok2 := list.List[i].(*ast.LiteralType)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	return &Fix{
+		Title: title,
+		Edits: []TextEdit{{Start: tokenEnd(prev.Token), End: tokenEnd(elem.Token)}},
+	}
+}
+
+// tokenEnd returns the position just past the end of t.
+func tokenEnd(t token.Token) ErrorPos {
+	pos := posFromToken(t)
+	for _, c := range t.Text {
+		if c == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	pos.Offset += len(t.Text)
+	return pos
+}
+
+// ApplyFixes applies the edits of the given fixes to src, returning the
+// result and the number of fixes applied.  Each fix is applied entirely
+// or not at all: a fix with an edit that overlaps an edit of an earlier
+// fix in the list is skipped, as is a fix with an edit outside src.
+// Parsing the result again may turn up further fixes.
+func ApplyFixes(src []byte, fixes []*Fix) ([]byte, int) {
+	var edits []TextEdit
+	applied := 0
+	for _, fix := range fixes {
+		if fix == nil || !canApply(fix, edits, len(src)) {
+			continue
+		}
+		edits = append(edits, fix.Edits...)
+		applied++
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Start.Offset < edits[j].Start.Offset })
+	var buf bytes.Buffer
+	buf.Grow(len(src))
+	last := 0
+	for _, e := range edits {
+		buf.Write(src[last:e.Start.Offset]) // nolint: errcheck
+		buf.WriteString(e.NewText)          // nolint: errcheck
+		last = e.End.Offset
+	}
+	buf.Write(src[last:]) // nolint: errcheck
+	return buf.Bytes(), applied
+}
+
+// canApply reports whether every edit of fix is within a source of
+// length n and doesn't overlap any of the accepted edits, or another edit
+// of fix.
+func canApply(fix *Fix, accepted []TextEdit, n int) bool {
+	for i, e := range fix.Edits {
+		if e.Start.Offset < 0 || e.Start.Offset > e.End.Offset || e.End.Offset > n {
+			return false
+		}
+		for _, a := range accepted {
+			if overlaps(e, a) {
+				return false
+			}
+		}
+		for _, other := range fix.Edits[:i] {
+			if overlaps(e, other) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// overlaps reports whether two edits touch the same text.  Two
+// insertions at the same offset overlap, since their order would be
+// ambiguous.
+func overlaps(a, b TextEdit) bool {
+	if a.Start.Offset == b.Start.Offset {
+		return true
+	}
+	return a.Start.Offset < b.End.Offset && b.Start.Offset < a.End.Offset
+}
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownAttributeFix(t *testing.T) {
+	workflow, err := parseString(`
+action "a" {
+  uses = "./x"
+  need = "b"
+  "secret" = ["X"]
+  bananas = "yes"
+}
+action "b" { uses = "./y" }
+workflow "w" {
+  on = "push"
+  resolve = "a"
+}`)
+	assertParseError(t, err, 2, 1, workflow,
+		"line 4: unknown action attribute `need'",
+		"line 5: unknown action attribute `secret'",
+		"line 6: unknown action attribute `bananas'",
+		"line 11: unknown workflow attribute `resolve'")
+	pe := extractParserError(t, err)
+
+	require.NotNil(t, pe.Errors[0].Fix)
+	assert.Equal(t, &Fix{
+		Title: "Rename `need' to `needs'",
+		Edits: []TextEdit{{
+			Start:   ErrorPos{Line: 4, Column: 3, Offset: 31},
+			End:     ErrorPos{Line: 4, Column: 7, Offset: 35},
+			NewText: "needs",
+		}},
+	}, pe.Errors[0].Fix)
+
+	require.NotNil(t, pe.Errors[1].Fix)
+	assert.Equal(t, "Rename `secret' to `secrets'", pe.Errors[1].Fix.Title)
+	assert.Equal(t, `"secrets"`, pe.Errors[1].Fix.Edits[0].NewText)
+	assert.Equal(t, 11, pe.Errors[1].Fix.Edits[0].End.Column)
+
+	assert.Nil(t, pe.Errors[2].Fix)
+
+	require.NotNil(t, pe.Errors[3].Fix)
+	assert.Equal(t, "resolves", pe.Errors[3].Fix.Edits[0].NewText)
+}
+
+func TestClosestMatch(t *testing.T) {
+	assert.Equal(t, "needs", closestMatch("need", actionAttributes))
+	assert.Equal(t, "uses", closestMatch("USE", actionAttributes))
+	assert.Equal(t, "args", closestMatch("arg", actionAttributes))
+	assert.Equal(t, "", closestMatch("x", actionAttributes))
+	assert.Equal(t, "", closestMatch("environment", actionAttributes))
+	assert.Equal(t, "", closestMatch("bar", workflowAttributes))
+}
+
+func TestMoreFixes(t *testing.T) {
+	src := `action a {
+  uses = "./x"
+}
+action "b" {
+  uses = "./y"
+  runs = ""
+  secrets = ["A", "B", "A"]
+}
+`
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+
+	require.NotNil(t, errs[0].Fix)
+	assert.Equal(t, "Quote identifier `a'", errs[0].Fix.Title)
+	require.NotNil(t, errs[1].Fix)
+	assert.Equal(t, "Remove blank `runs'", errs[1].Fix.Title)
+	require.NotNil(t, errs[2].Fix)
+	assert.Equal(t, "Remove duplicate secret `A'", errs[2].Fix.Title)
+
+	fixed, n := ApplyFixes([]byte(src), []*Fix{errs[0].Fix, errs[1].Fix, errs[2].Fix})
+	assert.Equal(t, 3, n)
+	assert.Equal(t, `action "a" {
+  uses = "./x"
+}
+action "b" {
+  uses = "./y"
+  
+  secrets = ["A", "B"]
+}
+`, string(fixed))
+
+	_, errs, err = ParseWithDiagnostics(bytes.NewReader(fixed))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+}
+
+func TestApplyFixesOverlap(t *testing.T) {
+	src := []byte("abcdef")
+	edit := func(start, end int, text string) *Fix {
+		return &Fix{Edits: []TextEdit{{Start: ErrorPos{Offset: start}, End: ErrorPos{Offset: end}, NewText: text}}}
+	}
+
+	fixed, n := ApplyFixes(src, []*Fix{edit(4, 6, "X"), edit(0, 1, "Y"), edit(5, 6, "Z"), nil, edit(3, 9, "W")})
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "YbcdX", string(fixed))
+
+	fixed, n = ApplyFixes(src, []*Fix{edit(2, 2, "1"), edit(2, 2, "2")})
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "ab1cdef", string(fixed))
+
+	both := &Fix{Edits: []TextEdit{edit(0, 2, "").Edits[0], edit(1, 3, "").Edits[0]}}
+	fixed, n = ApplyFixes(src, []*Fix{both})
+	assert.Equal(t, 0, n)
+	assert.Equal(t, "abcdef", string(fixed))
+}
@@ -0,0 +1,347 @@
+package parser
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+	hcl2 "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Syntax is a version of the HCL syntax that .workflow files are written
+// in.
+type Syntax int
+
+const (
+	// HCL is the original HCL syntax, which every .workflow file has
+	// used so far.
+	HCL Syntax = iota
+
+	// HCL2 is the newer syntax of hashicorp/hcl/v2, which allows
+	// unquoted block labels, and expressions like "a-${"b"}" or 1 + 1,
+	// as long as they don't use variables or functions.
+	HCL2
+)
+
+// WithSyntax parses files written in HCL, rather than JSON, with the
+// given syntax.  Either way, a file produces the same model, and goes
+// through the same checks.
+//
+// An HCL2 file is always parsed whole, so WithSyntaxRecovery has no effect
+// on it, and neither do the comments in it: the parser doesn't keep them.
+func WithSyntax(s Syntax) OptionFunc {
+	return func(ps *Parser) {
+		ps.syntax = s
+	}
+}
+
+// parseHCL2 parses src, in HCL2 syntax, into the AST the same file would
+// have in HCL.  A block with labels, like `action build {', is a block
+// with the same identifier, quoted or not; a block without any, like
+// `env {', is an assignment of an object.  Expressions are evaluated, so
+// `args = ["a-${"b"}", 1 + 1]' is the same as `args = ["a-b", 2]'.
+//
+// Syntax errors, and expressions that can't be evaluated, are
+// *hclparser.PosError, as they are for HCL.
+func parseHCL2(src []byte) (*ast.File, error) {
+	p := &hcl2Parser{src: src, lines: []int{0}}
+	for i, c := range src {
+		if c == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, "", hcl2.InitialPos)
+	if err := p.diagError(diags); err != nil {
+		return nil, err
+	}
+	list, err := p.body(file.Body.(*hclsyntax.Body))
+	if err != nil {
+		return nil, err
+	}
+	return &ast.File{Node: list}, nil
+}
+
+// hcl2Parser converts an hclsyntax AST into an HCL one.
+type hcl2Parser struct {
+	src   []byte
+	lines []int // the offset at which each line starts
+}
+
+// pos returns the position of the byte at off.
+func (p *hcl2Parser) pos(off int) token.Pos {
+	line := sort.Search(len(p.lines), func(i int) bool { return p.lines[i] > off }) - 1
+	return token.Pos{
+		Offset: off,
+		Line:   line + 1,
+		Column: utf8.RuneCount(p.src[p.lines[line]:off]) + 1,
+	}
+}
+
+func (p *hcl2Parser) errorf(off int, format string, a ...interface{}) error {
+	return &hclparser.PosError{Pos: p.pos(off), Err: fmt.Errorf(format, a...)}
+}
+
+// diagError returns the first error in diags, if there is one.
+func (p *hcl2Parser) diagError(diags hcl2.Diagnostics) error {
+	for _, d := range diags {
+		if d.Severity != hcl2.DiagError {
+			continue
+		}
+		off := 0
+		if d.Subject != nil {
+			off = d.Subject.Start.Byte
+		}
+		if d.Detail == "" {
+			return p.errorf(off, "%s", d.Summary)
+		}
+		return p.errorf(off, "%s; %s", d.Summary, strings.TrimSuffix(d.Detail, "."))
+	}
+	return nil
+}
+
+// body converts the attributes and blocks of b, in the order they appear
+// in the source.
+func (p *hcl2Parser) body(b *hclsyntax.Body) (*ast.ObjectList, error) {
+	type entry struct {
+		off  int
+		attr *hclsyntax.Attribute
+		blk  *hclsyntax.Block
+	}
+	var entries []entry
+	for _, attr := range b.Attributes {
+		entries = append(entries, entry{off: attr.SrcRange.Start.Byte, attr: attr})
+	}
+	for _, blk := range b.Blocks {
+		entries = append(entries, entry{off: blk.TypeRange.Start.Byte, blk: blk})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].off < entries[j].off })
+
+	list := &ast.ObjectList{}
+	for _, e := range entries {
+		var item *ast.ObjectItem
+		var err error
+		if e.attr != nil {
+			item, err = p.attribute(e.attr)
+		} else {
+			item, err = p.block(e.blk)
+		}
+		if err != nil {
+			return nil, err
+		}
+		list.Add(item)
+	}
+	return list, nil
+}
+
+func (p *hcl2Parser) attribute(attr *hclsyntax.Attribute) (*ast.ObjectItem, error) {
+	val, err := p.expr(attr.Expr)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.ObjectItem{
+		Keys:   []*ast.ObjectKey{{Token: p.ident(attr.Name, attr.NameRange)}},
+		Assign: p.pos(attr.EqualsRange.Start.Byte),
+		Val:    val,
+	}, nil
+}
+
+func (p *hcl2Parser) block(blk *hclsyntax.Block) (*ast.ObjectItem, error) {
+	list, err := p.body(blk.Body)
+	if err != nil {
+		return nil, err
+	}
+	item := &ast.ObjectItem{
+		Keys: []*ast.ObjectKey{{Token: p.ident(blk.Type, blk.TypeRange)}},
+		Val: &ast.ObjectType{
+			Lbrace: p.pos(blk.OpenBraceRange.Start.Byte),
+			Rbrace: p.pos(blk.CloseBraceRange.Start.Byte),
+			List:   list,
+		},
+	}
+	if len(blk.Labels) == 0 {
+		// There's no `=' to point at, so the object is assigned where
+		// it starts.
+		item.Assign = p.pos(blk.OpenBraceRange.Start.Byte)
+	}
+	for i, label := range blk.Labels {
+		// Like identifiers in HCL, labels are taken literally, so the
+		// key gets the quotes HCL would have around it.
+		r := blk.LabelRanges[i]
+		text := string(r.SliceBytes(p.src))
+		if !strings.HasPrefix(text, `"`) {
+			text = `"` + label + `"`
+		}
+		item.Keys = append(item.Keys, &ast.ObjectKey{Token: token.Token{Type: token.STRING, Pos: p.pos(r.Start.Byte), Text: text}})
+	}
+	return item, nil
+}
+
+func (p *hcl2Parser) ident(name string, r hcl2.Range) token.Token {
+	return token.Token{Type: token.IDENT, Pos: p.pos(r.Start.Byte), Text: name}
+}
+
+// expr converts an expression.  Lists and objects keep the positions of
+// their elements; anything else is evaluated, and is a single literal.
+func (p *hcl2Parser) expr(e hclsyntax.Expression) (ast.Node, error) {
+	switch e := e.(type) {
+	case *hclsyntax.TupleConsExpr:
+		list := &ast.ListType{
+			Lbrack: p.pos(e.OpenRange.Start.Byte),
+			Rbrack: p.pos(e.SrcRange.End.Byte - 1),
+		}
+		for _, elem := range e.Exprs {
+			val, err := p.expr(elem)
+			if err != nil {
+				return nil, err
+			}
+			list.Add(val)
+		}
+		return list, nil
+
+	case *hclsyntax.ObjectConsExpr:
+		obj := &ast.ObjectType{
+			Lbrace: p.pos(e.OpenRange.Start.Byte),
+			Rbrace: p.pos(e.SrcRange.End.Byte - 1),
+			List:   &ast.ObjectList{},
+		}
+		for _, item := range e.Items {
+			key, err := p.key(item.KeyExpr)
+			if err != nil {
+				return nil, err
+			}
+			val, err := p.expr(item.ValueExpr)
+			if err != nil {
+				return nil, err
+			}
+			obj.List.Add(&ast.ObjectItem{
+				Keys:   []*ast.ObjectKey{{Token: key}},
+				Assign: p.pos(p.assign(item.KeyExpr.Range().End.Byte)),
+				Val:    val,
+			})
+		}
+		return obj, nil
+	}
+
+	v, diags := e.Value(nil)
+	if err := p.diagError(diags); err != nil {
+		return nil, err
+	}
+	r := e.Range()
+	return p.value(v, r.Start.Byte, string(r.SliceBytes(p.src)))
+}
+
+// key converts the key of an object item: an identifier if it is a bare
+// word, and a string otherwise.
+func (p *hcl2Parser) key(e hclsyntax.Expression) (token.Token, error) {
+	r := e.Range()
+	if word := hcl2.ExprAsKeyword(e); word != "" {
+		return p.ident(word, r), nil
+	}
+	v, diags := e.Value(nil)
+	if err := p.diagError(diags); err != nil {
+		return token.Token{}, err
+	}
+	if v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+		return token.Token{}, p.errorf(r.Start.Byte, "object keys must be strings")
+	}
+	return p.stringToken(v.AsString(), r.Start.Byte, string(r.SliceBytes(p.src))), nil
+}
+
+// assign returns the offset of the `=' or `:' after the key that ends at
+// off.
+func (p *hcl2Parser) assign(off int) int {
+	for i := off; i < len(p.src); i++ {
+		if p.src[i] == '=' || p.src[i] == ':' {
+			return i
+		}
+	}
+	return off
+}
+
+// value converts v, the value of the expression at off, written as text,
+// into a literal, or a list or object of them.
+func (p *hcl2Parser) value(v cty.Value, off int, text string) (ast.Node, error) {
+	if v.IsNull() || !v.IsKnown() {
+		return nil, p.errorf(off, "null is not a valid value")
+	}
+	pos := p.pos(off)
+	switch t := v.Type(); {
+	case t == cty.String:
+		s := v.AsString()
+		if strings.HasPrefix(text, "<<") {
+			// A heredoc keeps its lines if HCL reads it the same way.
+			heredoc := token.Token{Type: token.HEREDOC, Pos: pos, Text: text + "\n"}
+			if got, ok := heredoc.Value().(string); ok && got == s {
+				return &ast.LiteralType{Token: heredoc}, nil
+			}
+		}
+		return &ast.LiteralType{Token: p.stringToken(s, off, text)}, nil
+
+	case t == cty.Number:
+		bf := v.AsBigFloat()
+		if bf.IsInt() {
+			i, _ := bf.Int(new(big.Int))
+			return &ast.LiteralType{Token: token.Token{Type: token.NUMBER, Pos: pos, Text: i.String()}}, nil
+		}
+		return &ast.LiteralType{Token: token.Token{Type: token.FLOAT, Pos: pos, Text: bf.Text('g', -1)}}, nil
+
+	case t == cty.Bool:
+		return &ast.LiteralType{Token: token.Token{Type: token.BOOL, Pos: pos, Text: strconv.FormatBool(v.True())}}, nil
+
+	case t.IsListType() || t.IsTupleType() || t.IsSetType():
+		list := &ast.ListType{Lbrack: pos, Rbrack: pos}
+		for it := v.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			val, err := p.value(elem, off, "")
+			if err != nil {
+				return nil, err
+			}
+			list.Add(val)
+		}
+		return list, nil
+
+	case t.IsMapType() || t.IsObjectType():
+		obj := &ast.ObjectType{Lbrace: pos, Rbrace: pos, List: &ast.ObjectList{}}
+		for it := v.ElementIterator(); it.Next(); {
+			k, elem := it.Element()
+			val, err := p.value(elem, off, "")
+			if err != nil {
+				return nil, err
+			}
+			obj.List.Add(&ast.ObjectItem{
+				Keys:   []*ast.ObjectKey{{Token: p.stringToken(k.AsString(), off, "")}},
+				Assign: pos,
+				Val:    val,
+			})
+		}
+		return obj, nil
+	}
+	return nil, p.errorf(off, "%s is not a valid value", v.Type().FriendlyName())
+}
+
+// stringToken returns a STRING token for s, written as text.  The token's
+// text is the string as written, if HCL would unquote it the same way, so
+// that fixes can replace it; otherwise, like for "a-${"b"}", it is the
+// string quoted so that tokenString returns it exactly.
+func (p *hcl2Parser) stringToken(s string, off int, text string) token.Token {
+	t := token.Token{Type: token.STRING, Pos: p.pos(off), Text: text}
+	if text != "" && strings.HasPrefix(text, `"`) && tokenString(t) == s {
+		return t
+	}
+	t.Text = strconv.Quote(s)
+	if tokenString(t) != s {
+		// HCL copies `${...}' as is, without unquoting it, so only
+		// Go's unquoting gets s back.
+		t.JSON = true
+	}
+	return t
+}
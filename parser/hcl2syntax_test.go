@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHCL2(t *testing.T) {
+	hclSrc := `version = 1
+env = { GOPATH = "/go" }
+workflow "ci" {
+  on = "push"
+  resolves = ["test"]
+}
+action "build" {
+  uses = "docker://golang"
+  runs = <<EOF
+make \
+  build
+EOF
+}
+action "test" {
+  needs = "build"
+  uses = "./test"
+  args = ["-v", "-n2", "a\tb"]
+  env = { DIR = "a/b" }
+  secrets = ["TOKEN"]
+}
+`
+	hcl2Src := `version = 1
+env {
+  GOPATH = "/go"
+}
+workflow ci {
+  on = "push"
+  resolves = ["test"]
+}
+action build {
+  uses = "docker://golang"
+  runs = <<EOF
+make \
+  build
+EOF
+}
+action "test" {
+  needs = "build"
+  uses = "./${"test"}"
+  args = ["-v", "-n${1 + 1}", "a\tb"]
+  env = { "DIR": "a/b" }
+  secrets = ["TOKEN"]
+}
+`
+	want, err := parseString(hclSrc)
+	require.NoError(t, err)
+	config, err := parseString(hcl2Src, WithSyntax(HCL2))
+	require.NoError(t, err)
+	assert.True(t, want.Equal(config))
+	assert.Equal(t, &model.HeredocCommand{Value: "make \\\n  build", Marker: "EOF"}, config.GetAction("build").Runs)
+	assert.Equal(t, 16, config.GetAction("test").Pos.Line)
+	assert.Equal(t, 20, config.GetAction("test").AttributePos("env").Line)
+
+	// The same checks apply, at the position of what they're about.
+	config, err = parseString(`action a {
+  uses = "./a"
+  needs = ["b"]
+}
+action a {
+  uses = "./a"
+  bananas = 1
+}
+`, WithSyntax(HCL2))
+	assertParseError(t, err, 2, 0, config,
+		"line 3: action `a' needs nonexistent action `b'",
+		"line 5: identifier `a' redefined",
+		"line 7: unknown action attribute `bananas'")
+
+	for src, msg := range map[string]string{
+		"action a {\n  uses = \"./a\"\n": "Line 1: Unclosed configuration block; There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file [E_SYNTAX]",
+		"action a {\n  uses = b\n}":     "Line 2: Variables not allowed; Variables may not be used here [E_SYNTAX]",
+		"action a {\n  uses = null\n}":  "Line 2: null is not a valid value [E_SYNTAX]",
+	} {
+		_, errs, err := ParseWithDiagnostics(strings.NewReader(src), WithSyntax(HCL2), WithSyntaxRecovery())
+		require.NoError(t, err)
+		require.Len(t, errs, 1, src)
+		assert.Equal(t, msg, errs[0].Error(), src)
+	}
+
+	// HCL2 documents are never split into segments.
+	d := ParseDocument([]byte("action a {\n  uses = \"./a\"\n}\n"), WithSyntax(HCL2))
+	assert.Nil(t, d.segments)
+	assert.Empty(t, d.Errors)
+	require.Len(t, d.Config.Actions, 1)
+	assert.Equal(t, "a", d.Config.Actions[0].Identifier)
+}
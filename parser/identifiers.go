@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+// DefaultIdentifierPattern matches the identifiers the grammar in
+// language.md describes: a letter or `_', then letters, digits, and `_'.
+// The parser itself accepts any string, so pass it to
+// WithIdentifierPattern to hold files to the grammar.
+var DefaultIdentifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// WithMaxIdentifierLength reports as an error each action or workflow
+// whose identifier is longer than n characters, for systems that store
+// identifiers in fixed-size fields.  A limit of zero or less disables the
+// check.
+func WithMaxIdentifierLength(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxIdentifierLen = n
+	}
+}
+
+// WithIdentifierPattern reports as an error each action or workflow
+// whose identifier re doesn't match, e.g., DefaultIdentifierPattern.  Re
+// should be anchored with `^' and `$' to check the whole identifier.
+func WithIdentifierPattern(re *regexp.Regexp) OptionFunc {
+	return func(ps *Parser) {
+		ps.identifierPattern = re
+	}
+}
+
+// checkIdentifierFormat reports an identifier, found at node, that is
+// too long or doesn't match the pattern set by the options above.
+func (p *Parser) checkIdentifierFormat(node ast.Node, id string) {
+	if n := utf8.RuneCountInString(id); p.maxIdentifierLen > 0 && n > p.maxIdentifierLen {
+		p.addError(node, CodeIdentifierTooLong, "Identifier `%s' is %d characters long, more than the maximum of %d", id, n, p.maxIdentifierLen)
+	}
+	if p.identifierPattern != nil && !p.identifierPattern.MatchString(id) {
+		p.addError(node, CodeIdentifierPattern, "Identifier `%s' does not match `%s'", id, p.identifierPattern)
+	}
+}
+
+// WithCaseCollisionWarnings warns about actions and workflows whose
+// identifiers differ only in case, like `Build' and `build'.  They are
+// different identifiers to the parser, but easy to mix up, and some of
+// GitHub's pages don't tell them apart.  With WithSeparateNamespaces,
+// only identifiers of the same kind are compared.
+func WithCaseCollisionWarnings() OptionFunc {
+	return func(ps *Parser) {
+		ps.caseCollisions = true
+	}
+}
+
+// checkCaseCollisions warns about each action or workflow whose
+// identifier differs only in case from that of an earlier one.
+func (p *Parser) checkCaseCollisions() {
+	first := make(map[string]string, len(p.actions)+len(p.workflows))
+	check := func(subject interface{}, cmd, id string) {
+		key := strings.ToLower(id)
+		if p.separateNamespaces {
+			key = cmd + " " + key
+		}
+		prev, ok := first[key]
+		if !ok {
+			first[key] = id
+			return
+		}
+		if prev != id {
+			p.addWarning(identifierNode(p.posMap[subject]), CodeIdentifierCase,
+				"Identifier `%s' differs only in case from `%s'", id, prev)
+		}
+	}
+	for _, action := range p.actions {
+		check(action, "action", action.Identifier)
+	}
+	for _, workflow := range p.workflows {
+		check(workflow, "workflow", workflow.Identifier)
+	}
+}
+
+// identifierNode returns the identifier of a block, for errors about it,
+// or node itself if it isn't a block.
+func identifierNode(node ast.Node) ast.Node {
+	if item, ok := node.(*ast.ObjectItem); ok && len(item.Keys) == 2 {
+		return item.Keys[1]
+	}
+	return node
+}
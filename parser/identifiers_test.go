@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaseCollisionWarnings(t *testing.T) {
+	src := `workflow "Build" { on = "push" resolves = ["build", "BUILD"] }
+action "build" { uses = "./a" }
+action "BUILD" { uses = "./b" }
+action "test" { uses = "./c" needs = "build" }
+`
+	_, err := Parse(strings.NewReader(src), WithoutChecks(CheckUnused))
+	require.NoError(t, err)
+
+	_, diags, err := ParseWithDiagnostics(strings.NewReader(src), WithCaseCollisionWarnings(), WithoutChecks(CheckUnused))
+	require.NoError(t, err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, "Line 1: Identifier `Build' differs only in case from `build' [W_IDENTIFIER_CASE]", diags[0].Error())
+	assert.Equal(t, 10, diags[0].Pos.Column)
+	assert.Equal(t, "Line 3: Identifier `BUILD' differs only in case from `build' [W_IDENTIFIER_CASE]", diags[1].Error())
+
+	_, diags, err = ParseWithDiagnostics(strings.NewReader(src), WithCaseCollisionWarnings(), WithSeparateNamespaces(), WithoutChecks(CheckUnused))
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Pos.Line)
+}
+
+func TestIdentifierFormat(t *testing.T) {
+	src := `workflow "ci" { on = "push" resolves = "Deploy to prod" }
+action "build_all" { uses = "./a" }
+action "Deploy to prod" { uses = "./b" needs = "build_all" }
+`
+	_, err := Parse(strings.NewReader(src))
+	require.NoError(t, err)
+
+	_, diags, err := ParseWithDiagnostics(strings.NewReader(src), WithMaxIdentifierLength(9), WithIdentifierPattern(DefaultIdentifierPattern))
+	require.NoError(t, err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, "Line 3: Identifier `Deploy to prod' is 14 characters long, more than the maximum of 9 [E_IDENTIFIER_TOO_LONG]", diags[0].Error())
+	assert.Equal(t, 8, diags[0].Pos.Column)
+	assert.Equal(t, "Line 3: Identifier `Deploy to prod' does not match `^[a-zA-Z_][a-zA-Z0-9_]*$' [E_IDENTIFIER_PATTERN]", diags[1].Error())
+	assert.Equal(t, Severity(ERROR), diags[1].Severity)
+
+	_, diags, err = ParseWithDiagnostics(strings.NewReader(src), WithMaxIdentifierLength(8))
+	require.NoError(t, err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, 2, diags[0].Pos.Line)
+	assert.Equal(t, CodeIdentifierTooLong, diags[0].Code)
+
+	config, err := Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	errs := Validate(config, WithIdentifierPattern(DefaultIdentifierPattern))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Identifier `Deploy to prod' does not match `^[a-zA-Z_][a-zA-Z0-9_]*$'", errs[0].Message())
+}
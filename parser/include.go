@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// WithIncludeFS makes the parser read the files that `include'
+// statements name from fsys, for files that Parse, ParseReader, and
+// ParseWithDiagnostics read.  Patterns are relative to the directory of
+// the including file's name, if it is a path in fsys, or to the root of
+// fsys otherwise.  ParseFS, ParseFile, and ParseFiles read included files
+// from the same place as the files they were given, without this option.
+func WithIncludeFS(fsys fs.FS) OptionFunc {
+	return func(ps *Parser) {
+		ps.includeFS = fsys
+	}
+}
+
+// isInclude reports whether item, a top-level item, is an `include'
+// statement.
+func isInclude(item *ast.ObjectItem) bool {
+	return item.Assign.IsValid() && len(item.Keys) == 1 && keyString(item.Keys[0].Token) == "include"
+}
+
+// parseInclude checks the value of a top-level `include' statement: a
+// glob pattern, or a list of them.  Resolving the patterns is up to
+// includes, since it needs to know which file the statement is in.
+func (p *Parser) parseInclude(item *ast.ObjectItem) {
+	patterns, ok := p.literalToStringArray(item.Val, true)
+	if !ok {
+		return
+	}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			p.addError(item.Val, CodeBlankValue, "`include' pattern cannot be blank")
+		}
+	}
+}
+
+// includePatterns returns the patterns of an `include' statement, leaving
+// reporting values of the wrong type to parseInclude.
+func includePatterns(node ast.Node) []string {
+	var literals []ast.Node
+	switch n := node.(type) {
+	case *ast.LiteralType:
+		literals = []ast.Node{n}
+	case *ast.ListType:
+		literals = n.List
+	}
+
+	var ret []string
+	for _, node := range literals {
+		if literal, ok := node.(*ast.LiteralType); ok && literal.Token.Type == token.STRING {
+			if pattern := tokenString(literal.Token); pattern != "" {
+				ret = append(ret, pattern)
+			}
+		}
+	}
+	return ret
+}
+
+// includes returns a source for each file that the `include' statements
+// in root, the AST of src, name and that seen doesn't hold yet, adding
+// them to seen.  Seen holds cleaned names, so that a file that includes
+// every file in its directory doesn't include itself again.  Each pattern
+// that matches no files, or names a file that can't be read, is an
+// error.
+func (p *Parser) includes(root *ast.File, src source, seen map[string]bool) []source {
+	list, ok := root.Node.(*ast.ObjectList)
+	if !ok {
+		return nil
+	}
+
+	fsys, dir, base := src.fsys, src.dir, src.base
+	if fsys == nil {
+		fsys, dir, base = p.includeFS, ".", ""
+		if fs.ValidPath(src.name) {
+			dir = path.Dir(src.name)
+		}
+	}
+
+	var ret []source
+	for _, item := range list.Items {
+		if !isInclude(item) {
+			continue
+		}
+		for _, pattern := range includePatterns(item.Val) {
+			if fsys == nil {
+				p.addError(item.Val, CodeIncludeFailed, "Cannot include `%s' without a file system to read it from; see WithIncludeFS", pattern)
+				continue
+			}
+			name := path.Join(dir, pattern)
+			if !fs.ValidPath(name) {
+				p.addError(item.Val, CodeIncludeFailed, "Pattern `%s' in `include' is outside the directory files are included from", pattern)
+				continue
+			}
+			matches, err := fs.Glob(fsys, name)
+			if err != nil {
+				p.addError(item.Val, CodeIncludeFailed, "Malformed pattern `%s' in `include': %s", pattern, err)
+				continue
+			}
+			if len(matches) == 0 {
+				p.addError(item.Val, CodeIncludeFailed, "Pattern `%s' in `include' matches no files", pattern)
+				continue
+			}
+			for _, match := range matches {
+				display := match
+				if base != "" {
+					display = filepath.Join(base, filepath.FromSlash(match))
+				}
+				if seen[filepath.Clean(display)] {
+					continue
+				}
+				seen[filepath.Clean(display)] = true
+
+				b, err := fs.ReadFile(fsys, match)
+				if err != nil {
+					p.addError(item.Val, CodeIncludeFailed, "Cannot include `%s': %s", display, err)
+					continue
+				}
+				ret = append(ret, source{name: display, r: bytes.NewReader(b), fsys: fsys, dir: path.Dir(match), base: base})
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,425 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// Document is a parsed .workflow source buffer, kept so that Reparse can
+// update it after an edit without parsing all of it again.  Config and
+// Errors are what ParseWithDiagnostics would return for Source.
+type Document struct {
+	Source []byte
+	Config *model.Configuration
+	Errors ErrorList
+
+	options []OptionFunc
+
+	// segments tile Source, one per top-level item, or are nil if the
+	// file can't be split into segments, in which case every Reparse
+	// parses the whole file.
+	segments []*segment
+}
+
+// segment is a top-level item and the lines around it: from the start
+// of the line after the previous item, or of the file, through the end
+// of the line the item ends on, or of the file.  It holds what parsing
+// the item on its own found, which stays valid while edits don't touch
+// it.
+type segment struct {
+	start, end int
+	item       *ast.ObjectItem
+	comments   []*ast.CommentGroup
+
+	kind, id    string
+	check       bool
+	actions     []*model.Action
+	workflows   []*model.Workflow
+	templates   []*model.Action
+	env         map[string]string
+	envComments map[string]model.Comments
+	versions    map[string]fileVersion
+	errors      ErrorList
+	posMap      map[interface{}]ast.Node
+	lead        []string
+	trailing    []string
+}
+
+// ParseDocument parses src, like ParseWithDiagnostics, into a Document
+// that Reparse can update.  A JSON document, or one in HCL2 syntax, is
+// always parsed whole.
+func ParseDocument(src []byte, options ...OptionFunc) *Document {
+	d := &Document{Source: src, options: options}
+	p := newParser(context.Background(), options...)
+	syntax := p.syntax
+	p.release()
+	if !isJSON("", src) && syntax != HCL2 {
+		if segments, ok := parseSegments(src, 0, len(src), 0, options); ok {
+			d.segments = segments
+			d.validate()
+			return d
+		}
+	}
+
+	config, errs, _ := parseWithDiagnostics(context.Background(), []source{{r: bytes.NewReader(src)}}, options...)
+	if config == nil {
+		config = &model.Configuration{}
+	}
+	d.Config, d.Errors = config, errs
+	return d
+}
+
+// Reparse applies edit to the source of prev and parses the result.  It
+// parses only the top-level blocks the edit touches again, reusing what
+// it found in the others, and then checks the whole configuration, e.g.,
+// for unknown `needs' and cycles, since an edit to one block can affect
+// any other.  Only the offsets of edit's Start and End are used.
+//
+// Reparse takes over prev: the blocks it reuses are shared with the new
+// Document and adjusted for the edit, so neither prev nor its Config
+// should be used afterwards.  It returns an error, and leaves prev alone,
+// if the edit is outside the source.
+func Reparse(prev *Document, edit TextEdit) (*Document, error) {
+	start, end := edit.Start.Offset, edit.End.Offset
+	if start < 0 || start > end || end > len(prev.Source) {
+		return nil, fmt.Errorf("edit from offset %d to %d is outside the source, which is %d bytes", start, end, len(prev.Source))
+	}
+
+	src := make([]byte, 0, len(prev.Source)-(end-start)+len(edit.NewText))
+	src = append(src, prev.Source[:start]...)
+	src = append(src, edit.NewText...)
+	src = append(src, prev.Source[end:]...)
+
+	segs := prev.segments
+	if len(segs) == 0 || isJSON("", src) {
+		return ParseDocument(src, prev.options...), nil
+	}
+
+	// Find the segments the edit touches.  An edit that ends where a
+	// segment starts may change its first line, so it touches that
+	// segment too.
+	first, last := len(segs)-1, 0
+	for i, s := range segs {
+		if start < s.end && i < first {
+			first = i
+		}
+		if s.start <= end {
+			last = i
+		}
+	}
+	if last < first {
+		last = first
+	}
+
+	delta := len(edit.NewText) - (end - start)
+	lines := strings.Count(edit.NewText, "\n") - bytes.Count(prev.Source[start:end], []byte("\n"))
+	regionStart, regionEnd := segs[first].start, segs[last].end+delta
+
+	replaced, ok := parseSegments(src, regionStart, regionEnd, first, prev.options)
+	if ok && len(replaced) != last-first+1 {
+		// The items after the region moved, which only matters to
+		// `version', which must be first.
+		for _, s := range segs[last+1:] {
+			if s.item.Assign.IsValid() && !isFileEnv(s.item) && !isInclude(s.item) {
+				ok = false
+			}
+		}
+	}
+	if !ok {
+		return ParseDocument(src, prev.options...), nil
+	}
+
+	d := &Document{Source: src, options: prev.options}
+	d.segments = make([]*segment, 0, len(segs)-(last-first+1)+len(replaced))
+	d.segments = append(d.segments, segs[:first]...)
+	d.segments = append(d.segments, replaced...)
+	for _, s := range segs[last+1:] {
+		s.shift(delta, lines)
+		d.segments = append(d.segments, s)
+	}
+
+	// Keep the segments tiling the source if the region now holds no
+	// items, only blank lines.
+	if len(replaced) == 0 {
+		if first < len(d.segments) {
+			d.segments[first].start = regionStart
+		} else if first > 0 {
+			d.segments[first-1].end = regionEnd
+		}
+	}
+
+	d.validate()
+	return d, nil
+}
+
+// validate combines what the segments found and checks the whole
+// configuration, as parseAndValidate does.  Like parseWithDiagnostics,
+// it reports only a FATAL error if the source is past the limits set by
+// WithMaxFileSize or WithMaxBlocks.
+func (d *Document) validate() {
+	p := newParser(context.Background(), d.options...)
+	defer p.release()
+
+	items := make([]*ast.ObjectItem, len(d.segments))
+	for i, s := range d.segments {
+		items[i] = s.item
+	}
+	blocks := 0
+	e := p.checkFileSize("", d.Source)
+	if e == nil {
+		e = p.checkBlocks(items, &blocks)
+	}
+	if e != nil {
+		d.Config, d.Errors = &model.Configuration{}, ErrorList{e}
+		return
+	}
+
+	p.actions = make([]*model.Action, 0)
+	p.workflows = make([]*model.Workflow, 0)
+	identifiers := make(map[string]token.Pos)
+	for _, s := range d.segments {
+		p.errors = append(p.errors, s.errors...)
+		p.actions = append(p.actions, s.actions...)
+		p.workflows = append(p.workflows, s.workflows...)
+		p.templates = append(p.templates, s.templates...)
+		p.fileComments.Lead = append(p.fileComments.Lead, s.lead...)
+		p.fileComments.Trailing = append(p.fileComments.Trailing, s.trailing...)
+		if isFileEnv(s.item) {
+			p.addFileEnv(s.item.Val, s.env, s.envComments)
+		}
+		for _, v := range s.versions {
+			p.setVersion(v.node, v.version)
+		}
+		for k, v := range s.posMap {
+			p.posMap[k] = v
+		}
+		if s.check {
+			p.checkIdentifier(s.item, s.kind, s.id, identifiers)
+		}
+	}
+	p.validate()
+	p.applyBaseline()
+	p.finishErrors()
+
+	d.Config = &model.Configuration{
+		Version:           p.version,
+		Actions:           p.actions,
+		Workflows:         p.workflows,
+		Templates:         p.templates,
+		Env:               p.env,
+		Comments:          p.fileComments,
+		AttributeComments: p.envComments,
+	}
+	d.Errors = p.errors
+}
+
+// parseSegments parses the part of src from start to end, which begins
+// at the start of a line and ends at the end of one or of src, splitting
+// it into a segment per item.  Idx is the index of the first item among
+// all the items of the file.  It returns false if the part isn't valid
+// HCL, or can't be split, e.g., because two items share a line.
+func parseSegments(src []byte, start, end, idx int, options []OptionFunc) ([]*segment, bool) {
+	text := src[start:end]
+	if !utf8.Valid(text) {
+		return nil, false
+	}
+	file, err := hcl.ParseBytes(text)
+	if err != nil {
+		return nil, false
+	}
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, false
+	}
+
+	// Positions in file are relative to start, which is at the beginning
+	// of a line, so only offsets and lines need to move.
+	shiftFile(file, start, model.PosAt(src, start).Line-1)
+	var comments []*ast.Comment
+	for _, group := range file.Comments {
+		comments = append(comments, group.List...)
+	}
+
+	ret := make([]*segment, 0, len(list.Items))
+	segStart := start
+	for i, item := range list.Items {
+		s := &segment{start: segStart, end: end, item: item}
+		if i+1 < len(list.Items) {
+			s.end = lineEnd(src, itemEnd(item))
+			if s.end > list.Items[i+1].Pos().Offset {
+				return nil, false
+			}
+		}
+
+		var own []*ast.Comment
+		for len(comments) > 0 && comments[0].Start.Offset < s.end {
+			c := comments[0]
+			if c.Start.Offset+len(c.Text) > s.end {
+				return nil, false
+			}
+			own = append(own, c)
+			comments = comments[1:]
+		}
+		if len(own) > 0 {
+			s.comments = []*ast.CommentGroup{{List: own}}
+		}
+
+		s.parse(idx+i, options)
+		if len(s.trailing) > 0 && (i+1 < len(list.Items) || end < len(src)) {
+			// Comments after the item that full parsing would attach to
+			// the next item.
+			return nil, false
+		}
+		ret = append(ret, s)
+		segStart = s.end
+	}
+	if len(list.Items) == 0 && len(comments) > 0 {
+		return nil, false
+	}
+	return ret, true
+}
+
+// parse parses the segment's item on its own.  Idx is its index among
+// all the items of the file.
+func (s *segment) parse(idx int, options []OptionFunc) {
+	p := newParser(context.Background(), options...)
+	defer p.release()
+
+	p.comments = newCommentMap(&ast.File{
+		Node:     &ast.ObjectList{Items: []*ast.ObjectItem{s.item}},
+		Comments: s.comments,
+	})
+	s.kind, s.id, s.check = p.parseItem(idx, s.item)
+	s.actions, s.workflows, s.templates, s.errors = p.actions, p.workflows, p.templates, p.errors
+	s.env, s.envComments = p.env, p.envComments
+	s.versions = p.versions
+	s.lead, s.trailing = p.fileComments.Lead, p.comments.trailing
+	s.posMap = make(map[interface{}]ast.Node, len(p.posMap))
+	for k, v := range p.posMap {
+		s.posMap[k] = v
+	}
+}
+
+// shift moves everything in the segment by the given number of bytes and
+// lines.
+func (s *segment) shift(offset, lines int) {
+	if offset == 0 && lines == 0 {
+		return
+	}
+	s.start += offset
+	s.end += offset
+	shiftNode(s.item, offset, lines)
+
+	shiftPos := func(pos *model.Pos) {
+		if pos.IsValid() {
+			pos.Offset += offset
+			pos.Line += lines
+		}
+	}
+	for _, a := range s.actions {
+		shiftPos(&a.Pos)
+		shiftPos(&a.End)
+		for k, pos := range a.Positions {
+			shiftPos(&pos)
+			a.Positions[k] = pos
+		}
+	}
+	for _, w := range s.workflows {
+		shiftPos(&w.Pos)
+		shiftPos(&w.End)
+		for k, pos := range w.Positions {
+			shiftPos(&pos)
+			w.Positions[k] = pos
+		}
+	}
+
+	shiftErrorPos := func(pos *ErrorPos) {
+		if pos.Line > 0 {
+			pos.Offset += offset
+			pos.Line += lines
+		}
+	}
+	for _, e := range s.errors {
+		shiftErrorPos(&e.Pos)
+		if e.Fix != nil {
+			for i := range e.Fix.Edits {
+				shiftErrorPos(&e.Fix.Edits[i].Start)
+				shiftErrorPos(&e.Fix.Edits[i].End)
+			}
+		}
+		for i := range e.Related {
+			shiftErrorPos(&e.Related[i].Pos)
+		}
+	}
+}
+
+// shiftFile moves every position in file, including those of its
+// comments, by the given number of bytes and lines.
+func shiftFile(file *ast.File, offset, lines int) {
+	shiftNode(file.Node, offset, lines)
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			c.Start.Offset += offset
+			c.Start.Line += lines
+		}
+	}
+}
+
+// shiftNode moves every position in the AST rooted at node by the given
+// number of bytes and lines.
+func shiftNode(node ast.Node, offset, lines int) {
+	move := func(pos *token.Pos) {
+		if pos.IsValid() {
+			pos.Offset += offset
+			pos.Line += lines
+		}
+	}
+	ast.Walk(node, func(n ast.Node) (ast.Node, bool) {
+		switch n := n.(type) {
+		case *ast.ObjectItem:
+			move(&n.Assign)
+		case *ast.ObjectKey:
+			move(&n.Token.Pos)
+		case *ast.LiteralType:
+			move(&n.Token.Pos)
+		case *ast.ListType:
+			move(&n.Lbrack)
+			move(&n.Rbrack)
+		case *ast.ObjectType:
+			move(&n.Lbrace)
+			move(&n.Rbrace)
+		}
+		return n, true
+	})
+}
+
+// itemEnd returns the offset of the last byte of item.
+func itemEnd(item *ast.ObjectItem) int {
+	switch val := item.Val.(type) {
+	case *ast.ObjectType:
+		return val.Rbrace.Offset
+	case *ast.ListType:
+		return val.Rbrack.Offset
+	case *ast.LiteralType:
+		return val.Token.Pos.Offset + len(strings.TrimSuffix(val.Token.Text, "\n")) - 1
+	default:
+		return item.Val.Pos().Offset
+	}
+}
+
+// lineEnd returns the offset just past the end of the line that offset
+// is on, including its newline.
+func lineEnd(src []byte, offset int) int {
+	if i := bytes.IndexByte(src[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(src)
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const incrementalSource = `# lead
+version = 0
+
+env = { GOPATH = "/go" }
+
+workflow "ci" {
+  on = "push"
+  resolves = ["b"] # line
+}
+
+# about a
+action "a" {
+  uses = "./a"
+  secrets = ["X", "X"]
+}
+action "b" {
+  needs = "a"
+  uses = "docker://alpine"
+  env = { A = "1" }
+}
+# trailing
+`
+
+// edit returns a TextEdit replacing the first occurrence of old in src.
+func edit(t *testing.T, src []byte, old, new string) TextEdit {
+	i := bytes.Index(src, []byte(old))
+	require.True(t, i >= 0, "no %q in source", old)
+	return TextEdit{Start: ErrorPos{Offset: i}, End: ErrorPos{Offset: i + len(old)}, NewText: new}
+}
+
+func assertSameAsParse(t *testing.T, d *Document) {
+	config, errs, err := ParseWithDiagnostics(bytes.NewReader(d.Source), d.options...)
+	require.NoError(t, err)
+	assert.Equal(t, config, d.Config)
+	assert.Equal(t, errs, d.Errors)
+}
+
+func TestParseDocument(t *testing.T) {
+	d := ParseDocument([]byte(incrementalSource))
+	require.Len(t, d.segments, 5)
+	assertSameAsParse(t, d)
+	assert.Len(t, d.Errors, 1)
+	assert.Equal(t, CodeSecretRedefined, d.Errors[0].Code)
+
+	// Items that share a line can't be split into segments.
+	d = ParseDocument([]byte(`action "a" { uses = "./a" } action "b" { uses = "./b" }`))
+	assert.Nil(t, d.segments)
+	assertSameAsParse(t, d)
+}
+
+func TestReparse(t *testing.T) {
+	d := ParseDocument([]byte(incrementalSource))
+	edits := []struct{ old, new string }{
+		{`needs = "a"`, `needs = "c"`},
+		{`uses = "./a"`, "uses = \"./a\"\n  args = \"x\""},
+		{`secrets = ["X", "X"]`, `secrets = ["X"]`},
+		{"# about a\n", ""},
+		{"action \"a\" {", "action \"c\" {"},
+		{"action \"b\" {", "action \"b\" {\n  bananas = 1"},
+		{"# trailing\n", "action \"d\" {\n  uses = \"./d\"\n}\n"},
+		{`GOPATH = "/go"`, `GOPATH = "/go", CI = "${{ x }"`},
+		{"action \"d\" {", "env = { GOPATH = \"/usr\" }\naction \"d\" {"},
+		{"workflow \"ci\" {\n  on = \"push\"\n  resolves = [\"b\"] # line\n}\n", ""},
+		{`uses = "./a"`, "uses = \"./a\"\n  if = \"success()\""},
+		{"version = 0", "version = 1"},
+		{"version = 1\n", ""},
+	}
+	for i, e := range edits {
+		version := d.segments[0]
+		var err error
+		d, err = Reparse(d, edit(t, d.Source, e.old, e.new))
+		require.NoError(t, err)
+		assertSameAsParse(t, d)
+		if i < len(edits)-2 {
+			// Only the last two edits touch the `version' statement.
+			assert.True(t, version == d.segments[0], "edit %d reparsed the whole file", i)
+		}
+	}
+	assert.NotNil(t, d.segments)
+	assert.NotNil(t, d.Config.GetAction("d"))
+	assert.NotEmpty(t, d.Errors.ByCode(CodeRequiresVersion))
+}
+
+func TestReparseLimits(t *testing.T) {
+	d := ParseDocument([]byte(incrementalSource), WithMaxBlocks(3))
+	assertSameAsParse(t, d)
+	require.Len(t, d.Errors, 1)
+
+	d, err := Reparse(d, edit(t, d.Source, "# trailing", `action "c" { uses = "./c" }`))
+	require.NoError(t, err)
+	assertSameAsParse(t, d)
+	require.Len(t, d.Errors, 1)
+	assert.Equal(t, CodeTooManyBlocks, d.Errors[0].Code)
+	assert.Empty(t, d.Config.Actions)
+}
+
+func TestReparseSyntaxError(t *testing.T) {
+	d := ParseDocument([]byte(incrementalSource))
+	d, err := Reparse(d, edit(t, d.Source, `uses = "./a"`, `uses = `))
+	require.NoError(t, err)
+	require.Len(t, d.Errors, 1)
+	assert.Equal(t, Severity(FATAL), d.Errors[0].Severity)
+	assert.Nil(t, d.segments)
+
+	d, err = Reparse(d, edit(t, d.Source, `uses = `, `uses = "./a"`))
+	require.NoError(t, err)
+	assert.NotNil(t, d.segments)
+	assertSameAsParse(t, d)
+
+	_, err = Reparse(d, TextEdit{Start: ErrorPos{Offset: 5}, End: ErrorPos{Offset: len(d.Source) + 1}})
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "outside the source"))
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"encoding/json"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// The JSON encoding of an Error is stable, like that of a
+// model.Configuration, and looks like this:
+//
+//	{
+//	  "message": "unable to parse and validate",
+//	  "errors": [
+//	    {"message": "Unknown action attribute `bananas'", "code": "W_UNKNOWN_ATTRIBUTE",
+//	     "severity": "warning", "line": 4, "column": 13, "offset": 52},
+//	    {"message": "Circular dependency on `a': a -> b -> a", "code": "E_CIRCULAR_DEPENDENCY",
+//	     "severity": "fatal", "line": 9, "column": 3, "offset": 97, "cycle": ["a", "b"]}
+//	  ],
+//	  "actions": [...],
+//	  "workflows": [...]
+//	}
+//
+// The severity is one of "info", "warning", "error", or "fatal".  The
+// file of an error is included only if it is known, "cycle" only for
+// circular dependencies, and "count" and "positions", a list of objects
+// with "file", "line", "column", and "offset", only for errors that
+// stand for several; see WithDeduplication.  "related" lists the
+// positions, each with a "message", that explain an error, like where
+// a redefined identifier was first defined.  If parsing stopped early,
+// for example because its context was canceled, "cause" says why.
+
+type errorJSON struct {
+	Message   string            `json:"message"`
+	Cause     string            `json:"cause,omitempty"`
+	Errors    []*ParseError     `json:"errors"`
+	Actions   []*model.Action   `json:"actions"`
+	Workflows []*model.Workflow `json:"workflows"`
+}
+
+type parseErrorJSON struct {
+	Message   string         `json:"message"`
+	Code      Code           `json:"code,omitempty"`
+	Severity  string         `json:"severity"`
+	File      string         `json:"file,omitempty"`
+	Line      int            `json:"line"`
+	Column    int            `json:"column"`
+	Offset    int            `json:"offset"`
+	Cycle     []string       `json:"cycle,omitempty"`
+	Count     int            `json:"count,omitempty"`
+	Positions []positionJSON `json:"positions,omitempty"`
+	Related   []relatedJSON  `json:"related,omitempty"`
+}
+
+type positionJSON struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Offset int    `json:"offset"`
+}
+
+type relatedJSON struct {
+	positionJSON
+	Message string `json:"message"`
+}
+
+// MarshalJSON encodes e in the stable JSON format described above.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	ret := errorJSON{
+		Message:   e.message,
+		Errors:    e.Errors,
+		Actions:   e.Actions,
+		Workflows: e.Workflows,
+	}
+	if e.cause != nil {
+		ret.Cause = e.cause.Error()
+	}
+	if ret.Errors == nil {
+		ret.Errors = []*ParseError{}
+	}
+	if ret.Actions == nil {
+		ret.Actions = []*model.Action{}
+	}
+	if ret.Workflows == nil {
+		ret.Workflows = []*model.Workflow{}
+	}
+	return json.Marshal(ret)
+}
+
+// MarshalJSON encodes e in the stable JSON format described above.
+func (e *ParseError) MarshalJSON() ([]byte, error) {
+	var positions []positionJSON
+	for _, pos := range e.Positions {
+		positions = append(positions, positionJSON{File: pos.File, Line: pos.Line, Column: pos.Column, Offset: pos.Offset})
+	}
+	var related []relatedJSON
+	for _, r := range e.Related {
+		related = append(related, relatedJSON{
+			positionJSON: positionJSON{File: r.Pos.File, Line: r.Pos.Line, Column: r.Pos.Column, Offset: r.Pos.Offset},
+			Message:      r.Message,
+		})
+	}
+	return json.Marshal(parseErrorJSON{
+		Message:   e.message,
+		Code:      e.Code,
+		Severity:  e.Severity.String(),
+		File:      e.Pos.File,
+		Line:      e.Pos.Line,
+		Column:    e.Pos.Column,
+		Offset:    e.Pos.Offset,
+		Cycle:     e.Cycle,
+		Count:     e.Count,
+		Positions: positions,
+		Related:   related,
+	})
+}
+
+// UnmarshalJSON decodes e from the stable JSON format described above.
+func (e *ParseError) UnmarshalJSON(b []byte) error {
+	var pj parseErrorJSON
+	if err := json.Unmarshal(b, &pj); err != nil {
+		return err
+	}
+	*e = ParseError{
+		message: pj.Message,
+		Code:    pj.Code,
+		Pos:     ErrorPos{File: pj.File, Line: pj.Line, Column: pj.Column, Offset: pj.Offset},
+		Cycle:   pj.Cycle,
+		Count:   pj.Count,
+	}
+	for _, pos := range pj.Positions {
+		e.Positions = append(e.Positions, ErrorPos{File: pos.File, Line: pos.Line, Column: pos.Column, Offset: pos.Offset})
+	}
+	for _, r := range pj.Related {
+		pos := ErrorPos{File: r.File, Line: r.Line, Column: r.Column, Offset: r.Offset}
+		e.Related = append(e.Related, RelatedPos{Pos: pos, Message: r.Message})
+	}
+	e.Severity, _ = ParseSeverity(pj.Severity)
+	return nil
+}
@@ -0,0 +1,337 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// isJSON reports whether src, read from the file name, holds JSON rather
+// than HCL: its name ends in .json, or it starts with `{', which no
+// valid .workflow file does.
+func isJSON(name string, src []byte) bool {
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		return true
+	}
+	trimmed := bytes.TrimLeft(src, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// parseJSON parses src, a JSON object, into the AST the same file would
+// have in HCL, so that it goes through the same checks.  The object's
+// "action", "template", and "workflow" members map identifiers to
+// blocks, or are lists of such maps; its other members, like "version"
+// and "env", are top-level assignments:
+//
+//	{
+//	  "version": 1,
+//	  "workflow": {"ci": {"on": "push", "resolves": ["test"]}},
+//	  "action": {"test": {"uses": "./test", "args": ["-v"]}}
+//	}
+//
+// Syntax errors are *hclparser.PosError, as they are for HCL.
+func parseJSON(src []byte) (*ast.File, error) {
+	p := &jsonParser{src: src, lines: []int{0}}
+	for i, c := range src {
+		if c == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+
+	p.skipSpace()
+	if p.peek() != '{' {
+		return nil, p.errorf("expected a JSON object")
+	}
+	root, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.off < len(p.src) {
+		return nil, p.errorf("unexpected %s after the JSON object", p.describe())
+	}
+
+	list := &ast.ObjectList{}
+	for _, item := range root.(*ast.ObjectType).List.Items {
+		list.Items = append(list.Items, jsonBlocks(item)...)
+	}
+	return &ast.File{Node: list}, nil
+}
+
+// jsonBlocks returns the top-level items that a member of the JSON object
+// stands for: a block for each identifier of an "action", "template", or
+// "workflow" member, or the member itself, as an assignment.
+func jsonBlocks(item *ast.ObjectItem) []*ast.ObjectItem {
+	kind := item.Keys[0].Token
+	switch keyString(kind) {
+	case "action", "template", "workflow":
+	default:
+		return []*ast.ObjectItem{item}
+	}
+
+	var objects []*ast.ObjectType
+	switch val := item.Val.(type) {
+	case *ast.ObjectType:
+		objects = []*ast.ObjectType{val}
+	case *ast.ListType:
+		for _, elem := range val.List {
+			obj, ok := elem.(*ast.ObjectType)
+			if !ok {
+				return []*ast.ObjectItem{item}
+			}
+			objects = append(objects, obj)
+		}
+	default:
+		return []*ast.ObjectItem{item}
+	}
+
+	var ret []*ast.ObjectItem
+	for _, obj := range objects {
+		for _, block := range obj.List.Items {
+			// The block is where its identifier is.  Identifiers are
+			// taken literally, without unquoting, so the key gets the
+			// quotes HCL would have around it.
+			id := block.Keys[0].Token
+			id.Text = `"` + keyString(id) + `"`
+			id.JSON = false
+			ret = append(ret, &ast.ObjectItem{
+				Keys: []*ast.ObjectKey{
+					{Token: token.Token{Type: token.IDENT, Pos: id.Pos, Text: keyString(kind)}},
+					{Token: id},
+				},
+				Val: block.Val,
+			})
+		}
+	}
+	return ret
+}
+
+// fromJSON reports whether node was parsed from JSON.
+func fromJSON(node ast.Node) bool {
+	ret := false
+	ast.Walk(node, func(n ast.Node) (ast.Node, bool) {
+		if literal, ok := n.(*ast.LiteralType); ok && literal.Token.JSON {
+			ret = true
+		}
+		if key, ok := n.(*ast.ObjectKey); ok && key.Token.JSON {
+			ret = true
+		}
+		return n, !ret
+	})
+	return ret
+}
+
+// jsonParser is a recursive-descent JSON parser that builds an HCL AST,
+// with the position of every token.
+type jsonParser struct {
+	src   []byte
+	off   int
+	lines []int // the offset at which each line starts
+}
+
+// pos returns the position of the byte at off.
+func (p *jsonParser) pos(off int) token.Pos {
+	line := sort.Search(len(p.lines), func(i int) bool { return p.lines[i] > off }) - 1
+	return token.Pos{
+		Offset: off,
+		Line:   line + 1,
+		Column: utf8.RuneCount(p.src[p.lines[line]:off]) + 1,
+	}
+}
+
+func (p *jsonParser) errorf(format string, a ...interface{}) error {
+	return &hclparser.PosError{Pos: p.pos(p.off), Err: fmt.Errorf(format, a...)}
+}
+
+// describe describes the next token for error messages.
+func (p *jsonParser) describe() string {
+	if p.off >= len(p.src) {
+		return "end of file"
+	}
+	r, _ := utf8.DecodeRune(p.src[p.off:])
+	return strconv.QuoteRune(r)
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.off < len(p.src) {
+		switch p.src[p.off] {
+		case ' ', '\t', '\r', '\n':
+			p.off++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next byte, or 0 at the end of the source.
+func (p *jsonParser) peek() byte {
+	if p.off >= len(p.src) {
+		return 0
+	}
+	return p.src[p.off]
+}
+
+// expect skips space and the byte c, which must come next.
+func (p *jsonParser) expect(c byte) (token.Pos, error) {
+	p.skipSpace()
+	if p.peek() != c {
+		return token.Pos{}, p.errorf("expected %q, got %s", c, p.describe())
+	}
+	pos := p.pos(p.off)
+	p.off++
+	return pos, nil
+}
+
+// value parses the JSON value at the current offset.
+func (p *jsonParser) value() (ast.Node, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.list()
+	case c == '"':
+		t, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.LiteralType{Token: t}, nil
+	case c == '-' || c >= '0' && c <= '9':
+		return p.number()
+	case c == 't' || c == 'f':
+		pos := p.pos(p.off)
+		for _, word := range []string{"true", "false"} {
+			if bytes.HasPrefix(p.src[p.off:], []byte(word)) {
+				p.off += len(word)
+				return &ast.LiteralType{Token: token.Token{Type: token.BOOL, Pos: pos, Text: word}}, nil
+			}
+		}
+	case c == 'n' && bytes.HasPrefix(p.src[p.off:], []byte("null")):
+		return nil, p.errorf("null is not a valid value")
+	}
+	return nil, p.errorf("expected a value, got %s", p.describe())
+}
+
+func (p *jsonParser) object() (ast.Node, error) {
+	lbrace, err := p.expect('{')
+	if err != nil {
+		return nil, err
+	}
+	obj := &ast.ObjectType{Lbrace: lbrace, List: &ast.ObjectList{}}
+	p.skipSpace()
+	for p.peek() != '}' {
+		if len(obj.List.Items) > 0 {
+			if _, err := p.expect(','); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+		}
+		if p.peek() != '"' {
+			return nil, p.errorf("expected a string key, got %s", p.describe())
+		}
+		key, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		assign, err := p.expect(':')
+		if err != nil {
+			return nil, err
+		}
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		obj.List.Add(&ast.ObjectItem{Keys: []*ast.ObjectKey{{Token: key}}, Assign: assign, Val: val})
+		p.skipSpace()
+	}
+	obj.Rbrace = p.pos(p.off)
+	p.off++
+	return obj, nil
+}
+
+func (p *jsonParser) list() (ast.Node, error) {
+	lbrack, err := p.expect('[')
+	if err != nil {
+		return nil, err
+	}
+	list := &ast.ListType{Lbrack: lbrack}
+	p.skipSpace()
+	for p.peek() != ']' {
+		if len(list.List) > 0 {
+			if _, err := p.expect(','); err != nil {
+				return nil, err
+			}
+		}
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list.Add(val)
+		p.skipSpace()
+	}
+	list.Rbrack = p.pos(p.off)
+	p.off++
+	return list, nil
+}
+
+// string parses a JSON string.  The token's text is the string as
+// written, if Go would unquote it the same way, so that fixes can replace
+// it; otherwise, like for `\/', it is the string quoted as Go would, so
+// that tokenString returns it exactly.
+func (p *jsonParser) string() (token.Token, error) {
+	start := p.off
+	end := start + 1
+	for {
+		if end >= len(p.src) || p.src[end] == '\n' {
+			p.off = end
+			return token.Token{}, p.errorf("unterminated string")
+		}
+		if p.src[end] == '\\' {
+			end += 2
+			continue
+		}
+		end++
+		if p.src[end-1] == '"' {
+			break
+		}
+	}
+	var s string
+	if err := json.Unmarshal(p.src[start:end], &s); err != nil {
+		p.off = start
+		return token.Token{}, p.errorf("invalid string: %s", strings.TrimPrefix(err.Error(), "json: "))
+	}
+	p.off = end
+	text := string(p.src[start:end])
+	if v, err := strconv.Unquote(text); err != nil || v != s {
+		text = strconv.Quote(s)
+	}
+	return token.Token{Type: token.STRING, Pos: p.pos(start), Text: text, JSON: true}, nil
+}
+
+// number parses a JSON number, which is a NUMBER token if it is an
+// integer and a FLOAT token otherwise.
+func (p *jsonParser) number() (ast.Node, error) {
+	start := p.off
+	for p.off < len(p.src) && strings.IndexByte("+-0123456789.eE", p.src[p.off]) >= 0 {
+		p.off++
+	}
+	text := string(p.src[start:p.off])
+	if !json.Valid([]byte(text)) {
+		p.off = start
+		return nil, p.errorf("invalid number `%s'", text)
+	}
+	t := token.Token{Type: token.NUMBER, Pos: p.pos(start), Text: text}
+	if strings.ContainsAny(text, ".eE") {
+		t.Type = token.FLOAT
+	}
+	return &ast.LiteralType{Token: t}, nil
+}
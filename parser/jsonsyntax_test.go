@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON(t *testing.T) {
+	hclSrc := `version = 1
+env = { GOPATH = "/go" }
+workflow "ci" {
+  on = "push"
+  resolves = ["test"]
+}
+action "build" {
+  uses = "docker://golang"
+  runs = ["go", "build"]
+}
+action "test" {
+  needs = "build"
+  uses = "./test"
+  if = "success()"
+  env = { DIR = "a/b" }
+  secrets = ["TOKEN"]
+  continue_on_error = true
+}
+`
+	jsonSrc := `{
+  "version": 1,
+  "env": {"GOPATH": "/go"},
+  "workflow": {"ci": {"on": "push", "resolves": ["test"]}},
+  "action": [
+    {"build": {"uses": "docker://golang", "runs": ["go", "build"]}},
+    {"test": {
+      "needs": "build",
+      "uses": "./test",
+      "if": "success()",
+      "env": {"DIR": "a\/b"},
+      "secrets": ["TOKEN"],
+      "continue_on_error": true
+    }}
+  ]
+}`
+	want, err := parseString(hclSrc)
+	require.NoError(t, err)
+	config, err := parseString(jsonSrc)
+	require.NoError(t, err)
+	assert.True(t, want.Equal(config))
+	assert.Equal(t, 7, config.GetAction("test").Pos.Line)
+	assert.Equal(t, 11, config.GetAction("test").AttributePos("env").Line)
+
+	// The same checks apply, at the position of what they're about.
+	config, err = parseString(`{
+  "action": {
+    "a": {"uses": "./a", "needs": ["b"]},
+    "a": {"uses": "./a", "bananas": 1}
+  }
+}`)
+	assertParseError(t, err, 2, 0, config,
+		"line 3: action `a' needs nonexistent action `b'",
+		"line 4: unknown action attribute `bananas'",
+		"line 4: identifier `a' redefined")
+
+	for src, msg := range map[string]string{
+		`{"action": {"a": {"uses": "./a"}}`: "Line 1: expected ',', got end of file [E_SYNTAX]",
+		`{"action": {"a": {"uses": null}}}`: "Line 1: null is not a valid value [E_SYNTAX]",
+		"{}\n[]":                            "Line 2: unexpected '[' after the JSON object [E_SYNTAX]",
+		`{"version": 01}`:                   "Line 1: invalid number `01' [E_SYNTAX]",
+	} {
+		_, errs, err := ParseWithDiagnostics(strings.NewReader(src))
+		require.NoError(t, err)
+		require.Len(t, errs, 1, src)
+		assert.Equal(t, msg, errs[0].Error(), src)
+	}
+}
+
+func TestParseJSONFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.workflow.json": "\n  [\"not an object\"]",
+	})
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "main.workflow.json")
+
+	_, err := ParseFile(fn)
+	pe := extractParserError(t, err)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, fn, pe.Errors[0].Pos.File)
+	assert.Equal(t, 2, pe.Errors[0].Pos.Line)
+	assert.Equal(t, "expected a JSON object", pe.Errors[0].Message())
+
+	// JSON documents are never split into segments.
+	d := ParseDocument([]byte(`{"action": {"a": {"uses": "./a", "if": "success()"}}}`))
+	assert.Nil(t, d.segments)
+	require.Len(t, d.Errors, 1)
+	assert.Equal(t, CodeRequiresVersion, d.Errors[0].Code)
+	assert.Nil(t, d.Errors[0].Fix)
+}
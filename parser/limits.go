@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"io"
+
+	"github.com/actions/workflow-parser/model"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+// limitReader returns r, or, if WithMaxFileSize set a limit, a reader
+// that stops one byte past it, so that checkFileSize can tell a file is
+// too large without reading all of it.
+func (p *Parser) limitReader(r io.Reader) io.Reader {
+	if p.maxFileSize <= 0 {
+		return r
+	}
+	return io.LimitReader(r, int64(p.maxFileSize)+1)
+}
+
+// checkFileSize returns a FATAL error if b, the contents of the named
+// file, is larger than the limit set by WithMaxFileSize.  The error is at
+// the first byte past the limit.
+func (p *Parser) checkFileSize(name string, b []byte) *ParseError {
+	if p.maxFileSize <= 0 || len(b) <= p.maxFileSize {
+		return nil
+	}
+	pos := model.PosAt(b, p.maxFileSize)
+	errPos := ErrorPos{File: name, Line: pos.Line, Column: pos.Column, Offset: pos.Offset}
+	return newFatal(errPos, CodeFileTooLarge, "File is larger than the maximum of %d bytes", p.maxFileSize)
+}
+
+// checkBlocks adds the blocks among items, the top-level items of a
+// file, to *count, the number of blocks in the files before it.  It
+// returns a FATAL error at the first block past the limit set by
+// WithMaxBlocks, if any.
+func (p *Parser) checkBlocks(items []*ast.ObjectItem, count *int) *ParseError {
+	for _, item := range items {
+		if item.Assign.IsValid() {
+			continue
+		}
+		*count++
+		if p.maxBlocks > 0 && *count > p.maxBlocks {
+			return newFatal(posFromNode(item), CodeTooManyBlocks, "More than the maximum of %d actions and workflows", p.maxBlocks)
+		}
+	}
+	return nil
+}
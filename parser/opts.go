@@ -13,3 +13,144 @@ func WithSuppressErrors() OptionFunc {
 		ps.suppressSeverity = ERROR
 	}
 }
+
+// WithSeparateNamespaces lets an action and a workflow share the same
+// identifier.  By default, actions and workflows share one namespace, and
+// reusing an identifier across them is an error.
+func WithSeparateNamespaces() OptionFunc {
+	return func(ps *Parser) {
+		ps.separateNamespaces = true
+	}
+}
+
+// WithMaxEnvValueLength limits the length, in bytes, of each environment
+// variable value in an action.  A limit of zero or less disables the check.
+func WithMaxEnvValueLength(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxEnvValueLength = n
+	}
+}
+
+// WithMaxEnvSize limits the total size, in bytes, of an action's env
+// block, counting each variable as len("KEY=VALUE").  A limit of zero or
+// less disables the check.
+func WithMaxEnvSize(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxEnvSize = n
+	}
+}
+
+// WithMaxSecrets limits the number of unique secrets all actions combined
+// may use, which is 100 by default.  A limit of zero or less disables the
+// check.
+func WithMaxSecrets(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxSecrets = n
+	}
+}
+
+// WithMaxDescriptionLength limits the number of characters in the
+// description of each action and workflow, which is 500 by default.  A
+// limit of zero or less disables the check.
+func WithMaxDescriptionLength(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxDescriptionLen = n
+	}
+}
+
+// WithMaxSecretsPerAction limits the number of unique secrets each action
+// may use.  By default, and with a limit of zero or less, only the limit
+// for all actions combined applies.
+func WithMaxSecretsPerAction(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxActionSecrets = n
+	}
+}
+
+// WithMaxErrors limits the number of diagnostics to n.  If there are
+// more, the first n, in the usual order, are kept, followed by a single
+// CodeTooManyErrors diagnostic counting the rest.  A limit of zero or less
+// disables the check.
+func WithMaxErrors(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxErrors = n
+	}
+}
+
+// WithMaxFileSize limits the size, in bytes, of each file.  The parser
+// stops reading a file once it passes the limit, and reports only a FATAL
+// CodeFileTooLarge diagnostic.  A limit of zero or less disables the
+// check.
+func WithMaxFileSize(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxFileSize = n
+	}
+}
+
+// WithMaxBlocks limits the number of actions and workflows all files
+// combined may define.  Past the limit, the parser stops and reports only
+// a FATAL CodeTooManyBlocks diagnostic.  A limit of zero or less disables
+// the check.
+func WithMaxBlocks(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxBlocks = n
+	}
+}
+
+// WithMaxActions limits the number of actions all files combined may
+// define.  Unlike WithMaxBlocks, it doesn't stop the parser: it reports a
+// single CodeTooManyActions error, at the first action past the limit,
+// alongside any other problems.  A limit of zero or less disables the
+// check.
+func WithMaxActions(n int) OptionFunc {
+	return func(ps *Parser) {
+		ps.maxActions = n
+	}
+}
+
+// WithAllowedEvents replaces the event types that workflows may use
+// with the given ones, for this parse only.
+func WithAllowedEvents(eventTypes []string) OptionFunc {
+	return func(ps *Parser) {
+		ps.events = NewEventRegistry(eventTypes...)
+	}
+}
+
+// WithEventRegistry checks event types against r instead of the built-in
+// set.  Changes to r while a parse is running may or may not be seen by
+// that parse.
+func WithEventRegistry(r *EventRegistry) OptionFunc {
+	return func(ps *Parser) {
+		ps.events = r
+	}
+}
+
+// WithoutChecks disables the given checks, e.g., CheckUnused for files
+// that define actions for other files to use.
+func WithoutChecks(checks Check) OptionFunc {
+	return func(ps *Parser) {
+		ps.checks &^= checks
+	}
+}
+
+// WithRules adds custom validation rules, which run after the built-in
+// checks.  The errors they report are subject to the same suppression as
+// the parser's own.
+func WithRules(rules ...Rule) OptionFunc {
+	return func(ps *Parser) {
+		ps.rules = append(ps.rules, rules...)
+	}
+}
+
+// WithSeverityOverride reports every diagnostic with the given code at
+// sev instead of its usual severity, e.g., to demote CodeUnknownAttribute
+// to INFO, or promote CodeUnpinnedRef to ERROR.  Suppression with
+// WithSuppressWarnings or WithSuppressErrors applies to the new severity.
+func WithSeverityOverride(code Code, sev Severity) OptionFunc {
+	return func(ps *Parser) {
+		if ps.severities == nil {
+			ps.severities = make(map[Code]Severity)
+		}
+		ps.severities[code] = sev
+	}
+}
@@ -1,98 +1,541 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"io/ioutil"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/actions/workflow-parser/model"
 	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/hcl/hcl/ast"
 	hclparser "github.com/hashicorp/hcl/hcl/parser"
+	hclstrconv "github.com/hashicorp/hcl/hcl/strconv"
 	"github.com/hashicorp/hcl/hcl/token"
 	"github.com/soniakeys/graph"
 )
 
 const minVersion = 0
-const maxVersion = 0
-const maxSecrets = 100
+const maxVersion = model.LatestVersion
+
+// defaultMaxSecrets is the number of unique secrets all actions combined
+// may use, unless WithMaxSecrets says otherwise.
+const defaultMaxSecrets = 100
+
+// maxMatrixCombinations is the number of actions the matrix of a single
+// action may expand to.
+const maxMatrixCombinations = 256
+
+// defaultMaxDescriptionLength is the number of characters a description
+// may have, unless WithMaxDescriptionLength says otherwise.
+const defaultMaxDescriptionLength = 500
 
 type Parser struct {
+	ctx       context.Context
 	version   int
+	versions  map[string]fileVersion
 	actions   []*model.Action
 	workflows []*model.Workflow
-	errors    errorList
-
-	posMap           map[interface{}]ast.Node
-	suppressSeverity Severity
+	templates []*model.Action
+	errors    ErrorList
+
+	posMap             map[interface{}]ast.Node
+	interned           map[string]string
+	comments           *commentMap
+	fileComments       model.Comments
+	env                map[string]string
+	envComments        map[string]model.Comments
+	envNodes           []ast.Node
+	checks             Check
+	suppressSeverity   Severity
+	separateNamespaces bool
+	caseCollisions     bool
+	maxIdentifierLen   int
+	identifierPattern  *regexp.Regexp
+	maxEnvValueLength  int
+	maxEnvSize         int
+	maxSecrets         int
+	maxDescriptionLen  int
+	maxActionSecrets   int
+	maxErrors          int
+	maxFileSize        int
+	maxBlocks          int
+	maxActions         int
+	dedup              bool
+	dedupPositions     int
+	severities         map[Code]Severity
+	baseline           *Baseline
+	baselineErr        *ParseError
+	syntaxRecovery     bool
+	syntax             Syntax
+	rules              []Rule
+	events             *EventRegistry
+	includeFS          fs.FS
 }
 
 // Parse parses a .workflow file and return the actions and global variables found within.
 func Parse(reader io.Reader, options ...OptionFunc) (*model.Configuration, error) {
-	// FIXME - check context for deadline?
-	b, err := ioutil.ReadAll(reader)
+	return ParseContext(context.Background(), reader, options...)
+}
+
+// ParseContext is like Parse, but stops early if ctx is canceled or its
+// deadline passes, whether that happens while reading, parsing, or
+// validating.  In that case, the returned error is a *Error holding the
+// actions, workflows, and diagnostics gathered before cancellation, and
+// errors.Is(err, ctx.Err()) is true.
+//
+// Parsing HCL can't be interrupted, so if ctx is canceled during it,
+// ParseContext returns right away and leaves it to finish in the
+// background.  Reading is done a chunk at a time in the background too,
+// and stops after the chunk being read when ctx is canceled.  That read
+// may still be in progress when ParseContext returns, so reader must not
+// be used again after a cancellation error.
+func ParseContext(ctx context.Context, reader io.Reader, options ...OptionFunc) (*model.Configuration, error) {
+	config, errors, err := parseWithDiagnostics(ctx, []source{{r: reader}}, options...)
+	return parseResult(ctx, config, errors, err)
+}
+
+// parseResult converts the results of parseWithDiagnostics to those of
+// ParseContext.
+func parseResult(ctx context.Context, config *model.Configuration, errors ErrorList, err error) (*model.Configuration, error) {
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			ret := &Error{message: "unable to parse", cause: ctxErr}
+			if config != nil {
+				ret.message = "unable to parse and validate"
+				ret.Errors, ret.Actions, ret.Workflows = errors, config.Actions, config.Workflows
+			}
+			return nil, ret
+		}
 		return nil, err
 	}
+	if config == nil {
+		return nil, &Error{message: "unable to parse", Errors: errors}
+	}
+	if errors.hasProblems() {
+		return nil, &Error{
+			message:   "unable to parse and validate",
+			Errors:    errors,
+			Actions:   config.Actions,
+			Workflows: config.Workflows,
+		}
+	}
+	return config, nil
+}
+
+// ParseWithDiagnostics parses a .workflow file and returns everything it
+// found, even if the file has problems: the actions and workflows that
+// could be parsed, and the diagnostics, sorted by line.  A file that
+// isn't valid HCL yields an empty configuration and a single FATAL
+// diagnostic.  The error is not nil only if reader could not be read;
+// problems with the file itself are always diagnostics.
+//
+// Parse is equivalent, except that it returns the configuration only if
+// there are no diagnostics above INFO, and the diagnostics as a *Error
+// otherwise.
+func ParseWithDiagnostics(reader io.Reader, options ...OptionFunc) (*model.Configuration, ErrorList, error) {
+	return ParseWithDiagnosticsContext(context.Background(), reader, options...)
+}
 
-	root, err := hcl.ParseBytes(b)
+// ParseWithDiagnosticsContext is like ParseWithDiagnostics, but stops
+// early if ctx is canceled or its deadline passes, as ParseContext does.
+// In that case, it returns ctx.Err() and nothing else.
+func ParseWithDiagnosticsContext(ctx context.Context, reader io.Reader, options ...OptionFunc) (*model.Configuration, ErrorList, error) {
+	config, errors, err := parseWithDiagnostics(ctx, []source{{r: reader}}, options...)
 	if err != nil {
-		if pe, ok := err.(*hclparser.PosError); ok {
-			pos := ErrorPos{File: pe.Pos.Filename, Line: pe.Pos.Line, Column: pe.Pos.Column}
-			errors := errorList{newFatal(pos, pe.Err.Error())}
-			return nil, &Error{
-				message: "unable to parse",
-				Errors:  errors,
+		return nil, nil, err
+	}
+	if config == nil {
+		config = &model.Configuration{}
+	}
+	return config, errors, nil
+}
+
+// source is a .workflow file to parse.  If name is not blank, it becomes
+// the File of every position in the file.
+type source struct {
+	name string
+	r    io.Reader
+
+	// fsys is where the files the source includes are, and dir the
+	// slash-separated directory in it that their patterns are relative
+	// to.  Base, if set, is the directory on disk that fsys is, which
+	// the names of included files are joined to.  If fsys is nil, they
+	// come from WithIncludeFS.
+	fsys fs.FS
+	dir  string
+	base string
+}
+
+// parseWithDiagnostics does the work of ParseContext and
+// ParseWithDiagnostics, merging the actions and workflows of all the
+// sources into one configuration.  It returns a nil configuration if any
+// source is not valid HCL, unless the parser recovers from syntax
+// errors, or if reading or parsing was interrupted.  If ctx is canceled
+// during validation, it returns what it found so far along with
+// ctx.Err().
+func parseWithDiagnostics(ctx context.Context, sources []source, options ...OptionFunc) (*model.Configuration, ErrorList, error) {
+	p := newParser(ctx, options...)
+	defer p.release()
+
+	roots := make([]*ast.File, 0, len(sources))
+	var fatals ErrorList
+	blocks := 0
+	seen := make(map[string]bool, len(sources))
+	for _, src := range sources {
+		seen[filepath.Clean(src.name)] = true
+	}
+	// Included files are added to sources as they are found, and parsed
+	// after the ones before them.
+	for i := 0; i < len(sources); i++ {
+		src := sources[i]
+		root, errs, err := p.parseSource(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Limits on untrusted input stop the parser even if it recovers
+		// from syntax errors.
+		if len(errs) > 0 && errs[0].Code == CodeFileTooLarge {
+			return nil, errs, nil
+		}
+		fatals = append(fatals, errs...)
+		if root != nil {
+			if list, ok := root.Node.(*ast.ObjectList); ok {
+				if e := p.checkBlocks(list.Items, &blocks); e != nil {
+					return nil, ErrorList{e}, nil
+				}
+			}
+			roots = append(roots, root)
+			sources = append(sources, p.includes(root, src, seen)...)
+		}
+	}
+	if len(fatals) > 0 && !p.syntaxRecovery {
+		return nil, p.limitErrors(fatals), nil
+	}
+
+	p.errors = append(p.errors, fatals...)
+	p.parseAndValidate(roots)
+
+	config := &model.Configuration{
+		Version:           p.version,
+		Actions:           p.actions,
+		Workflows:         p.workflows,
+		Templates:         p.templates,
+		Env:               p.env,
+		Comments:          p.fileComments,
+		AttributeComments: p.envComments,
+	}
+	return config, p.errors, ctx.Err()
+}
+
+// parseSource reads and parses src as HCL, in the syntax WithSyntax
+// chose, or as JSON if isJSON says it is.  If src is larger than WithMaxFileSize allows, not valid UTF-8, or
+// not valid HCL or JSON, it returns a FATAL error describing the problem,
+// and no AST.  If the parser recovers from syntax errors, it instead
+// returns a FATAL error for each top-level block that isn't valid HCL,
+// and an AST of the others; JSON and HCL2 files are always parsed whole.
+func (p *Parser) parseSource(src source) (*ast.File, ErrorList, error) {
+	ctx := p.ctx
+	b, err := readAll(ctx, p.limitReader(src.r))
+	if err != nil {
+		return nil, nil, err
+	}
+	if e := p.checkFileSize(src.name, b); e != nil {
+		return nil, ErrorList{e}, nil
+	}
+
+	if pos, ok := validUTF8(b); !ok {
+		pos.File = src.name
+		return nil, ErrorList{newFatal(pos, CodeInvalidUTF8, "Invalid UTF-8 sequence at byte offset %d", pos.Offset)}, nil
+	}
+
+	parse := hcl.ParseBytes
+	json := isJSON(src.name, b)
+	if json {
+		parse = parseJSON
+	} else if p.syntax == HCL2 {
+		parse = parseHCL2
+	}
+	root, err := parseHCL(ctx, b, parse)
+	if err != nil {
+		if ctx.Err() == nil {
+			if pe, ok := err.(*hclparser.PosError); ok {
+				if p.syntaxRecovery && !json && p.syntax != HCL2 {
+					root, fatals := recoverSyntax(b, pe)
+					if src.name != "" {
+						setFilename(root.Node, src.name)
+						for _, e := range fatals {
+							e.Pos.File = src.name
+						}
+					}
+					return root, fatals, nil
+				}
+				pos := ErrorPos{File: src.name, Line: pe.Pos.Line, Column: pe.Pos.Column, Offset: pe.Pos.Offset}
+				return nil, ErrorList{newFatal(pos, CodeSyntax, "%s", pe.Err.Error())}, nil
 			}
 		}
+		return nil, nil, err
+	}
+	if src.name != "" {
+		setFilename(root.Node, src.name)
+	}
+	return root, nil, nil
+}
+
+// setFilename sets the file name of every position in the AST rooted at
+// node, so that errors and model positions record which file they are
+// in.
+func setFilename(node ast.Node, filename string) {
+	ast.Walk(node, func(n ast.Node) (ast.Node, bool) {
+		switch n := n.(type) {
+		case *ast.ObjectItem:
+			n.Assign.Filename = filename
+		case *ast.ObjectKey:
+			n.Token.Pos.Filename = filename
+		case *ast.LiteralType:
+			n.Token.Pos.Filename = filename
+		case *ast.ListType:
+			n.Lbrack.Filename, n.Rbrack.Filename = filename, filename
+		case *ast.ObjectType:
+			n.Lbrace.Filename, n.Rbrace.Filename = filename, filename
+		}
+		return n, true
+	})
+}
+
+// readChunkSize is how much readAll reads from its reader at a time.
+const readChunkSize = 32 * 1024
+
+// readAll reads all of r, unless ctx is canceled first.  The reading is
+// done in a goroutine, readChunkSize bytes at a time, so that readAll can
+// return as soon as ctx is canceled; the goroutine stops once the read
+// in progress, if any, returns.
+func readAll(ctx context.Context, r io.Reader) ([]byte, error) {
+	if ctx.Done() == nil {
+		return ioutil.ReadAll(r)
+	}
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	p := parseAndValidate(root.Node, options...)
-	if len(p.errors) > 0 {
-		return nil, &Error{
-			message:   "unable to parse and validate",
-			Errors:    p.errors,
-			Actions:   p.actions,
-			Workflows: p.workflows,
+	type result struct {
+		b   []byte
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		var b []byte
+		chunk := make([]byte, readChunkSize)
+		for ctx.Err() == nil {
+			n, err := r.Read(chunk)
+			b = append(b, chunk[:n]...)
+			if err == io.EOF {
+				ch <- result{b, nil}
+				return
+			}
+			if err != nil {
+				ch <- result{nil, err}
+				return
+			}
 		}
+		ch <- result{nil, ctx.Err()}
+	}()
+
+	select {
+	case res := <-ch:
+		return res.b, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
+}
 
-	return &model.Configuration{
-		Actions:   p.actions,
-		Workflows: p.workflows,
-	}, nil
+// parseHCL parses b with parse, unless ctx is canceled first.
+func parseHCL(ctx context.Context, b []byte, parse func([]byte) (*ast.File, error)) (*ast.File, error) {
+	if ctx.Done() == nil {
+		return parse(b)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		root *ast.File
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		root, err := parse(b)
+		ch <- result{root, err}
+	}()
+
+	select {
+	case res := <-ch:
+		return res.root, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
-// parseAndValidate converts a HCL AST into a Parser and validates
-// high-level structure.
-// Parameters:
-//  - root - the contents of a .workflow file, as AST
-// Returns:
-//  - a Parser structure containing actions and workflow definitions
-func parseAndValidate(root ast.Node, options ...OptionFunc) *Parser {
-	p := &Parser{
-		posMap: make(map[interface{}]ast.Node),
+// validUTF8 checks that b is well-formed UTF-8.  If it is not, it returns
+// false and the position of the first invalid byte sequence.  Columns are
+// counted in runes, like the HCL scanner does.
+func validUTF8(b []byte) (ErrorPos, bool) {
+	if utf8.Valid(b) {
+		return ErrorPos{}, true
 	}
 
-	for _, option := range options {
-		option(p)
+	pos := ErrorPos{Line: 1, Column: 1}
+	for pos.Offset < len(b) {
+		r, size := utf8.DecodeRune(b[pos.Offset:])
+		if r == utf8.RuneError && size <= 1 {
+			return pos, false
+		}
+		pos.Offset += size
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
 	}
+	return pos, false
+}
 
-	p.parseRoot(root)
+// parseAndValidate converts HCL ASTs into actions and workflows and
+// validates high-level structure.
+// Parameters:
+//   - roots - the contents of one or more .workflow files, as AST
+func (p *Parser) parseAndValidate(roots []*ast.File) {
+	p.parseRoots(roots)
 	p.validate()
+	p.applyBaseline()
+	p.finishErrors()
+}
+
+// finishErrors sorts the errors, and then folds and cuts them down as
+// WithDeduplication and WithMaxErrors ask.  Do this after validation is
+// complete.
+func (p *Parser) finishErrors() {
 	p.errors.sort()
+	p.errors = p.limitErrors(p.dedupErrors(p.errors))
+}
+
+// parserPool recycles Parser structures, and in particular their posMap
+// and interned strings, between calls to Parse.  Services that validate
+// many files per second would otherwise spend a noticeable fraction of
+// their time growing maps.
+var parserPool = sync.Pool{
+	New: func() interface{} {
+		return &Parser{posMap: make(map[interface{}]ast.Node), interned: make(map[string]string)}
+	},
+}
+
+// newParser returns an empty Parser, with the given options applied.
+// Callers should call release when they are done with it.
+func newParser(ctx context.Context, options ...OptionFunc) *Parser {
+	p := parserPool.Get().(*Parser)
+	p.ctx = ctx
+	p.checks = AllChecks
+	p.maxSecrets = defaultMaxSecrets
+	p.maxDescriptionLen = defaultMaxDescriptionLength
+
+	for _, option := range options {
+		option(p)
+	}
 
 	return p
 }
 
+// release returns p to the pool.  Neither p nor its maps may be used
+// afterwards, but the actions, workflows, and errors it collected remain
+// valid.
+func (p *Parser) release() {
+	posMap, interned := p.posMap, p.interned
+	for k := range posMap {
+		delete(posMap, k)
+	}
+	for k := range interned {
+		delete(interned, k)
+	}
+	*p = Parser{posMap: posMap, interned: interned}
+	parserPool.Put(p)
+}
+
+// intern returns s, or an equal string seen earlier in the same parse.
+// Identifiers turn up again and again, in `needs' and `resolves' and as
+// attribute and variable names, and interning them keeps the model from
+// holding a copy of the source for each.
+func (p *Parser) intern(s string) string {
+	if ret, ok := p.interned[s]; ok {
+		return ret
+	}
+	p.interned[s] = s
+	return s
+}
+
+// internAll interns each of list, in place.
+func (p *Parser) internAll(list []string) []string {
+	for i, s := range list {
+		list[i] = p.intern(s)
+	}
+	return list
+}
+
 func (p *Parser) validate() {
-	p.analyzeDependencies()
-	p.checkCircularDependencies()
-	p.checkActions()
-	p.checkFlows()
+	checks := []struct {
+		check Check
+		run   func()
+	}{
+		{CheckNeeds, p.analyzeDependencies},
+		{CheckCycles, p.checkCircularDependencies},
+		{CheckActions, p.checkActions},
+		{CheckTemplates, p.checkTemplates},
+		{CheckWorkflows, p.checkFlows},
+		{CheckUnused, p.checkUnusedActions},
+		{CheckExpressions, p.checkExpressions},
+	}
+	for _, c := range checks {
+		if p.canceled() {
+			return
+		}
+		if p.checks&c.check != 0 {
+			c.run()
+		}
+	}
+	// A feature the file's version doesn't have is an error whichever
+	// checks are on, like a syntax error.
+	if !p.canceled() {
+		p.checkVersion()
+	}
+	if p.caseCollisions && !p.canceled() {
+		p.checkCaseCollisions()
+	}
+	if !p.canceled() {
+		p.runRules()
+	}
+}
+
+// canceled reports whether the parse has been canceled, in which case the
+// caller should stop and return whatever it has collected so far.
+func (p *Parser) canceled() bool {
+	return p.ctx.Err() != nil
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
 func uniqStrings(items []string) []string {
@@ -118,34 +561,128 @@ func (p *Parser) checkCircularDependencies() {
 		actionmap[action.Identifier] = graph.NI(i)
 	}
 
-	// make an adjacency list representation of the action dependency graph
+	// make an adjacency list representation of the action dependency
+	// graph, with all of the edges sharing one backing array
+	nedges := 0
+	for _, action := range p.actions {
+		nedges += len(action.Needs)
+	}
+	edges := make([]graph.NI, 0, nedges)
 	adjList := make(graph.AdjacencyList, len(p.actions))
 	for i, action := range p.actions {
-		adjList[i] = make([]graph.NI, 0, len(action.Needs))
+		start := len(edges)
 		for _, depName := range action.Needs {
 			if depIdx, ok := actionmap[depName]; ok {
-				adjList[i] = append(adjList[i], depIdx)
+				edges = append(edges, depIdx)
 			}
 		}
+		adjList[i] = edges[start:len(edges):len(edges)]
 	}
 
-	// find cycles, and print a fatal error for each one
+	// Most files have no cycles.  Finding that out is much cheaper than
+	// enumerating the cycles, so check first.
 	g := graph.Directed{AdjacencyList: adjList}
+	if cyclic, _, _ := g.Cyclic(); !cyclic {
+		return
+	}
+
+	// find cycles, and print a fatal error for each one
 	g.Cycles(func(cycle []graph.NI) bool {
+		ids := make([]string, len(cycle), len(cycle)+1)
+		for i, n := range cycle {
+			ids[i] = p.actions[n].Identifier
+		}
 		node := p.posMap[&p.actions[cycle[len(cycle)-1]].Needs]
-		p.addFatal(node, "Circular dependency on `%s'", p.actions[cycle[0]].Identifier)
+		pe := p.addFatal(node, CodeCircularDependency, "Circular dependency on `%s': %s", ids[0], strings.Join(append(ids, ids[0]), " -> "))
+		if pe != nil {
+			pe.Cycle = ids
+			for i, n := range cycle {
+				needs := p.posMap[&p.actions[n].Needs]
+				next := ids[(i+1)%len(ids)]
+				pe.Related = append(pe.Related, RelatedPos{Pos: posFromNode(needs), Message: fmt.Sprintf("`%s' needs `%s'", ids[i], next)})
+			}
+		}
 		return true
 	})
 }
 
+// checkUnusedActions warns about each action that is not resolved by
+// any workflow, directly or through `needs'.  If a workflow resolves
+// nothing, usually because its `resolves' attribute is misspelled or
+// couldn't be parsed, the check would only add noise to the problem
+// already reported, so it is skipped.
+func (p *Parser) checkUnusedActions() {
+	if len(p.workflows) == 0 {
+		return
+	}
+	for _, workflow := range p.workflows {
+		if len(workflow.Resolves) == 0 {
+			return
+		}
+	}
+
+	// Actions are tracked by identifier, so that an action defined twice
+	// isn't reported as unused on top of being redefined.
+	actionmap := makeActionMap(p.actions)
+	used := make(map[string]bool, len(p.actions))
+	queue := make([]*model.Action, 0, len(p.actions))
+	visit := func(ids []string) {
+		for _, id := range ids {
+			if action := actionmap[id]; action != nil && !used[id] {
+				used[id] = true
+				queue = append(queue, action)
+			}
+		}
+	}
+	for _, workflow := range p.workflows {
+		visit(workflow.Resolves)
+	}
+	for len(queue) > 0 {
+		action := queue[0]
+		queue = queue[1:]
+		visit(action.Needs)
+	}
+
+	for _, action := range p.actions {
+		if !used[action.Identifier] {
+			p.addWarning(p.posMap[action], CodeUnusedAction, "Action `%s' is never used", action.Identifier)
+		}
+	}
+}
+
 // checkActions returns error if any actions are syntactically correct but
 // have structural errors
 func (p *Parser) checkActions() {
+	for k := range p.env {
+		p.checkEnvironmentVariable(k, p.fileEnvNode(k))
+	}
+	if p.maxActions > 0 && len(p.actions) > p.maxActions {
+		t := p.actions[p.maxActions]
+		p.addError(identifierNode(p.posMap[t]), CodeTooManyActions, "There are %d actions, more than the maximum of %d", len(p.actions), p.maxActions)
+	}
+
+	ids := make(map[string]bool, len(p.actions)+len(p.workflows))
+	for _, t := range p.actions {
+		ids[t.Identifier] = true
+	}
+	for _, f := range p.workflows {
+		ids[f.Identifier] = true
+	}
+
+	templates := make(map[string]*model.Action, len(p.templates))
+	for _, t := range p.templates {
+		templates[t.Identifier] = t
+	}
+
 	secrets := make(map[string]bool)
 	for _, t := range p.actions {
-		// Ensure the Action has a `uses` attribute
-		if t.Uses == nil {
-			p.addError(p.posMap[t], "Action `%s' must have a `uses' attribute", t.Identifier)
+		// Ensure the Action has a `uses` attribute, or extends a template
+		// that does
+		template, found := templates[t.Extends]
+		if t.Extends != "" && !found {
+			p.addError(p.posMap[&t.Extends], CodeUnknownTemplate, "Action `%s' extends unknown template `%s'", t.Identifier, t.Extends)
+		} else if t.Uses == nil && (template == nil || template.Uses == nil) {
+			p.addError(p.posMap[t], CodeUsesMissing, "Action `%s' must have a `uses' attribute", t.Identifier)
 			// continue, checking other actions
 		}
 
@@ -153,11 +690,12 @@ func (p *Parser) checkActions() {
 		for _, str := range t.Secrets {
 			if !secrets[str] {
 				secrets[str] = true
-				if len(secrets) == maxSecrets+1 {
-					p.addError(p.posMap[&t.Secrets], "All actions combined must not have more than %d unique secrets", maxSecrets)
+				if p.maxSecrets > 0 && len(secrets) == p.maxSecrets+1 {
+					p.addError(p.posMap[&t.Secrets], CodeTooManySecrets, "All actions combined must not have more than %d unique secrets", p.maxSecrets)
 				}
 			}
 		}
+		p.checkActionSecrets(t)
 
 		// Ensure that no environment variable or secret begins with
 		// "GITHUB_", unless it's "GITHUB_TOKEN".
@@ -168,28 +706,169 @@ func (p *Parser) checkActions() {
 		for k := range t.Env {
 			p.checkEnvironmentVariable(k, p.posMap[&t.Env])
 		}
+		p.checkEnvSize(t)
+		p.checkDescription(p.posMap[&t.Description], "action", t.Identifier, t.Description)
+		p.checkMatrix(t, ids)
 		secretVars := make(map[string]bool)
-		for _, k := range t.Secrets {
+		for i, k := range t.Secrets {
 			p.checkEnvironmentVariable(k, p.posMap[&t.Secrets])
 			if _, found := t.Env[k]; found {
-				p.addError(p.posMap[&t.Secrets], "Secret `%s' conflicts with an environment variable with the same name", k)
+				p.addError(p.posMap[&t.Secrets], CodeSecretEnvConflict, "Secret `%s' conflicts with an environment variable with the same name", k)
 			}
 			if secretVars[k] {
-				p.addWarning(p.posMap[&t.Secrets], "Secret `%s' redefined", k)
+				if e := p.addWarning(p.posMap[&t.Secrets], CodeSecretRedefined, "Secret `%s' redefined", k); e != nil {
+					e.Fix = removeListElement(p.posMap[&t.Secrets], len(t.Secrets), i, "Remove duplicate secret `"+k+"'")
+				}
 			}
 			secretVars[k] = true
 		}
 	}
 }
 
+// checkTemplates checks what checkActions checks of actions, where it
+// applies to templates: the names of their environment variables and
+// secrets.  Templates can't have `needs', since the actions they apply
+// to each have their own place in the graph, or extend other templates.
+func (p *Parser) checkTemplates() {
+	for _, t := range p.templates {
+		if len(t.Needs) > 0 {
+			p.addError(p.posMap[&t.Needs], CodeInvalidTemplate, "Template `%s' cannot have `needs'", t.Identifier)
+		}
+		if t.Extends != "" {
+			p.addError(p.posMap[&t.Extends], CodeInvalidTemplate, "Template `%s' cannot extend another template", t.Identifier)
+		}
+		for _, k := range envKeys(t.Env) {
+			p.checkEnvironmentVariable(k, p.posMap[&t.Env])
+		}
+		for _, k := range t.Secrets {
+			p.checkEnvironmentVariable(k, p.posMap[&t.Secrets])
+		}
+	}
+}
+
+// checkMatrix checks that the matrix of an action, if it has one, can be
+// expanded: that each dimension has a name that works in an environment
+// variable and at least one value, with no value listed twice, and that
+// the actions it expands to are few enough and have names of their own.
+func (p *Parser) checkMatrix(action *model.Action, ids map[string]bool) {
+	if len(action.Matrix) == 0 {
+		return
+	}
+	node := p.posMap[&action.Matrix]
+
+	keys := make([]string, 0, len(action.Matrix))
+	for k := range action.Matrix {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	valid := true
+	for _, k := range keys {
+		if !envVarChecker.MatchString(k) {
+			p.addError(node, CodeInvalidMatrix, "Matrix dimension `%s' in action `%s' must contain only A-Z, a-z, 0-9, and _ characters", k, action.Identifier)
+			valid = false
+		}
+		values := action.Matrix[k]
+		if len(values) == 0 {
+			p.addError(node, CodeInvalidMatrix, "Matrix dimension `%s' in action `%s' has no values", k, action.Identifier)
+			valid = false
+		}
+		seen := make(map[string]bool, len(values))
+		for _, v := range values {
+			if seen[v] {
+				p.addError(node, CodeInvalidMatrix, "Value `%s' is listed twice in matrix dimension `%s' of action `%s'", v, k, action.Identifier)
+				valid = false
+			}
+			seen[v] = true
+		}
+		if _, found := action.Env[model.MatrixEnvName(k)]; found {
+			p.addWarning(node, CodeEnvRedefined, "Environment variable `%s' of action `%s' is set by its matrix", model.MatrixEnvName(k), action.Identifier)
+		}
+	}
+	if !valid {
+		return
+	}
+
+	n := 1
+	for _, values := range action.Matrix {
+		n *= len(values)
+		if n > maxMatrixCombinations {
+			p.addError(node, CodeMatrixTooLarge, "Matrix of action `%s' has more than %d combinations", action.Identifier, maxMatrixCombinations)
+			return
+		}
+	}
+	for _, combination := range action.MatrixCombinations() {
+		id := model.MatrixIdentifier(action.Identifier, combination)
+		if ids[id] {
+			p.addError(node, CodeInvalidMatrix, "Matrix of action `%s' expands to `%s', which is already used", action.Identifier, id)
+		}
+	}
+}
+
+// checkDescription checks the length of the description of an action or
+// workflow, found at node, against the limit set by
+// WithMaxDescriptionLength.
+func (p *Parser) checkDescription(node ast.Node, nodeType, id, description string) {
+	if p.maxDescriptionLen <= 0 {
+		return
+	}
+	if n := utf8.RuneCountInString(description); n > p.maxDescriptionLen {
+		p.addError(node, CodeDescriptionTooLong, "Description of %s `%s' is %d characters long, more than the maximum of %d", nodeType, id, n, p.maxDescriptionLen)
+	}
+}
+
+// checkActionSecrets checks the number of unique secrets in an action
+// against the limit set by WithMaxSecretsPerAction.
+func (p *Parser) checkActionSecrets(action *model.Action) {
+	if p.maxActionSecrets <= 0 || len(action.Secrets) <= p.maxActionSecrets {
+		return
+	}
+	unique := make(map[string]bool)
+	for _, s := range action.Secrets {
+		unique[s] = true
+	}
+	if len(unique) > p.maxActionSecrets {
+		p.addError(p.posMap[&action.Secrets], CodeTooManyActionSecrets, "Action `%s' has %d unique secrets, more than the maximum of %d", action.Identifier, len(unique), p.maxActionSecrets)
+	}
+}
+
+// checkEnvSize enforces the configured limits on the length of each
+// environment variable value and on the size of the action's env block as
+// a whole.  The size of the block is measured as the sum of len("KEY=VALUE")
+// over all variables.
+func (p *Parser) checkEnvSize(action *model.Action) {
+	if p.maxEnvValueLength <= 0 && p.maxEnvSize <= 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(action.Env))
+	for k := range action.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	total := 0
+	for _, k := range keys {
+		v := action.Env[k]
+		if p.maxEnvValueLength > 0 && len(v) > p.maxEnvValueLength {
+			p.addError(p.posMap[&action.Env], CodeEnvValueTooLong, "Environment variable `%s' in action `%s' is longer than %d bytes", k, action.Identifier, p.maxEnvValueLength)
+		}
+		total += len(k) + 1 + len(v)
+	}
+
+	if p.maxEnvSize > 0 && total > p.maxEnvSize {
+		p.addError(p.posMap[&action.Env], CodeEnvTooLarge, "Environment of action `%s' is %d bytes, more than the maximum of %d", action.Identifier, total, p.maxEnvSize)
+	}
+}
+
 var envVarChecker = regexp.MustCompile(`\A[A-Za-z_][A-Za-z_0-9]*\z`)
 
 func (p *Parser) checkEnvironmentVariable(key string, node ast.Node) {
 	if key != "GITHUB_TOKEN" && strings.HasPrefix(key, "GITHUB_") {
-		p.addWarning(node, "Environment variables and secrets beginning with `GITHUB_' are reserved")
+		p.addWarning(node, CodeReservedEnvName, "Environment variables and secrets beginning with `GITHUB_' are reserved")
 	}
 	if !envVarChecker.MatchString(key) {
-		p.addWarning(node, "Environment variables and secrets must contain only A-Z, a-z, 0-9, and _ characters, got `%s'", key)
+		p.addWarning(node, CodeInvalidEnvName, "Environment variables and secrets must contain only A-Z, a-z, 0-9, and _ characters, got `%s'", key)
 	}
 }
 
@@ -198,23 +877,83 @@ func (p *Parser) checkEnvironmentVariable(key string, node ast.Node) {
 func (p *Parser) checkFlows() {
 	actionmap := makeActionMap(p.actions)
 	for _, f := range p.workflows {
-		// make sure there's an `on` attribute
-		if f.On == "" {
-			p.addError(p.posMap[f], "Workflow `%s' must have an `on' attribute", f.Identifier)
-			// continue, checking other workflows
-		} else if !isAllowedEventType(f.On) {
-			p.addError(p.posMap[&f.On], "Workflow `%s' has unknown `on' value `%s'", f.Identifier, f.On)
+		// make sure there's an `on` attribute, and that it names only
+		// known events
+		if len(f.On) == 0 {
+			p.addError(p.posMap[f], CodeOnMissing, "Workflow `%s' must have an `on' attribute", f.Identifier)
 			// continue, checking other workflows
 		}
+		for _, event := range f.On {
+			if !p.isAllowedEventType(event.Type) {
+				p.addError(p.posMap[&f.On], CodeUnknownEvent, "Workflow `%s' has unknown `on' value `%s'", f.Identifier, event.Type)
+				// continue, checking other workflows
+			} else if strings.EqualFold(event.Type, model.ScheduleEventType) {
+				p.checkSchedule(f, event)
+			} else if event.Filter != "" && !IsAllowedEventFilter(event.Type, event.Filter) {
+				p.addError(p.posMap[&f.On], CodeUnknownEventFilter, "Workflow `%s' has unknown filter `%s' for event `%s'", f.Identifier, event.Filter, event.Type)
+				// continue, checking other workflows
+			}
+		}
 
 		// make sure that the actions that are resolved all exist
 		for _, actionID := range f.Resolves {
 			_, ok := actionmap[actionID]
 			if !ok {
-				p.addError(p.posMap[&f.Resolves], "Workflow `%s' resolves unknown action `%s'", f.Identifier, actionID)
+				p.addError(p.posMap[&f.Resolves], CodeUnknownResolves, "Workflow `%s' resolves unknown action `%s'", f.Identifier, actionID)
 				// continue, checking other workflows
 			}
 		}
+
+		for k := range f.Env {
+			p.checkEnvironmentVariable(k, p.posMap[&f.Env])
+		}
+		p.checkDescription(p.posMap[&f.Description], "workflow", f.Identifier, f.Description)
+
+		p.checkRedundantResolves(f)
+	}
+}
+
+// checkRedundantResolves warns about each action a workflow resolves that
+// another action it resolves already needs, directly or transitively.
+// Actions that need each other are left to checkCircularDependencies.
+func (p *Parser) checkRedundantResolves(f *model.Workflow) {
+	if len(f.Resolves) < 2 {
+		return
+	}
+	config := &model.Configuration{Actions: p.actions}
+	for i, actionID := range f.Resolves {
+		for _, other := range f.Resolves {
+			if other == actionID || !config.DependsOn(other, actionID) || config.DependsOn(actionID, other) {
+				continue
+			}
+			if e := p.addWarning(p.posMap[&f.Resolves], CodeRedundantResolves, "Workflow `%s' resolves `%s', which `%s' already needs", f.Identifier, actionID, other); e != nil {
+				if item, ok := p.posMap[&f.Resolves].(*ast.ObjectItem); ok {
+					e.Fix = removeListElement(item.Val, len(f.Resolves), i, "Remove `"+actionID+"' from `resolves'")
+				}
+			}
+			break
+		}
+	}
+}
+
+// isAllowedEventType checks eventType against the parser's registry, if
+// it has one, or the built-in event types otherwise.
+func (p *Parser) isAllowedEventType(eventType string) bool {
+	if p.events != nil {
+		return p.events.IsAllowed(eventType)
+	}
+	return IsAllowedEventType(eventType)
+}
+
+// checkSchedule appends an error if a scheduled event doesn't have a
+// valid cron expression.
+func (p *Parser) checkSchedule(f *model.Workflow, event model.Event) {
+	if event.Schedule == nil {
+		p.addError(p.posMap[&f.On], CodeInvalidSchedule, "Workflow `%s' has a `schedule' event without a cron expression, like `schedule(0 * * * *)'", f.Identifier)
+		return
+	}
+	if _, err := model.ParseSchedule(event.Schedule.Expression); err != nil {
+		p.addError(p.posMap[&f.On], CodeInvalidSchedule, "Workflow `%s' has an invalid schedule `%s': %s", f.Identifier, event.Schedule.Expression, err)
 	}
 }
 
@@ -250,7 +989,7 @@ func (p *Parser) analyzeNeeds(action *model.Action, actionmap map[string]*model.
 	for _, need := range action.Needs {
 		_, ok := actionmap[need]
 		if !ok {
-			p.addError(p.posMap[&action.Needs], "Action `%s' needs nonexistent action `%s'", action.Identifier, need)
+			p.addError(p.posMap[&action.Needs], CodeUnknownNeeds, "Action `%s' needs nonexistent action `%s'", action.Identifier, need)
 			// continue, checking other actions
 		}
 	}
@@ -267,7 +1006,7 @@ func (p *Parser) literalToStringMap(node ast.Node) map[string]string {
 	obj, ok := node.(*ast.ObjectType)
 
 	if !ok {
-		p.addError(node, "Expected object, got %s", typename(node))
+		p.addError(node, CodeTypeMismatch, "Expected object, got %s", typename(node))
 		return nil
 	}
 
@@ -283,7 +1022,7 @@ func (p *Parser) literalToStringMap(node ast.Node) map[string]string {
 			key := p.identString(item.Keys[0].Token)
 			if key != "" {
 				if _, found := ret[key]; found {
-					p.addWarning(node, "Environment variable `%s' redefined", key)
+					p.addWarning(node, CodeEnvRedefined, "Environment variable `%s' redefined", key)
 				}
 				ret[key] = str
 			}
@@ -293,14 +1032,50 @@ func (p *Parser) literalToStringMap(node ast.Node) map[string]string {
 	return ret
 }
 
+// literalToMatrix converts an object value from the AST, each of whose
+// values is a list of strings or a single string, into a
+// map[string][]string.  For example, the HCL `{ os=["a", "b"] go="c" }`
+// becomes the Go expression
+// map[string][]string{ "os": {"a", "b"}, "go": {"c"} }.  If the value
+// doesn't adhere to that format, the function appends an appropriate
+// error.
+func (p *Parser) literalToMatrix(node ast.Node, id string) map[string][]string {
+	obj, ok := node.(*ast.ObjectType)
+	if !ok {
+		p.addError(node, CodeTypeMismatch, "Expected object, got %s", typename(node))
+		return nil
+	}
+
+	p.checkAssignmentsOnly(obj.List, "")
+
+	ret := make(map[string][]string)
+	for _, item := range obj.List.Items {
+		if !isAssignment(item) {
+			continue
+		}
+		values, ok := p.literalToStringArray(item.Val, true)
+		if ok {
+			key := p.identString(item.Keys[0].Token)
+			if key != "" {
+				if _, found := ret[key]; found {
+					p.addWarning(item.Val, CodeAttributeRedefined, "Matrix dimension `%s' redefined in action `%s'", key, id)
+				}
+				ret[key] = values
+			}
+		}
+	}
+
+	return ret
+}
+
 func (p *Parser) identString(t token.Token) string {
 	switch t.Type {
 	case token.STRING:
-		return t.Value().(string)
+		return p.intern(tokenString(t))
 	case token.IDENT:
-		return t.Text
+		return p.intern(t.Text)
 	default:
-		p.addErrorFromToken(t,
+		p.addErrorFromToken(t, CodeTypeMismatch,
 			"Each identifier should be a string, got %s",
 			strings.ToLower(t.Type.String()))
 		return ""
@@ -320,15 +1095,15 @@ func (p *Parser) literalToStringArray(node ast.Node, promoteScalars bool) ([]str
 	literal, ok := node.(*ast.LiteralType)
 	if ok {
 		if promoteScalars && literal.Token.Type == token.STRING {
-			return []string{literal.Token.Value().(string)}, true
+			return []string{tokenString(literal.Token)}, true
 		}
-		p.addError(node, "Expected list, got %s", typename(node))
+		p.addError(node, CodeTypeMismatch, "Expected list, got %s", typename(node))
 		return nil, false
 	}
 
 	list, ok := node.(*ast.ListType)
 	if !ok {
-		p.addError(node, "Expected list, got %s", typename(node))
+		p.addError(node, CodeTypeMismatch, "Expected list, got %s", typename(node))
 		return nil, false
 	}
 
@@ -347,6 +1122,9 @@ func (p *Parser) literalToStringArray(node ast.Node, promoteScalars bool) ([]str
 // If the value isn't a scalar or isn't a string, the function appends an
 // appropriate error and returns "", false.
 func (p *Parser) literalToString(node ast.Node) (string, bool) {
+	if literal, ok := node.(*ast.LiteralType); ok && literal.Token.Type == token.STRING {
+		return tokenString(literal.Token), true
+	}
 	val := p.literalCast(node, token.STRING)
 	if val == nil {
 		return "", false
@@ -354,6 +1132,23 @@ func (p *Parser) literalToString(node ast.Node) (string, bool) {
 	return val.(string), true
 }
 
+// tokenString returns the value of a STRING token.  It is equivalent to
+// t.Value().(string), but avoids boxing the string in an interface{},
+// which is one of the most common allocations while parsing.  A string
+// HCL can't unquote, like one with an unterminated `${', is unquoted the
+// way Go would, leaving CheckExpressions to report it.
+func tokenString(t token.Token) string {
+	if !t.JSON {
+		if s, err := hclstrconv.Unquote(t.Text); err == nil {
+			return s
+		}
+		if s, err := strconv.Unquote(t.Text); err == nil {
+			return s
+		}
+	}
+	return t.Value().(string)
+}
+
 // literalToInt converts a literal value from the AST into an int64.
 // Supported number formats are: 123, 0x123, and 0123.
 // Exponents (1e6) and floats (123.456) generate errors.
@@ -367,50 +1162,105 @@ func (p *Parser) literalToInt(node ast.Node) (int64, bool) {
 	return val.(int64), true
 }
 
+// literalToBool converts a literal value from the AST, `true' or
+// `false', into a bool.  If the value isn't a scalar or isn't a boolean,
+// the function appends an appropriate error and returns false, false.
+func (p *Parser) literalToBool(node ast.Node) (bool, bool) {
+	val := p.literalCast(node, token.BOOL)
+	if val == nil {
+		return false, false
+	}
+	return val.(bool), true
+}
+
 func (p *Parser) literalCast(node ast.Node, t token.Type) interface{} {
 	literal, ok := node.(*ast.LiteralType)
 	if !ok {
-		p.addError(node, "Expected %s, got %s", strings.ToLower(t.String()), typename(node))
+		p.addError(node, CodeTypeMismatch, "Expected %s, got %s", strings.ToLower(t.String()), typename(node))
 		return nil
 	}
 
 	if literal.Token.Type != t {
-		p.addError(node, "Expected %s, got %s", strings.ToLower(t.String()), typename(node))
+		p.addError(node, CodeTypeMismatch, "Expected %s, got %s", strings.ToLower(t.String()), typename(node))
 		return nil
 	}
 
 	return literal.Token.Value()
 }
 
-// parseRoot parses the root of the AST, filling in p.version, p.actions,
-// and p.workflows.
-func (p *Parser) parseRoot(node ast.Node) {
+// parseRoots parses the root of each file's AST in turn, filling in
+// p.version, p.actions, and p.workflows, and detecting identifiers that
+// are defined more than once, whether in the same file or in different
+// ones.
+func (p *Parser) parseRoots(roots []*ast.File) {
+	p.actions = make([]*model.Action, 0)
+	p.workflows = make([]*model.Workflow, 0)
+	identifiers := make(map[string]token.Pos)
+	for _, root := range roots {
+		p.parseRoot(root, identifiers)
+	}
+}
+
+// parseRoot parses the blocks of a single file.  Identifiers maps the
+// identifier of each block parsed so far, from any file, to its position.
+func (p *Parser) parseRoot(file *ast.File, identifiers map[string]token.Pos) {
+	p.comments = newCommentMap(file)
+	defer func() {
+		p.fileComments.Trailing = append(p.fileComments.Trailing, p.comments.trailing...)
+	}()
+
+	node := file.Node
 	objectList, ok := node.(*ast.ObjectList)
 	if !ok {
 		// It should be impossible for HCL to return anything other than an
 		// ObjectList as the root node.  This error should never happen.
-		p.addError(node, "Internal error: root node must be an ObjectList")
+		p.addError(node, CodeInternal, "Internal error: root node must be an ObjectList")
 		return
 	}
 
-	p.actions = make([]*model.Action, 0, len(objectList.Items))
-	p.workflows = make([]*model.Workflow, 0, len(objectList.Items))
-	identifiers := make(map[string]bool)
 	for idx, item := range objectList.Items {
-		if item.Assign.IsValid() {
-			p.parseVersion(idx, item)
-			continue
+		if p.canceled() {
+			return
+		}
+		if kind, id, ok := p.parseItem(idx, item); ok {
+			p.checkIdentifier(item, kind, id, identifiers)
 		}
-		p.parseBlock(item, identifiers)
 	}
 }
 
-// parseBlock parses a single, top-level "action" or "workflow" block,
-// appending it to p.actions or p.workflows as appropriate.
-func (p *Parser) parseBlock(item *ast.ObjectItem, identifiers map[string]bool) {
+// parseItem parses the top-level item at index idx: a `version'
+// statement, an `env' block, an `include' statement, or a block.  For a
+// block, it returns the block's kind and identifier, and whether they
+// should be checked for uniqueness.
+func (p *Parser) parseItem(idx int, item *ast.ObjectItem) (string, string, bool) {
+	if item.Assign.IsValid() {
+		if isFileEnv(item) {
+			p.addFileEnv(item.Val, p.literalToStringMap(item.Val), p.envBlockComments(item))
+			return "", "", false
+		}
+		if isInclude(item) {
+			p.parseInclude(item)
+			return "", "", false
+		}
+		p.parseVersion(idx, item)
+		c := p.comments.get(item)
+		p.fileComments.Lead = append(p.fileComments.Lead, c.Lead...)
+		if c.Line != "" {
+			p.fileComments.Lead = append(p.fileComments.Lead, c.Line)
+		}
+		return "", "", false
+	}
+	return p.parseBlock(item)
+}
+
+// parseBlock parses a single, top-level "action", "workflow", or
+// "template" block, appending it to p.actions, p.workflows, or
+// p.templates as appropriate.  It returns the block's kind and
+// identifier, and false if it isn't a valid declaration at all.
+func (p *Parser) parseBlock(item *ast.ObjectItem) (string, string, bool) {
 	if len(item.Keys) != 2 {
-		p.addError(item, "Invalid toplevel declaration")
-		return
+		p.addError(item, CodeInvalidDeclaration, "Invalid toplevel declaration")
+		return "", "", false
 	}
 
 	cmd := p.identString(item.Keys[0].Token)
@@ -418,11 +1268,17 @@ func (p *Parser) parseBlock(item *ast.ObjectItem, identifiers map[string]bool) {
 
 	switch cmd {
 	case "action":
-		action := p.actionifyItem(item)
+		action := p.actionifyItem(item, cmd)
 		if action != nil {
 			id = action.Identifier
 			p.actions = append(p.actions, action)
 		}
+	case "template":
+		template := p.actionifyItem(item, cmd)
+		if template != nil {
+			id = template.Identifier
+			p.templates = append(p.templates, template)
+		}
 	case "workflow":
 		workflow := p.workflowifyItem(item)
 		if workflow != nil {
@@ -430,27 +1286,97 @@ func (p *Parser) parseBlock(item *ast.ObjectItem, identifiers map[string]bool) {
 			p.workflows = append(p.workflows, workflow)
 		}
 	default:
-		p.addError(item, "Invalid toplevel keyword, `%s'", cmd)
+		p.addError(item, CodeInvalidDeclaration, "Invalid toplevel keyword, `%s'", cmd)
+		return "", "", false
+	}
+	return cmd, id, true
+}
+
+// isFileEnv reports whether item, a top-level assignment, is an `env'
+// block.
+func isFileEnv(item *ast.ObjectItem) bool {
+	return item.Assign.IsValid() && len(item.Keys) == 1 && keyString(item.Keys[0].Token) == "env"
+}
+
+// addFileEnv adds the variables of a top-level `env' block, found at
+// node, to those of the blocks before it, in this file or an earlier
+// one, warning about each variable they already set.
+func (p *Parser) addFileEnv(node ast.Node, env map[string]string, comments map[string]model.Comments) {
+	p.envNodes = append(p.envNodes, node)
+	if env == nil {
 		return
 	}
+	if p.env == nil {
+		p.env = make(map[string]string, len(env))
+	}
+	for _, k := range envKeys(env) {
+		if _, found := p.env[k]; found {
+			p.addWarning(node, CodeEnvRedefined, "Environment variable `%s' redefined", k)
+		}
+		p.env[k] = env[k]
+	}
+	for k, c := range comments {
+		if p.envComments == nil {
+			p.envComments = make(map[string]model.Comments)
+		}
+		p.envComments[k] = c
+	}
+}
 
-	if identifiers[id] {
-		p.addError(item, "Identifier `%s' redefined", id)
+// fileEnvNode returns the value of the variable k in the last top-level
+// `env' block that sets it, or nil if there is none.
+func (p *Parser) fileEnvNode(k string) ast.Node {
+	for i := len(p.envNodes) - 1; i >= 0; i-- {
+		if node := p.envValueNode(p.envNodes[i], k); node != p.envNodes[i] {
+			return node
+		}
 	}
+	return nil
+}
 
-	identifiers[id] = true
+// checkIdentifier reports a block whose identifier is already used.
+// Identifiers maps the identifier of each block checked so far, from any
+// file, to its position.
+func (p *Parser) checkIdentifier(item *ast.ObjectItem, cmd, id string, identifiers map[string]token.Pos) {
+	// Actions and workflows share a single namespace unless the caller
+	// asked for them to be kept apart.  Templates always have their own.
+	key := id
+	if p.separateNamespaces || cmd == "template" {
+		key = cmd + " " + id
+	}
+
+	first, ok := identifiers[key]
+	switch {
+	case !ok:
+		identifiers[key] = item.Keys[1].Token.Pos
+	case first.Filename != item.Keys[1].Token.Pos.Filename:
+		e := p.addError(item, CodeIdentifierRedefined, "Identifier `%s' redefined, first defined in %s", id, first.Filename)
+		addFirstDefined(e, ErrorPos{File: first.Filename, Line: first.Line, Column: first.Column, Offset: first.Offset})
+	default:
+		e := p.addError(item, CodeIdentifierRedefined, "Identifier `%s' redefined", id)
+		addFirstDefined(e, ErrorPos{File: first.Filename, Line: first.Line, Column: first.Column, Offset: first.Offset})
+	}
+}
+
+// addFirstDefined points e, about something defined twice, at where it
+// was first defined.  e may be nil, for a suppressed diagnostic.
+func addFirstDefined(e *ParseError, first ErrorPos) {
+	if e != nil && first.Line > 0 {
+		e.Related = append(e.Related, RelatedPos{Pos: first, Message: "First defined here"})
+	}
 }
 
 // parseVersion parses a top-level `version=N` statement, filling in
-// p.version.
+// p.versions for the file it is in, and p.version, the highest version
+// of any file.
 func (p *Parser) parseVersion(idx int, item *ast.ObjectItem) {
 	if len(item.Keys) != 1 || p.identString(item.Keys[0].Token) != "version" {
 		// not a valid `version` declaration
-		p.addError(item.Val, "Toplevel declarations cannot be assignments")
+		p.addError(item.Val, CodeInvalidDeclaration, "Toplevel declarations cannot be assignments")
 		return
 	}
 	if idx != 0 {
-		p.addError(item.Val, "`version` must be the first declaration")
+		p.addError(item.Val, CodeVersionNotFirst, "`version` must be the first declaration")
 		return
 	}
 	version, ok := p.literalToInt(item.Val)
@@ -458,10 +1384,10 @@ func (p *Parser) parseVersion(idx int, item *ast.ObjectItem) {
 		return
 	}
 	if version < minVersion || version > maxVersion {
-		p.addError(item.Val, "`version = %d` is not supported", version)
+		p.addError(item.Val, CodeUnsupportedVersion, "`version = %d` is not supported", version)
 		return
 	}
-	p.version = int(version)
+	p.setVersion(item.Val, int(version))
 }
 
 // parseIdentifier parses the double-quoted identifier (name) for a
@@ -469,28 +1395,36 @@ func (p *Parser) parseVersion(idx int, item *ast.ObjectItem) {
 func (p *Parser) parseIdentifier(key *ast.ObjectKey) string {
 	id := key.Token.Text
 	if len(id) < 3 || id[0] != '"' || id[len(id)-1] != '"' {
-		p.addError(key, "Invalid format for identifier `%s'", id)
+		e := p.addError(key, CodeInvalidIdentifier, "Invalid format for identifier `%s'", id)
+		if e != nil && key.Token.Type == token.IDENT {
+			e.Fix = quoteKey(key)
+		}
 		return ""
 	}
-	return id[1 : len(id)-1]
+	id = p.intern(id[1 : len(id)-1])
+	p.checkIdentifierFormat(key, id)
+	return id
 }
 
 // parseRequiredString parses a string value, setting its value into the
 // out-parameter `value` and returning true if successful.
 func (p *Parser) parseRequiredString(value *string, val ast.Node, nodeType, name, id string) bool {
 	if *value != "" {
-		p.addWarning(val, "`%s' redefined in %s `%s'", name, nodeType, id)
+		e := p.addWarning(val, CodeAttributeRedefined, "`%s' redefined in %s `%s'", name, nodeType, id)
+		if first, ok := p.posMap[value]; ok {
+			addFirstDefined(e, posFromNode(first))
+		}
 		// continue, allowing the redefinition
 	}
 
 	newVal, ok := p.literalToString(val)
 	if !ok {
-		p.addError(val, "Invalid format for `%s' in %s `%s', expected string", name, nodeType, id)
+		p.addError(val, CodeTypeMismatch, "Invalid format for `%s' in %s `%s', expected string", name, nodeType, id)
 		return false
 	}
 
 	if newVal == "" {
-		p.addError(val, "`%s' value in %s `%s' cannot be blank", name, nodeType, id)
+		p.addError(val, CodeBlankValue, "`%s' value in %s `%s' cannot be blank", name, nodeType, id)
 		return false
 	}
 
@@ -509,7 +1443,7 @@ func (p *Parser) parseBlockPreamble(item *ast.ObjectItem, nodeType string) (stri
 	node := item.Val
 	obj, ok := node.(*ast.ObjectType)
 	if !ok {
-		p.addError(node, "Each %s must have an { ...  } block", nodeType)
+		p.addError(node, CodeInvalidDeclaration, "Each %s must have an { ...  } block", nodeType)
 		return "", nil
 	}
 
@@ -518,20 +1452,29 @@ func (p *Parser) parseBlockPreamble(item *ast.ObjectItem, nodeType string) (stri
 	return id, obj
 }
 
-// actionifyItem converts an AST block to an Action object.
-func (p *Parser) actionifyItem(item *ast.ObjectItem) *model.Action {
-	id, obj := p.parseBlockPreamble(item, "action")
+// actionifyItem converts an AST block to an Action object.  NodeType is
+// "action" or "template", which have the same attributes.
+func (p *Parser) actionifyItem(item *ast.ObjectItem, nodeType string) *model.Action {
+	id, obj := p.parseBlockPreamble(item, nodeType)
 	if obj == nil {
 		return nil
 	}
 
 	action := &model.Action{
 		Identifier: id,
+		Pos:        modelPos(item.Keys[0].Token),
+		End:        modelPos(token.Token{Pos: obj.Rbrace}),
+		Positions:  make(map[string]model.Pos, len(obj.List.Items)),
 	}
+	action.Comments, action.AttributeComments = p.blockComments(item, obj)
 	p.posMap[action] = item
 
 	for _, item := range obj.List.Items {
-		p.parseActionAttribute(p.identString(item.Keys[0].Token), action, item.Val)
+		name := p.identString(item.Keys[0].Token)
+		p.parseActionAttribute(item.Keys[0], name, action, item.Val)
+		if containsString(actionAttributes, name) {
+			action.Positions[name] = modelPos(item.Keys[0].Token)
+		}
 	}
 
 	return action
@@ -543,35 +1486,68 @@ func (p *Parser) actionifyItem(item *ast.ObjectItem) *model.Action {
 // It also has higher-than-normal cyclomatic complexity, so we ask the
 // gocyclo linter to ignore it.
 // nolint: gocyclo
-func (p *Parser) parseActionAttribute(name string, action *model.Action, val ast.Node) {
+func (p *Parser) parseActionAttribute(key *ast.ObjectKey, name string, action *model.Action, val ast.Node) {
 	switch name {
+	case "description":
+		if p.parseRequiredString(&action.Description, val, "action", name, action.Identifier) {
+			p.posMap[&action.Description] = val
+		}
+	case "extends":
+		if p.parseRequiredString(&action.Extends, val, "action", name, action.Identifier) {
+			p.posMap[&action.Extends] = val
+		}
 	case "uses":
 		p.parseUses(action, val)
+		p.posMap[&action.Uses] = val
 	case "needs":
 		if needs, ok := p.literalToStringArray(val, true); ok {
-			action.Needs = needs
+			action.Needs = p.internAll(needs)
 			p.posMap[&action.Needs] = val
 		}
+	case "if":
+		if p.parseRequiredString(&action.If, val, "action", name, action.Identifier) {
+			p.posMap[&action.If] = val
+		}
 	case "runs":
-		if runs := p.parseCommand(action, action.Runs, name, val, false); runs != nil {
+		if runs := p.parseCommand(action, action.Runs, key, name, val, false); runs != nil {
 			action.Runs = runs
+			p.posMap[&action.Runs] = val
 		}
 	case "args":
-		if args := p.parseCommand(action, action.Args, name, val, true); args != nil {
+		if args := p.parseCommand(action, action.Args, key, name, val, true); args != nil {
 			action.Args = args
+			p.posMap[&action.Args] = val
 		}
 	case "env":
 		if env := p.literalToStringMap(val); env != nil {
 			action.Env = env
 		}
 		p.posMap[&action.Env] = val
+	case "matrix":
+		if first, found := p.posMap[&action.Matrix]; found {
+			e := p.addWarning(val, CodeAttributeRedefined, "`%s' redefined in action `%s'", name, action.Identifier)
+			addFirstDefined(e, posFromNode(first))
+		}
+		if matrix := p.literalToMatrix(val, action.Identifier); matrix != nil {
+			action.Matrix = matrix
+		}
+		p.posMap[&action.Matrix] = val
 	case "secrets":
 		if secrets, ok := p.literalToStringArray(val, false); ok {
 			action.Secrets = secrets
 			p.posMap[&action.Secrets] = val
 		}
+	case "continue_on_error":
+		if first, found := p.posMap[&action.ContinueOnError]; found {
+			e := p.addWarning(val, CodeAttributeRedefined, "`%s' redefined in action `%s'", name, action.Identifier)
+			addFirstDefined(e, posFromNode(first))
+		}
+		if b, ok := p.literalToBool(val); ok {
+			action.ContinueOnError = b
+			p.posMap[&action.ContinueOnError] = val
+		}
 	default:
-		p.addWarning(val, "Unknown action attribute `%s'", name)
+		p.addUnknownAttribute(key, val, "action", name, actionAttributes)
 	}
 }
 
@@ -579,7 +1555,10 @@ func (p *Parser) parseActionAttribute(name string, action *model.Action, val ast
 // node.  This function enforces formatting requirements on the value.
 func (p *Parser) parseUses(action *model.Action, node ast.Node) {
 	if action.Uses != nil {
-		p.addWarning(node, "`uses' redefined in action `%s'", action.Identifier)
+		e := p.addWarning(node, CodeAttributeRedefined, "`uses' redefined in action `%s'", action.Identifier)
+		if first, ok := p.posMap[&action.Uses]; ok {
+			addFirstDefined(e, posFromNode(first))
+		}
 		// continue, allowing the redefinition
 	}
 	strVal, ok := p.literalToString(node)
@@ -589,45 +1568,27 @@ func (p *Parser) parseUses(action *model.Action, node ast.Node) {
 
 	if strVal == "" {
 		action.Uses = &model.UsesInvalid{}
-		p.addError(node, "`uses' value in action `%s' cannot be blank", action.Identifier)
-		return
-	}
-	if strings.HasPrefix(strVal, "./") {
-		action.Uses = &model.UsesPath{Path: strings.TrimPrefix(strVal, "./")}
+		p.addError(node, CodeBlankValue, "`uses' value in action `%s' cannot be blank", action.Identifier)
 		return
 	}
 
-	if strings.HasPrefix(strVal, "docker://") {
-		action.Uses = &model.UsesDockerImage{Image: strings.TrimPrefix(strVal, "docker://")}
-		return
-	}
-
-	tok := strings.Split(strVal, "@")
-	if len(tok) != 2 {
-		action.Uses = &model.UsesInvalid{Raw: strVal}
-		p.addError(node, "The `uses' attribute must be a path, a Docker image, or owner/repo@ref")
-		return
-	}
-	ref := tok[1]
-	tok = strings.SplitN(tok[0], "/", 3)
-	if len(tok) < 2 {
-		action.Uses = &model.UsesInvalid{Raw: strVal}
-		p.addError(node, "The `uses' attribute must be a path, a Docker image, or owner/repo@ref")
-		return
-	}
-	usesRepo := &model.UsesRepository{Repository: tok[0] + "/" + tok[1], Ref: ref}
-	action.Uses = usesRepo
-	if len(tok) == 3 {
-		usesRepo.Path = tok[2]
+	action.Uses = model.ParseUses(strVal)
+	switch uses := action.Uses.(type) {
+	case *model.UsesInvalid:
+		p.addError(node, CodeUsesInvalid, "The `uses' attribute must be a path, a Docker image, or owner/repo@ref")
+	case *model.UsesDockerImage:
+		if _, err := model.ParseDockerImage(uses.Image); err != nil {
+			p.addError(node, CodeImageInvalid, "Invalid Docker image `%s' in action `%s': %s", uses.Image, action.Identifier, err)
+		}
 	}
 }
 
 // parseUses sets the action.Runs or action.Args value based on the
 // contents of the AST node.  This function enforces formatting
 // requirements on the value.
-func (p *Parser) parseCommand(action *model.Action, cmd model.Command, name string, node ast.Node, allowBlank bool) model.Command {
+func (p *Parser) parseCommand(action *model.Action, cmd model.Command, key *ast.ObjectKey, name string, node ast.Node, allowBlank bool) model.Command {
 	if cmd != nil {
-		p.addWarning(node, "`%s' redefined in action `%s'", name, action.Identifier)
+		p.addWarning(node, CodeAttributeRedefined, "`%s' redefined in action `%s'", name, action.Identifier)
 		// continue, allowing the redefinition
 	}
 
@@ -639,20 +1600,43 @@ func (p *Parser) parseCommand(action *model.Action, cmd model.Command, name stri
 		return nil
 	}
 
+	// A heredoc keeps its lines, and is split at whitespace like a string.
+	if literal, ok := node.(*ast.LiteralType); ok && literal.Token.Type == token.HEREDOC {
+		heredoc := heredocCommand(literal.Token)
+		if heredoc.Value == "" && !allowBlank {
+			if e := p.addError(node, CodeBlankValue, "`%s' value in action `%s' cannot be blank", name, action.Identifier); e != nil {
+				e.Fix = removeAttribute(key, node)
+			}
+			return nil
+		}
+		return heredoc
+	}
+
 	// If not, parse a whitespace-separated string into a list.
 	var raw string
 	var ok bool
 	if raw, ok = p.literalToString(node); !ok {
-		p.addError(node, "The `%s' attribute must be a string or a list", name)
+		p.addError(node, CodeTypeMismatch, "The `%s' attribute must be a string or a list", name)
 		return nil
 	}
 	if raw == "" && !allowBlank {
-		p.addError(node, "`%s' value in action `%s' cannot be blank", name, action.Identifier)
+		if e := p.addError(node, CodeBlankValue, "`%s' value in action `%s' cannot be blank", name, action.Identifier); e != nil {
+			e.Fix = removeAttribute(key, node)
+		}
 		return nil
 	}
 	return &model.StringCommand{Value: raw}
 }
 
+// heredocCommand returns the command in a HEREDOC token, like
+// "<<EOF\necho hi\nEOF\n".
+func heredocCommand(t token.Token) *model.HeredocCommand {
+	marker := t.Text[2:strings.IndexByte(t.Text, '\n')]
+	marker = strings.TrimSuffix(strings.TrimPrefix(marker, "-"), "\r")
+	value, _ := t.Value().(string)
+	return &model.HeredocCommand{Value: strings.TrimSuffix(value, "\n"), Marker: marker}
+}
+
 func typename(val interface{}) string {
 	switch cast := val.(type) {
 	case *ast.ListType:
@@ -666,6 +1650,72 @@ func typename(val interface{}) string {
 	}
 }
 
+// parseEvents sets workflow.On from the value of an `on' attribute, which
+// can be a single event or a list of them.
+func (p *Parser) parseEvents(workflow *model.Workflow, val ast.Node) bool {
+	if workflow.On != nil {
+		e := p.addWarning(val, CodeAttributeRedefined, "`on' redefined in workflow `%s'", workflow.Identifier)
+		if first, ok := p.posMap[&workflow.On]; ok {
+			addFirstDefined(e, posFromNode(first))
+		}
+		// continue, allowing the redefinition
+	}
+
+	if _, ok := val.(*ast.ListType); !ok {
+		var s string
+		if !p.parseRequiredString(&s, val, "workflow", "on", workflow.Identifier) {
+			return false
+		}
+		p.checkEventSyntax(val, workflow, s)
+		workflow.On = model.ParseEvents(s)
+		return true
+	}
+
+	types, ok := p.literalToStringArray(val, false)
+	if !ok {
+		p.addError(val, CodeTypeMismatch, "Invalid format for `on' in workflow `%s', expected string or list of strings", workflow.Identifier)
+		return false
+	}
+	if len(types) == 0 {
+		p.addError(val, CodeBlankValue, "`on' value in workflow `%s' cannot be blank", workflow.Identifier)
+		return false
+	}
+
+	events := make([]model.Event, 0, len(types))
+	seen := make(map[string]bool, len(types))
+	for _, t := range types {
+		if t == "" {
+			p.addError(val, CodeBlankValue, "`on' value in workflow `%s' cannot be blank", workflow.Identifier)
+			return false
+		}
+		if !p.checkEventSyntax(val, workflow, t) {
+			continue
+		}
+		if seen[strings.ToLower(t)] {
+			p.addWarning(val, CodeEventRedefined, "Event `%s' listed more than once in workflow `%s'", t, workflow.Identifier)
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		events = append(events, model.ParseEvent(t))
+	}
+	if len(events) == 0 {
+		return false
+	}
+	workflow.On = events
+	return true
+}
+
+// checkEventSyntax appends an error if an `on' value has a dot but no
+// filter after it, like "pull_request.".  Whether the type and filter
+// exist is checked later, by checkFlows.
+func (p *Parser) checkEventSyntax(val ast.Node, workflow *model.Workflow, s string) bool {
+	if strings.HasSuffix(s, ".") {
+		p.addError(val, CodeBlankValue, "Event `%s' in workflow `%s' has a blank filter", s, workflow.Identifier)
+		return false
+	}
+	return true
+}
+
 // workflowifyItem converts an AST block to a Workflow object.
 func (p *Parser) workflowifyItem(item *ast.ObjectItem) *model.Workflow {
 	id, obj := p.parseBlockPreamble(item, "workflow")
@@ -674,29 +1724,50 @@ func (p *Parser) workflowifyItem(item *ast.ObjectItem) *model.Workflow {
 	}
 
 	var ok bool
-	workflow := &model.Workflow{Identifier: id}
+	workflow := &model.Workflow{
+		Identifier: id,
+		Pos:        modelPos(item.Keys[0].Token),
+		End:        modelPos(token.Token{Pos: obj.Rbrace}),
+		Positions:  make(map[string]model.Pos, len(obj.List.Items)),
+	}
+	workflow.Comments, workflow.AttributeComments = p.blockComments(item, obj)
 	for _, item := range obj.List.Items {
 		name := p.identString(item.Keys[0].Token)
+		if containsString(workflowAttributes, name) {
+			workflow.Positions[name] = modelPos(item.Keys[0].Token)
+		}
 
 		switch name {
+		case "description":
+			if p.parseRequiredString(&workflow.Description, item.Val, "workflow", name, id) {
+				p.posMap[&workflow.Description] = item.Val
+			}
 		case "on":
-			ok = p.parseRequiredString(&workflow.On, item.Val, "workflow", name, id)
-			if ok {
+			if p.parseEvents(workflow, item.Val) {
 				p.posMap[&workflow.On] = item
 			}
+		case "env":
+			if env := p.literalToStringMap(item.Val); env != nil {
+				workflow.Env = env
+			}
+			p.posMap[&workflow.Env] = item.Val
 		case "resolves":
 			if workflow.Resolves != nil {
-				p.addWarning(item.Val, "`resolves' redefined in workflow `%s'", id)
+				e := p.addWarning(item.Val, CodeAttributeRedefined, "`resolves' redefined in workflow `%s'", id)
+				if first, ok := p.posMap[&workflow.Resolves]; ok {
+					addFirstDefined(e, posFromNode(first))
+				}
 				// continue, allowing the redefinition
 			}
 			workflow.Resolves, ok = p.literalToStringArray(item.Val, true)
+			p.internAll(workflow.Resolves)
 			p.posMap[&workflow.Resolves] = item
 			if !ok {
-				p.addError(item.Val, "Invalid format for `resolves' in workflow `%s', expected list of strings", id)
+				p.addError(item.Val, CodeTypeMismatch, "Invalid format for `resolves' in workflow `%s', expected list of strings", id)
 				// continue, allowing workflow with no `resolves`
 			}
 		default:
-			p.addWarning(item.Val, "Unknown workflow attribute `%s'", name)
+			p.addUnknownAttribute(item.Keys[0], item.Val, "workflow", name, workflowAttributes)
 			// continue, treat as no-op
 		}
 	}
@@ -720,7 +1791,7 @@ func (p *Parser) checkAssignmentsOnly(objectList *ast.ObjectList, actionID strin
 			} else {
 				desc = fmt.Sprintf("action `%s'", actionID)
 			}
-			p.addErrorFromObjectItem(item, "Each attribute of %s must be an assignment", desc)
+			p.addErrorFromObjectItem(item, CodeInvalidDeclaration, "Each attribute of %s must be an assignment", desc)
 			continue
 		}
 
@@ -731,34 +1802,62 @@ func (p *Parser) checkAssignmentsOnly(objectList *ast.ObjectList, actionID strin
 	}
 }
 
-func (p *Parser) addWarning(node ast.Node, format string, a ...interface{}) {
-	if p.suppressSeverity < WARNING {
-		p.errors = append(p.errors, newWarning(posFromNode(node), format, a...))
-	}
+func (p *Parser) addWarning(node ast.Node, code Code, format string, a ...interface{}) *ParseError {
+	return p.appendError(newWarning(posFromNode(node), code, format, a...))
 }
 
-func (p *Parser) addError(node ast.Node, format string, a ...interface{}) {
-	if p.suppressSeverity < ERROR {
-		p.errors = append(p.errors, newError(posFromNode(node), format, a...))
-	}
+func (p *Parser) addError(node ast.Node, code Code, format string, a ...interface{}) *ParseError {
+	return p.appendError(newError(posFromNode(node), code, format, a...))
 }
 
-func (p *Parser) addErrorFromToken(t token.Token, format string, a ...interface{}) {
-	if p.suppressSeverity < ERROR {
-		p.errors = append(p.errors, newError(posFromToken(t), format, a...))
-	}
+func (p *Parser) addErrorFromToken(t token.Token, code Code, format string, a ...interface{}) *ParseError {
+	return p.appendError(newError(posFromToken(t), code, format, a...))
+}
+
+func (p *Parser) addErrorFromObjectItem(objectItem *ast.ObjectItem, code Code, format string, a ...interface{}) *ParseError {
+	return p.appendError(newError(posFromObjectItem(objectItem), code, format, a...))
+}
+
+func (p *Parser) addFatal(node ast.Node, code Code, format string, a ...interface{}) *ParseError {
+	return p.appendError(newFatal(posFromNode(node), code, format, a...))
 }
 
-func (p *Parser) addErrorFromObjectItem(objectItem *ast.ObjectItem, format string, a ...interface{}) {
-	if p.suppressSeverity < ERROR {
-		p.errors = append(p.errors, newError(posFromObjectItem(objectItem), format, a...))
+// appendError adds e to the list of errors and returns it, so the caller
+// can attach further information, like a Fix.  It first applies any
+// severity override for e's code, and returns nil without adding e if
+// its severity is suppressed.  A suppressSeverity of zero suppresses
+// nothing, not even INFO.
+func (p *Parser) appendError(e *ParseError) *ParseError {
+	if sev, ok := p.severities[e.Code]; ok {
+		e.Severity = sev
 	}
+	if p.suppressSeverity != 0 && e.Severity <= p.suppressSeverity {
+		return nil
+	}
+	p.errors = append(p.errors, e)
+	return e
 }
 
-func (p *Parser) addFatal(node ast.Node, format string, a ...interface{}) {
-	if p.suppressSeverity < FATAL {
-		p.errors = append(p.errors, newFatal(posFromNode(node), format, a...))
+// limitErrors returns errors, which must be sorted, cut down to the
+// limit set by WithMaxErrors.  The diagnostic that replaces the dropped
+// ones is at the position of the first of them, and as severe as the
+// most severe of them, so that cutting the list never makes the file
+// look better than it is.
+func (p *Parser) limitErrors(errors ErrorList) ErrorList {
+	if p.maxErrors <= 0 || len(errors) <= p.maxErrors {
+		return errors
+	}
+	dropped := errors[p.maxErrors:]
+	e := newError(dropped[0].Pos, CodeTooManyErrors, "Too many errors, %d more not shown", len(dropped))
+	e.Severity = dropped[0].Severity
+	for _, d := range dropped[1:] {
+		if d.Severity > e.Severity {
+			e.Severity = d.Severity
+		}
 	}
+	ret := make(ErrorList, p.maxErrors, p.maxErrors+1)
+	copy(ret, errors)
+	return append(ret, e)
 }
 
 // posFromNode returns an ErrorPos (file, line, and column) from an AST
@@ -787,7 +1886,7 @@ func posFromNode(node ast.Node) ErrorPos {
 	if pos == nil {
 		return ErrorPos{}
 	}
-	return ErrorPos{File: pos.Filename, Line: pos.Line, Column: pos.Column}
+	return ErrorPos{File: pos.Filename, Line: pos.Line, Column: pos.Column, Offset: pos.Offset}
 }
 
 // posFromObjectItem returns an ErrorPos from an ObjectItem.  This is for
@@ -800,8 +1899,13 @@ func posFromObjectItem(item *ast.ObjectItem) ErrorPos {
 	return ErrorPos{}
 }
 
+// modelPos returns the position of a Token, for the model.
+func modelPos(t token.Token) model.Pos {
+	return model.Pos{File: t.Pos.Filename, Line: t.Pos.Line, Column: t.Pos.Column, Offset: t.Pos.Offset}
+}
+
 // posFromToken returns an ErrorPos from a Token.  We can't use
 // posFromNode here because Tokens aren't Nodes.
 func posFromToken(token token.Token) ErrorPos {
-	return ErrorPos{File: token.Pos.Filename, Line: token.Pos.Line, Column: token.Pos.Column}
+	return ErrorPos{File: token.Pos.Filename, Line: token.Pos.Line, Column: token.Pos.Column, Offset: token.Pos.Offset}
 }
@@ -1,8 +1,13 @@
 package parser
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/actions/workflow-parser/model"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +39,33 @@ func TestSeveritySuppression(t *testing.T) {
 	assertParseSuccess(t, err, 1, 0, workflow)
 }
 
+func TestSeverityOverride(t *testing.T) {
+	src := `
+	  action "a" {
+		  uses = "owner/repo@master"
+		  argz = "x"
+	  }`
+
+	workflow, err := parseString(src, WithSeverityOverride(CodeUnknownAttribute, INFO))
+	assertParseSuccess(t, err, 1, 0, workflow)
+
+	_, diags, err := ParseWithDiagnostics(strings.NewReader(src), WithSeverityOverride(CodeUnknownAttribute, INFO))
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, Severity(INFO), diags[0].Severity)
+	assert.NotNil(t, diags[0].Fix)
+
+	workflow, err = parseString(src,
+		WithRequirePinnedRefs(),
+		WithSeverityOverride(CodeUnpinnedRef, ERROR),
+		WithSeverityOverride(CodeUnknownAttribute, WARNING),
+		WithSuppressWarnings())
+	assertParseError(t, err, 1, 0, workflow, "line 3: action `a' uses `owner/repo@master', which is not pinned")
+	pe := extractParserError(t, err)
+	require.Len(t, pe.Errors, 1)
+	assert.Equal(t, Severity(ERROR), pe.Errors[0].Severity)
+}
+
 func TestActionsAndAttributes(t *testing.T) {
 	workflow, err := parseString(`
 		"action" "a" {
@@ -83,6 +115,48 @@ func TestFileVersion42(t *testing.T) {
 	assertParseError(t, err, 1, 0, workflow, "`version = 42` is not supported")
 }
 
+func TestFileVersion1(t *testing.T) {
+	workflow, err := parseString(`version = 1
+action "a" {
+  uses = "./a"
+  if = "success()"
+  matrix = { go = ["1.11", "1.12"] }
+}`)
+	assertParseSuccess(t, err, 1, 0, workflow)
+	assert.Equal(t, 1, workflow.Version)
+
+	src := `template "t" {
+  uses = "./t"
+  if = "success()"
+}
+action "a" {
+  uses = "./a"
+  matrix = { go = ["1.11", "1.12"] }
+}`
+	workflow, err = parseString(src)
+	assertParseError(t, err, 1, 0, workflow,
+		"line 3: `if' in template `t' requires `version = 1'",
+		"line 7: `matrix' in action `a' requires `version = 1'")
+
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.NotNil(t, errs[0].Fix)
+	assert.Equal(t, "Add `version = 1'", errs[0].Fix.Title)
+	fixed, n := ApplyFixes([]byte(src), []*Fix{errs[0].Fix, errs[1].Fix})
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "version = 1\n\n"+src, string(fixed))
+
+	src = "version = 0\n" + src
+	_, errs, err = ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	require.NotNil(t, errs[0].Fix)
+	assert.Equal(t, "Change to `version = 1'", errs[0].Fix.Title)
+	fixed, _ = ApplyFixes([]byte(src), []*Fix{errs[0].Fix})
+	_, err = Parse(strings.NewReader(string(fixed)))
+	assert.NoError(t, err)
+}
+
 func TestFileVersionMustComeFirst(t *testing.T) {
 	workflow, err := parseString(`action "a" { uses="./foo" } version=0`)
 	assertParseError(t, err, 1, 0, workflow, "`version` must be the first declaration")
@@ -100,6 +174,21 @@ func TestActionCollision(t *testing.T) {
 	assertParseError(t, err, 2, 0, workflow, "identifier `a' redefined")
 }
 
+func TestActionWorkflowCollision(t *testing.T) {
+	src := `
+		workflow "a" { on="push" resolves="a" }
+		action "a" { uses="./x" }`
+	workflow, err := parseString(src)
+	assertParseError(t, err, 1, 1, workflow, "line 3: identifier `a' redefined")
+	workflow, err = parseString(src, WithSeparateNamespaces())
+	assertParseSuccess(t, err, 1, 1, workflow)
+
+	workflow, err = parseString(`
+		action "a" { uses="./x" }
+		action "a" { uses="./x" }`, WithSeparateNamespaces())
+	assertParseError(t, err, 2, 0, workflow, "identifier `a' redefined")
+}
+
 func TestBadHCL(t *testing.T) {
 	workflow, err := parseString(`this is definitely not valid HCL!`)
 	assertSyntaxError(t, err, workflow, "illegal char")
@@ -113,6 +202,14 @@ func TestBadHCL(t *testing.T) {
 	assertSyntaxError(t, err, workflow, "literal not terminated")
 }
 
+func TestInvalidUTF8(t *testing.T) {
+	workflow, err := parseString("action \"a\" {\n  uses=\"./x\"\n  runs=\"\xe2\x82\"\n}")
+	assertSyntaxError(t, err, workflow, "line 3: invalid utf-8 sequence at byte offset 34")
+	pe := extractParserError(t, err)
+	assert.Equal(t, ErrorPos{Line: 3, Column: 9, Offset: 34}, pe.Errors[0].Pos)
+	assert.Equal(t, Severity(FATAL), pe.Errors[0].Severity)
+}
+
 func TestCircularDependencySelf(t *testing.T) {
 	workflow, err := parseString(`
 		action "a" {
@@ -151,25 +248,29 @@ func TestCircularDependencyOther(t *testing.T) {
 	// (reading top to bottom, left to right) that the cycle is apparent to
 	// the parser.
 	assertParseError(t, err, 10, 0, workflow,
-		"line 4: circular dependency on `a'",
-		"line 9: circular dependency on `c'",
-		"line 13: circular dependency on `b'",
-		"line 16: circular dependency on `a'",
-		"line 19: circular dependency on `h'",
-		"line 22: circular dependency on `a'")
+		"line 4: circular dependency on `a': a -> b -> a",
+		"line 9: circular dependency on `c': c -> e -> d -> c",
+		"line 13: circular dependency on `b': b -> f -> b",
+		"line 16: circular dependency on `a': a -> g -> a",
+		"line 19: circular dependency on `h': h -> h",
+		"line 22: circular dependency on `a': a -> g -> i -> a")
+
+	pe := err.(*Error)
+	assert.Equal(t, []string{"c", "e", "d"}, pe.Errors[1].Cycle)
+	assert.Equal(t, []string{"h"}, pe.Errors[4].Cycle)
 }
 
 func TestFlowMapping(t *testing.T) {
 	workflow, err := parseString(`"workflow" "foo" { "on" = "push" resolves = ["a", "b"] } action "a" { uses="./x" } action "b" { uses="./y" }`)
 	assertParseSuccess(t, err, 2, 1, workflow)
-	assert.Equal(t, "push", workflow.Workflows[0].On)
+	assert.Equal(t, []string{"push"}, model.EventStrings(workflow.Workflows[0].On))
 	assert.ElementsMatch(t, []string{"a", "b"}, workflow.Workflows[0].Resolves)
 }
 
 func TestFlowOneResolve(t *testing.T) {
 	workflow, err := parseString(`workflow "foo" { on = "push" resolves = "a" } action "a" { uses="./x" }`)
 	assertParseSuccess(t, err, 1, 1, workflow)
-	assert.Equal(t, "push", workflow.Workflows[0].On)
+	assert.Equal(t, []string{"push"}, model.EventStrings(workflow.Workflows[0].On))
 	assert.Len(t, workflow.Workflows[0].Resolves[0], 1)
 	assert.Equal(t, "a", workflow.Workflows[0].Resolves[0])
 }
@@ -177,7 +278,7 @@ func TestFlowOneResolve(t *testing.T) {
 func TestFlowNoResolves(t *testing.T) {
 	workflow, err := parseString(`workflow "foo" { on = "push"}`)
 	assertParseSuccess(t, err, 0, 1, workflow)
-	assert.Equal(t, "push", workflow.Workflows[0].On)
+	assert.Equal(t, []string{"push"}, model.EventStrings(workflow.Workflows[0].On))
 	assert.Len(t, workflow.Workflows[0].Resolves, 0)
 	assert.Empty(t, workflow.Workflows[0].Resolves)
 }
@@ -204,7 +305,7 @@ func TestUses(t *testing.T) {
 	}
 	d := workflow.GetAction("d")
 	if assert.NotNil(t, d) {
-		assert.Equal(t, &model.UsesDockerImage{Image: "alpine"}, d.Uses)
+		assert.Equal(t, &model.UsesDockerImage{Image: "alpine", Repository: "alpine"}, d.Uses)
 	}
 }
 
@@ -235,6 +336,12 @@ func TestUsesFailures(t *testing.T) {
 		"action `a' must have a `uses' attribute")
 }
 
+func TestUsesInvalidImage(t *testing.T) {
+	workflow, err := parseString(`action "a" { uses="docker://Alpine:3.8" }`)
+	assertParseError(t, err, 1, 0, workflow,
+		"line 1: invalid docker image `alpine:3.8' in action `a': invalid repository name `alpine' [e_image_invalid]")
+}
+
 func TestGetCommand(t *testing.T) {
 	workflow, err := parseString(`
 		action "a" { uses="./x" runs="a b c d" }
@@ -330,6 +437,30 @@ func TestBadEnv(t *testing.T) {
 	assert.Equal(t, map[string]string{"x": "bar"}, pe.Actions[0].Env)
 }
 
+func TestEnvSizeLimits(t *testing.T) {
+	src := `
+		action "a" {
+			uses="./x"
+			env={
+				SHORT="abc"
+				LONG="abcdefghij"
+			}
+		}`
+	workflow, err := parseString(src)
+	assertParseSuccess(t, err, 1, 0, workflow)
+
+	workflow, err = parseString(src, WithMaxEnvValueLength(5))
+	assertParseError(t, err, 1, 0, workflow,
+		"line 4: environment variable `long' in action `a' is longer than 5 bytes")
+
+	// SHORT=abc is 9 bytes, LONG=abcdefghij is 15 bytes
+	workflow, err = parseString(src, WithMaxEnvSize(24))
+	assertParseSuccess(t, err, 1, 0, workflow)
+	workflow, err = parseString(src, WithMaxEnvSize(23))
+	assertParseError(t, err, 1, 0, workflow,
+		"line 4: environment of action `a' is 24 bytes, more than the maximum of 23")
+}
+
 func TestBadSecrets(t *testing.T) {
 	workflow, err := parseString(`action "a" { uses="./x" secrets={} }`)
 	assertParseError(t, err, 1, 0, workflow, "expected list, got object")
@@ -378,10 +509,10 @@ func TestUsesCustomActionsShortPath(t *testing.T) {
 func TestTwoFlows(t *testing.T) {
 	workflow, err := parseString(`workflow "foo" { on = "push" resolves = "a" } workflow "bar" { on = "push" resolves = "a" } action "a" { uses="./x" }`)
 	assertParseSuccess(t, err, 1, 2, workflow)
-	assert.Equal(t, "push", workflow.Workflows[0].On)
+	assert.Equal(t, []string{"push"}, model.EventStrings(workflow.Workflows[0].On))
 	assert.Len(t, workflow.Workflows[0].Resolves[0], 1)
 	assert.Equal(t, "a", workflow.Workflows[0].Resolves[0])
-	assert.Equal(t, "push", workflow.Workflows[1].On)
+	assert.Equal(t, []string{"push"}, model.EventStrings(workflow.Workflows[1].On))
 	assert.Len(t, workflow.Workflows[1].Resolves[0], 1)
 	assert.Equal(t, "a", workflow.Workflows[1].Resolves[0])
 }
@@ -389,15 +520,13 @@ func TestTwoFlows(t *testing.T) {
 func TestOnPush(t *testing.T) {
 	workflow, err := parseString(`workflow "foo" { on = "push" resolves = "a" } action "a" { uses="./x" }`)
 	assertParseSuccess(t, err, 1, 1, workflow)
-	onValue := workflow.Workflows[0].On
-	assert.Equal(t, "push", onValue)
+	assert.Equal(t, model.ParseEvents("push"), workflow.Workflows[0].On)
 }
 
 func TestOnPullRequest(t *testing.T) {
 	workflow, err := parseString(`workflow "foo" { on = "pull_request" resolves = "a" } action "a" { uses="./x" }`)
 	assertParseSuccess(t, err, 1, 1, workflow)
-	onValue := workflow.Workflows[0].On
-	assert.Equal(t, "pull_request", onValue)
+	assert.Equal(t, model.ParseEvents("pull_request"), workflow.Workflows[0].On)
 }
 
 func TestResolves(t *testing.T) {
@@ -441,6 +570,95 @@ func TestGetWorkflows(t *testing.T) {
 	require.Equal(t, 0, len(workflows))
 }
 
+func TestMultipleEvents(t *testing.T) {
+	config, err := parseString(`workflow "foo" { on = ["push", "pull_request"] resolves = "a" } action "a" { uses="./x" }`)
+	assertParseSuccess(t, err, 1, 1, config)
+	w := config.Workflows[0]
+	assert.Equal(t, []model.Event{{Raw: "push", Type: "push"}, {Raw: "pull_request", Type: "pull_request"}}, w.On)
+	assert.Len(t, config.GetWorkflows("pull_request"), 1)
+	assert.Len(t, config.GetWorkflows("push"), 1)
+
+	config, err = parseString(`workflow "foo" { on = ["push", "shove", "PUSH"] resolves = "a" } action "a" { uses="./x" }`)
+	assertParseError(t, err, 1, 1, config,
+		"line 1: event `push' listed more than once",
+		"line 1: workflow `foo' has unknown `on' value `shove'")
+
+	config, err = parseString(`workflow "foo" { on = [] resolves = "a" } action "a" { uses="./x" }`)
+	assertParseError(t, err, 1, 1, config,
+		"`on' value in workflow `foo' cannot be blank",
+		"workflow `foo' must have an `on' attribute")
+
+	config, err = parseString(`workflow "foo" { on = ["push", 1] resolves = "a" } action "a" { uses="./x" }`)
+	assertParseError(t, err, 1, 1, config,
+		"expected string, got number")
+}
+
+func TestEventFilters(t *testing.T) {
+	config, err := parseString(`workflow "foo" { on = "pull_request.opened" resolves = "a" } action "a" { uses="./x" }`)
+	assertParseSuccess(t, err, 1, 1, config)
+	assert.Equal(t, []model.Event{{Raw: "pull_request.opened", Type: "pull_request", Filter: "opened"}}, config.Workflows[0].On)
+	assert.Len(t, config.GetWorkflows("pull_request.opened"), 1)
+	assert.Empty(t, config.GetWorkflows("pull_request.closed"))
+
+	config, err = parseString(`workflow "foo" { on = ["push", "issues.closed"] resolves = "a" } action "a" { uses="./x" }`)
+	assertParseSuccess(t, err, 1, 1, config)
+	assert.Equal(t, []model.Event{{Raw: "push", Type: "push"}, {Raw: "issues.closed", Type: "issues", Filter: "closed"}}, config.Workflows[0].On)
+	assert.Len(t, config.GetWorkflows("issues.closed"), 1)
+	assert.Empty(t, config.GetWorkflows("issues.opened"))
+
+	config, err = parseString(`workflow "foo" { on = ["push.opened", "pull_request.bogus", "bogus.opened"] resolves = "a" } action "a" { uses="./x" }`)
+	assertParseError(t, err, 1, 1, config,
+		"line 1: workflow `foo' has unknown filter `opened' for event `push'",
+		"line 1: workflow `foo' has unknown filter `bogus' for event `pull_request'",
+		"line 1: workflow `foo' has unknown `on' value `bogus'")
+
+	config, err = parseString(`workflow "foo" { on = "pull_request." resolves = "a" } action "a" { uses="./x" }`)
+	assertParseError(t, err, 1, 1, config,
+		"line 1: event `pull_request.' in workflow `foo' has a blank filter")
+}
+
+func TestSchedules(t *testing.T) {
+	config, err := parseString(`workflow "foo" { on = "schedule(*/15 * * * *)" resolves = "a" } action "a" { uses="./x" }`)
+	assertParseSuccess(t, err, 1, 1, config)
+	events := config.Workflows[0].On
+	require.Len(t, events, 1)
+	assert.Equal(t, "schedule", events[0].Type)
+	assert.Equal(t, []int{0, 15, 30, 45}, events[0].Schedule.Minute)
+	assert.Len(t, config.GetWorkflows("schedule"), 1)
+
+	config, err = parseString(`workflow "foo" {
+		on = ["push", "schedule(0 25 * * *)", "schedule"]
+		resolves = "a"
+	}
+	action "a" { uses="./x" }`)
+	assertParseError(t, err, 1, 1, config,
+		"line 2: workflow `foo' has an invalid schedule `0 25 * * *': value 25 out of range 0-23 in hour field",
+		"line 2: workflow `foo' has a `schedule' event without a cron expression")
+	assert.Equal(t, CodeInvalidSchedule, err.(*Error).Errors[0].Code)
+}
+
+func TestWithAllowedEvents(t *testing.T) {
+	src := `workflow "foo" { on = ["deploy", "push"] resolves = "a" } action "a" { uses="./x" }`
+
+	config, err := parseString(src)
+	assertParseError(t, err, 1, 1, config,
+		"line 1: workflow `foo' has unknown `on' value `deploy'")
+
+	config, err = parseString(src, WithAllowedEvents([]string{"deploy", "push"}))
+	assertParseSuccess(t, err, 1, 1, config)
+
+	config, err = parseString(src, WithAllowedEvents([]string{"deploy"}))
+	assertParseError(t, err, 1, 1, config,
+		"line 1: workflow `foo' has unknown `on' value `push'")
+
+	r := NewEventRegistry("deploy")
+	_, err = parseString(src, WithEventRegistry(r))
+	assert.Error(t, err)
+	r.Add("push")
+	config, err = parseString(src, WithEventRegistry(r))
+	assertParseSuccess(t, err, 1, 1, config)
+}
+
 func TestFlowMissingOn(t *testing.T) {
 	workflow, err := parseString(`workflow "foo" { resolves = "a" } action "a" { uses="./x" }`)
 	assertParseError(t, err, 1, 1, workflow, "workflow `foo' must have an `on' attribute")
@@ -470,7 +688,7 @@ func TestFlowOnUnexpectedValue(t *testing.T) {
 		"line 5: expected string, got number",
 		"line 5: invalid format for `on' in workflow `foo', expected string")
 	pe := extractParserError(t, err)
-	assert.Equal(t, "hsup", pe.Workflows[0].On)
+	assert.Equal(t, []string{"hsup"}, model.EventStrings(pe.Workflows[0].On))
 }
 
 func TestFlowResolvesTypeError(t *testing.T) {
@@ -522,6 +740,263 @@ func TestCommandDuplicatesCheck(t *testing.T) {
 	}
 }
 
+func TestEnvBlocks(t *testing.T) {
+	workflow, err := parseString(`version = 0
+# defaults
+env = {
+  GOPATH = "/go"
+  CI = "false" # overridden
+}
+workflow "ci" {
+  on = "push"
+  resolves = "a"
+  env = { CI = "true" }
+}
+action "a" {
+  uses = "./a"
+}
+`)
+	assertParseSuccess(t, err, 1, 1, workflow)
+	assert.Equal(t, map[string]string{"GOPATH": "/go", "CI": "false"}, workflow.Env)
+	assert.Equal(t, map[string]string{"CI": "true"}, workflow.Workflows[0].Env)
+	assert.Equal(t, model.Pos{Line: 10, Column: 3, Offset: 127}, workflow.Workflows[0].AttributePos("env"))
+	assert.Equal(t, []string{"# defaults"}, workflow.AttributeComments["env"].Lead)
+	assert.Equal(t, "# overridden", workflow.AttributeComments["env.CI"].Line)
+	assert.Equal(t, map[string]string{"GOPATH": "/go", "CI": "true"}, workflow.ResolveEnv(workflow.Workflows[0], workflow.Actions[0]))
+
+	workflow, err = parseString(`env = { A = "1" }
+env = { A = "2", GITHUB_X = "3" }
+workflow "ci" {
+  on = "push"
+  resolves = "a"
+  env = { "B C" = "4" }
+}
+action "a" { uses = "./a" }
+`)
+	assertParseError(t, err, 1, 1, workflow,
+		"line 2: environment variable `a' redefined",
+		"line 2: environment variables and secrets beginning with `github_' are reserved",
+		"line 6: environment variables and secrets must contain only a-z, a-z, 0-9, and _ characters, got `b c'")
+
+	workflow, err = parseString(`env = "x"`)
+	assertParseError(t, err, 0, 0, workflow, "line 1: expected object, got string")
+}
+
+func TestContinueOnError(t *testing.T) {
+	workflow, err := parseString(`action "a" {
+  uses = "./a"
+  continue_on_error = true
+}
+action "b" {
+  uses = "./b"
+  continue_on_error = false
+}
+action "c" {
+  uses = "./c"
+}`)
+	assertParseSuccess(t, err, 3, 0, workflow)
+	assert.True(t, workflow.Actions[0].ContinueOnError)
+	assert.Equal(t, 3, workflow.Actions[0].AttributePos("continue_on_error").Line)
+	assert.False(t, workflow.Actions[1].ContinueOnError)
+	assert.False(t, workflow.Actions[2].ContinueOnError)
+
+	workflow, err = parseString(`action "a" {
+  uses = "./a"
+  continue_on_error = "true"
+}
+action "b" {
+  uses = "./b"
+  continue_on_error = true
+  continue_on_error = false
+}`)
+	assertParseError(t, err, 2, 0, workflow,
+		"line 3: expected bool, got string",
+		"line 8: `continue_on_error' redefined in action `b'")
+}
+
+func TestMatrix(t *testing.T) {
+	workflow, err := parseString(`version = 1
+workflow "ci" {
+  on = "push"
+  resolves = "test"
+}
+action "test" {
+  uses = "docker://golang"
+  matrix = {
+    go = ["1.11", "1.12"]
+    os = "linux"
+  }
+  args = "go test ${{ matrix.go }}"
+}`)
+	assertParseSuccess(t, err, 1, 1, workflow)
+	assert.Equal(t, map[string][]string{"go": {"1.11", "1.12"}, "os": {"linux"}}, workflow.Actions[0].Matrix)
+	assert.Equal(t, 8, workflow.Actions[0].AttributePos("matrix").Line)
+
+	workflow, err = parseString(`version = 1
+action "a" {
+  uses = "./a"
+  matrix = ["1.11"]
+}
+action "b" {
+  uses = "./b"
+  matrix = {
+    go-version = ["1.11", "1.12", "1.11"]
+    os = []
+  }
+  env = {
+    MATRIX_OS = "linux"
+  }
+}
+action "c (go=1.12)" {
+  uses = "./c"
+}
+action "c" {
+  uses = "./c"
+  matrix = {
+    go = ["1.11", "1.12"]
+  }
+}
+action "d" {
+  uses = "./d"
+  matrix = {
+    a = ["1", "2", "3", "4", "5", "6", "7", "8"]
+    b = ["1", "2", "3", "4", "5", "6", "7", "8"]
+    c = ["1", "2", "3", "4", "5"]
+  }
+}`)
+	assertParseError(t, err, 5, 0, workflow,
+		"line 4: expected object, got list",
+		"line 8: matrix dimension `go-version' in action `b' must contain only a-z, a-z, 0-9, and _ characters",
+		"line 8: value `1.11' is listed twice in matrix dimension `go-version' of action `b'",
+		"line 8: matrix dimension `os' in action `b' has no values",
+		"line 8: environment variable `matrix_os' of action `b' is set by its matrix",
+		"line 21: matrix of action `c' expands to `c (go=1.12)', which is already used",
+		"line 27: matrix of action `d' has more than 256 combinations")
+}
+
+func TestTemplates(t *testing.T) {
+	workflow, err := parseString(`workflow "ci" {
+  on = "push"
+  resolves = ["test", "go"]
+}
+template "go" {
+  uses = "docker://golang"
+  env = {
+    GOPATH = "/go"
+  }
+}
+action "go" {
+  uses = "./go"
+}
+action "test" {
+  extends = "go"
+  args = "go test ./..."
+}`)
+	assertParseSuccess(t, err, 2, 1, workflow)
+	require.Len(t, workflow.Templates, 1)
+	assert.Equal(t, "go", workflow.Templates[0].Identifier)
+	assert.Equal(t, 5, workflow.Templates[0].Pos.Line)
+	assert.Equal(t, "go", workflow.Actions[1].Extends)
+	assert.Nil(t, workflow.Actions[1].Uses)
+	assert.Equal(t, "docker://golang", workflow.ResolveTemplate(workflow.Actions[1]).Uses.String())
+
+	workflow, err = parseString(`template "base" {
+  uses = "./base"
+  needs = "a"
+  extends = "other"
+  env = {
+    GITHUB_X = "1"
+  }
+  args = "${{ bogus.x }}"
+}
+template "other" {
+  runs = "make"
+}
+template "base" {
+  uses = "./base"
+}
+action "a" {
+  extends = "missing"
+}
+action "b" {
+  extends = "other"
+}`)
+	assertParseError(t, err, 2, 0, workflow,
+		"line 3: template `base' cannot have `needs'",
+		"line 4: template `base' cannot extend another template",
+		"line 5: environment variables and secrets beginning with `github_' are reserved",
+		"line 8: unknown context `bogus' in `args' of template `base'",
+		"line 13: identifier `base' redefined",
+		"line 17: action `a' extends unknown template `missing'",
+		"line 19: action `b' must have a `uses' attribute")
+}
+
+func TestDescription(t *testing.T) {
+	workflow, err := parseString(`workflow "ci" {
+  on = "push"
+  description = "Check every push"
+  resolves = "a"
+}
+action "a" {
+  description = "Run the tests ✓"
+  uses = "./a"
+}`)
+	assertParseSuccess(t, err, 1, 1, workflow)
+	assert.Equal(t, "Check every push", workflow.Workflows[0].Description)
+	assert.Equal(t, "Run the tests ✓", workflow.Actions[0].Description)
+
+	workflow, err = parseString(`workflow "ci" {
+  on = "push"
+  description = "Check every push"
+  resolves = "a"
+}
+action "a" {
+  description = "Run the tests ✓"
+  uses = "./a"
+}`, WithMaxDescriptionLength(15))
+	assertParseError(t, err, 1, 1, workflow,
+		"line 3: description of workflow `ci' is 16 characters long, more than the maximum of 15")
+
+	workflow, err = parseString(`action "a" {
+  description = ""
+  uses = "./a"
+}
+action "b" {
+  description = ["x"]
+  uses = "./b"
+}`)
+	assertParseError(t, err, 2, 0, workflow,
+		"line 2: `description' value in action `a' cannot be blank",
+		"line 6: expected string, got list",
+		"line 6: invalid format for `description' in action `b', expected string")
+}
+
+func TestHeredocCommand(t *testing.T) {
+	workflow, err := parseString(`action "a" {
+  uses = "./x"
+  runs = <<EOF
+sh -c
+EOF
+  args = <<-SCRIPT
+    echo "one" \
+      two
+    three
+    SCRIPT
+}`)
+	assertParseSuccess(t, err, 1, 0, workflow)
+	a := workflow.Actions[0]
+	assert.Equal(t, &model.HeredocCommand{Value: "sh -c", Marker: "EOF"}, a.Runs)
+	assert.Equal(t, &model.HeredocCommand{Value: "echo \"one\" \\\n  two\nthree", Marker: "SCRIPT"}, a.Args)
+	assert.Equal(t, []string{"echo", `"one"`, "two", "three"}, a.Args.Split())
+
+	workflow, err = parseString("action \"a\" {\n  uses = \"./x\"\n  runs = <<EOF\nEOF\n}")
+	assertParseError(t, err, 1, 0, workflow, "line 3: `runs' value in action `a' cannot be blank")
+
+	workflow, err = parseString("action \"a\" {\n  uses = \"./x\"\n  args = <<EOF\nEOF\n}")
+	assertParseSuccess(t, err, 1, 0, workflow)
+	assert.Equal(t, &model.HeredocCommand{Marker: "EOF"}, workflow.Actions[0].Args)
+}
+
 func TestFlowKeywordsRedefined(t *testing.T) {
 	workflow, err := parseString(`workflow "a" { on="push" on="push" resolves=["c"] }`)
 	assertParseError(t, err, 0, 1, workflow,
@@ -538,6 +1013,48 @@ func TestNonExistentExplicitDependency(t *testing.T) {
 	assertParseError(t, err, 1, 0, workflow, "action `a' needs nonexistent action `b'")
 }
 
+func TestUnusedActions(t *testing.T) {
+	src := `
+		workflow "w" { on="push" resolves="a" }
+		action "a" { uses="./x" needs="b" }
+		action "b" { uses="./x" }
+		action "c" { uses="./x" needs="d" }
+		action "d" { uses="./x" }`
+	workflow, err := parseString(src)
+	assertParseError(t, err, 4, 1, workflow,
+		"line 5: action `c' is never used [w_unused_action]",
+		"line 6: action `d' is never used")
+
+	workflow, err = Parse(strings.NewReader(src), WithoutChecks(CheckUnused))
+	assertParseSuccess(t, err, 4, 1, workflow)
+
+	// files with only actions are not checked
+	workflow, err = parseString(`action "a" { uses="./x" }`)
+	assertParseSuccess(t, err, 1, 0, workflow)
+}
+
+func TestRedundantResolves(t *testing.T) {
+	src := `workflow "w" {
+  on = "push"
+  resolves = ["deploy", "build", "test"]
+}
+action "build" { uses="./x" }
+action "test" { uses="./x" needs="build" }
+action "deploy" { uses="./x" needs="test" }`
+	workflow, err := parseString(src)
+	assertParseError(t, err, 3, 1, workflow,
+		"line 3: workflow `w' resolves `build', which `deploy' already needs [w_redundant_resolves]",
+		"line 3: workflow `w' resolves `test', which `deploy' already needs")
+
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	require.NotNil(t, errs[1].Fix)
+	fixed, n := ApplyFixes([]byte(src), []*Fix{errs[1].Fix})
+	assert.Equal(t, 1, n)
+	assert.Contains(t, string(fixed), `resolves = ["deploy", "build"]`)
+}
+
 func TestBadDependenciesList(t *testing.T) {
 	workflow, err := parseString(`action "a" { uses="./x" needs=42 }`)
 	assertParseError(t, err, 1, 0, workflow, "expected list, got number")
@@ -604,8 +1121,177 @@ func TestTooManySecrets(t *testing.T) {
 	assertParseError(t, err, 3, 0, workflow, "all actions combined must not have more than 100 unique secrets")
 }
 
+func TestMaxSecrets(t *testing.T) {
+	src := `
+		action "a" { uses="./a" secrets=["A", "B", "C"] }
+		action "b" { uses="./b" secrets=["C", "D"] }
+	`
+	workflow, err := parseString(src, WithMaxSecrets(4), WithMaxSecretsPerAction(3))
+	assertParseSuccess(t, err, 2, 0, workflow)
+
+	workflow, err = parseString(src, WithMaxSecrets(3))
+	assertParseError(t, err, 2, 0, workflow, "line 3: all actions combined must not have more than 3 unique secrets")
+
+	workflow, err = parseString(src, WithMaxSecretsPerAction(2))
+	assertParseError(t, err, 2, 0, workflow, "line 2: action `a' has 3 unique secrets, more than the maximum of 2")
+
+	workflow, err = parseString(src, WithMaxSecrets(0), WithMaxSecretsPerAction(0))
+	assertParseSuccess(t, err, 2, 0, workflow)
+}
+
+func TestMaxActions(t *testing.T) {
+	src := `
+		action "a" { uses="./a" }
+		action "b" { uses="./b" needs="a" }
+		action "c" { uses="./c" needs="d" }
+	`
+	workflow, err := parseString(src, WithMaxActions(3), WithoutChecks(CheckNeeds))
+	assertParseSuccess(t, err, 3, 0, workflow)
+
+	workflow, err = parseString(src, WithMaxActions(2))
+	assertParseError(t, err, 3, 0, workflow,
+		"line 4: action `c' needs nonexistent action `d'",
+		"line 4: there are 3 actions, more than the maximum of 2")
+	pe := extractParserError(t, err)
+	assert.Equal(t, CodeTooManyActions, pe.Errors[1].Code)
+
+	workflow, err = parseString(src, WithMaxActions(0), WithoutChecks(CheckNeeds))
+	assertParseSuccess(t, err, 3, 0, workflow)
+}
+
+func TestMaxErrors(t *testing.T) {
+	src := `
+		action "a" { uses="./a" foo="1" }
+		action "b" { uses="./b" bar="2" }
+		action "c" { uses="./c" baz="3" }
+		workflow "w" { on="push" resolves=["a", "b", "c", "d"] }
+	`
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(src), WithMaxErrors(2))
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+	assert.Equal(t, CodeUnknownAttribute, errs[0].Code)
+	assert.Equal(t, CodeUnknownAttribute, errs[1].Code)
+	assert.Equal(t, CodeTooManyErrors, errs[2].Code)
+	assert.Equal(t, "Too many errors, 2 more not shown", errs[2].Message())
+	assert.Equal(t, 4, errs[2].Pos.Line)
+	assert.Equal(t, Severity(ERROR), errs[2].Severity)
+
+	_, errs, err = ParseWithDiagnostics(strings.NewReader(src), WithMaxErrors(4))
+	require.NoError(t, err)
+	assert.Len(t, errs, 4)
+
+	_, errs, err = ParseWithDiagnostics(strings.NewReader(src), WithMaxErrors(0))
+	require.NoError(t, err)
+	assert.Len(t, errs, 4)
+}
+
+func TestMaxFileSizeAndBlocks(t *testing.T) {
+	src := `
+		action "a" { uses="./a" }
+		action "b" { uses="./b" }
+		workflow "w" { on="push" resolves=["a", "b"] }
+	`
+	workflow, err := parseString(src, WithMaxFileSize(len(src)), WithMaxBlocks(3))
+	assertParseSuccess(t, err, 2, 1, workflow)
+
+	workflow, err = parseString(src, WithMaxFileSize(len(src)-1))
+	assertParseError(t, err, 0, 0, workflow, "line 5: file is larger than the maximum of 106 bytes")
+	pe := extractParserError(t, err)
+	assert.Equal(t, CodeFileTooLarge, pe.Errors[0].Code)
+	assert.Equal(t, Severity(FATAL), pe.Errors[0].Severity)
+
+	workflow, err = parseString(src, WithMaxBlocks(2), WithSyntaxRecovery())
+	assertParseError(t, err, 0, 0, workflow, "line 4: more than the maximum of 2 actions and workflows")
+	pe = extractParserError(t, err)
+	assert.Equal(t, CodeTooManyBlocks, pe.Errors[0].Code)
+
+	workflow, err = parseString(src, WithMaxFileSize(0), WithMaxBlocks(0))
+	assertParseSuccess(t, err, 2, 1, workflow)
+}
+
+func TestErrorUnwrap(t *testing.T) {
+	workflow, err := parseString(`
+		action "a" { uses="./a" foo="1" }
+		action "b" { uses="./b" needs="c" }
+		action "c" { uses="./c" needs="b" }
+	`)
+	assertParseError(t, err, 3, 0, workflow,
+		"line 2: unknown action attribute `foo'",
+		"line 4: circular dependency on `b'")
+
+	assert.True(t, errors.Is(err, CodeCircularDependency))
+	assert.True(t, errors.Is(err, CodeUnknownAttribute))
+	assert.False(t, errors.Is(err, CodeSyntax))
+	assert.False(t, errors.Is(err, context.Canceled))
+
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, Severity(FATAL), pe.Severity)
+	assert.Equal(t, CodeCircularDependency, pe.Code)
+
+	assert.Nil(t, errors.Unwrap(err))
+}
+
+func TestSeverity(t *testing.T) {
+	for _, sev := range []Severity{INFO, WARNING, ERROR, FATAL} {
+		parsed, err := ParseSeverity(sev.String())
+		require.NoError(t, err)
+		assert.Equal(t, sev, parsed)
+	}
+	assert.Equal(t, "warning", Severity(WARNING).String())
+	assert.Equal(t, "Severity(7)", Severity(7).String())
+
+	sev, err := ParseSeverity("Fatal")
+	require.NoError(t, err)
+	assert.Equal(t, Severity(FATAL), sev)
+	_, err = ParseSeverity("bananas")
+	assert.EqualError(t, err, "unknown severity `bananas'; expected info, warning, error, or fatal")
+
+	var threshold struct {
+		FailOn Severity `json:"fail_on"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"fail_on": "error"}`), &threshold))
+	assert.Equal(t, Severity(ERROR), threshold.FailOn)
+	b, err := json.Marshal(threshold)
+	require.NoError(t, err)
+	assert.Equal(t, `{"fail_on":"error"}`, string(b))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"fail_on": "bananas"}`), &threshold))
+	_, err = json.Marshal(Severity(0))
+	assert.Error(t, err)
+}
+
+func TestSeverityValues(t *testing.T) {
+	// These are stored and sent as numbers, e.g., in protobuf, so they
+	// never change.
+	assert.Equal(t, []int{-1, 1, 2, 3}, []int{INFO, WARNING, ERROR, FATAL})
+	assert.Equal(t, "info", Severity(INFO).String())
+	assert.Equal(t, "Severity(0)", Severity(0).String())
+	sev, err := ParseSeverity("INFO")
+	require.NoError(t, err)
+	assert.Equal(t, Severity(INFO), sev)
+}
+
+func TestErrorListHelpers(t *testing.T) {
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(`
+		action "a" { uses="./a" foo="1" }
+		action "b" { uses="./b" needs="c" }
+		action "c" { uses="./c" needs="b" bar="2" }
+	`))
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+
+	assert.Equal(t, ErrorList{errs[2]}, errs.FilterSeverity(ERROR))
+	assert.Equal(t, errs, errs.FilterSeverity(WARNING))
+	assert.Equal(t, ErrorList{errs[1], errs[2]}, errs.ForLineRange(3, 4))
+	assert.Empty(t, errs.ForLineRange(5, 10))
+	assert.Equal(t, ErrorList{errs[0], errs[1]}, errs.ByCode(CodeUnknownAttribute))
+	assert.True(t, errs.HasFatal())
+	assert.False(t, errs.ByCode(CodeUnknownAttribute).HasFatal())
+}
+
 func TestUnknownAttributes(t *testing.T) {
-	workflow, err := parseString(`action "a" { uses="./a" foo="1" } workflow "b" { on="push" bar="2" }`)
+	workflow, err := parseString(`action "a" { uses="./a" foo="1" } workflow "b" { on="push" resolves="a" bar="2" }`)
 	assertParseError(t, err, 1, 1, workflow,
 		"unknown action attribute `foo'",
 		"unknown workflow attribute `bar'")
@@ -681,6 +1367,148 @@ func TestUsesForm(t *testing.T) {
 	}
 }
 
+func TestParseContextCanceled(t *testing.T) {
+	src := `
+		workflow "w" { on="push" resolves=["a", "b"] }
+		action "a" { uses="./x" }`
+
+	config, err := ParseContext(context.Background(), strings.NewReader(src))
+	assertParseError(t, err, 1, 1, config, "line 2: workflow `w' resolves unknown action `b'")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	config, err = ParseContext(ctx, strings.NewReader(src))
+	require.Error(t, err)
+	assert.Nil(t, config)
+	assert.True(t, errors.Is(err, context.Canceled))
+	pe := extractParserError(t, err)
+	assert.Empty(t, pe.Actions)
+	assert.Empty(t, pe.Errors)
+	assert.Contains(t, pe.Error(), "context canceled")
+
+	// Canceling during validation keeps what was parsed.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	cancelRule := RuleFunc(func(c *model.Configuration) []*ParseError {
+		cancel()
+		return []*ParseError{NewError(c.Actions[0], "X", "after cancel")}
+	})
+	config, err = ParseContext(ctx, strings.NewReader(src), WithRules(cancelRule, cancelRule))
+	require.Error(t, err)
+	assert.Nil(t, config)
+	assert.True(t, errors.Is(err, context.Canceled))
+	pe = extractParserError(t, err)
+	assert.Len(t, pe.Actions, 1)
+	assert.Len(t, pe.Workflows, 1)
+	assert.Len(t, pe.Errors, 2, "the second rule should not have run")
+}
+
+// blockingReader never returns from Read until it is closed.
+type blockingReader chan struct{}
+
+func (r blockingReader) Read([]byte) (int, error) {
+	<-r
+	return 0, errors.New("closed")
+}
+
+func TestParseContextDeadline(t *testing.T) {
+	r := make(blockingReader)
+	defer close(r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := ParseContext(ctx, r)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+// countingReader returns a byte from each Read, after waiting for
+// permission on next, and counts the reads.
+type countingReader struct {
+	next  chan struct{}
+	reads int32
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	<-r.next
+	atomic.AddInt32(&r.reads, 1)
+	b[0] = ' '
+	return 1, nil
+}
+
+func TestParseContextStopsReading(t *testing.T) {
+	r := &countingReader{next: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := ParseContext(ctx, r)
+		done <- err
+	}()
+
+	r.next <- struct{}{}
+	r.next <- struct{}{}
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+
+	// The read in progress when ctx was canceled, if any, finishes, but
+	// no more are made.
+	extra := 0
+	for i := 0; i < 2; i++ {
+		select {
+		case r.next <- struct{}{}:
+			extra++
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	assert.True(t, extra <= 1, "%d reads after cancellation", extra)
+	assert.True(t, atomic.LoadInt32(&r.reads) <= 3)
+}
+
+func TestParseWithDiagnostics(t *testing.T) {
+	src := `
+		workflow "w" { on="push" resolves=["a", "b"] }
+		action "a" { uses="./x" }`
+	config, errs, err := ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	assert.Len(t, config.Actions, 1)
+	assert.Len(t, config.Workflows, 1)
+	require.Len(t, errs, 1)
+	assert.Equal(t, CodeUnknownResolves, errs[0].Code)
+
+	config, errs, err = ParseWithDiagnostics(strings.NewReader(`action "a" { uses="./x" }`))
+	require.NoError(t, err)
+	assert.Len(t, config.Actions, 1)
+	assert.Empty(t, errs)
+
+	config, errs, err = ParseWithDiagnostics(strings.NewReader(`action "a" {`))
+	require.NoError(t, err)
+	assert.Equal(t, &model.Configuration{}, config)
+	require.Len(t, errs, 1)
+	assert.Equal(t, Severity(FATAL), errs[0].Severity)
+	assert.Equal(t, CodeSyntax, errs[0].Code)
+
+	r := make(blockingReader)
+	close(r)
+	config, errs, err = ParseWithDiagnostics(r)
+	assert.EqualError(t, err, "closed")
+	assert.Nil(t, config)
+	assert.Nil(t, errs)
+}
+
+func TestParseWithDiagnosticsContext(t *testing.T) {
+	r := make(blockingReader)
+	defer close(r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	config, errs, err := ParseWithDiagnosticsContext(ctx, r)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Nil(t, config)
+	assert.Nil(t, errs)
+}
+
 func TestMultilineErrors(t *testing.T) {
 	_, err := parseString(`
 		workflow "a" {
@@ -693,10 +1521,10 @@ func TestMultilineErrors(t *testing.T) {
 	`)
 	require.Error(t, err)
 	expect := "unable to parse and validate\n" +
-		"  Line 2: Workflow `a' must have an `on' attribute\n" +
-		"  Line 3: Expected string, got number\n" +
-		"  Line 3: Invalid format for `on' in workflow `a', expected string\n" +
-		"  Line 7: The `uses' attribute must be a path, a Docker image, or owner/repo@ref"
+		"  Line 2: Workflow `a' must have an `on' attribute [E_ON_MISSING]\n" +
+		"  Line 3: Expected string, got number [E_TYPE_MISMATCH]\n" +
+		"  Line 3: Invalid format for `on' in workflow `a', expected string [E_TYPE_MISMATCH]\n" +
+		"  Line 7: The `uses' attribute must be a path, a Docker image, or owner/repo@ref [E_USES_INVALID]"
 	assert.Equal(t, expect, err.Error())
 
 	require.IsType(t, &Error{}, err)
@@ -769,3 +1597,135 @@ func extractParserError(t *testing.T, err error) *Error {
 	require.Fail(t, "expected parser error, but got %T", err)
 	return nil
 }
+
+func TestErrorJSON(t *testing.T) {
+	_, err := Parse(strings.NewReader(`action "a" {
+  uses = "./a"
+  bananas = "yes"
+}`))
+	require.Error(t, err)
+
+	b, jerr := json.Marshal(err)
+	require.NoError(t, jerr)
+	assert.JSONEq(t, `{
+	  "message": "unable to parse and validate",
+	  "errors": [
+	    {"message": "Unknown action attribute `+"`bananas'"+`", "code": "W_UNKNOWN_ATTRIBUTE", "severity": "warning", "line": 3, "column": 13, "offset": 40}
+	  ],
+	  "actions": [{"identifier": "a", "uses": {"kind": "path", "raw": "./a", "path": "a"}}],
+	  "workflows": []
+	}`, string(b))
+
+	var pe ParseError
+	require.NoError(t, json.Unmarshal([]byte(`{"message": "m", "code": "E_SYNTAX", "severity": "error", "line": 2}`), &pe))
+	assert.Equal(t, ParseError{message: "m", Code: CodeSyntax, Severity: ERROR, Pos: ErrorPos{Line: 2}}, pe)
+}
+
+func TestErrorCodes(t *testing.T) {
+	_, err := parseString(`
+workflow "w" {
+  on = "shove"
+  resolves = ["a", "z"]
+}
+action "a" {
+  needs = "y"
+  bananas = 1
+}`)
+	require.Error(t, err)
+	pe := err.(*Error)
+
+	var codes []Code
+	for _, e := range pe.Errors {
+		codes = append(codes, e.Code)
+	}
+	assert.Equal(t, []Code{CodeUnknownEvent, CodeUnknownResolves, CodeUsesMissing, CodeUnknownNeeds, CodeUnknownAttribute}, codes)
+	assert.Equal(t, "Line 3: Workflow `w' has unknown `on' value `shove' [E_UNKNOWN_EVENT]", pe.Errors[0].Error())
+}
+
+func TestModelPositions(t *testing.T) {
+	config, err := Parse(strings.NewReader(`workflow "w" {
+  on = "push"
+  resolves = "a"
+}
+
+action "a" {
+  uses = "./a"
+  env = {
+    A = "b"
+  }
+  bananas = 1
+}`), WithSuppressWarnings())
+	require.NoError(t, err)
+
+	w := config.Workflows[0]
+	assert.Equal(t, model.Pos{Line: 1, Column: 1, Offset: 0}, w.Pos)
+	assert.Equal(t, model.Pos{Line: 2, Column: 3, Offset: 17}, w.AttributePos("on"))
+	assert.Equal(t, 3, w.AttributePos("resolves").Line)
+
+	a := config.Actions[0]
+	assert.Equal(t, 6, a.Pos.Line)
+	assert.Equal(t, model.Pos{Line: 7, Column: 3, Offset: 64}, a.AttributePos("uses"))
+	assert.Equal(t, 8, a.AttributePos("env").Line)
+	assert.False(t, a.AttributePos("needs").IsValid())
+	assert.False(t, a.AttributePos("bananas").IsValid())
+}
+
+func TestModelComments(t *testing.T) {
+	config, err := Parse(strings.NewReader(`# header
+version = 0
+
+# lead w
+workflow "w" {
+  on = "push" # push
+  resolves = [
+    # the only one
+    "a",
+  ]
+}
+
+/* lead a */
+action "a" { # brace
+  uses = "./a"
+  env = {
+    // A
+    A = "b"
+    # end of env
+  }
+  # end of a
+} # after a
+
+# trailer`))
+	require.NoError(t, err)
+
+	assert.Equal(t, model.Comments{Lead: []string{"# header"}, Trailing: []string{"# trailer"}}, config.Comments)
+
+	w := config.Workflows[0]
+	assert.Equal(t, []string{"# lead w"}, w.Comments.Lead)
+	assert.Equal(t, "# push", w.AttributeComments["on"].Line)
+	assert.Equal(t, []string{"# the only one"}, w.AttributeComments["resolves"].Lead)
+
+	a := config.Actions[0]
+	assert.Equal(t, model.Comments{Lead: []string{"/* lead a */"}, Line: "# after a", Trailing: []string{"# end of a"}}, a.Comments)
+	assert.Equal(t, []string{"# brace"}, a.AttributeComments["uses"].Lead)
+	assert.Equal(t, []string{"# end of env"}, a.AttributeComments["env"].Trailing)
+	assert.Equal(t, []string{"// A"}, a.AttributeComments["env.A"].Lead)
+	assert.Len(t, a.AttributeComments, 3)
+}
+
+func TestRelatedPositions(t *testing.T) {
+	src := `action "a" {
+  uses = "./a"
+  uses = "./b"
+}`
+	_, errs, err := ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, []RelatedPos{{Pos: ErrorPos{Line: 2, Column: 10, Offset: 22}, Message: "First defined here"}}, errs[0].Related)
+
+	b, err := json.Marshal(errs[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"related":[{"line":2,"column":10,"offset":22,"message":"First defined here"}]`)
+	var back ParseError
+	require.NoError(t, json.Unmarshal(b, &back))
+	assert.Equal(t, errs[0].Related, back.Related)
+}
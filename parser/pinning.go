@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+var (
+	// commitSHA matches a full Git commit SHA, which always refers to the
+	// same code.
+	commitSHA = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+	// DefaultPinnedTag matches the tags WithRequirePinnedRefs accepts if
+	// not given any patterns: full semantic versions, like `v1.2.3'.
+	DefaultPinnedTag = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+$`)
+)
+
+// WithRequirePinnedRefs warns about every action that uses another
+// repository at a ref that can move, like `owner/repo@master'.  Refs
+// that are a full commit SHA pass, as do tags matching any of the given
+// patterns, or DefaultPinnedTag if none are given.  Branches and tags
+// can't be told apart from the ref alone, so a branch named like a
+// release passes too.
+func WithRequirePinnedRefs(tags ...*regexp.Regexp) OptionFunc {
+	if len(tags) == 0 {
+		tags = []*regexp.Regexp{DefaultPinnedTag}
+	}
+	return WithRules(pinnedRefs{tags: tags})
+}
+
+// pinnedRefs is the rule added by WithRequirePinnedRefs.
+type pinnedRefs struct {
+	tags []*regexp.Regexp
+}
+
+func (r pinnedRefs) Check(c *model.Configuration) []*ParseError {
+	var ret []*ParseError
+	for _, action := range usesBlocks(c) {
+		repo, ok := action.Uses.(*model.UsesRepository)
+		if !ok || r.pinned(repo.Ref) {
+			continue
+		}
+		ret = append(ret, NewWarning(&action.Uses, CodeUnpinnedRef,
+			"Action `%s' uses `%s', which is not pinned to a commit SHA or a release tag", action.Identifier, repo))
+	}
+	return ret
+}
+
+func (r pinnedRefs) pinned(ref string) bool {
+	if commitSHA.MatchString(ref) {
+		return true
+	}
+	for _, tag := range r.tags {
+		if tag.MatchString(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithConsistentRefs warns about actions that use a repository at a
+// different ref than an earlier action does, like `actions/checkout@v1'
+// and `actions/checkout@master', so that a file converges on one version
+// of each repository.  Repositories are compared ignoring case and the
+// path within them, since all of a repository's actions share its refs.
+func WithConsistentRefs() OptionFunc {
+	return WithRules(RuleFunc(consistentRefs))
+}
+
+// consistentRefs is the rule added by WithConsistentRefs.
+func consistentRefs(c *model.Configuration) []*ParseError {
+	var ret []*ParseError
+	first := make(map[string]*model.Action)
+	for _, action := range usesBlocks(c) {
+		repo, ok := action.Uses.(*model.UsesRepository)
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(repo.Repository)
+		prev, ok := first[key]
+		if !ok {
+			first[key] = action
+			continue
+		}
+		if prevRef := prev.Uses.(*model.UsesRepository).Ref; prevRef != repo.Ref {
+			ret = append(ret, NewWarning(&action.Uses, CodeInconsistentRef,
+				"Action `%s' uses `%s' at `%s', but action `%s' uses it at `%s'",
+				action.Identifier, repo.Repository, repo.Ref, prev.Identifier, prevRef))
+		}
+	}
+	return ret
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"path"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// UsesPolicy restricts what actions may use.  Every `uses' attribute that
+// matches a Deny pattern is an error.  If Allow has any patterns, so is
+// every attribute that doesn't match one of them: with only Owners set,
+// for example, Docker images and local paths are not allowed at all.
+type UsesPolicy struct {
+	Allow UsesPatterns
+	Deny  UsesPatterns
+}
+
+// UsesPatterns lists glob patterns, in the syntax of path.Match, for each
+// kind of `uses' attribute.  Malformed patterns match nothing.
+type UsesPatterns struct {
+	// Owners match the owner of a repository, like `actions', and
+	// Repositories match the owner and name, like `actions/*', both
+	// ignoring case.
+	Owners       []string
+	Repositories []string
+
+	// Registries match the host, and port if any, of a Docker image,
+	// like `gcr.io'.  Images without a host are on `docker.io'.
+	Registries []string
+
+	// Paths match the path of an action in the same repository, without
+	// the leading `./', like `actions/*'.
+	Paths []string
+}
+
+// WithUsesPolicy reports every action whose `uses' attribute policy does
+// not permit as an error.
+func WithUsesPolicy(policy UsesPolicy) OptionFunc {
+	return WithRules(usesPolicyRule{policy})
+}
+
+// usesPolicyRule is the rule added by WithUsesPolicy.
+type usesPolicyRule struct {
+	policy UsesPolicy
+}
+
+func (r usesPolicyRule) Check(c *model.Configuration) []*ParseError {
+	var ret []*ParseError
+	for _, action := range usesBlocks(c) {
+		if !resolvable(action.Uses) {
+			continue
+		}
+		if pattern, ok := r.policy.Deny.match(action.Uses); ok {
+			ret = append(ret, NewError(&action.Uses, CodeUsesDenied,
+				"Action `%s' uses `%s', which policy denies by `%s'", action.Identifier, action.Uses, pattern))
+		} else if !r.policy.Allow.isEmpty() {
+			if _, ok := r.policy.Allow.match(action.Uses); !ok {
+				ret = append(ret, NewError(&action.Uses, CodeUsesDenied,
+					"Action `%s' uses `%s', which policy does not allow", action.Identifier, action.Uses))
+			}
+		}
+	}
+	return ret
+}
+
+func (p UsesPatterns) isEmpty() bool {
+	return len(p.Owners) == 0 && len(p.Repositories) == 0 && len(p.Registries) == 0 && len(p.Paths) == 0
+}
+
+// match returns the first pattern that u matches, if any.
+func (p UsesPatterns) match(u model.Uses) (string, bool) {
+	switch u := u.(type) {
+	case *model.UsesRepository:
+		repo := strings.ToLower(u.Repository)
+		owner := repo[:strings.IndexByte(repo, '/')]
+		if pattern, ok := matchAny(p.Owners, owner, true); ok {
+			return pattern, true
+		}
+		return matchAny(p.Repositories, repo, true)
+	case *model.UsesDockerImage:
+		host := u.Host
+		if host == "" {
+			host = "docker.io"
+		}
+		if u.Port != "" {
+			host += ":" + u.Port
+		}
+		return matchAny(p.Registries, host, true)
+	case *model.UsesPath:
+		return matchAny(p.Paths, u.Path, false)
+	}
+	return "", false
+}
+
+func matchAny(patterns []string, name string, ignoreCase bool) (string, bool) {
+	for _, pattern := range patterns {
+		p := pattern
+		if ignoreCase {
+			p = strings.ToLower(p)
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
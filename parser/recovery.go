@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+)
+
+// WithSyntaxRecovery keeps parsing a file that isn't valid HCL.  Instead
+// of a single FATAL diagnostic and no actions or workflows, the parser
+// reports a FATAL diagnostic for each top-level block with a syntax
+// error, and parses and validates the rest of the file as if those
+// blocks weren't there.  Editors use it to keep a usable model while a
+// file is being typed.
+//
+// The parser finds top-level blocks by looking for lines that start with
+// `action "', `workflow "', or `template "', along with the comment
+// lines right before them, so a block that doesn't start at the
+// beginning of its line is parsed along with the one before it.
+func WithSyntaxRecovery() OptionFunc {
+	return func(ps *Parser) {
+		ps.syntaxRecovery = true
+	}
+}
+
+// blockStart matches the first line of a top-level block.
+var blockStart = regexp.MustCompile(`(?m)^(action|workflow|template)\s+"`)
+
+// recoverSyntax splits b, which failed to parse with the error first,
+// into chunks at the start of each top-level block, and parses each of
+// them on its own.  It returns an AST of the chunks that parse, and a
+// FATAL error for each of the others.
+func recoverSyntax(b []byte, first *hclparser.PosError) (*ast.File, ErrorList) {
+	starts := []int{0}
+	for _, loc := range blockStart.FindAllIndex(b, -1) {
+		if start := withLeadComments(b, loc[0]); start > starts[len(starts)-1] {
+			starts = append(starts, start)
+		}
+	}
+
+	root := &ast.File{Node: &ast.ObjectList{}}
+	items := root.Node.(*ast.ObjectList)
+	var fatals ErrorList
+	line := 1
+	for i, start := range starts {
+		end := len(b)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		chunk := b[start:end]
+
+		// Each chunk starts at the beginning of a line, so only offsets
+		// and lines need to move.
+		file, err := hcl.ParseBytes(chunk)
+		if err != nil {
+			if pe, ok := err.(*hclparser.PosError); ok {
+				pos := ErrorPos{Line: pe.Pos.Line + line - 1, Column: pe.Pos.Column, Offset: pe.Pos.Offset + start}
+				fatals = append(fatals, newFatal(pos, CodeSyntax, "%s", pe.Err.Error()))
+			}
+		} else {
+			shiftFile(file, start, line-1)
+			if list, ok := file.Node.(*ast.ObjectList); ok {
+				items.Items = append(items.Items, list.Items...)
+			}
+			root.Comments = append(root.Comments, file.Comments...)
+		}
+		line += bytes.Count(chunk, []byte("\n"))
+	}
+
+	if len(fatals) == 0 {
+		// Every chunk parsed on its own, so the error is in how they fit
+		// together; report it as it is.
+		pos := ErrorPos{Line: first.Pos.Line, Column: first.Pos.Column, Offset: first.Pos.Offset}
+		fatals = append(fatals, newFatal(pos, CodeSyntax, "%s", first.Err.Error()))
+	}
+	return root, fatals
+}
+
+// withLeadComments returns the start of the comment lines right before
+// the line at offset, so that a block keeps its comments even if the
+// block before it doesn't parse.
+func withLeadComments(b []byte, offset int) int {
+	for offset > 0 {
+		prev := bytes.LastIndexByte(b[:offset-1], '\n') + 1
+		line := bytes.TrimSpace(b[prev : offset-1])
+		if !bytes.HasPrefix(line, []byte("#")) && !bytes.HasPrefix(line, []byte("//")) {
+			break
+		}
+		offset = prev
+	}
+	return offset
+}
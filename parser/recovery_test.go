@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyntaxRecovery(t *testing.T) {
+	src := `workflow "w" {
+  on = "push"
+  resolves = ["b", "c"]
+}
+
+action "a" {
+  uses = 
+}
+
+# about b
+action "b" {
+  uses = "./b"
+}
+
+action "c" {
+  uses = "./c"
+  args = [
+}
+
+action "d" {
+  uses = "./d"
+  needs = "b"
+}
+`
+	_, err := Parse(strings.NewReader(src))
+	pe := extractParserError(t, err)
+	require.Len(t, pe.Errors, 1)
+	assert.Nil(t, pe.Actions)
+
+	config, errs, err := ParseWithDiagnostics(strings.NewReader(src), WithSyntaxRecovery())
+	require.NoError(t, err)
+	// Each broken block reports where HCL gave up on it: at the end of
+	// the chunk, which is where the next block or its comments start.
+	require.Len(t, errs, 4)
+	assert.Equal(t, CodeUnknownResolves, errs[0].Code)
+	assert.Equal(t, Severity(FATAL), errs[1].Severity)
+	assert.Equal(t, CodeSyntax, errs[1].Code)
+	assert.Equal(t, 10, errs[1].Pos.Line)
+	assert.Equal(t, CodeSyntax, errs[2].Code)
+	assert.Equal(t, 20, errs[2].Pos.Line)
+	assert.Equal(t, CodeUnusedAction, errs[3].Code)
+
+	require.Len(t, config.Actions, 2)
+	assert.Equal(t, "b", config.Actions[0].Identifier)
+	assert.Equal(t, []string{"# about b"}, config.Actions[0].Comments.Lead)
+	assert.Equal(t, 11, config.Actions[0].Pos.Line)
+	assert.Equal(t, "d", config.Actions[1].Identifier)
+	assert.Equal(t, 20, config.Actions[1].Pos.Line)
+	require.Len(t, config.Workflows, 1)
+
+	_, err = Parse(strings.NewReader(src), WithSyntaxRecovery())
+	pe = extractParserError(t, err)
+	assert.Len(t, pe.Actions, 2)
+}
+
+func TestSyntaxRecoveryValidFile(t *testing.T) {
+	config, errs, err := ParseWithDiagnostics(strings.NewReader(`action "a" { uses = "./a" }`), WithSyntaxRecovery())
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Len(t, config.Actions, 1)
+}
+
+func TestSyntaxRecoveryTemplate(t *testing.T) {
+	src := `action "a" {
+  uses = 
+}
+
+template "t" {
+  uses = "./t"
+}
+`
+	config, errs, err := ParseWithDiagnostics(strings.NewReader(src), WithSyntaxRecovery())
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, CodeSyntax, errs[0].Code)
+	require.Len(t, config.Templates, 1)
+	assert.Equal(t, "t", config.Templates[0].Identifier)
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// UsesResolver checks that what a `uses' attribute refers to exists: a
+// repository at a ref, a path in the workflow's own repository, or a
+// Docker image.  An implementation might ask the GitHub API, look at the
+// local checkout, or query a registry.  See WithUsesResolver.
+//
+// Resolve returns nil if u exists, or an error explaining why not.
+type UsesResolver interface {
+	Resolve(u model.Uses) error
+}
+
+// UsesResolverFunc adapts an ordinary function to the UsesResolver
+// interface.
+type UsesResolverFunc func(u model.Uses) error
+
+// Resolve calls f(u).
+func (f UsesResolverFunc) Resolve(u model.Uses) error {
+	return f(u)
+}
+
+// WithUsesResolver checks every action's `uses' attribute with r, and
+// reports those that don't resolve as errors at the attribute.  Each
+// distinct value is resolved once, no matter how many actions use it.
+// Values the parser already rejects, like malformed image references,
+// are not passed to r.
+func WithUsesResolver(r UsesResolver) OptionFunc {
+	return WithRules(usesResolverRule{r})
+}
+
+// usesResolverRule is the rule added by WithUsesResolver.
+type usesResolverRule struct {
+	resolver UsesResolver
+}
+
+func (r usesResolverRule) Check(c *model.Configuration) []*ParseError {
+	var ret []*ParseError
+	results := make(map[string]error)
+	for _, action := range usesBlocks(c) {
+		if !resolvable(action.Uses) {
+			continue
+		}
+		key := fmt.Sprintf("%T %s", action.Uses, action.Uses)
+		err, ok := results[key]
+		if !ok {
+			err = r.resolver.Resolve(action.Uses)
+			results[key] = err
+		}
+		if err != nil {
+			ret = append(ret, NewError(&action.Uses, CodeUsesUnresolved,
+				"Action `%s' uses `%s', which could not be resolved: %s", action.Identifier, action.Uses, err))
+		}
+	}
+	return ret
+}
+
+// resolvable returns whether u is well-formed enough to pass to a
+// UsesResolver.
+func resolvable(u model.Uses) bool {
+	switch u := u.(type) {
+	case *model.UsesDockerImage:
+		return u.Repository != ""
+	case *model.UsesRepository, *model.UsesPath:
+		return true
+	default:
+		return false
+	}
+}
+
+// DirResolver returns a UsesResolver that checks `uses = "./path"'
+// attributes against the repository checked out in dir, and accepts
+// everything else.
+func DirResolver(dir string) UsesResolver {
+	return UsesResolverFunc(func(u model.Uses) error {
+		path, ok := u.(*model.UsesPath)
+		if !ok {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path.Path))); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("no such directory `%s' in %s", path.Path, dir)
+			}
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"github.com/actions/workflow-parser/model"
+)
+
+// Rule is a custom validation, such as an organization's naming
+// conventions or a list of forbidden images, that runs alongside the
+// parser's built-in checks.  See WithRules.
+//
+// Check returns the problems it finds in c, created with NewWarning or
+// NewError so that the parser can locate them in the source.
+type Rule interface {
+	Check(c *model.Configuration) []*ParseError
+}
+
+// RuleFunc adapts an ordinary function to the Rule interface.
+type RuleFunc func(c *model.Configuration) []*ParseError
+
+// Check calls f(c).
+func (f RuleFunc) Check(c *model.Configuration) []*ParseError {
+	return f(c)
+}
+
+// NewWarning creates a warning for a Rule to return.  The subject is the
+// part of the configuration the warning is about, and determines the
+// position of the warning: an *model.Action or *model.Workflow points at
+// its block, and a pointer to one of their fields, like &action.Uses or
+// &workflow.Resolves, points at that attribute.  Custom rules should use
+// codes that don't start with `E_' or `W_', which are reserved for the
+// parser's own.
+func NewWarning(subject interface{}, code Code, format string, a ...interface{}) *ParseError {
+	e := newWarning(ErrorPos{}, code, format, a...)
+	e.subject = subject
+	return e
+}
+
+// NewError creates an error for a Rule to return.  See NewWarning.
+func NewError(subject interface{}, code Code, format string, a ...interface{}) *ParseError {
+	e := newError(ErrorPos{}, code, format, a...)
+	e.subject = subject
+	return e
+}
+
+// usesBlocks returns the actions and templates of c, the blocks that can
+// have a `uses' attribute, for rules about what actions use.
+func usesBlocks(c *model.Configuration) []*model.Action {
+	ret := make([]*model.Action, 0, len(c.Actions)+len(c.Templates))
+	ret = append(ret, c.Actions...)
+	return append(ret, c.Templates...)
+}
+
+// runRules runs the custom rules and records what they find.
+func (p *Parser) runRules() {
+	if len(p.rules) == 0 {
+		return
+	}
+
+	c := &model.Configuration{Version: p.version, Actions: p.actions, Workflows: p.workflows, Templates: p.templates, Env: p.env}
+	for _, rule := range p.rules {
+		if p.canceled() {
+			return
+		}
+		for _, e := range rule.Check(c) {
+			if e == nil {
+				continue
+			}
+			if node, ok := p.posMap[e.subject]; ok && e.Pos == (ErrorPos{}) {
+				e.Pos = posFromNode(node)
+			}
+			e.subject = nil
+			p.appendError(e)
+		}
+	}
+}
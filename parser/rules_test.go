@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noLatest forbids Docker images without an explicit tag.
+type noLatest struct{}
+
+func (noLatest) Check(c *model.Configuration) []*ParseError {
+	var ret []*ParseError
+	for _, action := range c.Actions {
+		if image, ok := action.Uses.(*model.UsesDockerImage); ok && !strings.Contains(image.Image, ":") {
+			ret = append(ret, NewError(&action.Uses, "ORG_UNTAGGED_IMAGE", "Image `%s' must have a tag", image.Image))
+		}
+	}
+	return ret
+}
+
+func TestWithRules(t *testing.T) {
+	lowercase := RuleFunc(func(c *model.Configuration) []*ParseError {
+		var ret []*ParseError
+		for _, w := range c.Workflows {
+			if strings.ToLower(w.Identifier) != w.Identifier {
+				ret = append(ret, NewWarning(w, "ORG_NAMING", "Workflow `%s' must be lowercase", w.Identifier))
+			}
+		}
+		return ret
+	})
+
+	src := `workflow "Build" {
+  on = "push"
+  resolves = ["a", "b"]
+}
+
+action "a" {
+  uses = "docker://alpine"
+}
+
+action "b" {
+  uses = "docker://alpine:3.8"
+}
+`
+	_, err := Parse(strings.NewReader(src), WithRules(noLatest{}, lowercase))
+	require.Error(t, err)
+	errs := err.(*Error).Errors
+	require.Len(t, errs, 2)
+
+	assert.Equal(t, "Line 1: Workflow `Build' must be lowercase [ORG_NAMING]", errs[0].Error())
+	assert.Equal(t, Severity(WARNING), errs[0].Severity)
+	assert.Equal(t, "Line 7: Image `alpine' must have a tag [ORG_UNTAGGED_IMAGE]", errs[1].Error())
+	assert.Equal(t, ErrorPos{Line: 7, Column: 10, Offset: 82}, errs[1].Pos)
+
+	_, err = Parse(strings.NewReader(src), WithRules(lowercase), WithSuppressWarnings())
+	assert.NoError(t, err)
+}
+
+func TestWithRequirePinnedRefs(t *testing.T) {
+	src := `workflow "w" {
+  on = "push"
+  resolves = ["a", "b", "c", "d", "e"]
+}
+action "a" { uses = "owner/repo@master" }
+action "b" { uses = "owner/repo/path@0123456789abcdef0123456789abcdef01234567" }
+action "c" { uses = "owner/repo@v1.2.3" }
+action "d" { uses = "owner/repo@v1" }
+action "e" { uses = "docker://alpine" }
+`
+	_, err := Parse(strings.NewReader(src))
+	require.NoError(t, err)
+
+	_, err = Parse(strings.NewReader(src), WithRequirePinnedRefs())
+	require.Error(t, err)
+	errs := err.(*Error).Errors
+	require.Len(t, errs, 2)
+	assert.Equal(t, "Line 5: Action `a' uses `owner/repo@master', which is not pinned to a commit SHA or a release tag [W_UNPINNED_REF]", errs[0].Error())
+	assert.Equal(t, 8, errs[1].Pos.Line)
+
+	_, err = Parse(strings.NewReader(src), WithRequirePinnedRefs(regexp.MustCompile(`^v[0-9]+$`)))
+	require.Error(t, err)
+	errs = err.(*Error).Errors
+	require.Len(t, errs, 2)
+	assert.Equal(t, 5, errs[0].Pos.Line)
+	assert.Equal(t, 7, errs[1].Pos.Line)
+}
+
+func TestWithConsistentRefs(t *testing.T) {
+	src := `workflow "w" {
+  on = "push"
+  resolves = ["a", "b", "c", "d", "e"]
+}
+action "a" { uses = "actions/checkout@v1" }
+action "b" { uses = "actions/bin/sh@master" }
+action "c" { uses = "Actions/Checkout@master" }
+action "d" { uses = "actions/bin/curl@master" }
+action "e" { uses = "actions/checkout/sub@v2" }
+`
+	_, err := Parse(strings.NewReader(src))
+	require.NoError(t, err)
+
+	_, err = Parse(strings.NewReader(src), WithConsistentRefs())
+	require.Error(t, err)
+	errs := err.(*Error).Errors
+	require.Len(t, errs, 2)
+	assert.Equal(t, "Line 7: Action `c' uses `Actions/Checkout' at `master', but action `a' uses it at `v1' [W_INCONSISTENT_REF]", errs[0].Error())
+	assert.Equal(t, 9, errs[1].Pos.Line)
+	assert.Equal(t, CodeInconsistentRef, errs[1].Code)
+}
+
+func TestWithUsesResolver(t *testing.T) {
+	src := `workflow "w" {
+  on = "push"
+  resolves = ["a", "b", "c", "d"]
+}
+action "a" { uses = "owner/missing@v1" }
+action "b" { uses = "owner/missing@v1" }
+action "c" { uses = "owner/repo@v1" }
+action "d" {
+  uses = "docker://Alpine"
+}
+`
+	var resolved []string
+	resolver := UsesResolverFunc(func(u model.Uses) error {
+		resolved = append(resolved, u.String())
+		if repo, ok := u.(*model.UsesRepository); ok && repo.Repository == "owner/missing" {
+			return errors.New("repository not found")
+		}
+		return nil
+	})
+
+	_, err := Parse(strings.NewReader(src), WithUsesResolver(resolver))
+	require.Error(t, err)
+	errs := err.(*Error).Errors
+	require.Len(t, errs, 3)
+	assert.Equal(t, "Line 5: Action `a' uses `owner/missing@v1', which could not be resolved: repository not found [E_USES_UNRESOLVED]", errs[0].Error())
+	assert.Equal(t, ErrorPos{Line: 6, Column: 21, Offset: 126}, errs[1].Pos)
+	assert.Equal(t, CodeImageInvalid, errs[2].Code)
+	assert.Equal(t, []string{"owner/missing@v1", "owner/repo@v1"}, resolved)
+}
+
+func TestDirResolver(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"actions/build/Dockerfile": "FROM alpine"})
+	defer os.RemoveAll(dir)
+
+	src := `workflow "w" {
+  on = "push"
+  resolves = ["a", "b", "c"]
+}
+action "a" { uses = "./actions/build" }
+action "b" { uses = "./actions/test" }
+action "c" { uses = "owner/repo@v1" }
+`
+	_, err := Parse(strings.NewReader(src), WithUsesResolver(DirResolver(dir)))
+	require.Error(t, err)
+	errs := err.(*Error).Errors
+	require.Len(t, errs, 1)
+	assert.Equal(t, 6, errs[0].Pos.Line)
+	assert.Contains(t, errs[0].Message(), "no such directory `actions/test'")
+}
+
+func TestWithUsesPolicy(t *testing.T) {
+	src := `workflow "w" {
+  on = "push"
+  resolves = ["a", "b", "c", "d", "e", "f", "g"]
+}
+action "a" { uses = "Actions/bin@v1" }
+action "b" { uses = "actions/deprecated@v1" }
+action "c" { uses = "stranger/repo@v1" }
+action "d" { uses = "docker://alpine" }
+action "e" { uses = "docker://gcr.io/project/image" }
+action "f" { uses = "./ci/build" }
+action "g" { uses = "./scripts/deploy" }
+`
+	_, err := Parse(strings.NewReader(src), WithUsesPolicy(UsesPolicy{}))
+	assert.NoError(t, err)
+
+	_, err = Parse(strings.NewReader(src), WithUsesPolicy(UsesPolicy{
+		Allow: UsesPatterns{
+			Owners:     []string{"actions"},
+			Registries: []string{"docker.io"},
+			Paths:      []string{"ci/*"},
+		},
+		Deny: UsesPatterns{
+			Repositories: []string{"actions/deprecated"},
+		},
+	}))
+	require.Error(t, err)
+	errs := err.(*Error).Errors
+	require.Len(t, errs, 4)
+	assert.Equal(t, "Line 6: Action `b' uses `actions/deprecated@v1', which policy denies by `actions/deprecated' [E_USES_DENIED]", errs[0].Error())
+	assert.Equal(t, "Line 7: Action `c' uses `stranger/repo@v1', which policy does not allow [E_USES_DENIED]", errs[1].Error())
+	assert.Equal(t, Severity(ERROR), errs[1].Severity)
+	assert.Equal(t, 9, errs[2].Pos.Line)
+	assert.Equal(t, 11, errs[3].Pos.Line)
+
+	// Only denying leaves everything else allowed.
+	_, err = Parse(strings.NewReader(src), WithUsesPolicy(UsesPolicy{
+		Deny: UsesPatterns{Registries: []string{"*.io"}},
+	}))
+	require.Error(t, err)
+	errs = err.(*Error).Errors
+	require.Len(t, errs, 2)
+	assert.Equal(t, 8, errs[0].Pos.Line)
+	assert.Equal(t, 9, errs[1].Pos.Line)
+}
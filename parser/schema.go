@@ -0,0 +1,234 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// schema is a JSON Schema, or part of one.
+type schema map[string]interface{}
+
+// attribute is what an attribute of an action, template, or workflow is
+// for, for editors to show, and the values it may have.
+type attribute struct {
+	description string
+	value       schema
+}
+
+// ref returns a schema that refers to one of the definitions in the
+// schema JSONSchema returns.
+func ref(name string) schema {
+	return schema{"$ref": "#/definitions/" + name}
+}
+
+// attributeSchemas holds the attributes of each kind of block, following
+// the checks parseActionAttribute and workflowifyItem make.  It must have
+// every name in actionAttributes and workflowAttributes.
+var attributeSchemas = map[string]map[string]attribute{
+	"action": {
+		"description":       {"What the action does.", ref("description")},
+		"extends":           {"The template the action takes its attributes from.", ref("nonBlankString")},
+		"uses":              {"The action to run: a repository path and ref, a local directory, or a `docker://' image.", ref("nonBlankString")},
+		"needs":             {"The actions that must finish before this one runs.", ref("stringOrList")},
+		"if":                {"An expression that must be true for the action to run.", ref("nonBlankString")},
+		"runs":              {"The command to run instead of the image's entrypoint.", ref("command")},
+		"args":              {"The arguments to the command.", ref("command")},
+		"env":               {"Environment variables for the action.", ref("env")},
+		"matrix":            {"Dimensions to run the action across, each with a list of values.", ref("matrix")},
+		"secrets":           {"Secrets to pass to the action as environment variables.", ref("stringList")},
+		"continue_on_error": {"Whether the workflow carries on if the action fails.", schema{"type": "boolean"}},
+	},
+	"workflow": {
+		"description": {"What the workflow does.", ref("description")},
+		"on":          {"The events that trigger the workflow.", ref("on")},
+		"resolves":    {"The actions the workflow runs, along with the actions they need.", ref("stringOrList")},
+		"env":         {"Environment variables for the actions the workflow runs.", ref("env")},
+	},
+}
+
+// JSONSchema returns a JSON Schema (draft 7) that describes the JSON form
+// of .workflow files: the blocks a file may have, their attributes, and
+// the types of their values.  It is built from the parser's own rules,
+// so that editors and documentation that use it stay in step with the
+// parser.  The options that change those rules are honored too: a
+// schema for WithIdentifierPattern, WithMaxIdentifierLength,
+// WithMaxDescriptionLength, or WithEventRegistry checks what a parser
+// with the same options does.  The identifier pattern is copied as it
+// is, so it should be one that JSON Schema's ECMA 262 regular expressions
+// read the same way Go's do.
+//
+// The schema can't express every check.  It lists events in lower case,
+// although the parser accepts any case, and it notes which version of
+// the language an attribute needs without enforcing it.  It knows
+// nothing of the relations between blocks, like `needs' naming an action
+// that exists.
+func JSONSchema(options ...OptionFunc) ([]byte, error) {
+	p := newParser(context.Background(), options...)
+	defer p.release()
+
+	definitions := schema{
+		"nonBlankString": schema{"type": "string", "minLength": 1},
+		"stringList":     schema{"type": "array", "items": schema{"type": "string"}},
+		"stringOrList": schema{"oneOf": []interface{}{
+			schema{"type": "string"},
+			ref("stringList"),
+		}},
+		"command": schema{"oneOf": []interface{}{
+			schema{"type": "string", "minLength": 1},
+			ref("stringList"),
+		}},
+		"env": schema{
+			"type":                 "object",
+			"additionalProperties": schema{"type": "string"},
+		},
+		"matrix": schema{
+			"type":                 "object",
+			"additionalProperties": ref("stringOrList"),
+		},
+		"description": p.descriptionSchema(),
+		"event":       p.eventSchema(),
+		"on": schema{"oneOf": []interface{}{
+			ref("event"),
+			schema{"type": "array", "items": ref("event"), "minItems": 1},
+		}},
+		"identifier": p.identifierSchema(),
+		"action": blockSchema("action", actionAttributes, nil, schema{"anyOf": []interface{}{
+			schema{"required": []string{"uses"}},
+			schema{"required": []string{"extends"}},
+		}}),
+		"template": blockSchema("action", actionAttributes, []string{"needs", "extends"}, nil),
+		"workflow": blockSchema("workflow", workflowAttributes, nil, schema{"required": []string{"on"}}),
+	}
+
+	properties := schema{
+		"version": schema{
+			"description": "The version of the language the file is written in.",
+			"type":        "integer",
+			"minimum":     minVersion,
+			"maximum":     maxVersion,
+		},
+		"env": schema{
+			"description": "Environment variables for every action in the file.",
+			"allOf":       []interface{}{ref("env")},
+		},
+		"include": schema{
+			"description": "Glob patterns naming other files whose blocks this file uses.",
+			"allOf":       []interface{}{ref("stringOrList")},
+		},
+	}
+	for _, kind := range []string{"action", "template", "workflow"} {
+		blocks := schema{
+			"type":                 "object",
+			"propertyNames":        ref("identifier"),
+			"additionalProperties": ref(kind),
+		}
+		properties[kind] = schema{"oneOf": []interface{}{
+			blocks,
+			schema{"type": "array", "items": blocks},
+		}}
+	}
+
+	return json.MarshalIndent(schema{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                ".workflow file",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+		"definitions":          definitions,
+	}, "", "  ")
+}
+
+// blockSchema returns the schema of a block of the given kind, which has
+// the attributes in names but those in omit, and whatever keywords extra
+// adds, like those that say which attributes it needs.
+func blockSchema(kind string, names, omit []string, extra schema) schema {
+	properties := schema{}
+	for _, name := range names {
+		if containsString(omit, name) {
+			continue
+		}
+		a, ok := attributeSchemas[kind][name]
+		if !ok {
+			panic(fmt.Sprintf("no schema for %s attribute `%s'", kind, name))
+		}
+		description := a.description
+		if v := model.FeatureVersion(name); kind == "action" && v > 0 {
+			description += fmt.Sprintf(" Requires `version = %d'.", v)
+		}
+		// Keywords beside a $ref are ignored, so the description goes
+		// around it.
+		properties[name] = schema{"description": description, "allOf": []interface{}{a.value}}
+	}
+	ret := schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	for k, v := range extra {
+		ret[k] = v
+	}
+	return ret
+}
+
+// descriptionSchema returns the schema of a `description', which
+// WithMaxDescriptionLength limits.
+func (p *Parser) descriptionSchema() schema {
+	ret := schema{"type": "string", "minLength": 1}
+	if p.maxDescriptionLen > 0 {
+		ret["maxLength"] = p.maxDescriptionLen
+	}
+	return ret
+}
+
+// identifierSchema returns the schema of the identifier of an action,
+// template, or workflow, which WithIdentifierPattern and
+// WithMaxIdentifierLength limit.
+func (p *Parser) identifierSchema() schema {
+	ret := schema{"type": "string", "minLength": 1}
+	if p.maxIdentifierLen > 0 {
+		ret["maxLength"] = p.maxIdentifierLen
+	}
+	if p.identifierPattern != nil {
+		ret["pattern"] = p.identifierPattern.String()
+	}
+	return ret
+}
+
+// eventSchema returns the schema of an event in a workflow's `on': an
+// event type the parser allows, that type with one of its activities,
+// or a schedule.
+func (p *Parser) eventSchema() schema {
+	var types []string
+	if p.events != nil {
+		types = p.events.Types()
+	} else {
+		for t := range eventTypeWhitelist {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+	}
+
+	var events []string
+	for _, t := range types {
+		if t == model.ScheduleEventType {
+			continue
+		}
+		events = append(events, t)
+		for _, activity := range eventActivities[t] {
+			events = append(events, t+"."+activity)
+		}
+	}
+	ret := []interface{}{schema{"type": "string", "enum": events}}
+	if containsString(types, model.ScheduleEventType) {
+		ret = append(ret, schema{
+			"type":    "string",
+			"pattern": "^" + regexp.QuoteMeta(model.ScheduleEventType) + `\(.+\)$`,
+		})
+	}
+	return schema{"anyOf": ret}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	b, err := JSONSchema()
+	require.NoError(t, err)
+
+	var s map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &s))
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", s["$schema"])
+
+	definitions := s["definitions"].(map[string]interface{})
+	attributes := func(kind string) map[string]interface{} {
+		return definitions[kind].(map[string]interface{})["properties"].(map[string]interface{})
+	}
+	for _, name := range actionAttributes {
+		assert.Contains(t, attributes("action"), name)
+	}
+	for _, name := range workflowAttributes {
+		assert.Contains(t, attributes("workflow"), name)
+	}
+	assert.NotContains(t, attributes("template"), "needs")
+	assert.NotContains(t, attributes("template"), "extends")
+	assert.Equal(t, "An expression that must be true for the action to run. Requires `version = 1'.",
+		attributes("action")["if"].(map[string]interface{})["description"])
+
+	version := s["properties"].(map[string]interface{})["version"].(map[string]interface{})
+	assert.Equal(t, float64(maxVersion), version["maximum"])
+
+	identifier := definitions["identifier"].(map[string]interface{})
+	assert.NotContains(t, identifier, "pattern")
+	assert.NotContains(t, identifier, "maxLength")
+
+	events := definitions["event"].(map[string]interface{})["anyOf"].([]interface{})
+	require.Len(t, events, 2)
+	enum := events[0].(map[string]interface{})["enum"]
+	assert.Contains(t, enum, "push")
+	assert.Contains(t, enum, "pull_request.opened")
+	assert.NotContains(t, enum, "schedule")
+}
+
+func TestJSONSchemaOptions(t *testing.T) {
+	b, err := JSONSchema(
+		WithIdentifierPattern(DefaultIdentifierPattern),
+		WithMaxIdentifierLength(32),
+		WithMaxDescriptionLength(0),
+		WithEventRegistry(NewEventRegistry("push", "deployment")),
+	)
+	require.NoError(t, err)
+
+	var s struct {
+		Definitions map[string]map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(b, &s))
+	assert.Equal(t, DefaultIdentifierPattern.String(), s.Definitions["identifier"]["pattern"])
+	assert.Equal(t, float64(32), s.Definitions["identifier"]["maxLength"])
+	assert.NotContains(t, s.Definitions["description"], "maxLength")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "string", "enum": []interface{}{"deployment", "push"}},
+	}, s.Definitions["event"]["anyOf"])
+}
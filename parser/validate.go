@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// Check identifies a group of semantic checks run by the validator.
+// Checks can be combined with bitwise OR.
+type Check int
+
+const (
+	// CheckNeeds verifies that every action named in a `needs' attribute
+	// exists.
+	CheckNeeds Check = 1 << iota
+
+	// CheckCycles looks for circular dependencies between actions.
+	CheckCycles
+
+	// CheckActions verifies the `uses', `env', and `secrets' attributes
+	// of every action, including the limit on unique secrets.
+	CheckActions
+
+	// CheckWorkflows verifies the `on' and `resolves' attributes of every
+	// workflow, including whether any action it resolves is already
+	// needed by another.
+	CheckWorkflows
+
+	// CheckUnused warns about actions that no workflow resolves, directly
+	// or through `needs'.  Files without workflows are not checked, since
+	// they usually hold actions for other files to use.
+	CheckUnused
+
+	// CheckExpressions checks the syntax of variable references like
+	// `${HOME}' and expressions like `${{ github.sha }}' in the `runs',
+	// `args', and `env' values of every action, and warns about
+	// expressions that use unknown contexts.  Platforms that pass these
+	// values through untouched can turn it off.
+	CheckExpressions
+
+	// CheckTemplates verifies the `env' and `secrets' attributes of every
+	// template, and that no template has `needs' or extends another.
+	CheckTemplates
+
+	// AllChecks runs every check.  This is what Parse does.
+	AllChecks = CheckNeeds | CheckCycles | CheckActions | CheckWorkflows | CheckUnused | CheckExpressions | CheckTemplates
+)
+
+// AffectedChecks returns the checks that must be re-run after the named
+// attribute of an action or workflow is changed.  Renaming, adding, or
+// removing an action or workflow can affect any check; pass "" for those.
+func AffectedChecks(attribute string) Check {
+	switch attribute {
+	case "needs":
+		// Templates can't have `needs'.
+		return CheckNeeds | CheckCycles | CheckWorkflows | CheckUnused | CheckTemplates
+	case "runs", "args":
+		return CheckActions | CheckExpressions
+	case "env":
+		// Workflows and templates have `env' blocks too.
+		return CheckActions | CheckWorkflows | CheckTemplates | CheckExpressions
+	case "if":
+		return CheckExpressions
+	case "uses", "matrix":
+		return CheckActions
+	case "secrets", "extends":
+		return CheckActions | CheckTemplates
+	case "on":
+		return CheckWorkflows
+	case "description":
+		return CheckActions | CheckWorkflows
+	case "resolves":
+		return CheckWorkflows | CheckUnused
+	default:
+		return AllChecks
+	}
+}
+
+// Validate runs the checks Parse runs against c, a Configuration that
+// was built or changed in memory, e.g., by a converter or a rewriter,
+// without printing and parsing it again.  Options apply as they do to
+// Parse, so WithoutChecks skips checks and WithRules adds rules.  Validate
+// also reports identifiers shared by more than one action or workflow or
+// of the wrong format, and invalid `uses' values, which the parser
+// otherwise catches while reading the file.
+//
+// Like Revalidate, Validate has no source to point at, so the returned
+// errors have no line or column information.  It leaves c as it is.
+func Validate(c *model.Configuration, options ...OptionFunc) ErrorList {
+	p := newParser(context.Background(), options...)
+	defer p.release()
+
+	// The checks tidy the model as they go, e.g., removing duplicate
+	// needs, so they get a copy.
+	c = c.Clone()
+	p.version = c.Version
+	p.actions = c.Actions
+	p.workflows = c.Workflows
+	p.templates = c.Templates
+	p.env = c.Env
+	p.checkModel()
+	p.validate()
+	p.finishErrors()
+
+	return p.errors
+}
+
+// checkModel reports what the parser checks while reading a file: each
+// identifier that an earlier action or workflow in the model already
+// has, as checkIdentifier does, identifiers of the wrong format, as
+// parseIdentifier does, and invalid `uses' values, as parseUses does.
+func (p *Parser) checkModel() {
+	seen := make(map[string]bool, len(p.actions)+len(p.workflows))
+	check := func(cmd, id string) {
+		key := id
+		if p.separateNamespaces {
+			key = cmd + " " + id
+		}
+		if seen[key] {
+			p.addError(nil, CodeIdentifierRedefined, "Identifier `%s' redefined", id)
+		}
+		seen[key] = true
+		p.checkIdentifierFormat(nil, id)
+	}
+	for _, action := range p.actions {
+		check("action", action.Identifier)
+		switch uses := action.Uses.(type) {
+		case *model.UsesInvalid:
+			p.addError(nil, CodeUsesInvalid, "The `uses' attribute must be a path, a Docker image, or owner/repo@ref")
+		case *model.UsesDockerImage:
+			if _, err := model.ParseDockerImage(uses.Image); err != nil {
+				p.addError(nil, CodeImageInvalid, "Invalid Docker image `%s' in action `%s': %s", uses.Image, action.Identifier, err)
+			}
+		}
+	}
+	for _, workflow := range p.workflows {
+		check("workflow", workflow.Identifier)
+	}
+}
+
+// Revalidate runs the given checks against a Configuration that has
+// already been parsed, typically after the caller has modified it.  Only
+// the requested checks are run, so a caller that changed a single
+// attribute can use AffectedChecks to avoid re-validating the whole file.
+//
+// The Configuration carries no source positions, so the returned errors
+// have no line or column information.  Like Validate, Revalidate leaves
+// c as it is.
+func Revalidate(c *model.Configuration, checks Check, options ...OptionFunc) []*ParseError {
+	p := newParser(context.Background(), options...)
+	defer p.release()
+
+	c = c.Clone()
+	p.version = c.Version
+	p.actions = c.Actions
+	p.workflows = c.Workflows
+	p.templates = c.Templates
+	p.checks = checks
+	p.validate()
+	p.finishErrors()
+
+	return p.errors
+}
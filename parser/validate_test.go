@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevalidate(t *testing.T) {
+	config, err := parseString(`
+		workflow "w" { on="push" resolves="a" }
+		action "a" { uses="./x" needs="b" }
+		action "b" { uses="./y" }`)
+	assertParseSuccess(t, err, 2, 1, config)
+	assert.Empty(t, Revalidate(config, AllChecks))
+
+	// adding a need only re-checks dependencies
+	b := config.GetAction("b")
+	b.Needs = []string{"a", "c"}
+	b.Secrets = []string{"GITHUB_NOPE"}
+	errs := Revalidate(config, AffectedChecks("needs"))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "Action `b' needs nonexistent action `c'", errs[0].Message())
+	assert.Equal(t, "Circular dependency on `a': a -> b -> a", errs[1].Message())
+	assert.Equal(t, []string{"a", "b"}, errs[1].Cycle)
+	assert.Equal(t, CodeUnknownNeeds, errs[0].Code)
+	assert.Equal(t, "Circular dependency on `a': a -> b -> a [E_CIRCULAR_DEPENDENCY]", errs[1].Error())
+
+	errs = Revalidate(config, AffectedChecks("secrets"))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "reserved")
+
+	config.Workflows[0].On = model.ParseEvents("shove")
+	errs = Revalidate(config, AffectedChecks("on"))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Workflow `w' has unknown `on' value `shove'", errs[0].Message())
+
+	assert.Len(t, Revalidate(config, AllChecks), 4)
+	errs = Revalidate(config, AllChecks, WithSuppressErrors())
+	require.Len(t, errs, 1)
+	assert.Equal(t, Severity(FATAL), errs[0].Severity)
+}
+
+func TestRevalidateLeavesConfiguration(t *testing.T) {
+	config := &model.Configuration{
+		Actions: []*model.Action{
+			{Identifier: "a", Uses: &model.UsesPath{Path: "x"}},
+			{Identifier: "b", Uses: &model.UsesPath{Path: "y"}, Needs: []string{"a", "a"}},
+		},
+	}
+
+	Revalidate(config, AllChecks)
+	Validate(config)
+	assert.Equal(t, []string{"a", "a"}, config.Actions[1].Needs)
+}
+
+func TestAffectedChecks(t *testing.T) {
+	assert.Equal(t, CheckNeeds|CheckCycles|CheckWorkflows|CheckUnused|CheckTemplates, AffectedChecks("needs"))
+	assert.Equal(t, CheckActions|CheckWorkflows|CheckTemplates|CheckExpressions, AffectedChecks("env"))
+	assert.Equal(t, CheckActions|CheckTemplates, AffectedChecks("secrets"))
+	assert.Equal(t, CheckActions, AffectedChecks("uses"))
+	assert.Equal(t, CheckWorkflows, AffectedChecks("on"))
+	assert.Equal(t, CheckWorkflows|CheckUnused, AffectedChecks("resolves"))
+	assert.Equal(t, AllChecks, AffectedChecks(""))
+}
+
+func TestValidate(t *testing.T) {
+	config := &model.Configuration{
+		Actions: []*model.Action{
+			{Identifier: "a", Uses: &model.UsesPath{Path: "x"}, Needs: []string{"b", "c"}},
+			{Identifier: "b", Uses: &model.UsesPath{Path: "y"}},
+		},
+		Workflows: []*model.Workflow{
+			{Identifier: "b", On: model.ParseEvents("push"), Resolves: []string{"a"}},
+		},
+	}
+
+	errs := Validate(config)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "Identifier `b' redefined", errs[0].Message())
+	assert.Equal(t, CodeIdentifierRedefined, errs[0].Code)
+	assert.Equal(t, "Action `a' needs nonexistent action `c'", errs[1].Message())
+	assert.Equal(t, Severity(ERROR), errs[1].Severity)
+
+	assert.Len(t, Validate(config, WithSeparateNamespaces()), 1)
+	assert.Empty(t, Validate(config, WithSeparateNamespaces(), WithoutChecks(CheckNeeds)))
+
+	config.Actions[0].Needs = []string{"b"}
+	assert.Empty(t, Validate(config, WithSeparateNamespaces()))
+
+	config.Actions[1].Uses = model.ParseUses("nope")
+	errs = Validate(config, WithSeparateNamespaces())
+	require.Len(t, errs, 1)
+	assert.Equal(t, CodeUsesInvalid, errs[0].Code)
+
+	config.Actions[1].Uses = model.ParseUses("./y")
+	config.Actions[1].If = "success()"
+	errs = Validate(config, WithSeparateNamespaces())
+	require.Len(t, errs, 1)
+	assert.Equal(t, CodeRequiresVersion, errs[0].Code)
+	assert.Nil(t, errs[0].Fix)
+	errs = Validate(config, WithSeparateNamespaces(), WithoutChecks(AllChecks))
+	require.Len(t, errs, 1)
+	assert.Equal(t, CodeRequiresVersion, errs[0].Code)
+	config.Version = 1
+	assert.Empty(t, Validate(config, WithSeparateNamespaces()))
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+// fileVersion is the version a file declares, and the value of its
+// `version' statement, for fixes that change it.
+type fileVersion struct {
+	version int
+	node    ast.Node
+}
+
+// setVersion records that the file node is in declares version, at
+// node.
+func (p *Parser) setVersion(node ast.Node, version int) {
+	if p.versions == nil {
+		p.versions = make(map[string]fileVersion)
+	}
+	p.versions[node.Pos().Filename] = fileVersion{version: version, node: node}
+	if version > p.version {
+		p.version = version
+	}
+}
+
+// versionAt returns the version of the file node is in, which is 0 if the
+// file has no `version' statement.  Without a node, as for a
+// Configuration passed to Validate, it is the version of the whole
+// configuration.
+func (p *Parser) versionAt(node ast.Node) int {
+	if node == nil {
+		return p.version
+	}
+	return p.versions[node.Pos().Filename].version
+}
+
+// checkVersion reports each feature that an action or template uses and
+// that the version of the file it is in doesn't have.
+func (p *Parser) checkVersion() {
+	check := func(kind string, a *model.Action) {
+		for _, f := range a.Features() {
+			node := featureNode(p.posMap, a, f.Attribute)
+			if p.versionAt(node) >= f.Version {
+				continue
+			}
+			e := p.addError(node, CodeRequiresVersion, "`%s' in %s `%s' requires `version = %d'", f.Attribute, kind, a.Identifier, f.Version)
+			if e != nil && node != nil {
+				e.Fix = p.requireVersion(node, f.Version)
+			}
+		}
+	}
+	for _, t := range p.templates {
+		check("template", t)
+	}
+	for _, a := range p.actions {
+		check("action", a)
+	}
+}
+
+// featureNode returns the value of the attribute of a that a Feature
+// names, or nil if posMap doesn't have it.
+func featureNode(posMap map[interface{}]ast.Node, a *model.Action, attribute string) ast.Node {
+	switch attribute {
+	case "if":
+		return posMap[&a.If]
+	case "matrix":
+		return posMap[&a.Matrix]
+	default:
+		return nil
+	}
+}
+
+// requireVersion returns a Fix that makes the file node is in declare
+// version: it changes the file's `version' statement, or adds one at the
+// top of the file if there is none.  It returns nil for a JSON file
+// without one, which has no line to add.
+func (p *Parser) requireVersion(node ast.Node, version int) *Fix {
+	text := fmt.Sprint(version)
+	if v, ok := p.versions[node.Pos().Filename]; ok {
+		literal, ok := v.node.(*ast.LiteralType)
+		if !ok {
+			return nil
+		}
+		return &Fix{
+			Title: "Change to `version = " + text + "'",
+			Edits: []TextEdit{{Start: posFromToken(literal.Token), End: tokenEnd(literal.Token), NewText: text}},
+		}
+	}
+	if fromJSON(node) {
+		return nil
+	}
+	start := ErrorPos{File: node.Pos().Filename, Line: 1, Column: 1}
+	return &Fix{
+		Title: "Add `version = " + text + "'",
+		Edits: []TextEdit{{Start: start, End: start, NewText: "version = " + text + "\n\n"}},
+	}
+}
@@ -0,0 +1,100 @@
+// Package plan works out the order in which a workflow's actions can run.
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/soniakeys/graph"
+)
+
+// Stages returns the actions that the workflow resolves, directly or
+// through `needs', grouped into stages that can run one after another.
+// The actions in each stage need only actions in earlier stages, so they
+// can run in parallel; each action runs in the earliest stage it can.
+// Within a stage, actions are in the order they appear in c.
+//
+// Stages returns an error if the workflow doesn't exist, if it refers to
+// an action that doesn't exist, or if its actions need each other in a
+// cycle.  The parser reports all of these, so they don't happen for a
+// configuration it returns without error.
+func Stages(c *model.Configuration, workflowID string) ([][]*model.Action, error) {
+	workflow := c.GetWorkflow(workflowID)
+	if workflow == nil {
+		return nil, fmt.Errorf("workflow `%s' does not exist", workflowID)
+	}
+
+	index := make(map[string]graph.NI, len(c.Actions))
+	for i, action := range c.Actions {
+		if _, ok := index[action.Identifier]; !ok {
+			index[action.Identifier] = graph.NI(i)
+		}
+	}
+
+	// Find the actions the workflow needs, and the edges between them.
+	adjList := make(graph.AdjacencyList, len(c.Actions))
+	included := make([]bool, len(c.Actions))
+	var queue []graph.NI
+	for _, id := range workflow.Resolves {
+		n, ok := index[id]
+		if !ok {
+			return nil, fmt.Errorf("workflow `%s' resolves unknown action `%s'", workflowID, id)
+		}
+		queue = append(queue, n)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if included[n] {
+			continue
+		}
+		included[n] = true
+		action := c.Actions[n]
+		for _, need := range action.Needs {
+			m, ok := index[need]
+			if !ok {
+				return nil, fmt.Errorf("action `%s' needs nonexistent action `%s'", action.Identifier, need)
+			}
+			adjList[n] = append(adjList[n], m)
+			queue = append(queue, m)
+		}
+	}
+
+	// Edges run from each action to the actions it needs, so a
+	// topological ordering puts every action before its needs.
+	ordering, cycle := graph.Directed{AdjacencyList: adjList}.Topological()
+	if cycle != nil {
+		path := make([]string, 0, len(cycle)+1)
+		for _, n := range cycle {
+			path = append(path, c.Actions[n].Identifier)
+		}
+		path = append(path, path[0])
+		return nil, fmt.Errorf("circular dependency on `%s': %s", path[0], strings.Join(path, " -> "))
+	}
+
+	stage := make([]int, len(c.Actions))
+	nstages := 0
+	for i := len(ordering) - 1; i >= 0; i-- {
+		n := ordering[i]
+		if !included[n] {
+			continue
+		}
+		for _, m := range adjList[n] {
+			if stage[m]+1 > stage[n] {
+				stage[n] = stage[m] + 1
+			}
+		}
+		if stage[n]+1 > nstages {
+			nstages = stage[n] + 1
+		}
+	}
+
+	ret := make([][]*model.Action, nstages)
+	for n, action := range c.Actions {
+		if included[n] {
+			ret[stage[n]] = append(ret[stage[n]], action)
+		}
+	}
+	return ret, nil
+}
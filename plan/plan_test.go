@@ -0,0 +1,86 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func identifiers(stages [][]*model.Action) [][]string {
+	ret := make([][]string, len(stages))
+	for i, stage := range stages {
+		for _, action := range stage {
+			ret[i] = append(ret[i], action.Identifier)
+		}
+	}
+	return ret
+}
+
+func TestStages(t *testing.T) {
+	config, err := parser.Parse(strings.NewReader(`
+workflow "ci" {
+  on = "push"
+  resolves = ["deploy", "lint"]
+}
+
+workflow "other" {
+  on = "push"
+  resolves = "unrelated"
+}
+
+action "deploy" {
+  uses = "./deploy"
+  needs = ["test", "build"]
+}
+action "test" {
+  uses = "./test"
+  needs = "build"
+}
+action "build" { uses = "./build" }
+action "lint" { uses = "./lint" }
+action "unrelated" { uses = "./unrelated" }
+`))
+	require.NoError(t, err)
+
+	stages, err := Stages(config, "ci")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"build", "lint"}, {"test"}, {"deploy"}}, identifiers(stages))
+
+	stages, err = Stages(config, "other")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"unrelated"}}, identifiers(stages))
+
+	_, err = Stages(config, "nope")
+	assert.EqualError(t, err, "workflow `nope' does not exist")
+}
+
+func TestStagesErrors(t *testing.T) {
+	config := &model.Configuration{
+		Actions: []*model.Action{
+			{Identifier: "a", Needs: []string{"b"}},
+			{Identifier: "b", Needs: []string{"a"}},
+			{Identifier: "c", Needs: []string{"missing"}},
+		},
+		Workflows: []*model.Workflow{
+			{Identifier: "cycle", Resolves: []string{"a"}},
+			{Identifier: "missing", Resolves: []string{"c"}},
+			{Identifier: "unknown", Resolves: []string{"z"}},
+			{Identifier: "empty"},
+		},
+	}
+
+	_, err := Stages(config, "cycle")
+	assert.EqualError(t, err, "circular dependency on `a': a -> b -> a")
+	_, err = Stages(config, "missing")
+	assert.EqualError(t, err, "action `c' needs nonexistent action `missing'")
+	_, err = Stages(config, "unknown")
+	assert.EqualError(t, err, "workflow `unknown' resolves unknown action `z'")
+
+	stages, err := Stages(config, "empty")
+	require.NoError(t, err)
+	assert.Empty(t, stages)
+}
@@ -0,0 +1,335 @@
+package printer
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+	hclstrconv "github.com/hashicorp/hcl/hcl/strconv"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// Format reprints the .workflow file in src in canonical form, like
+// `terraform fmt': two-space indentation, one attribute per line, the
+// attributes of actions and workflows in a fixed order, and strings
+// quoted the same way Write quotes them.  Unlike Write, it works from
+// the syntax of the file rather than the parsed model, so it keeps
+// comments, the order of blocks, and anything the parser would reject.
+// It returns an error only if src is not valid HCL.
+func Format(src []byte) ([]byte, error) {
+	file, err := hclparser.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &formatter{}
+	for _, group := range file.Comments {
+		f.comments = append(f.comments, group.List...)
+	}
+	sort.Slice(f.comments, func(i, j int) bool {
+		return f.comments[i].Start.Offset < f.comments[j].Start.Offset
+	})
+
+	root, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return src, nil
+	}
+	items := f.items(root, "", -1)
+	trailing := f.take(-1)
+
+	for i, item := range items {
+		if i > 0 {
+			f.buf.WriteString("\n")
+		}
+		f.item(item, 0)
+	}
+	if len(trailing) > 0 && len(items) > 0 {
+		f.buf.WriteString("\n")
+	}
+	f.comment(trailing, 0)
+	return f.buf.Bytes(), nil
+}
+
+// formatter collects the syntax of a file, with every comment attached
+// to the item or list element it belongs with, and then prints it.
+type formatter struct {
+	buf      bytes.Buffer
+	comments []*ast.Comment
+}
+
+// fItem is an attribute or block, with its comments: the lead comments
+// on the lines before it, and a line comment after it on its last line.
+// Gap is whether a blank line separates the lead comments from the item.
+type fItem struct {
+	lead []*ast.Comment
+	gap  bool
+	keys []string
+	val  interface{} // string, *fList, or *fObject
+	line *ast.Comment
+}
+
+type fList struct {
+	elems     []*fItem // values only, without keys
+	multiline bool
+	trailing  []*ast.Comment
+}
+
+type fObject struct {
+	items    []*fItem
+	trailing []*ast.Comment
+}
+
+// take removes and returns the comments before offset, or all of them if
+// offset is negative.
+func (f *formatter) take(offset int) []*ast.Comment {
+	n := 0
+	for n < len(f.comments) && (offset < 0 || f.comments[n].Start.Offset < offset) {
+		n++
+	}
+	ret := f.comments[:n]
+	f.comments = f.comments[n:]
+	return ret
+}
+
+// lineComment removes and returns the next comment if it is on the same
+// line as end and before limit, the offset of the next token, if any.
+func (f *formatter) lineComment(end token.Pos, limit int) *ast.Comment {
+	if len(f.comments) > 0 && f.comments[0].Start.Line == end.Line &&
+		(limit < 0 || f.comments[0].Start.Offset < limit) {
+		c := f.comments[0]
+		f.comments = f.comments[1:]
+		return c
+	}
+	return nil
+}
+
+// items collects the items of list, in canonical order for a block of
+// the given kind, "action", "workflow", or "template", or in source
+// order otherwise.  Limit is the offset of the token after the list, if any.
+func (f *formatter) items(list *ast.ObjectList, kind string, limit int) []*fItem {
+	ret := make([]*fItem, 0, len(list.Items))
+	for i, item := range list.Items {
+		fi := &fItem{lead: f.take(item.Pos().Offset)}
+		fi.gap = hasGap(fi.lead, item.Pos())
+		for i, key := range item.Keys {
+			fi.keys = append(fi.keys, formatKey(key.Token, i == 0 && len(item.Keys) == 1))
+		}
+
+		var blockKind string
+		if len(item.Keys) == 2 {
+			blockKind = item.Keys[0].Token.Text
+		}
+		var end token.Pos
+		fi.val, end = f.value(item.Val, blockKind)
+		next := limit
+		if i+1 < len(list.Items) {
+			next = list.Items[i+1].Pos().Offset
+		}
+		fi.line = f.lineComment(end, next)
+		ret = append(ret, fi)
+	}
+
+	if order := attributeOrder[kind]; order != nil {
+		sort.SliceStable(ret, func(i, j int) bool {
+			return rank(order, ret[i].keys[0]) < rank(order, ret[j].keys[0])
+		})
+	}
+	return ret
+}
+
+var attributeOrder = map[string][]string{
+	"action":   {"description", "extends", "uses", "needs", "if", "runs", "args", "env", "matrix", "secrets", "continue_on_error"},
+	"workflow": {"description", "on", "resolves", "env"},
+	"template": {"description", "uses", "if", "runs", "args", "env", "matrix", "secrets", "continue_on_error"},
+}
+
+// rank returns the position of key in order, with unknown keys last.
+func rank(order []string, key string) int {
+	for i, k := range order {
+		if k == key {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// value collects a value, returning it and the position of its last
+// character.  Kind is the kind of block the value is the body of, if
+// any.
+func (f *formatter) value(node ast.Node, kind string) (interface{}, token.Pos) {
+	switch n := node.(type) {
+	case *ast.LiteralType:
+		return formatLiteral(n.Token), tokenEnd(n.Token)
+	case *ast.ListType:
+		l := &fList{multiline: n.Lbrack.Line != n.Rbrack.Line}
+		for i, elem := range n.List {
+			fe := &fItem{lead: f.take(elem.Pos().Offset)}
+			fe.gap = hasGap(fe.lead, elem.Pos())
+			var end token.Pos
+			fe.val, end = f.value(elem, "")
+			next := n.Rbrack.Offset
+			if i+1 < len(n.List) {
+				next = n.List[i+1].Pos().Offset
+			}
+			fe.line = f.lineComment(end, next)
+			l.elems = append(l.elems, fe)
+		}
+		l.trailing = f.take(n.Rbrack.Offset)
+		return l, n.Rbrack
+	case *ast.ObjectType:
+		o := &fObject{items: f.items(n.List, kind, n.Rbrace.Offset)}
+		o.trailing = f.take(n.Rbrace.Offset)
+		return o, n.Rbrace
+	default:
+		return "", node.Pos()
+	}
+}
+
+// item prints an item at the given depth.
+func (f *formatter) item(item *fItem, depth int) {
+	f.comment(item.lead, depth)
+	if item.gap {
+		f.buf.WriteString("\n")
+	}
+	f.indent(depth)
+	f.buf.WriteString(strings.Join(item.keys, " "))
+	if _, ok := item.val.(*fObject); !ok || len(item.keys) == 1 {
+		f.buf.WriteString(" =")
+	}
+	f.buf.WriteString(" ")
+	f.val(item.val, depth)
+	f.line(item.line)
+}
+
+// val prints a value, starting on the current line.
+func (f *formatter) val(val interface{}, depth int) {
+	switch v := val.(type) {
+	case string:
+		f.buf.WriteString(v)
+	case *fList:
+		if !v.multiline && len(v.trailing) == 0 && !hasComments(v.elems) {
+			f.buf.WriteString("[")
+			for i, elem := range v.elems {
+				if i > 0 {
+					f.buf.WriteString(", ")
+				}
+				f.val(elem.val, depth)
+			}
+			f.buf.WriteString("]")
+			return
+		}
+		f.buf.WriteString("[\n")
+		for _, elem := range v.elems {
+			f.comment(elem.lead, depth+1)
+			if elem.gap {
+				f.buf.WriteString("\n")
+			}
+			f.indent(depth + 1)
+			f.val(elem.val, depth+1)
+			f.buf.WriteString(",")
+			f.line(elem.line)
+		}
+		f.comment(v.trailing, depth+1)
+		f.indent(depth)
+		f.buf.WriteString("]")
+	case *fObject:
+		if len(v.items) == 0 && len(v.trailing) == 0 {
+			f.buf.WriteString("{}")
+			return
+		}
+		f.buf.WriteString("{\n")
+		for _, item := range v.items {
+			f.item(item, depth+1)
+		}
+		f.comment(v.trailing, depth+1)
+		f.indent(depth)
+		f.buf.WriteString("}")
+	}
+}
+
+// comment prints comments on lines of their own, keeping a single blank
+// line wherever the source had one or more.
+func (f *formatter) comment(comments []*ast.Comment, depth int) {
+	for i, c := range comments {
+		f.indent(depth)
+		f.buf.WriteString(strings.TrimRight(c.Text, "\n"))
+		f.buf.WriteString("\n")
+		if i+1 < len(comments) && hasGap(comments[i:i+1], comments[i+1].Start) {
+			f.buf.WriteString("\n")
+		}
+	}
+}
+
+// hasGap returns whether there is a blank line between the last of
+// comments and pos.
+func hasGap(comments []*ast.Comment, pos token.Pos) bool {
+	if len(comments) == 0 {
+		return false
+	}
+	c := comments[len(comments)-1]
+	return pos.Line > c.Start.Line+strings.Count(strings.TrimRight(c.Text, "\n"), "\n")+1
+}
+
+// line ends the current line, with a comment if there is one.
+func (f *formatter) line(c *ast.Comment) {
+	if c != nil {
+		f.buf.WriteString(" ")
+		f.buf.WriteString(strings.TrimRight(c.Text, "\n"))
+	}
+	f.buf.WriteString("\n")
+}
+
+func (f *formatter) indent(depth int) {
+	f.buf.WriteString(strings.Repeat("  ", depth))
+}
+
+func hasComments(items []*fItem) bool {
+	for _, item := range items {
+		if len(item.lead) > 0 || item.line != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// formatKey returns an object key in canonical form.  Attribute names
+// are unquoted if they can be, but block identifiers are kept as they
+// are, since the parser takes them literally.
+func formatKey(t token.Token, attribute bool) string {
+	if !attribute || t.Type != token.STRING {
+		return t.Text
+	}
+	s, err := hclstrconv.Unquote(t.Text)
+	if err != nil {
+		return t.Text
+	}
+	return key(s)
+}
+
+// formatLiteral returns a literal in canonical form: strings are quoted
+// as Quote does, and anything else is kept as it is.
+func formatLiteral(t token.Token) string {
+	switch t.Type {
+	case token.STRING:
+		s, err := hclstrconv.Unquote(t.Text)
+		if err != nil {
+			return t.Text
+		}
+		return Quote(s)
+	case token.HEREDOC:
+		return strings.TrimSuffix(t.Text, "\n")
+	default:
+		return t.Text
+	}
+}
+
+// tokenEnd returns the position of the last character of t.
+func tokenEnd(t token.Token) token.Pos {
+	pos := t.Pos
+	text := strings.TrimSuffix(t.Text, "\n")
+	pos.Line += strings.Count(text, "\n")
+	pos.Offset += len(text)
+	return pos
+}
@@ -0,0 +1,116 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	src := `# header comment
+
+version = 0
+workflow   "ci" {
+resolves = [ "b",
+  "a" ] # trailing
+  on="push"
+}
+
+// lead for a
+action "a" {
+  secrets = ["X"]
+  uses = "./a"   # use it
+  "env" = {
+    # inside env
+    A = "1"
+    "B-C" = "2" // bc
+  }
+  args = <<EOF
+hello
+EOF
+  bogus = true
+  # end of block
+}
+action "b" { uses = "docker://alpine" args = ["a", # first
+"b"] }
+# trailing file comment
+`
+	out, err := Format([]byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, `# header comment
+
+version = 0
+
+workflow "ci" {
+  on = "push"
+  resolves = [
+    "b",
+    "a",
+  ] # trailing
+}
+
+// lead for a
+action "a" {
+  uses = "./a" # use it
+  args = <<EOF
+hello
+EOF
+  env = {
+    # inside env
+    A = "1"
+    "B-C" = "2" // bc
+  }
+  secrets = ["X"]
+  bogus = true
+  # end of block
+}
+
+action "b" {
+  uses = "docker://alpine"
+  args = [
+    "a", # first
+    "b",
+  ]
+}
+
+# trailing file comment
+`, string(out))
+
+	again, err := Format(out)
+	require.NoError(t, err)
+	assert.Equal(t, string(out), string(again))
+}
+
+func TestFormatQuoting(t *testing.T) {
+	out, err := Format([]byte(`action "a" { uses = "./a" args = "${HOME}\ttab" env = {} }`))
+	require.NoError(t, err)
+	assert.Equal(t, `action "a" {
+  uses = "./a"
+  args = "${HOME}\ttab"
+  env = {}
+}
+`, string(out))
+}
+
+func TestFormatSameConfiguration(t *testing.T) {
+	src := `action "b" { needs = "a" uses="docker://alpine" runs = ["sh", "-c"] args = "echo hi" secrets = ["TOKEN"] env = { Z = "z", A = "a\tb" } }
+workflow "w" { resolves = "b" on = "push" }
+action "a" { uses = "./a" }
+`
+	out, err := Format([]byte(src))
+	require.NoError(t, err)
+
+	before, err := parser.Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	after, err := parser.Parse(strings.NewReader(string(out)))
+	require.NoError(t, err)
+	assert.Equal(t, String(before), String(after))
+}
+
+func TestFormatSyntaxError(t *testing.T) {
+	_, err := Format([]byte(`action "a" {`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,392 @@
+// Package printer writes a model.Configuration back out as a .workflow
+// file, so that tools can parse a file, change it programmatically, and
+// save the result.  The output is canonical: the same configuration
+// always prints the same way, regardless of how the original file was
+// formatted.  Comments the parser attached to the configuration are
+// printed next to the blocks and attributes they belong to.
+package printer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// Write prints c to w in .workflow syntax.  A `version' statement comes
+// first, unless c.Version is 0, then templates, workflows, and actions,
+// each in the order they appear in c.  Parsing the output with
+// parser.Parse yields an equivalent configuration, so a workflow without
+// a trigger is printed without `on', not with an empty one.  Write fails
+// if an identifier cannot be represented in a .workflow file.
+func Write(w io.Writer, c *model.Configuration) error {
+	for _, workflow := range c.Workflows {
+		if err := checkIdentifier(workflow.Identifier); err != nil {
+			return err
+		}
+	}
+	for _, action := range c.Actions {
+		if err := checkIdentifier(action.Identifier); err != nil {
+			return err
+		}
+	}
+	for _, template := range c.Templates {
+		if err := checkIdentifier(template.Identifier); err != nil {
+			return err
+		}
+	}
+
+	p := &printer{w: bufio.NewWriter(w)}
+	p.comments("", c.Comments.Lead)
+	first := len(c.Comments.Lead) == 0
+	if c.Version != 0 {
+		p.printf("version = %d\n", c.Version)
+		first = false
+	}
+	if len(c.Env) > 0 {
+		if !first {
+			p.printf("\n")
+		}
+		first = false
+		p.env("", c.Env, c.AttributeComments)
+	}
+	for _, template := range c.Templates {
+		if !first {
+			p.printf("\n")
+		}
+		first = false
+		p.action("template", template)
+	}
+	for _, workflow := range c.Workflows {
+		if !first {
+			p.printf("\n")
+		}
+		first = false
+		p.workflow(workflow)
+	}
+	for _, action := range c.Actions {
+		if !first {
+			p.printf("\n")
+		}
+		first = false
+		p.action("action", action)
+	}
+	if len(c.Comments.Trailing) > 0 {
+		if !first {
+			p.printf("\n")
+		}
+		p.comments("", c.Comments.Trailing)
+	}
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// checkIdentifier returns an error if id cannot be written as a block
+// identifier.  The parser takes identifiers literally, without
+// interpreting escape sequences, so there is no way to write one that
+// contains a double quote, a backslash, or a control character.
+func checkIdentifier(id string) error {
+	if id == "" {
+		return fmt.Errorf("identifiers cannot be blank")
+	}
+	for _, c := range id {
+		if c == '"' || c == '\\' || c < ' ' || c == 0x7f {
+			return fmt.Errorf("identifier `%s' cannot be written to a .workflow file", id)
+		}
+	}
+	return nil
+}
+
+// String returns the .workflow syntax for c, or an empty string if c
+// cannot be written.
+func String(c *model.Configuration) string {
+	var sb strings.Builder
+	if err := Write(&sb, c); err != nil {
+		return ""
+	}
+	return sb.String()
+}
+
+type printer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (p *printer) printf(format string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, a...)
+}
+
+// comments prints each comment on a line of its own.
+func (p *printer) comments(indent string, comments []string) {
+	for _, c := range comments {
+		p.printf("%s%s\n", indent, strings.TrimRight(c, "\n"))
+	}
+}
+
+// attribute prints a single-line attribute with its comments.
+func (p *printer) attribute(indent, name, value string, c model.Comments) {
+	p.comments(indent, c.Lead)
+	p.printf("%s%s = %s%s\n", indent, name, value, lineComment(c))
+}
+
+// lineComment returns the line comment in c, with a leading space, or an
+// empty string if there is none.
+func lineComment(c model.Comments) string {
+	if c.Line == "" {
+		return ""
+	}
+	return " " + strings.TrimRight(c.Line, "\n")
+}
+
+func (p *printer) workflow(w *model.Workflow) {
+	p.comments("", w.Comments.Lead)
+	p.printf("workflow \"%s\" {\n", w.Identifier)
+	if w.Description != "" {
+		p.attribute("  ", "description", Quote(w.Description), w.AttributeComments["description"])
+	}
+	switch len(w.On) {
+	case 0:
+	case 1:
+		p.attribute("  ", "on", Quote(w.On[0].String()), w.AttributeComments["on"])
+	default:
+		p.attribute("  ", "on", list(model.EventStrings(w.On)), w.AttributeComments["on"])
+	}
+	if len(w.Resolves) > 0 {
+		p.attribute("  ", "resolves", list(w.Resolves), w.AttributeComments["resolves"])
+	}
+	if len(w.Env) > 0 {
+		p.env("  ", w.Env, w.AttributeComments)
+	}
+	p.comments("  ", w.Comments.Trailing)
+	p.printf("}%s\n", lineComment(w.Comments))
+}
+
+// action prints an action, or a template if kind is "template".
+func (p *printer) action(kind string, a *model.Action) {
+	p.comments("", a.Comments.Lead)
+	p.printf("%s \"%s\" {\n", kind, a.Identifier)
+	if a.Description != "" {
+		p.attribute("  ", "description", Quote(a.Description), a.AttributeComments["description"])
+	}
+	if a.Extends != "" {
+		p.attribute("  ", "extends", Quote(a.Extends), a.AttributeComments["extends"])
+	}
+	if a.Uses != nil {
+		p.attribute("  ", "uses", Quote(a.Uses.String()), a.AttributeComments["uses"])
+	}
+	if len(a.Needs) > 0 {
+		p.attribute("  ", "needs", list(a.Needs), a.AttributeComments["needs"])
+	}
+	if a.If != "" {
+		p.attribute("  ", "if", Quote(a.If), a.AttributeComments["if"])
+	}
+	if a.Runs != nil {
+		p.command("runs", a.Runs, a.AttributeComments["runs"])
+	}
+	if a.Args != nil {
+		p.command("args", a.Args, a.AttributeComments["args"])
+	}
+	if len(a.Env) > 0 {
+		p.env("  ", a.Env, a.AttributeComments)
+	}
+	if len(a.Matrix) > 0 {
+		p.matrix(a.Matrix, a.AttributeComments)
+	}
+	if len(a.Secrets) > 0 {
+		p.attribute("  ", "secrets", list(a.Secrets), a.AttributeComments["secrets"])
+	}
+	if a.ContinueOnError {
+		p.attribute("  ", "continue_on_error", "true", a.AttributeComments["continue_on_error"])
+	}
+	p.comments("  ", a.Comments.Trailing)
+	p.printf("}%s\n", lineComment(a.Comments))
+}
+
+// env prints an `env' block, with the comments for it and its variables
+// in attrs.
+func (p *printer) env(indent string, env map[string]string, attrs map[string]model.Comments) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	c := attrs["env"]
+	p.comments(indent, c.Lead)
+	p.printf("%senv = {\n", indent)
+	for _, k := range keys {
+		p.attribute(indent+"  ", key(k), Quote(env[k]), attrs["env."+k])
+	}
+	p.comments(indent+"  ", c.Trailing)
+	p.printf("%s}%s\n", indent, lineComment(c))
+}
+
+// matrix prints an action's `matrix' block, with the comments for it and
+// its dimensions in attrs.
+func (p *printer) matrix(matrix map[string][]string, attrs map[string]model.Comments) {
+	keys := make([]string, 0, len(matrix))
+	for k := range matrix {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	c := attrs["matrix"]
+	p.comments("  ", c.Lead)
+	p.printf("  matrix = {\n")
+	for _, k := range keys {
+		p.attribute("    ", key(k), list(matrix[k]), attrs["matrix."+k])
+	}
+	p.comments("    ", c.Trailing)
+	p.printf("  }%s\n", lineComment(c))
+}
+
+// command prints a `runs' or `args' attribute.  Nothing can follow the
+// closing marker of a heredoc on its line, so a line comment goes before
+// the attribute instead.
+func (p *printer) command(name string, c model.Command, comments model.Comments) {
+	if _, ok := c.(*model.HeredocCommand); ok && comments.Line != "" {
+		comments.Lead = append(append([]string(nil), comments.Lead...), comments.Line)
+		comments.Line = ""
+	}
+	p.attribute("  ", name, command(c), comments)
+}
+
+func command(c model.Command) string {
+	switch c := c.(type) {
+	case *model.StringCommand:
+		return Quote(c.Value)
+	case *model.HeredocCommand:
+		return heredoc(c)
+	case *model.ListCommand:
+		return list(c.Values)
+	default:
+		return list(c.Split())
+	}
+}
+
+// heredoc returns c in heredoc form, ending with its marker, or with
+// `EOF' if it has none.  The marker gets underscores added until no line
+// of the text could be mistaken for it.
+func heredoc(c *model.HeredocCommand) string {
+	marker := c.Marker
+	if marker == "" {
+		marker = "EOF"
+	}
+	for {
+		clash := false
+		for _, line := range strings.Split(c.Value, "\n") {
+			if strings.TrimSpace(line) == marker {
+				clash = true
+				break
+			}
+		}
+		if !clash {
+			break
+		}
+		marker += "_"
+	}
+	if c.Value == "" {
+		return "<<" + marker + "\n" + marker
+	}
+	return "<<" + marker + "\n" + c.Value + "\n" + marker
+}
+
+func list(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// key returns an object key, quoting it only if it is not a valid bare
+// identifier.
+func key(k string) string {
+	if k == "" {
+		return Quote(k)
+	}
+	for i, c := range k {
+		if c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return Quote(k)
+	}
+	return k
+}
+
+// Quote returns s as a double-quoted HCL string.  HCL copies `${...}'
+// interpolations verbatim rather than interpreting escapes inside them,
+// so those are left untouched.  An interpolation that contains a double
+// quote or a control character cannot be written so that it reads back
+// exactly, and is escaped like the rest of the string.
+func Quote(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('"')
+	for len(s) > 0 {
+		if n := interpolationLen(s); n > 0 {
+			sb.WriteString(s[:n])
+			s = s[n:]
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s)
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&sb, `\x%02x`, s[0])
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteString(s[:size])
+		}
+		s = s[size:]
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// interpolationLen returns the length of the balanced `${...}' at the
+// start of s, or 0 if s doesn't start with one that can be written
+// verbatim.
+func interpolationLen(s string) int {
+	if !strings.HasPrefix(s, "${") {
+		return 0
+	}
+	depth := 0
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '"':
+			return 0
+		default:
+			if s[i] < ' ' {
+				return 0
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,292 @@
+package printer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	config, err := parser.Parse(strings.NewReader(`version = 1
+action "b" {
+  needs = "a"
+  uses="docker://alpine"
+  runs = ["sh", "-c"]
+  args = "echo hi"
+  secrets = ["TOKEN"]
+  continue_on_error = true
+  if = "success()"
+  env = { Z = "z", A = "a\tb" }
+}
+workflow "w" { on = "push", resolves = "b", description = "W" }
+workflow "x" { on = ["push", "release"], resolves = "a" }
+action "a" { uses = "./a" }
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, `version = 1
+
+workflow "w" {
+  description = "W"
+  on = "push"
+  resolves = ["b"]
+}
+
+workflow "x" {
+  on = ["push", "release"]
+  resolves = ["a"]
+}
+
+action "b" {
+  uses = "docker://alpine"
+  needs = ["a"]
+  if = "success()"
+  runs = ["sh", "-c"]
+  args = "echo hi"
+  env = {
+    A = "a\tb"
+    Z = "z"
+  }
+  secrets = ["TOKEN"]
+  continue_on_error = true
+}
+
+action "a" {
+  uses = "./a"
+}
+`, String(config))
+}
+
+func TestRoundTrip(t *testing.T) {
+	b, err := ioutil.ReadFile("../samples/a.workflow")
+	require.NoError(t, err)
+	config, err := parser.Parse(bytes.NewReader(b))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, config))
+	reparsed, err := parser.Parse(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, withoutPositions(config), withoutPositions(reparsed))
+}
+
+// withoutPositions clears the source positions in c, which differ between
+// the original file and the printed one.
+func withoutPositions(c *model.Configuration) *model.Configuration {
+	for _, a := range c.Actions {
+		a.Pos, a.End, a.Positions = model.Pos{}, model.Pos{}, nil
+	}
+	for _, w := range c.Workflows {
+		w.Pos, w.End, w.Positions = model.Pos{}, model.Pos{}, nil
+	}
+	return c
+}
+
+func TestWriteComments(t *testing.T) {
+	src := `# header
+version = 0
+
+# lead w
+workflow "w" {
+  on = "push" # push
+  resolves = ["a"]
+}
+
+/* lead a */
+action "a" {
+  # brace
+  uses = "./a"
+  env = {
+    // A
+    A = "b"
+    # end of env
+  }
+  # end of a
+} # after a
+
+# trailer
+`
+	config, err := parser.Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(src, "version = 0\n", "", 1), String(config))
+}
+
+func TestWriteEnv(t *testing.T) {
+	src := `# defaults
+env = {
+  GOPATH = "/go" # go
+}
+
+workflow "w" {
+  on = "push"
+  resolves = ["a"]
+  env = {
+    CI = "true"
+  }
+}
+
+action "a" {
+  uses = "./a"
+}
+`
+	config, err := parser.Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, src, String(config))
+
+	config.AttributeComments = nil
+	assert.True(t, strings.HasPrefix(String(config), "env = {\n  GOPATH = \"/go\"\n}\n\nworkflow"))
+}
+
+func TestWriteMatrix(t *testing.T) {
+	src := `version = 1
+
+action "test" {
+  uses = "docker://golang"
+  matrix = {
+    # versions
+    go = ["1.11", "1.12"]
+    os = ["linux"]
+  }
+}
+`
+	config, err := parser.Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, src, String(config))
+
+	out, err := Format([]byte(`action "test" {
+matrix = { os = ["linux"], go = ["1.11","1.12"] }
+  uses = "docker://golang"
+}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, `action "test" {
+  uses = "docker://golang"
+  matrix = {
+    os = ["linux"]
+    go = ["1.11", "1.12"]
+  }
+}
+`, string(out))
+}
+
+func TestWriteTemplates(t *testing.T) {
+	src := `template "go" {
+  uses = "docker://golang"
+  env = {
+    GOPATH = "/go"
+  }
+}
+
+action "test" {
+  extends = "go"
+  args = "go test"
+}
+`
+	config, err := parser.Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, src, String(config))
+}
+
+func TestWriteHeredoc(t *testing.T) {
+	src := `action "a" {
+  uses = "./a"
+  # lead
+  runs = <<-END
+    sh -c
+    END
+  args = <<EOF
+echo \
+  hi
+EOF
+}
+`
+	config, err := parser.Parse(strings.NewReader(src), parser.WithoutChecks(parser.CheckUnused))
+	require.NoError(t, err)
+	config.Actions[0].AttributeComments["args"] = model.Comments{Line: "# line"}
+	out := String(config)
+	assert.Equal(t, `action "a" {
+  uses = "./a"
+  # lead
+  runs = <<END
+sh -c
+END
+  # line
+  args = <<EOF
+echo \
+  hi
+EOF
+}
+`, out)
+
+	reparsed, err := parser.Parse(strings.NewReader(out), parser.WithoutChecks(parser.CheckUnused))
+	require.NoError(t, err)
+	assert.Equal(t, config.Actions[0].Runs, reparsed.Actions[0].Runs)
+	assert.Equal(t, config.Actions[0].Args, reparsed.Actions[0].Args)
+
+	clash := &model.HeredocCommand{Value: "a\n EOF\nEOF_", Marker: "EOF"}
+	assert.Equal(t, "<<EOF__\na\n EOF\nEOF_\nEOF__", command(clash))
+}
+
+func TestQuote(t *testing.T) {
+	values := []string{
+		"",
+		"plain",
+		`quote " and backslash \`,
+		"line\nbreak\r\ttab\x01",
+		"${{ secrets.TOKEN }}",
+		"unicode é ☃",
+	}
+	for _, v := range values {
+		config := &model.Configuration{Actions: []*model.Action{{
+			Identifier: "id",
+			Uses:       &model.UsesPath{Path: "x"},
+			Env:        map[string]string{"V": v, v + "_K": v},
+		}}}
+		reparsed, err := parser.Parse(strings.NewReader(String(config)), parser.WithSuppressWarnings())
+		require.NoError(t, err, v)
+		assert.Equal(t, config.Actions[0].Identifier, reparsed.Actions[0].Identifier, v)
+		assert.Equal(t, config.Actions[0].Env, reparsed.Actions[0].Env, v)
+	}
+}
+
+func TestWriteNoTrigger(t *testing.T) {
+	config := &model.Configuration{Workflows: []*model.Workflow{{Identifier: "w", Resolves: []string{"a"}}}}
+	assert.Equal(t, "workflow \"w\" {\n  resolves = [\"a\"]\n}\n", String(config))
+
+	_, err := parser.Parse(strings.NewReader(String(config)))
+	require.IsType(t, &parser.Error{}, err)
+	assert.Empty(t, err.(*parser.Error).Workflows[0].On)
+}
+
+func TestWriteEventAsWritten(t *testing.T) {
+	src := `workflow "w" {
+  on = "Schedule(0 * * * *)"
+}
+`
+	config, _, err := parser.ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, config.Workflows, 1)
+	assert.Equal(t, model.ScheduleEventType, config.Workflows[0].On[0].Type)
+	assert.Equal(t, src, String(config))
+}
+
+func TestInvalidIdentifier(t *testing.T) {
+	for _, id := range []string{"", `a"b`, `a\b`, "a\nb"} {
+		config := &model.Configuration{Workflows: []*model.Workflow{{Identifier: id, On: model.ParseEvents("push")}}}
+		assert.Error(t, Write(ioutil.Discard, config), id)
+		assert.Equal(t, "", String(config))
+	}
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "FOO_1", key("FOO_1"))
+	assert.Equal(t, `"1FOO"`, key("1FOO"))
+	assert.Equal(t, `"a-b"`, key("a-b"))
+	assert.Equal(t, `""`, key(""))
+}
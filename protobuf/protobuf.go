@@ -0,0 +1,276 @@
+// Package protobuf reads and writes parse results in the Protocol
+// Buffers encoding described by workflow.proto, so that services written
+// in other languages can consume them through generated code instead of
+// following the shape of the JSON encoding by hand.  The messages in
+// workflow.pb.go are generated from that file; ToProto and FromProto
+// convert between them and the model and parser packages.
+package protobuf
+
+//go:generate protoc --go_out=paths=source_relative:. workflow.proto
+
+import (
+	"fmt"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/golang/protobuf/proto"
+)
+
+// ToProto encodes c and errs as a ParseResult message.  Either may be
+// nil.  Like the JSON encoding, it leaves out positions, comments, and
+// fixes, and writes heredocs as strings.  Map entries are sorted by key,
+// so the same input always encodes the same way.  It returns an error
+// only if a string isn't valid UTF-8, which proto3 requires of strings.
+func ToProto(c *model.Configuration, errs parser.ErrorList) ([]byte, error) {
+	result := &ParseResult{}
+	if c != nil {
+		result.Configuration = configurationToProto(c)
+	}
+	for _, pe := range errs {
+		result.Errors = append(result.Errors, parseErrorToProto(pe))
+	}
+	var buf proto.Buffer
+	buf.SetDeterministic(true)
+	if err := buf.Marshal(result); err != nil {
+		return nil, fmt.Errorf("protobuf: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// FromProto decodes a ParseResult message.  The configuration is nil
+// only if the message has none.
+func FromProto(b []byte) (*model.Configuration, parser.ErrorList, error) {
+	var result ParseResult
+	if err := proto.Unmarshal(b, &result); err != nil {
+		return nil, nil, fmt.Errorf("protobuf: %v", err)
+	}
+
+	var c *model.Configuration
+	if result.Configuration != nil {
+		var err error
+		if c, err = configurationFromProto(result.Configuration); err != nil {
+			return nil, nil, err
+		}
+	}
+	var errs parser.ErrorList
+	for _, pe := range result.Errors {
+		errs = append(errs, parseErrorFromProto(pe))
+	}
+	return c, errs, nil
+}
+
+func configurationToProto(c *model.Configuration) *Configuration {
+	ret := &Configuration{Version: int32(c.Version), Env: c.Env}
+	for _, a := range c.Actions {
+		ret.Actions = append(ret.Actions, actionToProto(a))
+	}
+	for _, w := range c.Workflows {
+		ret.Workflows = append(ret.Workflows, workflowToProto(w))
+	}
+	for _, t := range c.Templates {
+		ret.Templates = append(ret.Templates, actionToProto(t))
+	}
+	return ret
+}
+
+func configurationFromProto(c *Configuration) (*model.Configuration, error) {
+	ret := &model.Configuration{Version: int(c.Version), Env: c.Env}
+	for _, a := range c.Actions {
+		action, err := actionFromProto(a)
+		if err != nil {
+			return nil, err
+		}
+		ret.Actions = append(ret.Actions, action)
+	}
+	for _, w := range c.Workflows {
+		ret.Workflows = append(ret.Workflows, workflowFromProto(w))
+	}
+	for _, t := range c.Templates {
+		template, err := actionFromProto(t)
+		if err != nil {
+			return nil, err
+		}
+		ret.Templates = append(ret.Templates, template)
+	}
+	return ret, nil
+}
+
+func actionToProto(a *model.Action) *Action {
+	ret := &Action{
+		Identifier:      a.Identifier,
+		Description:     a.Description,
+		Extends:         a.Extends,
+		Runs:            commandToProto(a.Runs),
+		Args:            commandToProto(a.Args),
+		Needs:           a.Needs,
+		Env:             a.Env,
+		Secrets:         a.Secrets,
+		If:              a.If,
+		ContinueOnError: a.ContinueOnError,
+	}
+	if a.Uses != nil {
+		ret.Uses = usesToProto(a.Uses)
+	}
+	if a.Matrix != nil {
+		ret.Matrix = make(map[string]*StringList, len(a.Matrix))
+		for k, values := range a.Matrix {
+			ret.Matrix[k] = &StringList{Values: values}
+		}
+	}
+	return ret
+}
+
+func actionFromProto(a *Action) (*model.Action, error) {
+	ret := &model.Action{
+		Identifier:      a.Identifier,
+		Description:     a.Description,
+		Extends:         a.Extends,
+		Runs:            commandFromProto(a.Runs),
+		Args:            commandFromProto(a.Args),
+		Needs:           a.Needs,
+		Env:             a.Env,
+		Secrets:         a.Secrets,
+		If:              a.If,
+		ContinueOnError: a.ContinueOnError,
+	}
+	if a.Uses != nil {
+		var err error
+		if ret.Uses, err = usesFromProto(a.Uses); err != nil {
+			return nil, err
+		}
+	}
+	if a.Matrix != nil {
+		ret.Matrix = make(map[string][]string, len(a.Matrix))
+		for k, list := range a.Matrix {
+			ret.Matrix[k] = append([]string{}, list.GetValues()...)
+		}
+	}
+	return ret, nil
+}
+
+func usesToProto(u model.Uses) *Uses {
+	ret := &Uses{Kind: model.UsesKindInvalid, Raw: u.String()}
+	switch u := u.(type) {
+	case *model.UsesPath:
+		ret.Kind, ret.Path = model.UsesKindPath, u.Path
+	case *model.UsesDockerImage:
+		ret.Kind, ret.Image = model.UsesKindDocker, u.Image
+	case *model.UsesRepository:
+		ret.Kind, ret.Repository, ret.Path, ret.Ref = model.UsesKindRepository, u.Repository, u.Path, u.Ref
+	}
+	return ret
+}
+
+func usesFromProto(u *Uses) (model.Uses, error) {
+	switch u.Kind {
+	case model.UsesKindPath:
+		return &model.UsesPath{Path: u.Path}, nil
+	case model.UsesKindDocker:
+		uses, _ := model.ParseDockerImage(u.Image)
+		return uses, nil
+	case model.UsesKindRepository:
+		return &model.UsesRepository{Repository: u.Repository, Path: u.Path, Ref: u.Ref}, nil
+	case model.UsesKindInvalid:
+		return &model.UsesInvalid{Raw: u.Raw}, nil
+	case "":
+		return model.ParseUses(u.Raw), nil
+	default:
+		return nil, fmt.Errorf("protobuf: unknown kind of uses `%s'", u.Kind)
+	}
+}
+
+func commandToProto(c model.Command) *Command {
+	switch c := c.(type) {
+	case nil:
+		return nil
+	case *model.StringCommand:
+		return &Command{Value: &Command_Text{Text: c.Value}}
+	case *model.HeredocCommand:
+		return &Command{Value: &Command_Text{Text: c.Value}}
+	case *model.ListCommand:
+		return &Command{Value: &Command_List{List: &StringList{Values: c.Values}}}
+	default:
+		return &Command{Value: &Command_List{List: &StringList{Values: c.Split()}}}
+	}
+}
+
+func commandFromProto(c *Command) model.Command {
+	switch v := c.GetValue().(type) {
+	case *Command_Text:
+		return &model.StringCommand{Value: v.Text}
+	case *Command_List:
+		return &model.ListCommand{Values: append([]string{}, v.List.GetValues()...)}
+	default:
+		return nil
+	}
+}
+
+func workflowToProto(w *model.Workflow) *Workflow {
+	return &Workflow{
+		Identifier:  w.Identifier,
+		Description: w.Description,
+		On:          model.EventStrings(w.On),
+		Resolves:    w.Resolves,
+		Env:         w.Env,
+	}
+}
+
+func workflowFromProto(w *Workflow) *model.Workflow {
+	ret := &model.Workflow{
+		Identifier:  w.Identifier,
+		Description: w.Description,
+		Resolves:    w.Resolves,
+		Env:         w.Env,
+	}
+	for _, s := range w.On {
+		ret.On = append(ret.On, model.ParseEvent(s))
+	}
+	return ret
+}
+
+// parseErrorToProto converts pe.  The values of Severity are those of
+// parser.Severity, so the severity converts as a number.
+func parseErrorToProto(pe *parser.ParseError) *ParseError {
+	ret := &ParseError{
+		Message:  pe.Message(),
+		Code:     string(pe.Code),
+		Severity: Severity(pe.Severity),
+		Pos:      positionToProto(pe.Pos),
+		Cycle:    pe.Cycle,
+		Count:    int32(pe.Count),
+	}
+	for _, pos := range pe.Positions {
+		ret.Positions = append(ret.Positions, positionToProto(pos))
+	}
+	return ret
+}
+
+func parseErrorFromProto(pe *ParseError) *parser.ParseError {
+	ret := parser.NewError(nil, parser.Code(pe.Code), "%s", pe.Message)
+	ret.Severity = parser.Severity(pe.Severity)
+	ret.Pos = positionFromProto(pe.Pos)
+	ret.Cycle = pe.Cycle
+	ret.Count = int(pe.Count)
+	for _, pos := range pe.Positions {
+		ret.Positions = append(ret.Positions, positionFromProto(pos))
+	}
+	return ret
+}
+
+func positionToProto(pos parser.ErrorPos) *Position {
+	return &Position{
+		File:   pos.File,
+		Line:   int32(pos.Line),
+		Column: int32(pos.Column),
+		Offset: int32(pos.Offset),
+	}
+}
+
+func positionFromProto(pos *Position) parser.ErrorPos {
+	return parser.ErrorPos{
+		File:   pos.GetFile(),
+		Line:   int(pos.GetLine()),
+		Column: int(pos.GetColumn()),
+		Offset: int(pos.GetOffset()),
+	}
+}
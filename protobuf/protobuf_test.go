@@ -0,0 +1,147 @@
+package protobuf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	config, errs, err := parser.ParseWithDiagnostics(strings.NewReader(`version = 1
+
+env = { GOPATH = "/go" }
+
+workflow "ci" {
+  on = ["push", "pull_request.opened"]
+  resolves = ["test"]
+  env = { CI = "true" }
+}
+
+template "go" {
+  uses = "docker://golang:1.12"
+  runs = <<EOF
+sh -c
+EOF
+}
+
+action "build" {
+  extends = "go"
+  args = ["make", ""]
+  matrix = { goos = ["linux", "darwin"] }
+}
+
+action "test" {
+  uses = "actions/bin/sh@master"
+  needs = "build"
+  if = "github.ref == 'refs/heads/master'"
+  secrets = ["GITHUB_TOKEN"]
+  continue_on_error = true
+  bananas = 1
+}
+
+action "unused" {
+  description = "Not resolved"
+  uses = "./unused"
+}
+`))
+	require.NoError(t, err)
+	require.NotEmpty(t, errs)
+
+	b, err := ToProto(config, errs)
+	require.NoError(t, err)
+	back, backErrs, err := FromProto(b)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, back.Version)
+	assert.Equal(t, config.Env, back.Env)
+	require.Len(t, back.Actions, 3)
+	require.Len(t, back.Templates, 1)
+	require.Len(t, back.Workflows, 1)
+
+	assert.Equal(t, &model.StringCommand{Value: "sh -c"}, back.Templates[0].Runs)
+	assert.Equal(t, config.Templates[0].Uses, back.Templates[0].Uses)
+	for i, a := range config.Actions {
+		b := back.Actions[i]
+		assert.Equal(t, a.Identifier, b.Identifier)
+		assert.Equal(t, a.Description, b.Description)
+		assert.Equal(t, a.Extends, b.Extends)
+		assert.Equal(t, a.Uses, b.Uses)
+		assert.Equal(t, a.Args, b.Args)
+		assert.Equal(t, a.Needs, b.Needs)
+		assert.Equal(t, a.Matrix, b.Matrix)
+		assert.Equal(t, a.Secrets, b.Secrets)
+		assert.Equal(t, a.If, b.If)
+		assert.Equal(t, a.ContinueOnError, b.ContinueOnError)
+	}
+	w, bw := config.Workflows[0], back.Workflows[0]
+	assert.Equal(t, w.On, bw.On)
+	assert.Equal(t, w.Resolves, bw.Resolves)
+	assert.Equal(t, w.Env, bw.Env)
+
+	require.Len(t, backErrs, len(errs))
+	for i, e := range errs {
+		assert.Equal(t, e.Error(), backErrs[i].Error())
+		assert.Equal(t, e.Severity, backErrs[i].Severity)
+		assert.Equal(t, e.Pos, backErrs[i].Pos)
+	}
+}
+
+func TestWireFormat(t *testing.T) {
+	// ParseResult{configuration: {version: 1, env: {"A": "b"}}}, as
+	// protoc would encode it.
+	want := []byte{
+		0x0a, 0x0a, // configuration, 10 bytes
+		0x08, 0x01, // version = 1
+		0x2a, 0x06, // env entry, 6 bytes
+		0x0a, 0x01, 'A', // key
+		0x12, 0x01, 'b', // value
+	}
+	c := &model.Configuration{Version: 1, Env: map[string]string{"A": "b"}}
+	b, err := ToProto(c, nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, b)
+
+	// Unknown fields of each wire type are skipped.
+	withUnknown := append([]byte{
+		0x78, 0x05, // field 15, varint
+		0x81, 0x01, 1, 2, 3, 4, 5, 6, 7, 8, // field 16, fixed64
+		0x8d, 0x01, 1, 2, 3, 4, // field 17, fixed32
+	}, want...)
+	back, errs, err := FromProto(withUnknown)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, c.Version, back.Version)
+	assert.Equal(t, c.Env, back.Env)
+
+	_, _, err = FromProto(want[:5])
+	assert.EqualError(t, err, "protobuf: unexpected EOF")
+
+	_, err = ToProto(&model.Configuration{Env: map[string]string{"A": "\xff"}}, nil)
+	assert.EqualError(t, err, `protobuf: proto: field "workflowparser.Configuration.Env" contains invalid UTF-8`)
+}
+
+func TestSeverity(t *testing.T) {
+	for _, sev := range []parser.Severity{parser.INFO, parser.WARNING, parser.ERROR, parser.FATAL} {
+		assert.Equal(t, "SEVERITY_"+strings.ToUpper(sev.String()), Severity(sev).String())
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	b, err := ToProto(nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, b)
+	c, errs, err := FromProto(b)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+	assert.Nil(t, errs)
+
+	b, err = ToProto(&model.Configuration{}, nil)
+	require.NoError(t, err)
+	c, _, err = FromProto(b)
+	require.NoError(t, err)
+	assert.Equal(t, &model.Configuration{}, c)
+}
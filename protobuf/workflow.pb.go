@@ -0,0 +1,814 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: workflow.proto
+
+package protobuf
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// The values are those of parser.Severity.
+type Severity int32
+
+const (
+	Severity_SEVERITY_UNSPECIFIED Severity = 0
+	Severity_SEVERITY_INFO        Severity = -1
+	Severity_SEVERITY_WARNING     Severity = 1
+	Severity_SEVERITY_ERROR       Severity = 2
+	Severity_SEVERITY_FATAL       Severity = 3
+)
+
+var Severity_name = map[int32]string{
+	0:  "SEVERITY_UNSPECIFIED",
+	-1: "SEVERITY_INFO",
+	1:  "SEVERITY_WARNING",
+	2:  "SEVERITY_ERROR",
+	3:  "SEVERITY_FATAL",
+}
+
+var Severity_value = map[string]int32{
+	"SEVERITY_UNSPECIFIED": 0,
+	"SEVERITY_INFO":        -1,
+	"SEVERITY_WARNING":     1,
+	"SEVERITY_ERROR":       2,
+	"SEVERITY_FATAL":       3,
+}
+
+func (x Severity) String() string {
+	return proto.EnumName(Severity_name, int32(x))
+}
+
+func (Severity) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{0}
+}
+
+// ParseResult is a configuration along with the diagnostics found while
+// parsing it.
+type ParseResult struct {
+	Configuration        *Configuration `protobuf:"bytes,1,opt,name=configuration,proto3" json:"configuration,omitempty"`
+	Errors               []*ParseError  `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ParseResult) Reset()         { *m = ParseResult{} }
+func (m *ParseResult) String() string { return proto.CompactTextString(m) }
+func (*ParseResult) ProtoMessage()    {}
+func (*ParseResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{0}
+}
+
+func (m *ParseResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ParseResult.Unmarshal(m, b)
+}
+func (m *ParseResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ParseResult.Marshal(b, m, deterministic)
+}
+func (m *ParseResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ParseResult.Merge(m, src)
+}
+func (m *ParseResult) XXX_Size() int {
+	return xxx_messageInfo_ParseResult.Size(m)
+}
+func (m *ParseResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_ParseResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ParseResult proto.InternalMessageInfo
+
+func (m *ParseResult) GetConfiguration() *Configuration {
+	if m != nil {
+		return m.Configuration
+	}
+	return nil
+}
+
+func (m *ParseResult) GetErrors() []*ParseError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+// Configuration is a parsed .workflow file, or several of them.
+type Configuration struct {
+	Version              int32             `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Actions              []*Action         `protobuf:"bytes,2,rep,name=actions,proto3" json:"actions,omitempty"`
+	Workflows            []*Workflow       `protobuf:"bytes,3,rep,name=workflows,proto3" json:"workflows,omitempty"`
+	Templates            []*Action         `protobuf:"bytes,4,rep,name=templates,proto3" json:"templates,omitempty"`
+	Env                  map[string]string `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Configuration) Reset()         { *m = Configuration{} }
+func (m *Configuration) String() string { return proto.CompactTextString(m) }
+func (*Configuration) ProtoMessage()    {}
+func (*Configuration) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{1}
+}
+
+func (m *Configuration) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Configuration.Unmarshal(m, b)
+}
+func (m *Configuration) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Configuration.Marshal(b, m, deterministic)
+}
+func (m *Configuration) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Configuration.Merge(m, src)
+}
+func (m *Configuration) XXX_Size() int {
+	return xxx_messageInfo_Configuration.Size(m)
+}
+func (m *Configuration) XXX_DiscardUnknown() {
+	xxx_messageInfo_Configuration.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Configuration proto.InternalMessageInfo
+
+func (m *Configuration) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Configuration) GetActions() []*Action {
+	if m != nil {
+		return m.Actions
+	}
+	return nil
+}
+
+func (m *Configuration) GetWorkflows() []*Workflow {
+	if m != nil {
+		return m.Workflows
+	}
+	return nil
+}
+
+func (m *Configuration) GetTemplates() []*Action {
+	if m != nil {
+		return m.Templates
+	}
+	return nil
+}
+
+func (m *Configuration) GetEnv() map[string]string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
+// Action is an `action' or `template' block.
+type Action struct {
+	Identifier           string                 `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	Description          string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Extends              string                 `protobuf:"bytes,3,opt,name=extends,proto3" json:"extends,omitempty"`
+	Uses                 *Uses                  `protobuf:"bytes,4,opt,name=uses,proto3" json:"uses,omitempty"`
+	Runs                 *Command               `protobuf:"bytes,5,opt,name=runs,proto3" json:"runs,omitempty"`
+	Args                 *Command               `protobuf:"bytes,6,opt,name=args,proto3" json:"args,omitempty"`
+	Needs                []string               `protobuf:"bytes,7,rep,name=needs,proto3" json:"needs,omitempty"`
+	Env                  map[string]string      `protobuf:"bytes,8,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Matrix               map[string]*StringList `protobuf:"bytes,9,rep,name=matrix,proto3" json:"matrix,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Secrets              []string               `protobuf:"bytes,10,rep,name=secrets,proto3" json:"secrets,omitempty"`
+	If                   string                 `protobuf:"bytes,11,opt,name=if,proto3" json:"if,omitempty"`
+	ContinueOnError      bool                   `protobuf:"varint,12,opt,name=continue_on_error,json=continueOnError,proto3" json:"continue_on_error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *Action) Reset()         { *m = Action{} }
+func (m *Action) String() string { return proto.CompactTextString(m) }
+func (*Action) ProtoMessage()    {}
+func (*Action) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{2}
+}
+
+func (m *Action) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Action.Unmarshal(m, b)
+}
+func (m *Action) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Action.Marshal(b, m, deterministic)
+}
+func (m *Action) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Action.Merge(m, src)
+}
+func (m *Action) XXX_Size() int {
+	return xxx_messageInfo_Action.Size(m)
+}
+func (m *Action) XXX_DiscardUnknown() {
+	xxx_messageInfo_Action.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Action proto.InternalMessageInfo
+
+func (m *Action) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *Action) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Action) GetExtends() string {
+	if m != nil {
+		return m.Extends
+	}
+	return ""
+}
+
+func (m *Action) GetUses() *Uses {
+	if m != nil {
+		return m.Uses
+	}
+	return nil
+}
+
+func (m *Action) GetRuns() *Command {
+	if m != nil {
+		return m.Runs
+	}
+	return nil
+}
+
+func (m *Action) GetArgs() *Command {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *Action) GetNeeds() []string {
+	if m != nil {
+		return m.Needs
+	}
+	return nil
+}
+
+func (m *Action) GetEnv() map[string]string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
+func (m *Action) GetMatrix() map[string]*StringList {
+	if m != nil {
+		return m.Matrix
+	}
+	return nil
+}
+
+func (m *Action) GetSecrets() []string {
+	if m != nil {
+		return m.Secrets
+	}
+	return nil
+}
+
+func (m *Action) GetIf() string {
+	if m != nil {
+		return m.If
+	}
+	return ""
+}
+
+func (m *Action) GetContinueOnError() bool {
+	if m != nil {
+		return m.ContinueOnError
+	}
+	return false
+}
+
+// Uses is the `uses' attribute of an action.  Kind is one of "path",
+// "docker", "repository", or "invalid", and the other fields besides raw
+// depend on it.
+type Uses struct {
+	Kind                 string   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Raw                  string   `protobuf:"bytes,2,opt,name=raw,proto3" json:"raw,omitempty"`
+	Image                string   `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	Repository           string   `protobuf:"bytes,4,opt,name=repository,proto3" json:"repository,omitempty"`
+	Path                 string   `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	Ref                  string   `protobuf:"bytes,6,opt,name=ref,proto3" json:"ref,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Uses) Reset()         { *m = Uses{} }
+func (m *Uses) String() string { return proto.CompactTextString(m) }
+func (*Uses) ProtoMessage()    {}
+func (*Uses) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{3}
+}
+
+func (m *Uses) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Uses.Unmarshal(m, b)
+}
+func (m *Uses) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Uses.Marshal(b, m, deterministic)
+}
+func (m *Uses) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Uses.Merge(m, src)
+}
+func (m *Uses) XXX_Size() int {
+	return xxx_messageInfo_Uses.Size(m)
+}
+func (m *Uses) XXX_DiscardUnknown() {
+	xxx_messageInfo_Uses.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Uses proto.InternalMessageInfo
+
+func (m *Uses) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Uses) GetRaw() string {
+	if m != nil {
+		return m.Raw
+	}
+	return ""
+}
+
+func (m *Uses) GetImage() string {
+	if m != nil {
+		return m.Image
+	}
+	return ""
+}
+
+func (m *Uses) GetRepository() string {
+	if m != nil {
+		return m.Repository
+	}
+	return ""
+}
+
+func (m *Uses) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Uses) GetRef() string {
+	if m != nil {
+		return m.Ref
+	}
+	return ""
+}
+
+// Command is the `runs' or `args' attribute of an action, a string or a
+// list of strings as written.  Heredocs are strings.
+type Command struct {
+	// Types that are valid to be assigned to Value:
+	//	*Command_Text
+	//	*Command_List
+	Value                isCommand_Value `protobuf_oneof:"value"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Command) Reset()         { *m = Command{} }
+func (m *Command) String() string { return proto.CompactTextString(m) }
+func (*Command) ProtoMessage()    {}
+func (*Command) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{4}
+}
+
+func (m *Command) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Command.Unmarshal(m, b)
+}
+func (m *Command) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Command.Marshal(b, m, deterministic)
+}
+func (m *Command) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Command.Merge(m, src)
+}
+func (m *Command) XXX_Size() int {
+	return xxx_messageInfo_Command.Size(m)
+}
+func (m *Command) XXX_DiscardUnknown() {
+	xxx_messageInfo_Command.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Command proto.InternalMessageInfo
+
+type isCommand_Value interface {
+	isCommand_Value()
+}
+
+type Command_Text struct {
+	Text string `protobuf:"bytes,1,opt,name=text,proto3,oneof"`
+}
+
+type Command_List struct {
+	List *StringList `protobuf:"bytes,2,opt,name=list,proto3,oneof"`
+}
+
+func (*Command_Text) isCommand_Value() {}
+
+func (*Command_List) isCommand_Value() {}
+
+func (m *Command) GetValue() isCommand_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *Command) GetText() string {
+	if x, ok := m.GetValue().(*Command_Text); ok {
+		return x.Text
+	}
+	return ""
+}
+
+func (m *Command) GetList() *StringList {
+	if x, ok := m.GetValue().(*Command_List); ok {
+		return x.List
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Command) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Command_Text)(nil),
+		(*Command_List)(nil),
+	}
+}
+
+type StringList struct {
+	Values               []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StringList) Reset()         { *m = StringList{} }
+func (m *StringList) String() string { return proto.CompactTextString(m) }
+func (*StringList) ProtoMessage()    {}
+func (*StringList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{5}
+}
+
+func (m *StringList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StringList.Unmarshal(m, b)
+}
+func (m *StringList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StringList.Marshal(b, m, deterministic)
+}
+func (m *StringList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StringList.Merge(m, src)
+}
+func (m *StringList) XXX_Size() int {
+	return xxx_messageInfo_StringList.Size(m)
+}
+func (m *StringList) XXX_DiscardUnknown() {
+	xxx_messageInfo_StringList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StringList proto.InternalMessageInfo
+
+func (m *StringList) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// Workflow is a `workflow' block.  Its events are written as in the
+// .workflow file, with any filter after a dot, whether the file lists
+// one or several.
+type Workflow struct {
+	Identifier           string            `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	Description          string            `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	On                   []string          `protobuf:"bytes,3,rep,name=on,proto3" json:"on,omitempty"`
+	Resolves             []string          `protobuf:"bytes,5,rep,name=resolves,proto3" json:"resolves,omitempty"`
+	Env                  map[string]string `protobuf:"bytes,6,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Workflow) Reset()         { *m = Workflow{} }
+func (m *Workflow) String() string { return proto.CompactTextString(m) }
+func (*Workflow) ProtoMessage()    {}
+func (*Workflow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{6}
+}
+
+func (m *Workflow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Workflow.Unmarshal(m, b)
+}
+func (m *Workflow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Workflow.Marshal(b, m, deterministic)
+}
+func (m *Workflow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Workflow.Merge(m, src)
+}
+func (m *Workflow) XXX_Size() int {
+	return xxx_messageInfo_Workflow.Size(m)
+}
+func (m *Workflow) XXX_DiscardUnknown() {
+	xxx_messageInfo_Workflow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Workflow proto.InternalMessageInfo
+
+func (m *Workflow) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *Workflow) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Workflow) GetOn() []string {
+	if m != nil {
+		return m.On
+	}
+	return nil
+}
+
+func (m *Workflow) GetResolves() []string {
+	if m != nil {
+		return m.Resolves
+	}
+	return nil
+}
+
+func (m *Workflow) GetEnv() map[string]string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
+// ParseError is a diagnostic.  Count and positions are set only for a
+// diagnostic that stands for several with the same code and message.
+type ParseError struct {
+	Message              string      `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Code                 string      `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Severity             Severity    `protobuf:"varint,3,opt,name=severity,proto3,enum=workflowparser.Severity" json:"severity,omitempty"`
+	Pos                  *Position   `protobuf:"bytes,4,opt,name=pos,proto3" json:"pos,omitempty"`
+	Cycle                []string    `protobuf:"bytes,5,rep,name=cycle,proto3" json:"cycle,omitempty"`
+	Count                int32       `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+	Positions            []*Position `protobuf:"bytes,7,rep,name=positions,proto3" json:"positions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ParseError) Reset()         { *m = ParseError{} }
+func (m *ParseError) String() string { return proto.CompactTextString(m) }
+func (*ParseError) ProtoMessage()    {}
+func (*ParseError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{7}
+}
+
+func (m *ParseError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ParseError.Unmarshal(m, b)
+}
+func (m *ParseError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ParseError.Marshal(b, m, deterministic)
+}
+func (m *ParseError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ParseError.Merge(m, src)
+}
+func (m *ParseError) XXX_Size() int {
+	return xxx_messageInfo_ParseError.Size(m)
+}
+func (m *ParseError) XXX_DiscardUnknown() {
+	xxx_messageInfo_ParseError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ParseError proto.InternalMessageInfo
+
+func (m *ParseError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *ParseError) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *ParseError) GetSeverity() Severity {
+	if m != nil {
+		return m.Severity
+	}
+	return Severity_SEVERITY_UNSPECIFIED
+}
+
+func (m *ParseError) GetPos() *Position {
+	if m != nil {
+		return m.Pos
+	}
+	return nil
+}
+
+func (m *ParseError) GetCycle() []string {
+	if m != nil {
+		return m.Cycle
+	}
+	return nil
+}
+
+func (m *ParseError) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *ParseError) GetPositions() []*Position {
+	if m != nil {
+		return m.Positions
+	}
+	return nil
+}
+
+type Position struct {
+	File                 string   `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Line                 int32    `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	Column               int32    `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	Offset               int32    `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Position) Reset()         { *m = Position{} }
+func (m *Position) String() string { return proto.CompactTextString(m) }
+func (*Position) ProtoMessage()    {}
+func (*Position) Descriptor() ([]byte, []int) {
+	return fileDescriptor_892c7f566756b0be, []int{8}
+}
+
+func (m *Position) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Position.Unmarshal(m, b)
+}
+func (m *Position) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Position.Marshal(b, m, deterministic)
+}
+func (m *Position) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Position.Merge(m, src)
+}
+func (m *Position) XXX_Size() int {
+	return xxx_messageInfo_Position.Size(m)
+}
+func (m *Position) XXX_DiscardUnknown() {
+	xxx_messageInfo_Position.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Position proto.InternalMessageInfo
+
+func (m *Position) GetFile() string {
+	if m != nil {
+		return m.File
+	}
+	return ""
+}
+
+func (m *Position) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *Position) GetColumn() int32 {
+	if m != nil {
+		return m.Column
+	}
+	return 0
+}
+
+func (m *Position) GetOffset() int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("workflowparser.Severity", Severity_name, Severity_value)
+	proto.RegisterType((*ParseResult)(nil), "workflowparser.ParseResult")
+	proto.RegisterType((*Configuration)(nil), "workflowparser.Configuration")
+	proto.RegisterMapType((map[string]string)(nil), "workflowparser.Configuration.EnvEntry")
+	proto.RegisterType((*Action)(nil), "workflowparser.Action")
+	proto.RegisterMapType((map[string]string)(nil), "workflowparser.Action.EnvEntry")
+	proto.RegisterMapType((map[string]*StringList)(nil), "workflowparser.Action.MatrixEntry")
+	proto.RegisterType((*Uses)(nil), "workflowparser.Uses")
+	proto.RegisterType((*Command)(nil), "workflowparser.Command")
+	proto.RegisterType((*StringList)(nil), "workflowparser.StringList")
+	proto.RegisterType((*Workflow)(nil), "workflowparser.Workflow")
+	proto.RegisterMapType((map[string]string)(nil), "workflowparser.Workflow.EnvEntry")
+	proto.RegisterType((*ParseError)(nil), "workflowparser.ParseError")
+	proto.RegisterType((*Position)(nil), "workflowparser.Position")
+}
+
+func init() { proto.RegisterFile("workflow.proto", fileDescriptor_892c7f566756b0be) }
+
+var fileDescriptor_892c7f566756b0be = []byte{
+	// 909 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xdd, 0x6e, 0xdb, 0x36,
+	0x14, 0xae, 0x64, 0xd9, 0xb1, 0x8f, 0x17, 0xcf, 0x23, 0x8c, 0x4e, 0x30, 0xb0, 0xcd, 0x13, 0x86,
+	0x21, 0x48, 0x51, 0x27, 0x4b, 0x8b, 0xa2, 0xe8, 0x5d, 0x9a, 0x39, 0x9b, 0x87, 0x2e, 0x09, 0x98,
+	0x66, 0xc5, 0x7a, 0x13, 0x28, 0x32, 0xe5, 0x12, 0x91, 0x49, 0x83, 0xa4, 0x9c, 0xf8, 0x66, 0xd8,
+	0xd5, 0xb0, 0x77, 0xda, 0x5b, 0xec, 0x55, 0xf6, 0x00, 0x1b, 0x78, 0x24, 0xfa, 0x27, 0x49, 0x53,
+	0x60, 0x68, 0xae, 0xf8, 0x1d, 0x7e, 0xdf, 0x21, 0xcf, 0xc7, 0xa3, 0xe3, 0x40, 0xeb, 0x4a, 0xaa,
+	0xcb, 0x34, 0x93, 0x57, 0xfd, 0xa9, 0x92, 0x46, 0x92, 0x05, 0x9e, 0xc6, 0x4a, 0x33, 0x15, 0xfd,
+	0xe1, 0x41, 0xf3, 0xc4, 0x2e, 0x29, 0xd3, 0x79, 0x66, 0xc8, 0x01, 0x6c, 0x26, 0x52, 0xa4, 0x7c,
+	0x9c, 0xab, 0xd8, 0x70, 0x29, 0x42, 0xaf, 0xe7, 0x6d, 0x35, 0xf7, 0xbe, 0xe8, 0xaf, 0xeb, 0xfa,
+	0x07, 0xab, 0x24, 0xba, 0xae, 0x21, 0x7b, 0x50, 0x63, 0x4a, 0x49, 0xa5, 0x43, 0xbf, 0x57, 0xd9,
+	0x6a, 0xee, 0x75, 0x6f, 0xaa, 0xf1, 0xc4, 0x81, 0xa5, 0xd0, 0x92, 0x19, 0xfd, 0xe5, 0xc3, 0xe6,
+	0x5a, 0x52, 0x12, 0xc2, 0xc6, 0x8c, 0x29, 0xed, 0x2e, 0x51, 0xa5, 0x0e, 0x92, 0x5d, 0xd8, 0x88,
+	0x13, 0xcb, 0x71, 0x07, 0x3c, 0xbc, 0x79, 0xc0, 0x3e, 0x6e, 0x53, 0x47, 0x23, 0xcf, 0xa0, 0xe1,
+	0x18, 0x3a, 0xac, 0xa0, 0x26, 0xbc, 0xa9, 0x79, 0x53, 0x42, 0xba, 0xa4, 0x92, 0xa7, 0xd0, 0x30,
+	0x6c, 0x32, 0xcd, 0x62, 0xc3, 0x74, 0x18, 0xdc, 0x7b, 0xd6, 0x92, 0x48, 0x9e, 0x43, 0x85, 0x89,
+	0x59, 0x58, 0x45, 0xfe, 0xb7, 0xf7, 0x5a, 0xd7, 0x1f, 0x88, 0xd9, 0x40, 0x18, 0x35, 0xa7, 0x56,
+	0xd2, 0x7d, 0x06, 0x75, 0x17, 0x20, 0x6d, 0xa8, 0x5c, 0xb2, 0x39, 0xd6, 0xde, 0xa0, 0x76, 0x49,
+	0x3a, 0x50, 0x9d, 0xc5, 0x59, 0xce, 0x42, 0x1f, 0x63, 0x05, 0x78, 0xe1, 0x3f, 0xf7, 0xa2, 0xbf,
+	0x03, 0xa8, 0x15, 0xf7, 0x20, 0x5f, 0x02, 0xf0, 0x11, 0x13, 0x86, 0xa7, 0x9c, 0xa9, 0x52, 0xbd,
+	0x12, 0x21, 0x3d, 0x68, 0x8e, 0x98, 0x4e, 0x14, 0x9f, 0xe2, 0xfb, 0x16, 0xa9, 0x56, 0x43, 0xd6,
+	0x78, 0x76, 0x6d, 0x98, 0x18, 0x59, 0xab, 0xec, 0xae, 0x83, 0x64, 0x0b, 0x82, 0x5c, 0xa3, 0x13,
+	0xb6, 0x29, 0x3a, 0x37, 0x2b, 0x3b, 0xd3, 0x4c, 0x53, 0x64, 0x90, 0x47, 0x10, 0xa8, 0x5c, 0xe8,
+	0xb0, 0x8a, 0xcc, 0xcf, 0x6f, 0x7b, 0x30, 0x99, 0xc4, 0x62, 0x44, 0x91, 0x64, 0xc9, 0xb1, 0x1a,
+	0xeb, 0xb0, 0xf6, 0x01, 0xb2, 0x25, 0x59, 0x13, 0x04, 0x63, 0x23, 0x1d, 0x6e, 0xf4, 0x2a, 0xd6,
+	0x04, 0x04, 0xe4, 0xbb, 0xc2, 0xf2, 0x3a, 0x5a, 0xfe, 0xd5, 0xdd, 0x4f, 0xb4, 0xee, 0x35, 0x79,
+	0x01, 0xb5, 0x49, 0x6c, 0x14, 0xbf, 0x0e, 0x1b, 0xa8, 0x8a, 0xde, 0xa3, 0xfa, 0x19, 0x49, 0x85,
+	0xb0, 0x54, 0x58, 0x8b, 0x34, 0x4b, 0x14, 0x33, 0x3a, 0x04, 0xbc, 0x86, 0x83, 0xa4, 0x05, 0x3e,
+	0x4f, 0xc3, 0x26, 0xfa, 0xe6, 0xf3, 0x94, 0x6c, 0xc3, 0x67, 0x89, 0x14, 0x86, 0x8b, 0x9c, 0x9d,
+	0x4b, 0x71, 0x8e, 0xdd, 0x1e, 0x7e, 0xd2, 0xf3, 0xb6, 0xea, 0xf4, 0x53, 0xb7, 0x71, 0x2c, 0xf0,
+	0x5b, 0xf8, 0xbf, 0xaf, 0xdf, 0x3d, 0x83, 0xe6, 0xca, 0x25, 0xef, 0x90, 0xee, 0xae, 0x4a, 0xef,
+	0xf8, 0x1e, 0x4f, 0x8d, 0xe2, 0x62, 0xfc, 0x8a, 0x6b, 0xb3, 0xda, 0x54, 0x7f, 0x7a, 0x10, 0xd8,
+	0x27, 0x25, 0x04, 0x82, 0x4b, 0x2e, 0x46, 0x65, 0x46, 0x5c, 0xdb, 0x43, 0x54, 0x7c, 0x55, 0xde,
+	0xc5, 0x2e, 0xed, 0xfd, 0xf8, 0x24, 0x1e, 0xb3, 0xb2, 0x69, 0x0a, 0x60, 0xdb, 0x51, 0xb1, 0xa9,
+	0xd4, 0xdc, 0x48, 0x35, 0xc7, 0xc6, 0x69, 0xd0, 0x95, 0x88, 0xcd, 0x3d, 0x8d, 0xcd, 0x3b, 0x6c,
+	0x94, 0x06, 0xc5, 0x35, 0xe6, 0x66, 0x69, 0x58, 0x2b, 0x73, 0xb3, 0x34, 0x7a, 0x0b, 0x1b, 0x65,
+	0x17, 0x90, 0x0e, 0x04, 0x86, 0x5d, 0x9b, 0xe2, 0x32, 0x3f, 0x3e, 0xa0, 0x88, 0xc8, 0x2e, 0x04,
+	0x19, 0xd7, 0xe6, 0xc3, 0x05, 0x5a, 0x85, 0x65, 0xbe, 0xdc, 0x28, 0x3d, 0x89, 0xbe, 0x01, 0x58,
+	0x6e, 0x93, 0x87, 0x50, 0xc3, 0xb0, 0x0e, 0x3d, 0x7c, 0xd8, 0x12, 0x45, 0xff, 0x78, 0x50, 0x77,
+	0x13, 0xe2, 0x23, 0x7c, 0x63, 0x2d, 0xf0, 0xa5, 0xc0, 0x49, 0xd4, 0xa0, 0xbe, 0x14, 0xa4, 0x0b,
+	0x75, 0xc5, 0xb4, 0xcc, 0x66, 0x4c, 0xe3, 0xdc, 0x68, 0xd0, 0x05, 0x26, 0x4f, 0x8a, 0xde, 0xae,
+	0x61, 0x97, 0x7e, 0xfd, 0xbe, 0xb1, 0xf5, 0x71, 0x26, 0xc9, 0x4f, 0x41, 0x3d, 0x68, 0x57, 0x69,
+	0x8d, 0xcd, 0x98, 0x30, 0x3a, 0xfa, 0xdd, 0x07, 0x58, 0x0e, 0x6b, 0xdb, 0xf6, 0x13, 0xa6, 0xb5,
+	0x7d, 0xe4, 0x22, 0x99, 0x83, 0xf6, 0x19, 0x13, 0x39, 0x72, 0xf9, 0x70, 0x4d, 0x9e, 0x42, 0x5d,
+	0xb3, 0x19, 0x53, 0xdc, 0xcc, 0xb1, 0x27, 0x5a, 0xb7, 0x67, 0xee, 0x69, 0xb9, 0x4f, 0x17, 0x4c,
+	0xb2, 0x0d, 0x95, 0xa9, 0x74, 0x23, 0xe6, 0x96, 0xe0, 0xc4, 0xf6, 0x8d, 0x1d, 0xb7, 0x96, 0x64,
+	0xcb, 0x48, 0xe6, 0x49, 0xc6, 0x4a, 0xcb, 0x0a, 0x80, 0x51, 0x99, 0x0b, 0x83, 0x0d, 0x54, 0xa5,
+	0x05, 0xb0, 0x3f, 0x01, 0xd3, 0x52, 0x5c, 0xcc, 0x8e, 0xfb, 0xb2, 0x2f, 0xa9, 0xd1, 0x05, 0xd4,
+	0x5d, 0xd8, 0x56, 0x99, 0xf2, 0xcc, 0x15, 0x8f, 0x6b, 0x1b, 0xcb, 0xb8, 0x28, 0x2a, 0xaf, 0x52,
+	0x5c, 0xdb, 0x26, 0x4a, 0x64, 0x96, 0x4f, 0x04, 0xd6, 0x5d, 0xa5, 0x25, 0xb2, 0x71, 0x99, 0xa6,
+	0x9a, 0x19, 0x2c, 0xaf, 0x4a, 0x4b, 0xb4, 0xfd, 0x1b, 0xd4, 0x9d, 0x13, 0x24, 0x84, 0xce, 0xe9,
+	0xe0, 0x97, 0x01, 0x1d, 0xbe, 0xfe, 0xf5, 0xfc, 0xec, 0xe8, 0xf4, 0x64, 0x70, 0x30, 0x3c, 0x1c,
+	0x0e, 0xbe, 0x6f, 0x3f, 0x20, 0x5d, 0xd8, 0x5c, 0xec, 0x0c, 0x8f, 0x0e, 0x8f, 0xdb, 0xff, 0xba,
+	0x3f, 0x8f, 0x74, 0xa0, 0xbd, 0xd8, 0x7b, 0xb3, 0x4f, 0x8f, 0x86, 0x47, 0x3f, 0xb4, 0x3d, 0x42,
+	0xa0, 0xb5, 0x88, 0x0e, 0x28, 0x3d, 0xa6, 0x6d, 0x7f, 0x2d, 0x76, 0xb8, 0xff, 0x7a, 0xff, 0x55,
+	0xbb, 0xf2, 0xf2, 0xf1, 0xdb, 0x47, 0x63, 0x6e, 0xde, 0xe5, 0x17, 0xfd, 0x44, 0x4e, 0x76, 0xca,
+	0x1f, 0xcd, 0x1d, 0x67, 0xce, 0xe3, 0xc2, 0x9d, 0x1d, 0xfc, 0x0f, 0xe2, 0x22, 0x4f, 0x2f, 0x6a,
+	0xb8, 0x7a, 0xf2, 0xdf, 0x00, 0x9f, 0x38, 0xfb, 0x43, 0x5d, 0x08, 0x00, 0x00,
+}
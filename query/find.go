@@ -0,0 +1,42 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// ActionsUsingRepo returns the actions in c that use repository, like
+// "actions/checkout", at any path and ref.  Repository names are
+// compared without regard to case, as GitHub compares them.
+func ActionsUsingRepo(c *model.Configuration, repository string) []*model.Action {
+	var ret []*model.Action
+	for _, a := range c.Actions {
+		if u, ok := a.Uses.(*model.UsesRepository); ok && strings.EqualFold(u.Repository, repository) {
+			ret = append(ret, a)
+		}
+	}
+	return ret
+}
+
+// ActionsWithSecret returns the actions in c that list secret in their
+// `secrets' attribute.
+func ActionsWithSecret(c *model.Configuration, secret string) []*model.Action {
+	var ret []*model.Action
+	for _, a := range c.Actions {
+		for _, s := range a.Secrets {
+			if s == secret {
+				ret = append(ret, a)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// WorkflowsOn returns the workflows in c that an event of the given type
+// starts, like "push" or "pull_request.opened", as
+// Workflow.IsTriggeredBy decides.
+func WorkflowsOn(c *model.Configuration, eventType string) []*model.Workflow {
+	return c.GetWorkflows(eventType)
+}
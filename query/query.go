@@ -0,0 +1,375 @@
+// Package query selects actions and workflows from configurations, for
+// audit scripts that ask the same question of many repositories' files:
+//
+//	q, err := query.Compile(`actions[uses.repo == 'actions/checkout' && secrets == 'GITHUB_TOKEN']`)
+//	...
+//	for _, config := range configs {
+//		for _, action := range q.Select(config).Actions {
+//			...
+//		}
+//	}
+//
+// A query names the kind of block it selects, `actions' or `workflows',
+// optionally followed by conditions in brackets, all of which a block
+// must meet.  A condition compares a field with a string in single or
+// double quotes: `==' and `!=' compare exactly, and `=~' and `!~' match
+// a regular expression, as the regexp package defines them, anywhere in
+// the field.  Fields that hold a list, like `needs', meet `==' and `=~'
+// if any element does, and `!=' and `!~' if none does.  A field the
+// block doesn't set, like `uses.ref' for a Docker image, is an empty
+// list.
+//
+// The fields of actions are `id', `uses' (as written), `uses.kind'
+// ("docker", "repository", "path", or "invalid"), `uses.repo',
+// `uses.path', `uses.ref', `uses.image', `needs', `runs', `args', `env'
+// (the names of the variables), and `secrets'.  The fields of workflows
+// are `id', `on' (each event, as written), `on.type', `on.filter', and
+// `resolves'.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// Result holds the blocks a query selected, in the order of the
+// configuration.  Only the slice for the kind of block the query names
+// is set.
+type Result struct {
+	Actions   []*model.Action
+	Workflows []*model.Workflow
+}
+
+// Query is a compiled query, which can select from any number of
+// configurations.
+type Query struct {
+	text       string
+	workflows  bool
+	conditions []condition
+}
+
+// condition is a single comparison, like `needs == 'build”.
+type condition struct {
+	field  string
+	negate bool
+	equals string
+	re     *regexp.Regexp
+}
+
+// Compile parses a query, returning an error that says where it went
+// wrong if the query is invalid.
+func Compile(query string) (*Query, error) {
+	l := &lexer{src: query}
+	q := &Query{text: query}
+
+	kind := l.word()
+	known := func(field string) bool {
+		_, ok := actionFields[field]
+		return ok
+	}
+	switch kind {
+	case "actions":
+	case "workflows":
+		q.workflows = true
+		known = func(field string) bool {
+			_, ok := workflowFields[field]
+			return ok
+		}
+	default:
+		return nil, l.errorAt(l.start, "expected `actions' or `workflows', got %s", l.describe(kind))
+	}
+
+	if l.done() {
+		return q, nil
+	}
+	if !l.consume("[") {
+		return nil, l.errorf("expected `[' after `%s'", kind)
+	}
+	for {
+		field := l.word()
+		if !known(field) {
+			return nil, l.errorAt(l.start, "unknown field %s for %s", l.describe(field), kind)
+		}
+		c := condition{field: field}
+
+		var op string
+		for _, o := range []string{"==", "!=", "=~", "!~"} {
+			if l.consume(o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return nil, l.errorf("expected `==', `!=', `=~', or `!~' after `%s'", field)
+		}
+		value, err := l.str()
+		if err != nil {
+			return nil, err
+		}
+		c.negate = op[0] == '!'
+		if op[1] == '~' {
+			if c.re, err = regexp.Compile(value); err != nil {
+				return nil, l.errorf("invalid regular expression `%s': %s", value, err)
+			}
+		} else {
+			c.equals = value
+		}
+		q.conditions = append(q.conditions, c)
+
+		if l.consume("]") {
+			break
+		}
+		if !l.consume("&&") {
+			return nil, l.errorf("expected `&&' or `]'")
+		}
+	}
+	if !l.done() {
+		return nil, l.errorf("unexpected `%s' after the query", strings.TrimSpace(l.src[l.pos:]))
+	}
+	return q, nil
+}
+
+// MustCompile is like Compile but panics if the query is invalid, for
+// queries fixed in a program.
+func MustCompile(query string) *Query {
+	q, err := Compile(query)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Select compiles query and selects from c with it.
+func Select(c *model.Configuration, query string) (Result, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return Result{}, err
+	}
+	return q.Select(c), nil
+}
+
+// Select returns the blocks of c that q selects.
+func (q *Query) Select(c *model.Configuration) Result {
+	var ret Result
+	if q.workflows {
+		for _, w := range c.Workflows {
+			if q.matches(func(field string) []string { return workflowFields[field](w) }) {
+				ret.Workflows = append(ret.Workflows, w)
+			}
+		}
+		return ret
+	}
+	for _, a := range c.Actions {
+		if q.matches(func(field string) []string { return actionFields[field](a) }) {
+			ret.Actions = append(ret.Actions, a)
+		}
+	}
+	return ret
+}
+
+// String returns the query as written.
+func (q *Query) String() string {
+	return q.text
+}
+
+// matches returns whether a block whose fields get returns meets every
+// condition of q.
+func (q *Query) matches(get func(field string) []string) bool {
+	for _, c := range q.conditions {
+		found := false
+		for _, v := range get(c.field) {
+			if c.re != nil && c.re.MatchString(v) || c.re == nil && v == c.equals {
+				found = true
+				break
+			}
+		}
+		if found == c.negate {
+			return false
+		}
+	}
+	return true
+}
+
+var actionFields = map[string]func(*model.Action) []string{
+	"id": func(a *model.Action) []string { return []string{a.Identifier} },
+	"uses": func(a *model.Action) []string {
+		if a.Uses == nil {
+			return nil
+		}
+		return []string{a.Uses.String()}
+	},
+	"uses.kind": func(a *model.Action) []string {
+		switch a.Uses.(type) {
+		case *model.UsesDockerImage:
+			return []string{"docker"}
+		case *model.UsesRepository:
+			return []string{"repository"}
+		case *model.UsesPath:
+			return []string{"path"}
+		case *model.UsesInvalid:
+			return []string{"invalid"}
+		}
+		return nil
+	},
+	"uses.repo": func(a *model.Action) []string {
+		if u, ok := a.Uses.(*model.UsesRepository); ok {
+			return []string{u.Repository}
+		}
+		return nil
+	},
+	"uses.path": func(a *model.Action) []string {
+		switch u := a.Uses.(type) {
+		case *model.UsesRepository:
+			if u.Path == "" {
+				return nil
+			}
+			return []string{u.Path}
+		case *model.UsesPath:
+			return []string{u.Path}
+		}
+		return nil
+	},
+	"uses.ref": func(a *model.Action) []string {
+		if u, ok := a.Uses.(*model.UsesRepository); ok {
+			return []string{u.Ref}
+		}
+		return nil
+	},
+	"uses.image": func(a *model.Action) []string {
+		if u, ok := a.Uses.(*model.UsesDockerImage); ok {
+			return []string{u.Image}
+		}
+		return nil
+	},
+	"needs": func(a *model.Action) []string { return a.Needs },
+	"runs":  func(a *model.Action) []string { return split(a.Runs) },
+	"args":  func(a *model.Action) []string { return split(a.Args) },
+	"env": func(a *model.Action) []string {
+		ret := make([]string, 0, len(a.Env))
+		for name := range a.Env {
+			ret = append(ret, name)
+		}
+		sort.Strings(ret)
+		return ret
+	},
+	"secrets": func(a *model.Action) []string { return a.Secrets },
+}
+
+var workflowFields = map[string]func(*model.Workflow) []string{
+	"id": func(w *model.Workflow) []string { return []string{w.Identifier} },
+	"on": func(w *model.Workflow) []string { return model.EventStrings(w.On) },
+	"on.type": func(w *model.Workflow) []string {
+		return model.EventTypes(w.On)
+	},
+	"on.filter": func(w *model.Workflow) []string {
+		var ret []string
+		for _, e := range w.On {
+			if e.Filter != "" {
+				ret = append(ret, e.Filter)
+			}
+		}
+		return ret
+	},
+	"resolves": func(w *model.Workflow) []string { return w.Resolves },
+}
+
+func split(c model.Command) []string {
+	if c == nil {
+		return nil
+	}
+	return c.Split()
+}
+
+// lexer reads the tokens of a query, skipping spaces between them.
+type lexer struct {
+	src string
+	pos int
+
+	// start is where the last word began, for errors about it.
+	start int
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+		l.pos++
+	}
+}
+
+func (l *lexer) done() bool {
+	l.skipSpace()
+	return l.pos == len(l.src)
+}
+
+// word reads a name made of letters, digits, `_', and `.', returning ""
+// if there is none.
+func (l *lexer) word() string {
+	l.skipSpace()
+	l.start = l.pos
+	for l.pos < len(l.src) {
+		c := rune(l.src[l.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.' {
+			break
+		}
+		l.pos++
+	}
+	return l.src[l.start:l.pos]
+}
+
+// consume reads s if it is next.
+func (l *lexer) consume(s string) bool {
+	l.skipSpace()
+	if strings.HasPrefix(l.src[l.pos:], s) {
+		l.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// str reads a string in single or double quotes, with `\' escaping the
+// quote or itself.
+func (l *lexer) str() (string, error) {
+	l.skipSpace()
+	if l.pos == len(l.src) || l.src[l.pos] != '\'' && l.src[l.pos] != '"' {
+		return "", l.errorf("expected a quoted string")
+	}
+	quote := l.src[l.pos]
+	var b strings.Builder
+	for i := l.pos + 1; i < len(l.src); i++ {
+		switch c := l.src[i]; {
+		case c == quote:
+			l.pos = i + 1
+			return b.String(), nil
+		case c == '\\' && i+1 < len(l.src) && (l.src[i+1] == quote || l.src[i+1] == '\\'):
+			b.WriteByte(l.src[i+1])
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", l.errorf("unterminated string")
+}
+
+// describe quotes a word read from the query for an error message.
+func (l *lexer) describe(word string) string {
+	if word == "" {
+		if l.pos == len(l.src) {
+			return "the end of the query"
+		}
+		return fmt.Sprintf("`%c'", l.src[l.pos])
+	}
+	return fmt.Sprintf("`%s'", word)
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return l.errorAt(l.pos, format, args...)
+}
+
+// errorAt returns an error about the query at offset pos.
+func (l *lexer) errorAt(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("query: column %d: %s", pos+1, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,121 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const workflow = `
+workflow "ci" { on = "push" resolves = ["deploy"] }
+workflow "review" { on = ["pull_request.opened", "pull_request.synchronize"] resolves = ["test"] }
+action "checkout" { uses = "actions/checkout@v1" }
+action "test" {
+  uses = "docker://golang:1.11"
+  needs = "checkout"
+  runs = ["go", "test", "./..."]
+  env = { GOFLAGS = "-mod=vendor" }
+}
+action "deploy" {
+  uses = "Actions/bin/sh@master"
+  needs = ["test"]
+  secrets = ["GITHUB_TOKEN", "DEPLOY_KEY"]
+}
+`
+
+func parse(t *testing.T) *model.Configuration {
+	config, err := parser.Parse(strings.NewReader(workflow))
+	require.NoError(t, err)
+	return config
+}
+
+func actionIDs(actions []*model.Action) []string {
+	ret := []string{}
+	for _, a := range actions {
+		ret = append(ret, a.Identifier)
+	}
+	return ret
+}
+
+func workflowIDs(workflows []*model.Workflow) []string {
+	ret := []string{}
+	for _, w := range workflows {
+		ret = append(ret, w.Identifier)
+	}
+	return ret
+}
+
+func TestSelect(t *testing.T) {
+	config := parse(t)
+	tests := []struct {
+		query     string
+		actions   []string
+		workflows []string
+	}{
+		{query: "actions", actions: []string{"checkout", "test", "deploy"}},
+		{query: `actions[uses.repo == 'actions/checkout']`, actions: []string{"checkout"}},
+		{query: `actions[ uses.kind == "repository" && secrets != 'GITHUB_TOKEN' ]`, actions: []string{"checkout"}},
+		{query: `actions[secrets == 'DEPLOY_KEY']`, actions: []string{"deploy"}},
+		{query: `actions[uses.ref =~ '^v\d+$']`, actions: []string{"checkout"}},
+		{query: `actions[uses.path == 'sh']`, actions: []string{"deploy"}},
+		{query: `actions[uses.image =~ 'golang' && env == 'GOFLAGS' && runs == 'test']`, actions: []string{"test"}},
+		{query: `actions[needs !~ '.']`, actions: []string{"checkout"}},
+		{query: `actions[uses == 'docker://golang:1.11']`, actions: []string{"test"}},
+		{query: `actions[id == 'nope']`, actions: []string{}},
+		{query: `workflows`, workflows: []string{"ci", "review"}},
+		{query: `workflows[on.type == 'pull_request' && on.filter == 'opened']`, workflows: []string{"review"}},
+		{query: `workflows[on == 'push' && resolves == 'deploy' && id != 'review']`, workflows: []string{"ci"}},
+		{query: `workflows[on.filter != 'opened']`, workflows: []string{"ci"}},
+	}
+	for _, tc := range tests {
+		res, err := Select(config, tc.query)
+		require.NoError(t, err, tc.query)
+		if tc.workflows != nil {
+			assert.Nil(t, res.Actions, tc.query)
+			assert.Equal(t, tc.workflows, workflowIDs(res.Workflows), tc.query)
+		} else {
+			assert.Nil(t, res.Workflows, tc.query)
+			assert.Equal(t, tc.actions, actionIDs(res.Actions), tc.query)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := map[string]string{
+		``:                                "query: column 1: expected `actions' or `workflows', got the end of the query",
+		`jobs`:                            "query: column 1: expected `actions' or `workflows', got `jobs'",
+		`actions[`:                        "query: column 9: unknown field the end of the query for actions",
+		`actions[on == 'push']`:           "query: column 9: unknown field `on' for actions",
+		`workflows[ uses == 'x']`:         "query: column 12: unknown field `uses' for workflows",
+		`actions (id == 'a')`:             "query: column 9: expected `[' after `actions'",
+		`actions[id = 'a']`:               "query: column 12: expected `==', `!=', `=~', or `!~' after `id'",
+		`actions[id == a]`:                "query: column 15: expected a quoted string",
+		`actions[id == 'a]`:               "query: column 15: unterminated string",
+		`actions[id == 'a' || id == 'b']`: "query: column 19: expected `&&' or `]'",
+		`actions[id =~ '(']`:              "query: column 18: invalid regular expression `(': error parsing regexp: missing closing ): `(`",
+		`actions[id == 'a'] extra`:        "query: column 20: unexpected `extra' after the query",
+	}
+	for query, msg := range tests {
+		_, err := Compile(query)
+		assert.EqualError(t, err, msg, query)
+	}
+
+	assert.Panics(t, func() { MustCompile("jobs") })
+	q := MustCompile(`actions[id == 'it\'s']`)
+	assert.Equal(t, `actions[id == 'it\'s']`, q.String())
+	assert.Equal(t, "it's", q.conditions[0].equals)
+}
+
+func TestFinders(t *testing.T) {
+	config := parse(t)
+	assert.Equal(t, []string{"checkout"}, actionIDs(ActionsUsingRepo(config, "actions/checkout")))
+	assert.Equal(t, []string{"deploy"}, actionIDs(ActionsUsingRepo(config, "actions/bin")))
+	assert.Equal(t, []string{"deploy"}, actionIDs(ActionsWithSecret(config, "GITHUB_TOKEN")))
+	assert.Empty(t, ActionsWithSecret(config, "github_token"))
+	assert.Equal(t, []string{"review"}, workflowIDs(WorkflowsOn(config, "pull_request.synchronize")))
+	assert.Equal(t, []string{"ci"}, workflowIDs(WorkflowsOn(config, "push")))
+}
@@ -0,0 +1,174 @@
+// Package refs resolves references between the blocks of a .workflow
+// file: from the `needs' and `resolves' entries that name an action to
+// the action's block, and back.  Editors use it for go-to-definition and
+// find-references, and refactoring tools to find what a change touches.
+package refs
+
+import (
+	"github.com/actions/workflow-parser/model"
+	"github.com/hashicorp/hcl/hcl/ast"
+	hclparser "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+// Span is the part of a file from Pos up to, but not including, End.
+type Span struct {
+	Pos, End model.Pos
+}
+
+// block is an action or workflow block.
+type block struct {
+	kind, id string
+	key      token.Token
+	span     Span
+}
+
+// reference is a `needs' or `resolves' entry.
+type reference struct {
+	id  string
+	tok token.Token
+}
+
+// index holds the blocks and references of a file.
+type index struct {
+	blocks []block
+	refs   []reference
+}
+
+// DefinitionAt returns the span of the block that defines what is at
+// pos in source, from its `action' or `workflow' keyword through its
+// closing brace.  Pos may be on a `needs' or `resolves' entry, which
+// leads to the action it names, or on the identifier of an action or
+// workflow, which leads to its own block.  Only pos's Line and Column
+// are used.  DefinitionAt returns false if there is no such block, or
+// source isn't valid HCL.
+func DefinitionAt(source []byte, pos model.Pos) (Span, bool) {
+	idx, ok := parse(source)
+	if !ok {
+		return Span{}, false
+	}
+	offset := pos.OffsetIn(source)
+
+	for _, b := range idx.blocks {
+		if contains(b.key, offset) {
+			return b.span, true
+		}
+	}
+	for _, r := range idx.refs {
+		if contains(r.tok, offset) {
+			return idx.action(r.id)
+		}
+	}
+	return Span{}, false
+}
+
+// ReferencesTo returns the span of every `needs' and `resolves' entry in
+// source that names the action actionID, including its quotes, in the
+// order they appear.  It returns nil if there are none, or source isn't
+// valid HCL.
+func ReferencesTo(source []byte, actionID string) []Span {
+	idx, ok := parse(source)
+	if !ok {
+		return nil
+	}
+
+	var ret []Span
+	for _, r := range idx.refs {
+		if r.id == actionID {
+			ret = append(ret, tokenSpan(source, r.tok))
+		}
+	}
+	return ret
+}
+
+// action returns the span of the first action block named id.
+func (idx *index) action(id string) (Span, bool) {
+	for _, b := range idx.blocks {
+		if b.kind == "action" && b.id == id {
+			return b.span, true
+		}
+	}
+	return Span{}, false
+}
+
+// parse indexes the blocks and references in source.
+func parse(source []byte) (*index, bool) {
+	file, err := hclparser.Parse(source)
+	if err != nil {
+		return nil, false
+	}
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, false
+	}
+
+	idx := &index{}
+	for _, item := range list.Items {
+		obj, ok := item.Val.(*ast.ObjectType)
+		if len(item.Keys) != 2 || !ok {
+			continue
+		}
+		kind := keyName(item.Keys[0].Token)
+		if kind != "action" && kind != "workflow" {
+			continue
+		}
+
+		end := obj.Rbrace
+		end.Offset++
+		idx.blocks = append(idx.blocks, block{
+			kind: kind,
+			id:   keyName(item.Keys[1].Token),
+			key:  item.Keys[1].Token,
+			span: Span{Pos: model.PosAt(source, item.Pos().Offset), End: model.PosAt(source, end.Offset)},
+		})
+
+		for _, attr := range obj.List.Items {
+			if len(attr.Keys) != 1 {
+				continue
+			}
+			name := keyName(attr.Keys[0].Token)
+			if kind == "action" && name == "needs" || kind == "workflow" && name == "resolves" {
+				idx.refs = append(idx.refs, references(attr.Val)...)
+			}
+		}
+	}
+	return idx, true
+}
+
+// references returns the string entries of a `needs' or `resolves'
+// value, which is a string or a list of them.
+func references(node ast.Node) []reference {
+	switch n := node.(type) {
+	case *ast.LiteralType:
+		if n.Token.Type == token.STRING {
+			return []reference{{id: keyName(n.Token), tok: n.Token}}
+		}
+	case *ast.ListType:
+		var ret []reference
+		for _, elem := range n.List {
+			ret = append(ret, references(elem)...)
+		}
+		return ret
+	}
+	return nil
+}
+
+// contains reports whether offset is in or just after t.
+func contains(t token.Token, offset int) bool {
+	return t.Pos.Offset <= offset && offset <= t.Pos.Offset+len(t.Text)
+}
+
+func tokenSpan(source []byte, t token.Token) Span {
+	return Span{Pos: model.PosAt(source, t.Pos.Offset), End: model.PosAt(source, t.Pos.Offset+len(t.Text))}
+}
+
+// keyName returns the text of an identifier or string token, without
+// quotes.
+func keyName(t token.Token) string {
+	if t.Type == token.STRING {
+		if s, ok := t.Value().(string); ok {
+			return s
+		}
+	}
+	return t.Text
+}
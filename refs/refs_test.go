@@ -0,0 +1,61 @@
+package refs
+
+import (
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const src = `workflow "w" {
+  on = "push"
+  resolves = ["b", "a"]
+}
+
+action "a" {
+  uses = "./a"
+}
+
+action "b" {
+  uses = "./b"
+  needs = "a"
+}
+`
+
+func TestDefinitionAt(t *testing.T) {
+	a := Span{Pos: model.Pos{Line: 6, Column: 1, Offset: 56}, End: model.Pos{Line: 8, Column: 2, Offset: 85}}
+
+	span, ok := DefinitionAt([]byte(src), model.Pos{Line: 3, Column: 21})
+	require.True(t, ok)
+	assert.Equal(t, a, span)
+
+	span, ok = DefinitionAt([]byte(src), model.Pos{Line: 12, Column: 12})
+	require.True(t, ok)
+	assert.Equal(t, a, span)
+
+	span, ok = DefinitionAt([]byte(src), model.Pos{Line: 6, Column: 9})
+	require.True(t, ok)
+	assert.Equal(t, a, span)
+
+	span, ok = DefinitionAt([]byte(src), model.Pos{Line: 1, Column: 11})
+	require.True(t, ok)
+	assert.Equal(t, model.Pos{Line: 1, Column: 1}, span.Pos)
+	assert.Equal(t, 4, span.End.Line)
+
+	_, ok = DefinitionAt([]byte(src), model.Pos{Line: 2, Column: 9})
+	assert.False(t, ok)
+	_, ok = DefinitionAt([]byte(`workflow "w" { resolves = "x" }`), model.Pos{Line: 1, Column: 28})
+	assert.False(t, ok)
+	_, ok = DefinitionAt([]byte(`action "a" {`), model.Pos{Line: 1, Column: 9})
+	assert.False(t, ok)
+}
+
+func TestReferencesTo(t *testing.T) {
+	assert.Equal(t, []Span{
+		{Pos: model.Pos{Line: 3, Column: 20, Offset: 48}, End: model.Pos{Line: 3, Column: 23, Offset: 51}},
+		{Pos: model.Pos{Line: 12, Column: 11, Offset: 125}, End: model.Pos{Line: 12, Column: 14, Offset: 128}},
+	}, ReferencesTo([]byte(src), "a"))
+	assert.Len(t, ReferencesTo([]byte(src), "b"), 1)
+	assert.Nil(t, ReferencesTo([]byte(src), "w"))
+}
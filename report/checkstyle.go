@@ -0,0 +1,76 @@
+// Package report prints parser diagnostics in formats meant for people
+// and for other tools.
+package report
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/actions/workflow-parser/parser"
+)
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr,omitempty"`
+}
+
+// WriteCheckstyle writes errs as a Checkstyle XML report, which many code
+// review tools, like reviewdog, read natively.  Errors are grouped by
+// file, in the order the files first appear in errs, and each error's
+// code is its source.  FATAL errors are reported as errors, since
+// Checkstyle has no equivalent.
+func WriteCheckstyle(w io.Writer, errs parser.ErrorList) error {
+	ret := checkstyleReport{Version: "4.3"}
+	files := make(map[string]int)
+	for _, e := range errs {
+		i, ok := files[e.Pos.File]
+		if !ok {
+			i = len(ret.Files)
+			files[e.Pos.File] = i
+			ret.Files = append(ret.Files, checkstyleFile{Name: e.Pos.File})
+		}
+		ret.Files[i].Errors = append(ret.Files[i].Errors, checkstyleError{
+			Line:     e.Pos.Line,
+			Column:   e.Pos.Column,
+			Severity: checkstyleSeverity(e.Severity),
+			Message:  e.Message(),
+			Source:   string(e.Code),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(ret); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func checkstyleSeverity(sev parser.Severity) string {
+	switch sev {
+	case parser.INFO:
+		return "info"
+	case parser.WARNING:
+		return "warning"
+	default:
+		return "error"
+	}
+}
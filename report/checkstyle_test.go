@@ -0,0 +1,51 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCheckstyle(t *testing.T) {
+	_, errs, err := parser.ParseWithDiagnostics(strings.NewReader(`action "a" {
+  uses = "./a"
+  needs = "b"
+  bogus = "x"
+}
+action "b" {
+  uses = "./b"
+  needs = "a"
+}
+`))
+	require.NoError(t, err)
+	for _, e := range errs {
+		e.Pos.File = "a.workflow"
+	}
+	errs = append(errs, parser.ErrorList{{Pos: parser.ErrorPos{File: "b.workflow", Line: 1, Column: 1}, Severity: parser.INFO}}...)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCheckstyle(&buf, errs))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="a.workflow">
+    <error line="4" column="11" severity="warning" message="Unknown action attribute `+"`bogus&#39;"+`" source="W_UNKNOWN_ATTRIBUTE"></error>
+    <error line="8" column="11" severity="error" message="Circular dependency on `+"`a&#39;: a -&gt; b -&gt; a"+`" source="E_CIRCULAR_DEPENDENCY"></error>
+  </file>
+  <file name="b.workflow">
+    <error line="1" column="1" severity="info" message=""></error>
+  </file>
+</checkstyle>
+`, buf.String())
+}
+
+func TestWriteCheckstyleEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCheckstyle(&buf, nil))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3"></checkstyle>
+`, buf.String())
+}
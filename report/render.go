@@ -0,0 +1,136 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/actions/workflow-parser/parser"
+)
+
+// ANSI escape sequences for Renderer.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// Renderer prints diagnostics for people to read, each followed by the
+// line of source it is about and a caret under its column:
+//
+//	a.workflow:3:11: error: Action `a' needs nonexistent action `b' [E_UNKNOWN_NEEDS]
+//	    3 |   needs = "b"
+//	      |           ^
+//
+// If Color is set, severities are colored with ANSI escape sequences.
+type Renderer struct {
+	Color bool
+}
+
+// Render prints errs, which are about source, with a Renderer that uses
+// color if w is a terminal and the NO_COLOR environment variable is not
+// set.
+func Render(w io.Writer, source []byte, errs parser.ErrorList) error {
+	color := false
+	if f, ok := w.(*os.File); ok {
+		color = IsTerminal(f) && os.Getenv("NO_COLOR") == ""
+	}
+	return Renderer{Color: color}.Render(w, source, errs)
+}
+
+// IsTerminal returns whether f is a terminal, or some other character
+// device.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Render prints errs, which are about source.  Errors without a line, or
+// with a line past the end of source, are printed without source.
+func (r Renderer) Render(w io.Writer, source []byte, errs parser.ErrorList) error {
+	lines := bytes.Split(source, []byte("\n"))
+	var buf bytes.Buffer
+	for _, e := range errs {
+		r.header(&buf, e)
+		if e.Pos.Line > 0 && e.Pos.Line <= len(lines) {
+			line := strings.TrimRight(string(lines[e.Pos.Line-1]), "\r")
+			num := fmt.Sprint(e.Pos.Line)
+			gutter := strings.Repeat(" ", len(num))
+			fmt.Fprintf(&buf, "  %s | %s\n", num, line)
+			if e.Pos.Column > 0 {
+				fmt.Fprintf(&buf, "  %s | %s%s\n", gutter, caretIndent(line, e.Pos.Column), r.paint("^", severityColor(e.Severity)))
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// header prints the first line of e: its position, severity, message,
+// code, and how many times it occurred, if it stands for several.
+func (r Renderer) header(buf *bytes.Buffer, e *parser.ParseError) {
+	var pos string
+	if e.Pos.File != "" {
+		pos = e.Pos.File + ":"
+	}
+	if e.Pos.Line > 0 {
+		pos += fmt.Sprintf("%d:", e.Pos.Line)
+		if e.Pos.Column > 0 {
+			pos += fmt.Sprintf("%d:", e.Pos.Column)
+		}
+	}
+	if pos != "" {
+		buf.WriteString(r.paint(pos, ansiBold))
+		buf.WriteString(" ")
+	}
+	buf.WriteString(r.paint(e.Severity.String()+":", severityColor(e.Severity)))
+	buf.WriteString(" ")
+	buf.WriteString(e.Message())
+	if e.Code != "" {
+		fmt.Fprintf(buf, " [%s]", e.Code)
+	}
+	if e.Count > 1 {
+		fmt.Fprintf(buf, " (%d times)", e.Count)
+	}
+	buf.WriteString("\n")
+}
+
+func (r Renderer) paint(s, color string) string {
+	if !r.Color {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// caretIndent returns the whitespace that puts a caret under the given
+// column of line, counted in runes as the parser counts them.  Tabs are
+// kept, so that the caret lines up however wide they are.
+func caretIndent(line string, column int) string {
+	var sb strings.Builder
+	for i, n := 0, 1; i < len(line) && n < column; n++ {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if r == '\t' {
+			sb.WriteByte('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+func severityColor(sev parser.Severity) string {
+	switch sev {
+	case parser.INFO:
+		return ansiCyan
+	case parser.WARNING:
+		return ansiYellow
+	default:
+		return ansiRed
+	}
+}
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	src := "action \"a\" {\n  uses = \"./a\"\n\tneeds = \"b\"\n  bögus = \"x\"\n}\n"
+	_, errs, err := parser.ParseWithDiagnostics(strings.NewReader(src))
+	require.NoError(t, err)
+	errs = append(errs, &parser.ParseError{Severity: parser.INFO})
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, []byte(src), errs))
+	assert.Equal(t, "3:10: error: Action `a' needs nonexistent action `b' [E_UNKNOWN_NEEDS]\n"+
+		"  3 | \tneeds = \"b\"\n"+
+		"    | \t        ^\n"+
+		"4:11: warning: Unknown action attribute `bögus' [W_UNKNOWN_ATTRIBUTE]\n"+
+		"  4 |   bögus = \"x\"\n"+
+		"    |           ^\n"+
+		"info: \n", buf.String())
+
+	errs[0].Pos.File = "a.workflow"
+	buf.Reset()
+	require.NoError(t, Renderer{Color: true}.Render(&buf, []byte(src), errs[:1]))
+	assert.Equal(t, "\x1b[1ma.workflow:3:10:\x1b[0m \x1b[31merror:\x1b[0m Action `a' needs nonexistent action `b' [E_UNKNOWN_NEEDS]\n"+
+		"  3 | \tneeds = \"b\"\n"+
+		"    | \t        \x1b[31m^\x1b[0m\n", buf.String())
+}
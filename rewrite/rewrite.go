@@ -0,0 +1,104 @@
+// Package rewrite changes a parsed configuration programmatically,
+// keeping the references between its actions and workflows consistent.
+// Print the result with the printer package to get the modified
+// .workflow file; comments the parser attached to the configuration are
+// kept.
+//
+//	config, err := parser.Parse(r)
+//	...
+//	if err := rewrite.RenameAction(config, "build", "compile"); err != nil {
+//		...
+//	}
+//	err = printer.Write(w, config)
+package rewrite
+
+import (
+	"fmt"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// AddAction appends a to c.  It fails if a's identifier is blank or
+// already names an action or workflow in c.
+func AddAction(c *model.Configuration, a *model.Action) error {
+	if err := checkNewIdentifier(c, a.Identifier); err != nil {
+		return err
+	}
+	c.Actions = append(c.Actions, a)
+	return nil
+}
+
+// RemoveAction removes the action with the given identifier from c,
+// along with every `needs' and `resolves' reference to it.  It fails if
+// there is no such action.
+func RemoveAction(c *model.Configuration, id string) error {
+	i := actionIndex(c, id)
+	if i < 0 {
+		return fmt.Errorf("no action `%s'", id)
+	}
+	c.Actions = append(c.Actions[:i], c.Actions[i+1:]...)
+
+	for _, action := range c.Actions {
+		action.Needs = remove(action.Needs, id)
+	}
+	for _, workflow := range c.Workflows {
+		workflow.Resolves = remove(workflow.Resolves, id)
+	}
+	return nil
+}
+
+// SetEnv sets the environment variable key to value in the action with
+// the given identifier.  It fails if there is no such action.
+func SetEnv(c *model.Configuration, actionID, key, value string) error {
+	action := c.GetAction(actionID)
+	if action == nil {
+		return fmt.Errorf("no action `%s'", actionID)
+	}
+	if action.Env == nil {
+		action.Env = make(map[string]string)
+	}
+	action.Env[key] = value
+	return nil
+}
+
+// RenameAction changes the identifier of an action from oldID to newID,
+// along with every `needs' and `resolves' reference to it.  See
+// model.Configuration.RenameAction.
+func RenameAction(c *model.Configuration, oldID, newID string) error {
+	return c.RenameAction(oldID, newID)
+}
+
+// checkNewIdentifier returns an error if id can't be given to a new
+// action or workflow in c.  Actions and workflows share a namespace.
+func checkNewIdentifier(c *model.Configuration, id string) error {
+	switch {
+	case id == "":
+		return fmt.Errorf("identifiers cannot be blank")
+	case c.GetAction(id) != nil:
+		return fmt.Errorf("action `%s' already exists", id)
+	case c.GetWorkflow(id) != nil:
+		return fmt.Errorf("workflow `%s' already exists", id)
+	}
+	return nil
+}
+
+func actionIndex(c *model.Configuration, id string) int {
+	for i, action := range c.Actions {
+		if action.Identifier == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove returns list without any occurrence of s, or nil if nothing is
+// left.
+func remove(list []string, s string) []string {
+	var ret []string
+	for _, v := range list {
+		if v != s {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,100 @@
+package rewrite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/actions/workflow-parser/printer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T) *model.Configuration {
+	config, err := parser.Parse(strings.NewReader(`
+workflow "w" {
+  on = "push"
+  resolves = ["b", "c"]
+}
+
+action "a" {
+  uses = "./a"
+}
+
+action "b" {
+  # b needs a
+  needs = ["a"]
+  uses = "./b"
+}
+
+action "c" {
+  needs = ["a"]
+  uses = "./c"
+}
+`))
+	require.NoError(t, err)
+	return config
+}
+
+func TestAddAction(t *testing.T) {
+	config := parse(t)
+	require.NoError(t, AddAction(config, &model.Action{Identifier: "d", Uses: &model.UsesPath{Path: "d"}, Needs: []string{"c"}}))
+	assert.NotNil(t, config.GetAction("d"))
+
+	assert.EqualError(t, AddAction(config, &model.Action{Identifier: "a"}), "action `a' already exists")
+	assert.EqualError(t, AddAction(config, &model.Action{Identifier: "w"}), "workflow `w' already exists")
+	assert.EqualError(t, AddAction(config, &model.Action{}), "identifiers cannot be blank")
+	assert.Len(t, config.Actions, 4)
+}
+
+func TestRemoveAction(t *testing.T) {
+	config := parse(t)
+	require.NoError(t, RemoveAction(config, "b"))
+	assert.Nil(t, config.GetAction("b"))
+	assert.Equal(t, []string{"a"}, config.GetAction("c").Needs)
+	assert.Equal(t, []string{"c"}, config.Workflows[0].Resolves)
+
+	require.NoError(t, RemoveAction(config, "a"))
+	assert.Nil(t, config.GetAction("c").Needs)
+
+	assert.EqualError(t, RemoveAction(config, "a"), "no action `a'")
+}
+
+func TestSetEnv(t *testing.T) {
+	config := parse(t)
+	require.NoError(t, SetEnv(config, "a", "X", "1"))
+	require.NoError(t, SetEnv(config, "a", "X", "2"))
+	assert.Equal(t, map[string]string{"X": "2"}, config.GetAction("a").Env)
+	assert.EqualError(t, SetEnv(config, "z", "X", "1"), "no action `z'")
+}
+
+func TestRenameAction(t *testing.T) {
+	config := parse(t)
+	require.NoError(t, RenameAction(config, "a", "setup"))
+	assert.Equal(t, `workflow "w" {
+  on = "push"
+  resolves = ["b", "c"]
+}
+
+action "setup" {
+  uses = "./a"
+}
+
+action "b" {
+  uses = "./b"
+  # b needs a
+  needs = ["setup"]
+}
+
+action "c" {
+  uses = "./c"
+  needs = ["setup"]
+}
+`, printer.String(config))
+
+	assert.EqualError(t, RenameAction(config, "a", "x"), "no action `a'")
+	assert.EqualError(t, RenameAction(config, "b", "c"), "action `c' already exists at line 17")
+	assert.EqualError(t, RenameAction(config, "b", "w"), "workflow `w' already exists at line 2")
+	assert.NotNil(t, config.GetAction("b"))
+}
@@ -0,0 +1,109 @@
+// Package symbols describes the structure of a parsed .workflow file as a
+// tree of symbols, for editors to show in an outline pane or breadcrumbs
+// without reading the HCL themselves.
+package symbols
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/actions/workflow-parser/model"
+)
+
+// Kind is the kind of thing a Symbol names.
+type Kind int
+
+const (
+	// Action is an action block.
+	Action Kind = iota
+
+	// Workflow is a workflow block.
+	Workflow
+
+	// Attribute is an attribute of an action or workflow.
+	Attribute
+)
+
+var kindNames = [...]string{
+	Action:    "action",
+	Workflow:  "workflow",
+	Attribute: "attribute",
+}
+
+// String returns the name of the kind, e.g., "action".
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Symbol is an action, a workflow, or one of their key attributes.  Pos
+// and End span the symbol: from its `action' or `workflow' keyword to its
+// closing brace for blocks, and just the attribute name for attributes,
+// whose End is the zero Pos.  Detail is a one-line summary of the
+// symbol, e.g., an action's `uses' value or a workflow's event.
+type Symbol struct {
+	Name     string
+	Kind     Kind
+	Detail   string
+	Pos      model.Pos
+	End      model.Pos
+	Children []Symbol
+}
+
+// Outline returns a symbol for each action and workflow in c, in the
+// order they appear in the file, with a child for each key attribute
+// they set: `uses' and `needs' for actions, and `on' and `resolves' for
+// workflows.  Blocks and attributes without positions, e.g., those of a
+// configuration not read from a file, come after those with positions, in
+// the order c lists them.
+func Outline(c *model.Configuration) []Symbol {
+	ret := make([]Symbol, 0, len(c.Actions)+len(c.Workflows))
+	for _, a := range c.Actions {
+		ret = append(ret, actionSymbol(a))
+	}
+	for _, w := range c.Workflows {
+		ret = append(ret, workflowSymbol(w))
+	}
+	sortByPos(ret)
+	return ret
+}
+
+func actionSymbol(a *model.Action) Symbol {
+	s := Symbol{Name: a.Identifier, Kind: Action, Pos: a.Pos, End: a.End}
+	if a.Uses != nil {
+		s.Detail = a.Uses.String()
+		s.Children = append(s.Children, attribute("uses", s.Detail, a.AttributePos("uses")))
+	}
+	if len(a.Needs) > 0 {
+		s.Children = append(s.Children, attribute("needs", strings.Join(a.Needs, ", "), a.AttributePos("needs")))
+	}
+	sortByPos(s.Children)
+	return s
+}
+
+func workflowSymbol(w *model.Workflow) Symbol {
+	s := Symbol{Name: w.Identifier, Kind: Workflow, Pos: w.Pos, End: w.End}
+	if len(w.On) > 0 {
+		s.Detail = strings.Join(model.EventStrings(w.On), ", ")
+		s.Children = append(s.Children, attribute("on", s.Detail, w.AttributePos("on")))
+	}
+	if len(w.Resolves) > 0 {
+		s.Children = append(s.Children, attribute("resolves", strings.Join(w.Resolves, ", "), w.AttributePos("resolves")))
+	}
+	sortByPos(s.Children)
+	return s
+}
+
+func attribute(name, detail string, pos model.Pos) Symbol {
+	return Symbol{Name: name, Kind: Attribute, Detail: detail, Pos: pos}
+}
+
+// sortByPos sorts symbols by position, keeping those without one last.
+func sortByPos(symbols []Symbol) {
+	sort.SliceStable(symbols, func(i, j int) bool {
+		pi, pj := symbols[i].Pos, symbols[j].Pos
+		if !pi.IsValid() || !pj.IsValid() {
+			return pi.IsValid() && !pj.IsValid()
+		}
+		return pi.Offset < pj.Offset
+	})
+}
@@ -0,0 +1,74 @@
+package symbols
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actions/workflow-parser/model"
+	"github.com/actions/workflow-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutline(t *testing.T) {
+	c, err := parser.Parse(strings.NewReader(`workflow "ci" {
+  resolves = ["b", "c"]
+  on = "push"
+}
+
+action "b" {
+  needs = "a"
+  uses = "docker://alpine"
+}
+
+action "a" {
+  uses = "./a"
+}
+
+action "c" {
+  uses = "./c"
+}
+`))
+	require.NoError(t, err)
+
+	syms := Outline(c)
+	require.Len(t, syms, 4)
+
+	w := syms[0]
+	assert.Equal(t, "ci", w.Name)
+	assert.Equal(t, Workflow, w.Kind)
+	assert.Equal(t, "push", w.Detail)
+	assert.Equal(t, model.Pos{Line: 1, Column: 1}, w.Pos)
+	assert.Equal(t, model.Pos{Line: 4, Column: 1, Offset: 54}, w.End)
+	assert.Equal(t, []Symbol{
+		{Name: "resolves", Kind: Attribute, Detail: "b, c", Pos: model.Pos{Line: 2, Column: 3, Offset: 18}},
+		{Name: "on", Kind: Attribute, Detail: "push", Pos: model.Pos{Line: 3, Column: 3, Offset: 42}},
+	}, w.Children)
+
+	b := syms[1]
+	assert.Equal(t, "b", b.Name)
+	assert.Equal(t, Action, b.Kind)
+	assert.Equal(t, "docker://alpine", b.Detail)
+	assert.Equal(t, 6, b.Pos.Line)
+	assert.Equal(t, 9, b.End.Line)
+	require.Len(t, b.Children, 2)
+	assert.Equal(t, "needs", b.Children[0].Name)
+	assert.Equal(t, "a", b.Children[0].Detail)
+	assert.Equal(t, "uses", b.Children[1].Name)
+
+	assert.Equal(t, "a", syms[2].Name)
+	assert.Equal(t, "./a", syms[2].Detail)
+}
+
+func TestOutlineWithoutPositions(t *testing.T) {
+	c := &model.Configuration{
+		Actions:   []*model.Action{{Identifier: "a", Uses: &model.UsesPath{Path: "a"}}},
+		Workflows: []*model.Workflow{{Identifier: "w", On: model.ParseEvents("push")}},
+	}
+	syms := Outline(c)
+	require.Len(t, syms, 2)
+	assert.Equal(t, "a", syms[0].Name)
+	assert.Equal(t, "w", syms[1].Name)
+	assert.Equal(t, "push", syms[1].Detail)
+	assert.Equal(t, Workflow.String(), "workflow")
+}
@@ -0,0 +1,70 @@
+// Package webhook reads the parts of a GitHub webhook delivery that
+// decide which workflows it triggers: the event type, the activity, and
+// the branch.
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Event is a webhook delivery, reduced to what matters for triggering
+// workflows.
+type Event struct {
+	// Type is the event type, from the X-GitHub-Event header, like
+	// "push" or "pull_request".
+	Type string
+
+	// Action is the activity, from the `action' field of the payload,
+	// like "opened".  Many event types have none.
+	Action string
+
+	// Branch is the branch the event is about, if any: the branch pushed
+	// to, or the base branch of a pull request.
+	Branch string
+
+	// Schedule, for a "schedule" event, is the cron expression that
+	// fired, like "*/15 * * * *".
+	Schedule string
+}
+
+// payload holds the fields of a webhook payload that Parse reads.
+type payload struct {
+	Action      string `json:"action"`
+	Ref         string `json:"ref"`
+	Schedule    string `json:"schedule"`
+	PullRequest *struct {
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+}
+
+// Parse reads the payload of a webhook delivery of the given type, as
+// named in its X-GitHub-Event header.  It returns an error only if
+// payload isn't a JSON object; fields it doesn't find are left blank.
+func Parse(eventType string, body []byte) (Event, error) {
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return Event{}, err
+	}
+
+	ret := Event{Type: eventType, Action: p.Action, Schedule: p.Schedule}
+	switch {
+	case p.PullRequest != nil:
+		ret.Branch = p.PullRequest.Base.Ref
+	case strings.HasPrefix(p.Ref, "refs/heads/"):
+		ret.Branch = strings.TrimPrefix(p.Ref, "refs/heads/")
+	}
+	return ret, nil
+}
+
+// String returns the event as a type and activity, like
+// "pull_request.opened", or just the type if there is no activity.  This
+// is the form model.IsMatchingEventType expects.
+func (e Event) String() string {
+	if e.Action == "" {
+		return e.Type
+	}
+	return e.Type + "." + e.Action
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	e, err := Parse("push", []byte(`{"ref": "refs/heads/main", "after": "abc"}`))
+	require.NoError(t, err)
+	assert.Equal(t, Event{Type: "push", Branch: "main"}, e)
+	assert.Equal(t, "push", e.String())
+
+	e, err = Parse("push", []byte(`{"ref": "refs/tags/v1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, Event{Type: "push"}, e)
+
+	e, err = Parse("pull_request", []byte(`{"action": "opened", "pull_request": {"base": {"ref": "release"}, "head": {"ref": "feature"}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, Event{Type: "pull_request", Action: "opened", Branch: "release"}, e)
+	assert.Equal(t, "pull_request.opened", e.String())
+
+	e, err = Parse("schedule", []byte(`{"schedule": "*/15 * * * *"}`))
+	require.NoError(t, err)
+	assert.Equal(t, Event{Type: "schedule", Schedule: "*/15 * * * *"}, e)
+
+	_, err = Parse("push", []byte(`[]`))
+	assert.Error(t, err)
+}